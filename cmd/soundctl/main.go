@@ -1,28 +1,156 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"sync"
+	"time"
 
+	"github.com/spf13/cobra"
 	"soundctl/pkg/cmd"
 	"soundctl/pkg/soundctl/audio"
 	"soundctl/pkg/soundctl/bluetooth"
+	"soundctl/pkg/soundctl/daemon"
 	sexec "soundctl/pkg/soundctl/exec"
 	"soundctl/pkg/soundctl/preset"
 )
 
 func main() {
-	runner := sexec.NewOSRunner()
+	b := &backends{runner: sexec.NewOSRunner()}
 	rootCmd, err := cmd.NewRootCommand(cmd.Dependencies{
-		Bluetooth:   bluetooth.NewExecService(runner),
-		Audio:       audio.NewExecService(runner),
+		Bluetooth:   bluetooth.NewLazy(b.bluetooth),
+		Audio:       audio.NewLazy(b.audio),
 		PresetStore: preset.NewStore(""),
+		Streamer:    sexec.NewOSStreamer(),
 	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to initialize root command: %v\n", err)
 		os.Exit(1)
 	}
+	// Only now is the command known; the backends connect when it first
+	// uses them.
+	preRun := rootCmd.PersistentPreRunE
+	rootCmd.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+		b.direct = isDaemonCommand(c)
+		return preRun(c, args)
+	}
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
 }
+
+// backends connects the audio and bluetooth services on first use: through
+// a running `soundctl daemon` if one answers, directly otherwise.
+type backends struct {
+	runner sexec.Runner
+	direct bool // the command is `daemon` itself, which needs the real backends
+
+	once   sync.Once
+	client *daemon.Client
+}
+
+func (b *backends) audio(ctx context.Context) (audio.Service, error) {
+	if client := b.daemon(); client != nil {
+		return client.Audio(), nil
+	}
+	svc, err := newAudioService(ctx, b.runner)
+	if err != nil {
+		return nil, fmt.Errorf("initialize audio backend: %w", err)
+	}
+	return svc, nil
+}
+
+func (b *backends) bluetooth(ctx context.Context) (bluetooth.Service, error) {
+	if client := b.daemon(); client != nil {
+		return client.Bluetooth(), nil
+	}
+	svc, err := newBluetoothService(ctx, b.runner)
+	if err != nil {
+		return nil, fmt.Errorf("initialize bluetooth backend: %w", err)
+	}
+	return svc, nil
+}
+
+// daemon connects to a running `soundctl daemon` so commands go through
+// it, unless SOUNDCTL_DAEMON=off or the command is `daemon` itself. It
+// dials once and returns nil when no daemon answers.
+func (b *backends) daemon() *daemon.Client {
+	b.once.Do(func() {
+		if b.direct || os.Getenv("SOUNDCTL_DAEMON") == "off" {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		if client, err := daemon.Dial(ctx, ""); err == nil {
+			b.client = client
+		}
+	})
+	return b.client
+}
+
+// isDaemonCommand reports whether c is `soundctl daemon` or one of its
+// subcommands.
+func isDaemonCommand(c *cobra.Command) bool {
+	for ; c.HasParent(); c = c.Parent() {
+		if c.Name() == "daemon" && !c.Parent().HasParent() {
+			return true
+		}
+	}
+	return false
+}
+
+// newAudioService picks the audio backend from SOUNDCTL_AUDIO_BACKEND:
+// "native" (PulseAudio protocol socket), "exec" (pactl), or "auto" (the
+// default), which tries native and falls back to exec.
+func newAudioService(ctx context.Context, runner sexec.Runner) (audio.Service, error) {
+	backend := os.Getenv("SOUNDCTL_AUDIO_BACKEND")
+	if backend == "" {
+		backend = "auto"
+	}
+	switch backend {
+	case "exec":
+		return audio.NewExecService(runner), nil
+	case "native", "auto":
+		ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		svc, err := audio.NewNativeService(ctx, "")
+		if err == nil {
+			return svc, nil
+		}
+		if backend == "native" {
+			return nil, err
+		}
+		return audio.NewExecService(runner), nil
+	default:
+		return nil, fmt.Errorf("invalid SOUNDCTL_AUDIO_BACKEND %q: expected auto, native or exec", backend)
+	}
+}
+
+// newBluetoothService picks the bluetooth backend from
+// SOUNDCTL_BLUETOOTH_BACKEND: "dbus" (org.bluez on the system bus), "exec"
+// (bluetoothctl), or "auto" (the default), which tries dbus and falls back
+// to exec.
+func newBluetoothService(ctx context.Context, runner sexec.Runner) (bluetooth.Service, error) {
+	backend := os.Getenv("SOUNDCTL_BLUETOOTH_BACKEND")
+	if backend == "" {
+		backend = "auto"
+	}
+	switch backend {
+	case "exec":
+		return bluetooth.NewExecService(runner), nil
+	case "dbus", "auto":
+		ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		bus, err := bluetooth.ConnectSystemBus(ctx)
+		if err == nil {
+			return bluetooth.NewDBusService(bus), nil
+		}
+		if backend == "dbus" {
+			return nil, err
+		}
+		return bluetooth.NewExecService(runner), nil
+	default:
+		return nil, fmt.Errorf("invalid SOUNDCTL_BLUETOOTH_BACKEND %q: expected auto, dbus or exec", backend)
+	}
+}
@@ -0,0 +1,168 @@
+package alias
+
+import (
+	"context"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"soundctl/pkg/cmd/common"
+	"soundctl/pkg/soundctl/alias"
+)
+
+// ── list ────────────────────────────────────────────────────────────────────
+
+type listCommand struct {
+	*cmds.CommandDescription
+	store *alias.Store
+}
+
+func newListCommand(store *alias.Store) (*listCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &listCommand{
+		CommandDescription: cmds.NewCommandDescription("list",
+			cmds.WithShort("List device aliases"),
+			cmds.WithSections(sections...),
+		),
+		store: store,
+	}, nil
+}
+
+func (c *listCommand) RunIntoGlazeProcessor(ctx context.Context, _ *values.Values, gp middlewares.Processor) error {
+	aliases, err := c.store.List()
+	if err != nil {
+		return err
+	}
+	for _, a := range aliases {
+		if err := gp.AddRow(ctx, types.NewRow(
+			types.MRP("name", a.Name),
+			types.MRP("target", a.Target),
+		)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ── add ─────────────────────────────────────────────────────────────────────
+
+type addSettings struct {
+	Name   string `glazed:"name"`
+	Target string `glazed:"target"`
+}
+
+type addCommand struct {
+	*cmds.CommandDescription
+	store *alias.Store
+}
+
+func newAddCommand(store *alias.Store) (*addCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &addCommand{
+		CommandDescription: cmds.NewCommandDescription("add",
+			cmds.WithShort("Add or retarget a device alias"),
+			cmds.WithLong("Maps a friendly name to a bluetooth address or a sink, source or card name. "+
+				"Aliases are accepted wherever a device, sink, source or card is expected and shown in the TUI. "+
+				"An alias of a bluetooth address covers the device's sinks, sources and card."),
+			cmds.WithFlags(
+				fields.New("name", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Alias, e.g. airpods")),
+				fields.New("target", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Bluetooth address or sink/source/card name")),
+			),
+			cmds.WithSections(sections...),
+		),
+		store: store,
+	}, nil
+}
+
+func (c *addCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &addSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	if err := c.store.Add(s.Name, s.Target); err != nil {
+		return err
+	}
+	return gp.AddRow(ctx, types.NewRow(
+		types.MRP("operation", "alias.add"),
+		types.MRP("name", s.Name),
+		types.MRP("target", s.Target),
+		types.MRP("ok", true),
+	))
+}
+
+// ── remove ──────────────────────────────────────────────────────────────────
+
+type removeSettings struct {
+	Name string `glazed:"name"`
+}
+
+type removeCommand struct {
+	*cmds.CommandDescription
+	store *alias.Store
+}
+
+func newRemoveCommand(store *alias.Store) (*removeCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &removeCommand{
+		CommandDescription: cmds.NewCommandDescription("remove",
+			cmds.WithShort("Remove a device alias"),
+			cmds.WithFlags(
+				fields.New("name", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Alias to remove")),
+			),
+			cmds.WithSections(sections...),
+		),
+		store: store,
+	}, nil
+}
+
+func (c *removeCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &removeSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	if err := c.store.Remove(s.Name); err != nil {
+		return err
+	}
+	return gp.AddRow(ctx, types.NewRow(
+		types.MRP("operation", "alias.remove"),
+		types.MRP("name", s.Name),
+		types.MRP("ok", true),
+	))
+}
+
+func Register(parent *cobra.Command, store *alias.Store) error {
+	listCmd, err := newListCommand(store)
+	if err != nil {
+		return err
+	}
+	addCmd, err := newAddCommand(store)
+	if err != nil {
+		return err
+	}
+	removeCmd, err := newRemoveCommand(store)
+	if err != nil {
+		return err
+	}
+	for _, command := range []cmds.Command{listCmd, addCmd, removeCmd} {
+		cobraCmd, err := common.BuildCobra(command)
+		if err != nil {
+			return err
+		}
+		parent.AddCommand(cobraCmd)
+	}
+	return nil
+}
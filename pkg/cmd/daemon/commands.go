@@ -0,0 +1,309 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"soundctl/pkg/cmd/common"
+	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/bluetooth"
+	sdaemon "soundctl/pkg/soundctl/daemon"
+	sexec "soundctl/pkg/soundctl/exec"
+	"soundctl/pkg/soundctl/preset"
+	"soundctl/pkg/soundctl/resolve"
+	"soundctl/pkg/soundctl/rules"
+	"soundctl/pkg/soundctl/triggers"
+)
+
+// ── daemon (run) ────────────────────────────────────────────────────────────
+
+type runCommand struct {
+	*cmds.CommandDescription
+	au        audio.Service
+	bt        bluetooth.Service
+	store     *preset.Store
+	streamer  sexec.Streamer
+	res       *resolve.Resolver
+	rulesPath string
+}
+
+type runSettings struct {
+	Socket   string `glazed:"socket"`
+	Rules    bool   `glazed:"rules"`
+	Triggers bool   `glazed:"triggers"`
+	History  int    `glazed:"history"`
+}
+
+func newRunCommand(au audio.Service, bt bluetooth.Service, store *preset.Store, streamer sexec.Streamer, res *resolve.Resolver, rulesPath string) (*runCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &runCommand{
+		CommandDescription: cmds.NewCommandDescription("daemon",
+			cmds.WithShort("Run soundctl in the background with a local control socket"),
+			cmds.WithLong("Owns the audio and bluetooth services and event subscriptions, and serves "+
+				"them as JSON-RPC over a Unix socket. While it runs, other soundctl commands and the TUI talk to "+
+				"it instead of the audio server and BlueZ (set SOUNDCTL_DAEMON=off to bypass); presets stay local, "+
+				"read from and written to presets.yaml directly by each command. Also runs the "+
+				"routing rules when enabled in rules.yaml, and the preset triggers when enabled in triggers.yaml, "+
+				"recording each firing in triggers.log (see presets triggers)."),
+			cmds.WithFlags(
+				fields.New("socket", fields.TypeString, fields.WithDefault(""), fields.WithHelp("Socket path (default $XDG_RUNTIME_DIR/soundctl/daemon.sock)")),
+				fields.New("rules", fields.TypeBool, fields.WithDefault(true), fields.WithHelp("Run the routing rules engine if rules.yaml enables it")),
+				fields.New("triggers", fields.TypeBool, fields.WithDefault(true), fields.WithHelp("Run the preset triggers if triggers.yaml enables them")),
+				fields.New("history", fields.TypeInteger, fields.WithDefault(sdaemon.DefaultHistorySize), fields.WithHelp("Number of events to keep for `daemon events`")),
+			),
+			cmds.WithSections(sections...),
+		),
+		au:        au,
+		bt:        bt,
+		store:     store,
+		streamer:  streamer,
+		res:       res,
+		rulesPath: rulesPath,
+	}, nil
+}
+
+func (c *runCommand) Run(ctx context.Context, vals *values.Values) error {
+	s := &runSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	path := s.Socket
+	if path == "" {
+		path = sdaemon.DefaultSocketPath()
+	}
+
+	cfg := sdaemon.Config{
+		Audio:       c.au,
+		Bluetooth:   c.bt,
+		Streamer:    c.streamer,
+		HistorySize: s.History,
+		OnEvent: func(ev sdaemon.Event) {
+			if ev.Source == "triggers" {
+				printFiring(ev)
+				return
+			}
+			if ev.Source != "rules" {
+				return
+			}
+			if ev.Error != "" {
+				fmt.Printf("%s rules %s %s %s: %s\n", ev.Time.Format(time.RFC3339), ev.Address, ev.Kind, ev.Target, ev.Error)
+				return
+			}
+			fmt.Printf("%s rules %s %s %s\n", ev.Time.Format(time.RFC3339), ev.Address, ev.Kind, ev.Target)
+		},
+	}
+	if s.Rules {
+		rcfg, err := rules.Load(c.rulesPath)
+		if err != nil {
+			return err
+		}
+		if rcfg.Enabled {
+			cfg.Rules = rules.NewEngine(c.au, rcfg)
+		}
+	}
+	if s.Triggers {
+		tcfg, err := triggers.Load(triggers.PathNextTo(c.store.Path()))
+		if err != nil {
+			return err
+		}
+		if tcfg.Enabled {
+			cfg.Triggers = triggers.NewEngine(c.au, c.store, tcfg).WithNames(func(ctx context.Context, kind string, name string) (string, error) {
+				return c.res.ResolveAlias(ctx, resolve.Kind(kind), name)
+			})
+			cfg.TriggerLog = triggers.NewLog(triggers.LogPathNextTo(c.store.Path()))
+		}
+	}
+
+	ln, err := sdaemon.Listen(path)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Printf("soundctl daemon listening on %s (rules: %t, triggers: %t)\n", path, cfg.Rules != nil, cfg.Triggers != nil)
+	return sdaemon.NewServer(cfg).Serve(ctx, ln)
+}
+
+func printFiring(ev sdaemon.Event) {
+	if ev.Error != "" {
+		fmt.Printf("%s triggers %s %s: %s\n", ev.Time.Format(time.RFC3339), ev.Kind, ev.Target, ev.Error)
+		return
+	}
+	fmt.Printf("%s triggers %s %s\n", ev.Time.Format(time.RFC3339), ev.Kind, ev.Target)
+}
+
+// ── status ──────────────────────────────────────────────────────────────────
+
+type socketSettings struct {
+	Socket string `glazed:"socket"`
+}
+
+func socketFlag() *fields.Definition {
+	return fields.New("socket", fields.TypeString, fields.WithDefault(""), fields.WithHelp("Socket path (default $XDG_RUNTIME_DIR/soundctl/daemon.sock)"))
+}
+
+// dial connects to the daemon named by the socket flag.
+func dial(ctx context.Context, vals *values.Values) (*sdaemon.Client, error) {
+	s := &socketSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return nil, errors.Wrap(err, "decode settings")
+	}
+	path := s.Socket
+	if path == "" {
+		path = sdaemon.DefaultSocketPath()
+	}
+	client, err := sdaemon.Dial(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("daemon not running at %s", path)
+	}
+	return client, nil
+}
+
+type statusCommand struct {
+	*cmds.CommandDescription
+}
+
+func newStatusCommand() (*statusCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &statusCommand{
+		CommandDescription: cmds.NewCommandDescription("status",
+			cmds.WithShort("Show whether the daemon is running"),
+			cmds.WithFlags(socketFlag()),
+			cmds.WithSections(sections...),
+		),
+	}, nil
+}
+
+func (c *statusCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	client, err := dial(ctx, vals)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	st, err := client.Status(ctx)
+	if err != nil {
+		return err
+	}
+	return gp.AddRow(ctx, types.NewRow(
+		types.MRP("pid", st.PID),
+		types.MRP("socket", st.Socket),
+		types.MRP("started", st.Started.Format(time.RFC3339)),
+		types.MRP("uptime", time.Since(st.Started).Round(time.Second).String()),
+		types.MRP("subscriptions", st.Subscriptions),
+		types.MRP("rules", st.Rules),
+		types.MRP("triggers", st.Triggers),
+		types.MRP("events_seen", st.EventsSeen),
+	))
+}
+
+// ── events ──────────────────────────────────────────────────────────────────
+
+type eventsCommand struct {
+	*cmds.CommandDescription
+}
+
+type eventsSettings struct {
+	Limit int `glazed:"limit"`
+}
+
+func newEventsCommand() (*eventsCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &eventsCommand{
+		CommandDescription: cmds.NewCommandDescription("events",
+			cmds.WithShort("Show the daemon's recent event history"),
+			cmds.WithLong("Lists the audio and bluetooth events the daemon has seen and the actions the rules engine took and the triggers that fired, oldest first."),
+			cmds.WithFlags(
+				socketFlag(),
+				fields.New("limit", fields.TypeInteger, fields.WithDefault(50), fields.WithHelp("Maximum number of events (0 for all)")),
+			),
+			cmds.WithSections(sections...),
+		),
+	}, nil
+}
+
+func (c *eventsCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &eventsSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	client, err := dial(ctx, vals)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	evs, err := client.Events(ctx, s.Limit)
+	if err != nil {
+		return err
+	}
+	for _, ev := range evs {
+		id := ""
+		if ev.Index >= 0 {
+			id = fmt.Sprint(ev.Index)
+		}
+		if err := gp.AddRow(ctx, types.NewRow(
+			types.MRP("timestamp", ev.Time.Format(time.RFC3339Nano)),
+			types.MRP("source", ev.Source),
+			types.MRP("kind", ev.Kind),
+			types.MRP("facility", ev.Facility),
+			types.MRP("id", id),
+			types.MRP("address", ev.Address),
+			types.MRP("target", ev.Target),
+			types.MRP("error", ev.Error),
+		)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ── Registration ────────────────────────────────────────────────────────────
+
+// Register adds `daemon` to parent, with `status` and `events` below it.
+func Register(parent *cobra.Command, au audio.Service, bt bluetooth.Service, store *preset.Store, streamer sexec.Streamer, res *resolve.Resolver, rulesPath string) error {
+	runCmd, err := newRunCommand(au, bt, store, streamer, res, rulesPath)
+	if err != nil {
+		return err
+	}
+	daemonCmd, err := common.BuildCobra(runCmd)
+	if err != nil {
+		return err
+	}
+	statusCmd, err := newStatusCommand()
+	if err != nil {
+		return err
+	}
+	eventsCmd, err := newEventsCommand()
+	if err != nil {
+		return err
+	}
+	for _, command := range []cmds.Command{statusCmd, eventsCmd} {
+		cobraCmd, err := common.BuildCobra(command)
+		if err != nil {
+			return err
+		}
+		daemonCmd.AddCommand(cobraCmd)
+	}
+	parent.AddCommand(daemonCmd)
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/spf13/cobra"
 	"soundctl/pkg/cmd/common"
 	"soundctl/pkg/soundctl/bluetooth"
+	"soundctl/pkg/soundctl/resolve"
 )
 
 type listCommand struct {
@@ -101,11 +102,12 @@ type addrSettings struct {
 type mutateCommand struct {
 	*cmds.CommandDescription
 	svc       bluetooth.Service
+	res       *resolve.Resolver
 	operation string
 	run       func(ctx context.Context, svc bluetooth.Service, addr string) error
 }
 
-func newAddrCommand(name string, short string, operation string, svc bluetooth.Service, run func(ctx context.Context, svc bluetooth.Service, addr string) error) (*mutateCommand, error) {
+func newAddrCommand(name string, short string, operation string, svc bluetooth.Service, res *resolve.Resolver, run func(ctx context.Context, svc bluetooth.Service, addr string) error) (*mutateCommand, error) {
 	sections, err := common.DefaultSections()
 	if err != nil {
 		return nil, err
@@ -114,10 +116,11 @@ func newAddrCommand(name string, short string, operation string, svc bluetooth.S
 		CommandDescription: cmds.NewCommandDescription(
 			name,
 			cmds.WithShort(short),
-			cmds.WithFlags(fields.New("addr", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Bluetooth MAC address"))),
+			cmds.WithFlags(fields.New("addr", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Bluetooth MAC address, device name, alias or unique prefix"))),
 			cmds.WithSections(sections...),
 		),
 		svc:       svc,
+		res:       res,
 		operation: operation,
 		run:       run,
 	}, nil
@@ -128,13 +131,17 @@ func (c *mutateCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.
 	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
 		return errors.Wrap(err, "decode settings")
 	}
-	if err := c.run(ctx, c.svc, s.Addr); err != nil {
+	device, err := c.res.Device(ctx, s.Addr)
+	if err != nil {
+		return err
+	}
+	if err := c.run(ctx, c.svc, device.Address); err != nil {
 		return err
 	}
-	return gp.AddRow(ctx, types.NewRow(types.MRP("operation", c.operation), types.MRP("address", s.Addr), types.MRP("ok", true)))
+	return gp.AddRow(ctx, types.NewRow(types.MRP("operation", c.operation), types.MRP("address", device.Address), types.MRP("ok", true)))
 }
 
-func Register(parent *cobra.Command, svc bluetooth.Service) error {
+func Register(parent *cobra.Command, svc bluetooth.Service, res *resolve.Resolver) error {
 	commands := []cmds.Command{}
 
 	listCmd, err := newListCommand(svc)
@@ -145,25 +152,25 @@ func Register(parent *cobra.Command, svc bluetooth.Service) error {
 	if err != nil {
 		return err
 	}
-	connectCmd, err := newAddrCommand("connect", "Connect bluetooth device", "devices.connect", svc, func(ctx context.Context, s bluetooth.Service, addr string) error {
+	connectCmd, err := newAddrCommand("connect", "Connect bluetooth device", "devices.connect", svc, res, func(ctx context.Context, s bluetooth.Service, addr string) error {
 		return s.Connect(ctx, addr)
 	})
 	if err != nil {
 		return err
 	}
-	disconnectCmd, err := newAddrCommand("disconnect", "Disconnect bluetooth device", "devices.disconnect", svc, func(ctx context.Context, s bluetooth.Service, addr string) error {
+	disconnectCmd, err := newAddrCommand("disconnect", "Disconnect bluetooth device", "devices.disconnect", svc, res, func(ctx context.Context, s bluetooth.Service, addr string) error {
 		return s.Disconnect(ctx, addr)
 	})
 	if err != nil {
 		return err
 	}
-	trustCmd, err := newAddrCommand("trust", "Trust bluetooth device", "devices.trust", svc, func(ctx context.Context, s bluetooth.Service, addr string) error {
+	trustCmd, err := newAddrCommand("trust", "Trust bluetooth device", "devices.trust", svc, res, func(ctx context.Context, s bluetooth.Service, addr string) error {
 		return s.Trust(ctx, addr)
 	})
 	if err != nil {
 		return err
 	}
-	forgetCmd, err := newAddrCommand("forget", "Remove bluetooth device", "devices.forget", svc, func(ctx context.Context, s bluetooth.Service, addr string) error {
+	forgetCmd, err := newAddrCommand("forget", "Remove bluetooth device", "devices.forget", svc, res, func(ctx context.Context, s bluetooth.Service, addr string) error {
 		return s.Remove(ctx, addr)
 	})
 	if err != nil {
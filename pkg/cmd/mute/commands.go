@@ -13,6 +13,7 @@ import (
 	"github.com/spf13/cobra"
 	"soundctl/pkg/cmd/common"
 	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/resolve"
 )
 
 type toggleSettings struct {
@@ -23,9 +24,10 @@ type toggleSettings struct {
 type toggleCommand struct {
 	*cmds.CommandDescription
 	svc audio.Service
+	res *resolve.Resolver
 }
 
-func newToggleCommand(svc audio.Service) (*toggleCommand, error) {
+func newToggleCommand(svc audio.Service, res *resolve.Resolver) (*toggleCommand, error) {
 	sections, err := common.DefaultSections()
 	if err != nil {
 		return nil, err
@@ -36,11 +38,12 @@ func newToggleCommand(svc audio.Service) (*toggleCommand, error) {
 			cmds.WithShort("Toggle sink/source mute"),
 			cmds.WithFlags(
 				fields.New("target", fields.TypeString, fields.WithDefault("sink"), fields.WithHelp("Target type: sink or source")),
-				fields.New("name", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Sink/source name or ID")),
+				fields.New("name", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Sink/source ID, name, description, alias or unique prefix")),
 			),
 			cmds.WithSections(sections...),
 		),
 		svc: svc,
+		res: res,
 	}, nil
 }
 
@@ -49,26 +52,90 @@ func (c *toggleCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.
 	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
 		return errors.Wrap(err, "decode settings")
 	}
-	if err := c.svc.ToggleMute(ctx, s.Target, s.Name); err != nil {
+	dev, err := c.res.Target(ctx, s.Target, s.Name)
+	if err != nil {
+		return err
+	}
+	if err := c.svc.ToggleMute(ctx, s.Target, dev.Name); err != nil {
 		return err
 	}
 	return gp.AddRow(ctx, types.NewRow(
 		types.MRP("operation", "mute.toggle"),
 		types.MRP("target", s.Target),
-		types.MRP("name", s.Name),
+		types.MRP("name", dev.Name),
+		types.MRP("ok", true),
+	))
+}
+
+type setSettings struct {
+	Target string `glazed:"target"`
+	Name   string `glazed:"name"`
+	Muted  bool   `glazed:"muted"`
+}
+
+type setCommand struct {
+	*cmds.CommandDescription
+	svc audio.Service
+	res *resolve.Resolver
+}
+
+func newSetCommand(svc audio.Service, res *resolve.Resolver) (*setCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &setCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			"set",
+			cmds.WithShort("Set sink/source mute state explicitly"),
+			cmds.WithFlags(
+				fields.New("target", fields.TypeString, fields.WithDefault("sink"), fields.WithHelp("Target type: sink or source")),
+				fields.New("name", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Sink/source ID, name, description, alias or unique prefix")),
+				fields.New("muted", fields.TypeBool, fields.WithDefault(true), fields.WithHelp("Mute (true) or unmute (false)")),
+			),
+			cmds.WithSections(sections...),
+		),
+		svc: svc,
+		res: res,
+	}, nil
+}
+
+func (c *setCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &setSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	dev, err := c.res.Target(ctx, s.Target, s.Name)
+	if err != nil {
+		return err
+	}
+	if err := c.svc.SetMute(ctx, s.Target, dev.Name, s.Muted); err != nil {
+		return err
+	}
+	return gp.AddRow(ctx, types.NewRow(
+		types.MRP("operation", "mute.set"),
+		types.MRP("target", s.Target),
+		types.MRP("name", dev.Name),
+		types.MRP("muted", s.Muted),
 		types.MRP("ok", true),
 	))
 }
 
-func Register(parent *cobra.Command, svc audio.Service) error {
-	toggleCmd, err := newToggleCommand(svc)
+func Register(parent *cobra.Command, svc audio.Service, res *resolve.Resolver) error {
+	toggleCmd, err := newToggleCommand(svc, res)
 	if err != nil {
 		return err
 	}
-	cobraCmd, err := common.BuildCobra(toggleCmd)
+	setCmd, err := newSetCommand(svc, res)
 	if err != nil {
 		return err
 	}
-	parent.AddCommand(cobraCmd)
+	for _, command := range []cmds.Command{toggleCmd, setCmd} {
+		cobraCmd, err := common.BuildCobra(command)
+		if err != nil {
+			return err
+		}
+		parent.AddCommand(cobraCmd)
+	}
 	return nil
 }
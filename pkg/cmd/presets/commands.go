@@ -1,8 +1,17 @@
 package presets
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-go-golems/glazed/pkg/cmds"
 	"github.com/go-go-golems/glazed/pkg/cmds/fields"
@@ -15,6 +24,8 @@ import (
 	"soundctl/pkg/cmd/common"
 	"soundctl/pkg/soundctl/audio"
 	"soundctl/pkg/soundctl/preset"
+	"soundctl/pkg/soundctl/resolve"
+	"soundctl/pkg/soundctl/triggers"
 )
 
 // ── list ────────────────────────────────────────────────────────────────────
@@ -46,10 +57,12 @@ func (c *listCommand) RunIntoGlazeProcessor(ctx context.Context, _ *values.Value
 	for _, p := range presets {
 		if err := gp.AddRow(ctx, types.NewRow(
 			types.MRP("name", p.Name),
+			types.MRP("extends", strings.Join(p.Extends, ", ")),
 			types.MRP("default_sink", p.DefaultSink),
 			types.MRP("profiles", len(p.CardProfiles)),
-			types.MRP("volumes", len(p.Volumes)),
+			types.MRP("volumes", len(p.Volumes)+len(p.SourceVolumes)),
 			types.MRP("routes", len(p.AppRoutes)),
+			types.MRP("app_volumes", len(p.AppVolumes)),
 			types.MRP("updated", p.UpdatedAt.Format("2006-01-02 15:04")),
 		)); err != nil {
 			return err
@@ -58,19 +71,147 @@ func (c *listCommand) RunIntoGlazeProcessor(ctx context.Context, _ *values.Value
 	return nil
 }
 
+// ── show ────────────────────────────────────────────────────────────────────
+
+type showSettings struct {
+	Name string `glazed:"name"`
+	Raw  bool   `glazed:"raw"`
+}
+
+type showCommand struct {
+	*cmds.CommandDescription
+	store *preset.Store
+}
+
+func newShowCommand(store *preset.Store) (*showCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &showCommand{
+		CommandDescription: cmds.NewCommandDescription("show",
+			cmds.WithShort("Show every setting of a preset"),
+			cmds.WithLong("Emits one row per setting of the preset as it would be applied, with the "+
+				"presets it extends merged in; the from column names the preset each setting "+
+				"comes from. --raw shows only what the preset itself stores."),
+			cmds.WithFlags(
+				fields.New("name", fields.TypeString, fields.WithRequired(true),
+					fields.WithHelp("Preset name to show")),
+				fields.New("raw", fields.TypeBool, fields.WithDefault(false),
+					fields.WithHelp("Do not resolve extends")),
+			),
+			cmds.WithSections(sections...),
+		),
+		store: store,
+	}, nil
+}
+
+func (c *showCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &showSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	p, err := c.store.Get(s.Name)
+	if err != nil {
+		return err
+	}
+	from := func(kind, key string) string { return p.Name }
+	if !s.Raw {
+		all, err := c.store.List()
+		if err != nil {
+			return err
+		}
+		resolved, origins, err := preset.ResolveOrigins(p, all)
+		if err != nil {
+			return err
+		}
+		p, from = resolved, origins.Of
+	}
+	return addSettingRows(ctx, gp, p, from)
+}
+
+// addSettingRows emits one row per setting of p, in a stable order.
+func addSettingRows(ctx context.Context, gp middlewares.Processor, p preset.Preset, from func(kind, key string) string) error {
+	add := func(kind, key, value string) error {
+		return gp.AddRow(ctx, types.NewRow(
+			types.MRP("preset", p.Name),
+			types.MRP("kind", kind),
+			types.MRP("key", key),
+			types.MRP("value", value),
+			types.MRP("from", from(kind, key)),
+		))
+	}
+	volume := func(v preset.VolumeSpec) string {
+		if v.Muted {
+			return fmt.Sprintf("%d%% muted", v.Level)
+		}
+		return fmt.Sprintf("%d%%", v.Level)
+	}
+	if len(p.Extends) > 0 {
+		if err := add("extends", "", strings.Join(p.Extends, ", ")); err != nil {
+			return err
+		}
+	}
+	for _, label := range sortedKeys(p.Targets) {
+		if err := add("target", label, p.Targets[label].String()); err != nil {
+			return err
+		}
+	}
+	for _, card := range sortedKeys(p.CardProfiles) {
+		if err := add("profile", card, p.CardProfiles[card]); err != nil {
+			return err
+		}
+	}
+	if p.DefaultSink != "" {
+		if err := add("default_sink", "", p.DefaultSink); err != nil {
+			return err
+		}
+	}
+	for _, m := range []struct {
+		kind    string
+		volumes map[string]preset.VolumeSpec
+	}{{"volume", p.Volumes}, {"source_volume", p.SourceVolumes}, {"app_volume", p.AppVolumes}} {
+		for _, name := range sortedKeys(m.volumes) {
+			if err := add(m.kind, name, volume(m.volumes[name])); err != nil {
+				return err
+			}
+		}
+	}
+	for _, app := range sortedKeys(p.AppRoutes) {
+		if err := add("app_route", app, p.AppRoutes[app]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // ── apply ───────────────────────────────────────────────────────────────────
 
 type applySettings struct {
-	Name string `glazed:"name"`
+	Name   string   `glazed:"name"`
+	Layers []string `glazed:"layer"`
+	Atomic bool     `glazed:"atomic"`
+	DryRun bool     `glazed:"dry-run"`
+	Force  bool     `glazed:"force"`
 }
 
 type applyCommand struct {
 	*cmds.CommandDescription
 	store *preset.Store
 	au    audio.Service
+	res   *resolve.Resolver
 }
 
-func newApplyCommand(store *preset.Store, au audio.Service) (*applyCommand, error) {
+func newApplyCommand(store *preset.Store, au audio.Service, res *resolve.Resolver) (*applyCommand, error) {
 	sections, err := common.DefaultSections()
 	if err != nil {
 		return nil, err
@@ -78,76 +219,395 @@ func newApplyCommand(store *preset.Store, au audio.Service) (*applyCommand, erro
 	return &applyCommand{
 		CommandDescription: cmds.NewCommandDescription("apply",
 			cmds.WithShort("Apply a saved preset"),
+			cmds.WithLong("Applies a saved preset. Names written as @target are resolved against the "+
+				"preset's targets section when it is applied; targets that match no single "+
+				"sink, source or card are reported and their entries skipped.\n\n"+
+				"After a card profile switch, apply waits for the new sinks and sources to appear "+
+				"and retries the steps that need them. The result is then verified against a fresh "+
+				"snapshot and any remaining drift is reported.\n\n"+
+				"A preset that extends others is applied with its parents layered underneath; "+
+				"--layer adds presets on top.\n\n"+
+				"Before anything is changed, the preset is validated against the live system as by "+
+				"presets validate; errors stop the apply unless --force is given, warnings are shown.\n\n"+
+				"With --atomic the current state is captured first and restored if a step fails, "+
+				"a target is unresolved or the state drifted."),
 			cmds.WithFlags(
 				fields.New("name", fields.TypeString, fields.WithRequired(true),
 					fields.WithHelp("Preset name to apply")),
+				fields.New("layer", fields.TypeStringList, fields.WithDefault([]string{}),
+					fields.WithHelp("Presets to layer on top, in order (e.g. a partial preset with only app routes)")),
+				fields.New("atomic", fields.TypeBool, fields.WithDefault(false),
+					fields.WithHelp("Verify the result and roll back to the previous state on failure")),
+				fields.New("dry-run", fields.TypeBool, fields.WithDefault(false),
+					fields.WithHelp("Print the plan instead of applying, like presets plan")),
+				fields.New("force", fields.TypeBool, fields.WithDefault(false),
+					fields.WithHelp("Apply even if the pre-flight check finds errors")),
 			),
 			cmds.WithSections(sections...),
 		),
 		store: store,
 		au:    au,
+		res:   res,
 	}, nil
 }
 
+// apply applies a saved preset, with its parents and layers, after
+// expanding the aliases it names and a pre-flight validation, whose
+// warnings it returns. An atomic apply refuses to start when an alias does
+// not resolve.
+func (c *applyCommand) apply(ctx context.Context, s *applySettings) (preset.Preset, []preset.Finding, preset.AtomicResult, error) {
+	p, err := c.store.Resolve(s.Name, s.Layers...)
+	if err != nil {
+		return preset.Preset{}, nil, preset.AtomicResult{}, err
+	}
+	resolved, errs := resolveAliases(ctx, c.res, p)
+	if s.Atomic && len(errs) > 0 {
+		return p, nil, preset.AtomicResult{}, errors.Wrapf(errs[0], "preset %q not applied", p.Name)
+	}
+	var warnings []preset.Finding
+	if !s.Force {
+		findings, err := preset.Validate(ctx, c.au, resolved)
+		if err != nil {
+			return p, nil, preset.AtomicResult{}, errors.Wrap(err, "pre-flight check")
+		}
+		if preset.HasErrors(findings) {
+			return p, nil, preset.AtomicResult{}, preflightError(p.Name, findings)
+		}
+		warnings = findings
+	}
+	if s.Atomic {
+		result, err := preset.ApplyAtomic(ctx, c.au, resolved, preset.DefaultApplyOptions())
+		return p, warnings, result, err
+	}
+	result := preset.Apply(ctx, c.au, resolved)
+	steps := make([]preset.StepResult, 0, len(errs)+len(result.Steps))
+	for _, e := range errs {
+		steps = append(steps, preset.StepResult{Step: "Resolve names", Err: e})
+	}
+	result.Steps = append(steps, result.Steps...)
+	result.Errors = append(errs, result.Errors...)
+	return p, warnings, preset.AtomicResult{ApplyResult: result}, nil
+}
+
+func preflightError(name string, findings []preset.Finding) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "preset %q not applied, pre-flight check failed (use --force to apply anyway):", name)
+	for _, f := range findings {
+		if f.Severity == preset.SeverityError {
+			fmt.Fprintf(&b, "\n  ✗ %s: %s", f.Field, f.Message)
+		}
+	}
+	return errors.New(b.String())
+}
+
 func (c *applyCommand) Run(ctx context.Context, vals *values.Values) error {
 	s := &applySettings{}
 	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
 		return errors.Wrap(err, "decode settings")
 	}
-	p, err := c.store.Get(s.Name)
+	if s.DryRun {
+		plan, errs, findings, err := planPreset(ctx, c.store, c.au, c.res, s.Name, s.Layers)
+		if err != nil {
+			return err
+		}
+		printPlan(plan, errs, findings)
+		return nil
+	}
+	p, warnings, result, err := c.apply(ctx, s)
 	if err != nil {
 		return err
 	}
-	result := preset.Apply(ctx, c.au, p)
-	for _, change := range result.Applied {
-		fmt.Printf("  ✓ %s\n", change)
+	for _, f := range warnings {
+		fmt.Printf("  ! %s: %s\n", f.Field, f.Message)
 	}
-	for _, e := range result.Errors {
-		fmt.Printf("  ✗ %v\n", e)
+	printSteps("", result.Steps)
+	for _, u := range result.Unresolved {
+		fmt.Printf("  ? %v\n", u)
+	}
+	for _, d := range result.Drift {
+		fmt.Printf("  ≠ %s: %s (want %s)\n", d.Field, d.From, d.To)
+	}
+	if result.RolledBack {
+		fmt.Println("  Rolling back:")
+		printSteps("  ", result.Rollback.Steps)
+		if len(result.Rollback.Errors) == 0 {
+			fmt.Printf("Preset %q was not applied; the previous state was restored.\n", p.Name)
+		} else {
+			fmt.Printf("Preset %q was not applied; restoring the previous state failed with %d error(s).\n", p.Name, len(result.Rollback.Errors))
+		}
+		return nil
 	}
-	if len(result.Errors) == 0 {
+	switch {
+	case result.OK():
 		fmt.Printf("Preset %q applied successfully.\n", p.Name)
-	} else {
-		fmt.Printf("Preset %q applied with %d error(s).\n", p.Name, len(result.Errors))
+	case len(result.Errors) == 0:
+		fmt.Printf("Preset %q applied; %d target(s) unresolved.\n", p.Name, len(result.Unresolved))
+	default:
+		fmt.Printf("Preset %q applied with %d error(s), %d target(s) unresolved.\n", p.Name, len(result.Errors), len(result.Unresolved))
 	}
 	return nil
 }
 
+func printSteps(indent string, steps []preset.StepResult) {
+	for _, step := range steps {
+		if step.Err != nil {
+			fmt.Printf("%s  ✗ %v\n", indent, step.Err)
+		} else {
+			fmt.Printf("%s  ✓ %s\n", indent, step.Step)
+		}
+	}
+}
+
 func (c *applyCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
 	s := &applySettings{}
 	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
 		return errors.Wrap(err, "decode settings")
 	}
-	p, err := c.store.Get(s.Name)
+	if s.DryRun {
+		plan, errs, findings, err := planPreset(ctx, c.store, c.au, c.res, s.Name, s.Layers)
+		if err != nil {
+			return err
+		}
+		return addPlanRows(ctx, gp, plan, errs, findings)
+	}
+	p, warnings, result, err := c.apply(ctx, s)
 	if err != nil {
 		return err
 	}
-	result := preset.Apply(ctx, c.au, p)
-	for _, change := range result.Applied {
+	if err := addFindingRows(ctx, gp, "", warnings); err != nil {
+		return err
+	}
+	if err := addStepRows(ctx, gp, "", result.Steps); err != nil {
+		return err
+	}
+	for _, u := range result.Unresolved {
 		if err := gp.AddRow(ctx, types.NewRow(
-			types.MRP("kind", "applied"),
-			types.MRP("change", change),
+			types.MRP("kind", "unresolved"),
+			types.MRP("change", u.Error()),
+			types.MRP("target", u.Target),
+			types.MRP("target_kind", u.Kind),
 		)); err != nil {
 			return err
 		}
 	}
-	for _, e := range result.Errors {
+	for _, d := range result.Drift {
 		if err := gp.AddRow(ctx, types.NewRow(
-			types.MRP("kind", "error"),
-			types.MRP("change", e.Error()),
+			types.MRP("kind", "drift"),
+			types.MRP("change", d.Field),
+			types.MRP("from", d.From),
+			types.MRP("to", d.To),
 		)); err != nil {
 			return err
 		}
 	}
+	if err := addStepRows(ctx, gp, "rollback ", result.Rollback.Steps); err != nil {
+		return err
+	}
 	return gp.AddRow(ctx, types.NewRow(
 		types.MRP("kind", "summary"),
 		types.MRP("preset", p.Name),
 		types.MRP("applied", len(result.Applied)),
 		types.MRP("errors", len(result.Errors)),
-		types.MRP("ok", len(result.Errors) == 0),
+		types.MRP("unresolved", len(result.Unresolved)),
+		types.MRP("atomic", s.Atomic),
+		types.MRP("rolled_back", result.RolledBack),
+		types.MRP("ok", result.OK()),
 	))
 }
 
+// addStepRows emits one "applied" or "error" row per step, with kinds
+// prefixed for the rollback steps.
+func addStepRows(ctx context.Context, gp middlewares.Processor, prefix string, steps []preset.StepResult) error {
+	for _, step := range steps {
+		row := types.NewRow(
+			types.MRP("kind", prefix+"applied"),
+			types.MRP("change", step.Step),
+		)
+		if step.Err != nil {
+			row = types.NewRow(
+				types.MRP("kind", prefix+"error"),
+				types.MRP("change", step.Err.Error()),
+			)
+		}
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ── plan ────────────────────────────────────────────────────────────────────
+
+type planSettings struct {
+	Name   string   `glazed:"name"`
+	Layers []string `glazed:"layer"`
+}
+
+type planCommand struct {
+	*cmds.CommandDescription
+	store *preset.Store
+	au    audio.Service
+	res   *resolve.Resolver
+}
+
+func newPlanCommand(store *preset.Store, au audio.Service, res *resolve.Resolver) (*planCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &planCommand{
+		CommandDescription: cmds.NewCommandDescription("plan",
+			cmds.WithShort("Show what applying a preset would do"),
+			cmds.WithLong("Snapshots the live state and lists, in order, the steps apply would take: "+
+				"each step's dependencies, the equivalent pactl command and the value it changes. "+
+				"The findings of apply's pre-flight check are listed first. Nothing is changed."),
+			cmds.WithFlags(
+				fields.New("name", fields.TypeString, fields.WithRequired(true),
+					fields.WithHelp("Preset name to plan")),
+				fields.New("layer", fields.TypeStringList, fields.WithDefault([]string{}),
+					fields.WithHelp("Presets to layer on top, in order (e.g. a partial preset with only app routes)")),
+			),
+			cmds.WithSections(sections...),
+		),
+		store: store,
+		au:    au,
+		res:   res,
+	}, nil
+}
+
+func (c *planCommand) Run(ctx context.Context, vals *values.Values) error {
+	s := &planSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	plan, errs, findings, err := planPreset(ctx, c.store, c.au, c.res, s.Name, s.Layers)
+	if err != nil {
+		return err
+	}
+	printPlan(plan, errs, findings)
+	return nil
+}
+
+func (c *planCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &planSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	plan, errs, findings, err := planPreset(ctx, c.store, c.au, c.res, s.Name, s.Layers)
+	if err != nil {
+		return err
+	}
+	return addPlanRows(ctx, gp, plan, errs, findings)
+}
+
+// resolveAliases expands the aliases a preset names.
+func resolveAliases(ctx context.Context, res *resolve.Resolver, p preset.Preset) (preset.Preset, []error) {
+	return preset.ResolveNames(ctx, p, func(ctx context.Context, kind string, name string) (string, error) {
+		return res.ResolveAlias(ctx, resolve.Kind(kind), name)
+	})
+}
+
+// planPreset plans a saved preset with its parents and layers, returning
+// the aliases that did not resolve and the findings of the pre-flight
+// check apply would run alongside.
+func planPreset(ctx context.Context, store *preset.Store, au audio.Service, res *resolve.Resolver, name string, layers []string) (preset.Plan, []error, []preset.Finding, error) {
+	p, err := store.Resolve(name, layers...)
+	if err != nil {
+		return preset.Plan{}, nil, nil, err
+	}
+	resolved, errs := resolveAliases(ctx, res, p)
+	findings, err := preset.Validate(ctx, au, resolved)
+	if err != nil {
+		return preset.Plan{}, nil, nil, errors.Wrap(err, "pre-flight check")
+	}
+	plan, err := preset.MakePlan(ctx, au, resolved)
+	return plan, errs, findings, err
+}
+
+func printPlan(plan preset.Plan, errs []error, findings []preset.Finding) {
+	for _, e := range errs {
+		fmt.Printf("  ✗ %v\n", e)
+	}
+	for _, f := range findings {
+		mark := "!"
+		if f.Severity == preset.SeverityError {
+			mark = "✗"
+		}
+		fmt.Printf("  %s %s: %s\n", mark, f.Field, f.Message)
+	}
+	if preset.HasErrors(findings) {
+		fmt.Println("Pre-flight check failed: apply would refuse this preset unless --force is given.")
+	}
+	fmt.Printf("Plan for preset %q: %d step(s), %d change(s)\n", plan.Preset, len(plan.Steps), plan.Changes())
+	if len(plan.Diff) > 0 {
+		fmt.Println("  Changes:")
+		for _, d := range plan.Diff {
+			fmt.Printf("    %s  %s → %s\n", d.Field, d.From, d.To)
+		}
+	}
+	fmt.Println("  Steps:")
+	for _, step := range plan.Steps {
+		line := fmt.Sprintf("    %d. %s", step.ID, step.Step)
+		if !step.Changes() {
+			line += " (unchanged)"
+		}
+		if len(step.DependsOn) > 0 {
+			line += " [after " + joinIDs(step.DependsOn) + "]"
+		}
+		fmt.Println(line)
+		fmt.Printf("       $ %s\n", step.Command)
+	}
+	fmt.Println("    then verify against a fresh snapshot")
+	for _, u := range plan.Unresolved {
+		fmt.Printf("  ? %v\n", u)
+	}
+}
+
+func addPlanRows(ctx context.Context, gp middlewares.Processor, plan preset.Plan, errs []error, findings []preset.Finding) error {
+	for _, e := range errs {
+		if err := gp.AddRow(ctx, types.NewRow(
+			types.MRP("kind", "error"),
+			types.MRP("step", e.Error()),
+		)); err != nil {
+			return err
+		}
+	}
+	if err := addFindingRows(ctx, gp, "", findings); err != nil {
+		return err
+	}
+	for _, step := range plan.Steps {
+		if err := gp.AddRow(ctx, types.NewRow(
+			types.MRP("kind", "step"),
+			types.MRP("id", step.ID),
+			types.MRP("step", step.Step),
+			types.MRP("command", step.Command),
+			types.MRP("depends_on", joinIDs(step.DependsOn)),
+			types.MRP("from", step.From),
+			types.MRP("to", step.To),
+			types.MRP("changes", step.Changes()),
+		)); err != nil {
+			return err
+		}
+	}
+	for _, u := range plan.Unresolved {
+		if err := gp.AddRow(ctx, types.NewRow(
+			types.MRP("kind", "unresolved"),
+			types.MRP("step", u.Error()),
+		)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func joinIDs(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ",")
+}
+
 // ── save ────────────────────────────────────────────────────────────────────
 
 type saveSettings struct {
@@ -172,7 +632,7 @@ func newSaveCommand(store *preset.Store) (*saveCommand, error) {
 				fields.New("name", fields.TypeString, fields.WithRequired(true),
 					fields.WithHelp("Preset name")),
 				fields.New("default-sink", fields.TypeString, fields.WithDefault(""),
-					fields.WithHelp("Default sink name")),
+					fields.WithHelp("Default sink name or alias")),
 			),
 			cmds.WithSections(sections...),
 		),
@@ -243,88 +703,809 @@ func (c *deleteCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.
 	))
 }
 
-// ── snapshot ────────────────────────────────────────────────────────────────
+// ── validate ────────────────────────────────────────────────────────────────
 
-type snapshotSettings struct {
-	Name string `glazed:"name"`
+type validateSettings struct {
+	Names []string `glazed:"name"`
 }
 
-type snapshotCommand struct {
+type validateCommand struct {
 	*cmds.CommandDescription
 	store *preset.Store
 	au    audio.Service
+	res   *resolve.Resolver
 }
 
-func newSnapshotCommand(store *preset.Store, au audio.Service) (*snapshotCommand, error) {
+func newValidateCommand(store *preset.Store, au audio.Service, res *resolve.Resolver) (*validateCommand, error) {
 	sections, err := common.DefaultSections()
 	if err != nil {
 		return nil, err
 	}
-	return &snapshotCommand{
-		CommandDescription: cmds.NewCommandDescription("snapshot",
-			cmds.WithShort("Capture current live state as a named preset"),
+	return &validateCommand{
+		CommandDescription: cmds.NewCommandDescription("validate",
+			cmds.WithShort("Check presets against the live system"),
+			cmds.WithLong("Checks each preset as it would be applied, with the presets it extends and its "+
+				"aliases resolved: that its cards exist and offer its profiles, available ones, that its "+
+				"sinks and sources exist, and that volumes are in range. Volumes above 100% are warnings, "+
+				"as are sinks and sources that only appear after a profile switch. Fails if any preset "+
+				"has errors."),
 			cmds.WithFlags(
-				fields.New("name", fields.TypeString, fields.WithRequired(true),
-					fields.WithHelp("Name for the new preset")),
+				fields.New("name", fields.TypeStringList, fields.WithDefault([]string{}),
+					fields.WithHelp("Presets to validate (default: all)")),
 			),
 			cmds.WithSections(sections...),
 		),
 		store: store,
 		au:    au,
+		res:   res,
 	}, nil
 }
 
-func (c *snapshotCommand) Run(ctx context.Context, vals *values.Values) error {
-	s := &snapshotSettings{}
-	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
-		return errors.Wrap(err, "decode settings")
-	}
-	p, err := preset.SnapshotCurrent(ctx, c.au)
+type validation struct {
+	name     string
+	findings []preset.Finding
+}
+
+func (c *validateCommand) validate(ctx context.Context, s *validateSettings) ([]validation, error) {
+	all, err := c.store.List()
 	if err != nil {
-		return fmt.Errorf("snapshot: %w", err)
+		return nil, err
 	}
-	p.Name = s.Name
-	if err := c.store.Save(p); err != nil {
-		return err
+	names := s.Names
+	if len(names) == 0 {
+		for _, p := range all {
+			names = append(names, p.Name)
+		}
+	}
+	var out []validation
+	for _, name := range names {
+		p, err := c.store.Resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		resolved, errs := resolveAliases(ctx, c.res, p)
+		findings, err := preset.Validate(ctx, c.au, resolved)
+		if err != nil {
+			return nil, fmt.Errorf("validate %q: %w", name, err)
+		}
+		for _, e := range errs {
+			findings = append(findings, preset.Finding{Severity: preset.SeverityWarning, Code: "unresolved-alias", Field: "alias", Message: e.Error()})
+		}
+		out = append(out, validation{name: name, findings: findings})
+	}
+	return out, nil
+}
+
+func failedValidations(results []validation) error {
+	failed := 0
+	for _, r := range results {
+		if preset.HasErrors(r.findings) {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d preset(s) failed validation", failed)
 	}
-	fmt.Printf("Preset %q saved from current state.\n", p.Name)
-	fmt.Printf("  Default sink: %s\n", p.DefaultSink)
-	fmt.Printf("  Card profiles: %d\n", len(p.CardProfiles))
-	fmt.Printf("  App routes: %d\n", len(p.AppRoutes))
 	return nil
 }
 
-func (c *snapshotCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
-	s := &snapshotSettings{}
+func (c *validateCommand) Run(ctx context.Context, vals *values.Values) error {
+	s := &validateSettings{}
 	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
 		return errors.Wrap(err, "decode settings")
 	}
-	p, err := preset.SnapshotCurrent(ctx, c.au)
+	results, err := c.validate(ctx, s)
 	if err != nil {
-		return fmt.Errorf("snapshot: %w", err)
-	}
-	p.Name = s.Name
-	if err := c.store.Save(p); err != nil {
 		return err
 	}
-	return gp.AddRow(ctx, types.NewRow(
-		types.MRP("operation", "presets.snapshot"),
-		types.MRP("name", p.Name),
-		types.MRP("default_sink", p.DefaultSink),
-		types.MRP("profiles", len(p.CardProfiles)),
-		types.MRP("routes", len(p.AppRoutes)),
-		types.MRP("ok", true),
-	))
+	for _, r := range results {
+		if len(r.findings) == 0 {
+			fmt.Printf("✓ %s\n", r.name)
+			continue
+		}
+		mark := "!"
+		if preset.HasErrors(r.findings) {
+			mark = "✗"
+		}
+		fmt.Printf("%s %s\n", mark, r.name)
+		for _, f := range r.findings {
+			fmt.Printf("    %-7s %s: %s\n", f.Severity, f.Field, f.Message)
+		}
+	}
+	return failedValidations(results)
 }
 
-// ── Registration ────────────────────────────────────────────────────────────
-
-func Register(parent *cobra.Command, store *preset.Store, au audio.Service) error {
-	listCmd, err := newListCommand(store)
-	if err != nil {
+func (c *validateCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &validateSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	results, err := c.validate(ctx, s)
+	if err != nil {
 		return err
 	}
-	applyCmd, err := newApplyCommand(store, au)
+	for _, r := range results {
+		if err := addFindingRows(ctx, gp, r.name, r.findings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addFindingRows emits one row per finding, tagged with the preset name
+// if given.
+func addFindingRows(ctx context.Context, gp middlewares.Processor, name string, findings []preset.Finding) error {
+	for _, f := range findings {
+		var pairs []types.MapRowPair
+		if name != "" {
+			pairs = append(pairs, types.MRP("preset", name))
+		}
+		pairs = append(pairs,
+			types.MRP("kind", string(f.Severity)),
+			types.MRP("code", f.Code),
+			types.MRP("change", f.Field),
+			types.MRP("message", f.Message),
+		)
+		if err := gp.AddRow(ctx, types.NewRow(pairs...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ── edit ────────────────────────────────────────────────────────────────────
+
+type editSettings struct {
+	Name  string `glazed:"name"`
+	Force bool   `glazed:"force"`
+}
+
+type editCommand struct {
+	*cmds.CommandDescription
+	store *preset.Store
+	au    audio.Service
+	res   *resolve.Resolver
+}
+
+func newEditCommand(store *preset.Store, au audio.Service, res *resolve.Resolver) (*editCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &editCommand{
+		CommandDescription: cmds.NewCommandDescription("edit",
+			cmds.WithShort("Edit a preset in $EDITOR"),
+			cmds.WithLong("Opens the preset as YAML in $VISUAL or $EDITOR (default vi). When the editor exits, "+
+				"the preset is checked: its structure, volumes within 0–150%, the presets it extends, and, "+
+				"against the live system, that its cards, profiles, sinks and sources exist. Only a preset "+
+				"that passes is saved; otherwise the editor reopens with the problems listed at the top. "+
+				"Saving an empty file or quitting without changes leaves the preset as it was.\n\n"+
+				"--force skips the live checks, for editing a preset whose devices are not connected."),
+			cmds.WithFlags(
+				fields.New("name", fields.TypeString, fields.WithRequired(true),
+					fields.WithHelp("Preset name to edit")),
+				fields.New("force", fields.TypeBool, fields.WithDefault(false),
+					fields.WithHelp("Save even if cards, profiles, sinks or sources are not present now")),
+			),
+			cmds.WithSections(sections...),
+		),
+		store: store,
+		au:    au,
+		res:   res,
+	}, nil
+}
+
+func (c *editCommand) Run(ctx context.Context, vals *values.Values) error {
+	s := &editSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	original, err := c.store.Get(s.Name)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp("", "soundctl-preset-*.yaml")
+	if err != nil {
+		return err
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+
+	doc, err := preset.EditDocument(original)
+	if err != nil {
+		return err
+	}
+	failed := false
+	for {
+		if err := os.WriteFile(path, doc, 0o600); err != nil {
+			return err
+		}
+		if err := runEditor(ctx, path); err != nil {
+			return err
+		}
+		edited, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(edited, doc) {
+			if failed {
+				return fmt.Errorf("preset %q not saved", s.Name)
+			}
+			fmt.Println("No changes.")
+			return nil
+		}
+		next, ok, err := preset.ParseEdited(edited)
+		if !ok {
+			fmt.Println("Edit cancelled.")
+			return nil
+		}
+		var errs []error
+		switch {
+		case err != nil:
+			errs = []error{err}
+		case next.Name != s.Name:
+			errs = []error{fmt.Errorf("name cannot change from %q to %q", s.Name, next.Name)}
+		default:
+			errs = c.check(ctx, next, s.Force)
+		}
+		if len(errs) == 0 {
+			next.CreatedAt = original.CreatedAt
+			if err := c.store.Save(next); err != nil {
+				return err
+			}
+			fmt.Printf("Preset %q saved.\n", s.Name)
+			return nil
+		}
+		// Reopen the text as edited, with the problems listed on top.
+		notes := []string{"Not saved:"}
+		for _, e := range errs {
+			fmt.Fprintf(os.Stderr, "  ✗ %v\n", e)
+			notes = append(notes, "  "+e.Error())
+		}
+		doc = preset.Annotate(edited, s.Name, notes...)
+		failed = true
+	}
+}
+
+// check resolves what p extends and the aliases it names and, unless
+// force is set, validates the result against the live system. Warnings
+// are printed and do not keep the preset from being saved.
+func (c *editCommand) check(ctx context.Context, p preset.Preset, force bool) []error {
+	all, err := c.store.List()
+	if err != nil {
+		return []error{err}
+	}
+	for i := range all {
+		if all[i].Name == p.Name {
+			all[i] = p
+		}
+	}
+	resolved, err := preset.Resolve(p, all)
+	if err != nil {
+		return []error{err}
+	}
+	if force {
+		return nil
+	}
+	resolved, errs := resolveAliases(ctx, c.res, resolved)
+	findings, err := preset.Validate(ctx, c.au, resolved)
+	if err != nil {
+		return append(errs, err)
+	}
+	for _, f := range findings {
+		if f.Severity == preset.SeverityError {
+			errs = append(errs, fmt.Errorf("%s: %s", f.Field, f.Message))
+		} else {
+			fmt.Fprintf(os.Stderr, "  ! %s: %s\n", f.Field, f.Message)
+		}
+	}
+	return errs
+}
+
+// runEditor opens path in the user's editor on the terminal. The editor
+// variable may carry arguments, as in "code --wait".
+func runEditor(ctx context.Context, path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd := osexec.CommandContext(ctx, "sh", "-c", editor+` "$1"`, "sh", path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("run editor %q: %w", editor, err)
+	}
+	return nil
+}
+
+// ── snapshot ────────────────────────────────────────────────────────────────
+
+type snapshotSettings struct {
+	Name     string `glazed:"name"`
+	Portable bool   `glazed:"portable"`
+}
+
+type snapshotCommand struct {
+	*cmds.CommandDescription
+	store *preset.Store
+	au    audio.Service
+}
+
+func newSnapshotCommand(store *preset.Store, au audio.Service) (*snapshotCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &snapshotCommand{
+		CommandDescription: cmds.NewCommandDescription("snapshot",
+			cmds.WithShort("Capture current live state as a named preset"),
+			cmds.WithFlags(
+				fields.New("name", fields.TypeString, fields.WithRequired(true),
+					fields.WithHelp("Name for the new preset")),
+				fields.New("portable", fields.TypeBool, fields.WithDefault(false),
+					fields.WithHelp("Refer to devices through @target matchers (bluetooth address, product, bus) instead of pactl names")),
+			),
+			cmds.WithSections(sections...),
+		),
+		store: store,
+		au:    au,
+	}, nil
+}
+
+// snapshot captures and saves the current state.
+func (c *snapshotCommand) snapshot(ctx context.Context, s *snapshotSettings) (preset.Preset, error) {
+	p, err := preset.SnapshotCurrent(ctx, c.au)
+	if err != nil {
+		return p, fmt.Errorf("snapshot: %w", err)
+	}
+	if s.Portable {
+		if p, err = preset.MakePortable(ctx, c.au, p); err != nil {
+			return p, fmt.Errorf("snapshot: %w", err)
+		}
+	}
+	p.Name = s.Name
+	return p, c.store.Save(p)
+}
+
+func (c *snapshotCommand) Run(ctx context.Context, vals *values.Values) error {
+	s := &snapshotSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	p, err := c.snapshot(ctx, s)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Preset %q saved from current state.\n", p.Name)
+	fmt.Printf("  Default sink: %s\n", p.DefaultSink)
+	fmt.Printf("  Card profiles: %d\n", len(p.CardProfiles))
+	fmt.Printf("  App routes: %d\n", len(p.AppRoutes))
+	fmt.Printf("  App volumes: %d\n", len(p.AppVolumes))
+	if len(p.Targets) > 0 {
+		fmt.Printf("  Targets: %d\n", len(p.Targets))
+	}
+	return nil
+}
+
+func (c *snapshotCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &snapshotSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	p, err := c.snapshot(ctx, s)
+	if err != nil {
+		return err
+	}
+	return gp.AddRow(ctx, types.NewRow(
+		types.MRP("operation", "presets.snapshot"),
+		types.MRP("name", p.Name),
+		types.MRP("default_sink", p.DefaultSink),
+		types.MRP("profiles", len(p.CardProfiles)),
+		types.MRP("routes", len(p.AppRoutes)),
+		types.MRP("app_volumes", len(p.AppVolumes)),
+		types.MRP("targets", len(p.Targets)),
+		types.MRP("ok", true),
+	))
+}
+
+// ── export ──────────────────────────────────────────────────────────────────
+
+type exportSettings struct {
+	Names    []string `glazed:"name"`
+	File     string   `glazed:"file"`
+	Format   string   `glazed:"format"`
+	Portable bool     `glazed:"portable"`
+	Resolved bool     `glazed:"resolved"`
+}
+
+type exportCommand struct {
+	*cmds.CommandDescription
+	store *preset.Store
+	au    audio.Service
+}
+
+func newExportCommand(store *preset.Store, au audio.Service) (*exportCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &exportCommand{
+		CommandDescription: cmds.NewCommandDescription("export",
+			cmds.WithShort("Export presets as a YAML or JSON file for sharing"),
+			cmds.WithLong("Writes the named presets (all when --name is not given) in the presets file format, "+
+				"without the timestamps of this machine's store. With --portable, pactl names are rewritten "+
+				"to @target matchers against the current devices so the file works on other machines."),
+			cmds.WithFlags(
+				fields.New("name", fields.TypeStringList, fields.WithDefault([]string{}),
+					fields.WithHelp("Presets to export (default: all)")),
+				fields.New("file", fields.TypeString, fields.WithDefault(""),
+					fields.WithHelp("Write to this file instead of stdout")),
+				fields.New("format", fields.TypeString, fields.WithDefault(""),
+					fields.WithHelp("yaml or json (default: from the file extension, else yaml)")),
+				fields.New("portable", fields.TypeBool, fields.WithDefault(false),
+					fields.WithHelp("Refer to devices through @target matchers instead of pactl names")),
+				fields.New("resolved", fields.TypeBool, fields.WithDefault(false),
+					fields.WithHelp("Merge the presets each one extends into it, so the file stands alone")),
+			),
+			cmds.WithSections(sections...),
+		),
+		store: store,
+		au:    au,
+	}, nil
+}
+
+// export encodes the selected presets and writes them to s.File, if set.
+func (c *exportCommand) export(ctx context.Context, s *exportSettings) ([]preset.Preset, []byte, error) {
+	var presets []preset.Preset
+	for _, name := range s.Names {
+		var p preset.Preset
+		var err error
+		if s.Resolved {
+			p, err = c.store.Resolve(name)
+		} else {
+			p, err = c.store.Get(name)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		presets = append(presets, p)
+	}
+	if len(s.Names) == 0 {
+		all, err := c.store.List()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, p := range all {
+			if s.Resolved {
+				if p, err = preset.Resolve(p, all); err != nil {
+					return nil, nil, err
+				}
+			}
+			presets = append(presets, p)
+		}
+	}
+	if len(presets) == 0 {
+		return nil, nil, fmt.Errorf("no presets to export")
+	}
+	if s.Portable {
+		for i, p := range presets {
+			portable, err := preset.MakePortable(ctx, c.au, p)
+			if err != nil {
+				return nil, nil, fmt.Errorf("make %q portable: %w", p.Name, err)
+			}
+			presets[i] = portable
+		}
+	}
+	format := s.Format
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(s.File), ".")
+		if format != "json" {
+			format = "yaml"
+		}
+	}
+	data, err := preset.Encode(presets, format)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.File != "" {
+		if err := os.WriteFile(s.File, data, 0o644); err != nil {
+			return nil, nil, fmt.Errorf("write %s: %w", s.File, err)
+		}
+	}
+	return presets, data, nil
+}
+
+func (c *exportCommand) Run(ctx context.Context, vals *values.Values) error {
+	s := &exportSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	presets, data, err := c.export(ctx, s)
+	if err != nil {
+		return err
+	}
+	if s.File == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	fmt.Printf("Exported %d preset(s) to %s.\n", len(presets), s.File)
+	return nil
+}
+
+func (c *exportCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &exportSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	if s.File == "" {
+		return fmt.Errorf("--file is required with structured output")
+	}
+	presets, _, err := c.export(ctx, s)
+	if err != nil {
+		return err
+	}
+	for _, p := range presets {
+		if err := gp.AddRow(ctx, types.NewRow(
+			types.MRP("operation", "presets.export"),
+			types.MRP("name", p.Name),
+			types.MRP("file", s.File),
+			types.MRP("targets", len(p.Targets)),
+			types.MRP("ok", true),
+		)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ── import ──────────────────────────────────────────────────────────────────
+
+type importSettings struct {
+	File       string `glazed:"file"`
+	OnConflict string `glazed:"on-conflict"`
+}
+
+type importCommand struct {
+	*cmds.CommandDescription
+	store *preset.Store
+}
+
+func newImportCommand(store *preset.Store) (*importCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &importCommand{
+		CommandDescription: cmds.NewCommandDescription("import",
+			cmds.WithShort("Import presets from a YAML or JSON file"),
+			cmds.WithLong("Reads a file written by presets export (or a presets.yaml) and adds its presets. "+
+				"The whole file is checked first: unknown keys, missing names, out-of-range volumes and "+
+				"references to undefined @targets reject it without importing anything."),
+			cmds.WithFlags(
+				fields.New("file", fields.TypeString, fields.WithRequired(true),
+					fields.WithHelp("File to import, or - for stdin")),
+				fields.New("on-conflict", fields.TypeString, fields.WithDefault(string(preset.ConflictRename)),
+					fields.WithHelp("When a preset name is taken: rename, overwrite or skip")),
+			),
+			cmds.WithSections(sections...),
+		),
+		store: store,
+	}, nil
+}
+
+func (c *importCommand) importFile(s *importSettings) ([]preset.ImportResult, error) {
+	conflict, err := preset.ParseConflict(s.OnConflict)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	if s.File == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(s.File)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", s.File, err)
+	}
+	presets, err := preset.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.File, err)
+	}
+	return c.store.Import(presets, conflict)
+}
+
+func (c *importCommand) Run(ctx context.Context, vals *values.Values) error {
+	s := &importSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	results, err := c.importFile(s)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		switch r.Action {
+		case "renamed":
+			fmt.Printf("  + %s (renamed from %q)\n", r.SavedAs, r.Name)
+		case "overwritten":
+			fmt.Printf("  ~ %s (overwritten)\n", r.Name)
+		case "skipped":
+			fmt.Printf("  - %s (skipped, name taken)\n", r.Name)
+		default:
+			fmt.Printf("  + %s\n", r.Name)
+		}
+	}
+	return nil
+}
+
+func (c *importCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &importSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	results, err := c.importFile(s)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := gp.AddRow(ctx, types.NewRow(
+			types.MRP("operation", "presets.import"),
+			types.MRP("name", r.Name),
+			types.MRP("saved_as", r.SavedAs),
+			types.MRP("action", r.Action),
+			types.MRP("ok", true),
+		)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ── triggers ────────────────────────────────────────────────────────────────
+
+type triggersListCommand struct {
+	*cmds.CommandDescription
+	store *preset.Store
+}
+
+func newTriggersListCommand(store *preset.Store) (*triggersListCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &triggersListCommand{
+		CommandDescription: cmds.NewCommandDescription("list",
+			cmds.WithShort("List the configured preset triggers"),
+			cmds.WithLong("Triggers live in triggers.yaml next to presets.yaml and are evaluated by soundctl daemon "+
+				"while enabled: true is set. Each names a preset (with optional layers) and one condition:\n\n"+
+				"  schedule: \"55 9 * * mon-fri\"            cron, or \"09:55\" / \"09:55 mon-fri\"\n"+
+				"  device_connected: 08:FF:44:2B:4C:90     bluetooth address\n"+
+				"  device_disconnected: 08:FF:44:2B:4C:90\n"+
+				"  sink_appeared: {product: Dock DAC}      match fields as in targets\n"+
+				"  stream_started: zoom                    application name or binary\n\n"+
+				"Event triggers stay quiet for their cooldown (default 1m) after firing. A bluetooth sink "+
+				"appears a moment after the device connects, so to apply a preset using it match the sink "+
+				"with sink_appeared: {address: ...} rather than device_connected. Firings are recorded in "+
+				"triggers.log; see presets triggers log."),
+			cmds.WithSections(sections...),
+		),
+		store: store,
+	}, nil
+}
+
+func (c *triggersListCommand) RunIntoGlazeProcessor(ctx context.Context, _ *values.Values, gp middlewares.Processor) error {
+	cfg, err := triggers.Load(triggers.PathNextTo(c.store.Path()))
+	if err != nil {
+		return err
+	}
+	presets, err := c.store.List()
+	if err != nil {
+		return err
+	}
+	saved := map[string]bool{}
+	for _, p := range presets {
+		saved[p.Name] = true
+	}
+	now := time.Now()
+	for _, t := range cfg.Triggers {
+		var problems []string
+		for _, name := range append([]string{t.Preset}, t.Layers...) {
+			if !saved[name] {
+				problems = append(problems, fmt.Sprintf("preset %q not found", name))
+			}
+		}
+		next, cooldown := "", ""
+		if t.Kind() == triggers.KindSchedule {
+			if at := t.Next(now); !at.IsZero() {
+				next = at.Format("Mon 2006-01-02 15:04")
+			}
+		} else {
+			cooldown = triggers.DefaultCooldown.String()
+			if t.Cooldown > 0 {
+				cooldown = t.Cooldown.String()
+			}
+		}
+		if err := gp.AddRow(ctx, types.NewRow(
+			types.MRP("name", t.Name),
+			types.MRP("preset", t.Preset),
+			types.MRP("layers", strings.Join(t.Layers, ", ")),
+			types.MRP("on", t.Kind()),
+			types.MRP("condition", t.Condition()),
+			types.MRP("next", next),
+			types.MRP("cooldown", cooldown),
+			types.MRP("enabled", cfg.Enabled),
+			types.MRP("problem", strings.Join(problems, "; ")),
+		)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type triggersLogSettings struct {
+	Limit int `glazed:"limit"`
+}
+
+type triggersLogCommand struct {
+	*cmds.CommandDescription
+	store *preset.Store
+}
+
+func newTriggersLogCommand(store *preset.Store) (*triggersLogCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &triggersLogCommand{
+		CommandDescription: cmds.NewCommandDescription("log",
+			cmds.WithShort("Show which triggers fired which presets"),
+			cmds.WithLong("Lists the trigger firings the daemon recorded in triggers.log, oldest first, "+
+				"with what set each off and any error applying the preset."),
+			cmds.WithFlags(
+				fields.New("limit", fields.TypeInteger, fields.WithDefault(50), fields.WithHelp("Maximum number of entries (0 for all)")),
+			),
+			cmds.WithSections(sections...),
+		),
+		store: store,
+	}, nil
+}
+
+func (c *triggersLogCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &triggersLogSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	entries, err := triggers.NewLog(triggers.LogPathNextTo(c.store.Path())).Read(s.Limit)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := gp.AddRow(ctx, types.NewRow(
+			types.MRP("timestamp", e.Time.Format(time.RFC3339)),
+			types.MRP("trigger", e.Trigger),
+			types.MRP("on", e.Kind),
+			types.MRP("preset", e.Preset),
+			types.MRP("event", e.Event),
+			types.MRP("ok", e.Error == ""),
+			types.MRP("error", e.Error),
+		)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ── Registration ────────────────────────────────────────────────────────────
+
+func Register(parent *cobra.Command, store *preset.Store, au audio.Service, res *resolve.Resolver) error {
+	listCmd, err := newListCommand(store)
+	if err != nil {
+		return err
+	}
+	applyCmd, err := newApplyCommand(store, au, res)
 	if err != nil {
 		return err
 	}
@@ -336,12 +1517,45 @@ func Register(parent *cobra.Command, store *preset.Store, au audio.Service) erro
 	if err != nil {
 		return err
 	}
+	showCmd, err := newShowCommand(store)
+	if err != nil {
+		return err
+	}
+	editCmd, err := newEditCommand(store, au, res)
+	if err != nil {
+		return err
+	}
+	validateCmd, err := newValidateCommand(store, au, res)
+	if err != nil {
+		return err
+	}
 	snapshotCmd, err := newSnapshotCommand(store, au)
 	if err != nil {
 		return err
 	}
+	planCmd, err := newPlanCommand(store, au, res)
+	if err != nil {
+		return err
+	}
+	exportCmd, err := newExportCommand(store, au)
+	if err != nil {
+		return err
+	}
+	importCmd, err := newImportCommand(store)
+	if err != nil {
+		return err
+	}
+
+	triggersListCmd, err := newTriggersListCommand(store)
+	if err != nil {
+		return err
+	}
+	triggersLogCmd, err := newTriggersLogCommand(store)
+	if err != nil {
+		return err
+	}
 
-	glazed := []cmds.Command{listCmd, saveCmd, deleteCmd}
+	glazed := []cmds.Command{listCmd, showCmd, saveCmd, deleteCmd, editCmd}
 	for _, command := range glazed {
 		cobraCmd, err := common.BuildCobra(command)
 		if err != nil {
@@ -350,8 +1564,18 @@ func Register(parent *cobra.Command, store *preset.Store, au audio.Service) erro
 		parent.AddCommand(cobraCmd)
 	}
 
-	// Dual mode for apply and snapshot (normal + glaze)
-	dual := []cmds.Command{applyCmd, snapshotCmd}
+	triggersCmd := &cobra.Command{Use: "triggers", Short: "Presets applied automatically on a schedule or on device and stream events"}
+	for _, command := range []cmds.Command{triggersListCmd, triggersLogCmd} {
+		cobraCmd, err := common.BuildCobra(command)
+		if err != nil {
+			return err
+		}
+		triggersCmd.AddCommand(cobraCmd)
+	}
+	parent.AddCommand(triggersCmd)
+
+	// Dual mode for apply, snapshot, plan, export, import and validate (normal + glaze)
+	dual := []cmds.Command{applyCmd, snapshotCmd, planCmd, exportCmd, importCmd, validateCmd}
 	for _, command := range dual {
 		cobraCmd, err := common.BuildCobraDual(command)
 		if err != nil {
@@ -2,6 +2,7 @@ package profiles
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-go-golems/glazed/pkg/cmds"
 	"github.com/go-go-golems/glazed/pkg/cmds/fields"
@@ -13,6 +14,10 @@ import (
 	"github.com/spf13/cobra"
 	"soundctl/pkg/cmd/common"
 	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/autoswitch"
+	"soundctl/pkg/soundctl/events"
+	sexec "soundctl/pkg/soundctl/exec"
+	"soundctl/pkg/soundctl/resolve"
 )
 
 type listCommand struct {
@@ -54,9 +59,10 @@ type setSettings struct {
 type setCommand struct {
 	*cmds.CommandDescription
 	svc audio.Service
+	res *resolve.Resolver
 }
 
-func newSetCommand(svc audio.Service) (*setCommand, error) {
+func newSetCommand(svc audio.Service, res *resolve.Resolver) (*setCommand, error) {
 	sections, err := common.DefaultSections()
 	if err != nil {
 		return nil, err
@@ -66,12 +72,13 @@ func newSetCommand(svc audio.Service) (*setCommand, error) {
 			"set",
 			cmds.WithShort("Set card profile"),
 			cmds.WithFlags(
-				fields.New("card", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Card name")),
+				fields.New("card", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Card index, name, description, alias or unique prefix")),
 				fields.New("profile", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Profile name")),
 			),
 			cmds.WithSections(sections...),
 		),
 		svc: svc,
+		res: res,
 	}, nil
 }
 
@@ -80,22 +87,278 @@ func (c *setCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Val
 	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
 		return errors.Wrap(err, "decode settings")
 	}
-	if err := c.svc.SetCardProfile(ctx, s.Card, s.Profile); err != nil {
+	card, err := c.res.Card(ctx, s.Card)
+	if err != nil {
+		return err
+	}
+	if err := c.svc.SetCardProfile(ctx, card.Name, s.Profile); err != nil {
+		return err
+	}
+	return gp.AddRow(ctx, types.NewRow(types.MRP("operation", "profiles.set"), types.MRP("card", card.Name), types.MRP("profile", s.Profile), types.MRP("ok", true)))
+}
+
+type codecsSettings struct {
+	Card string `glazed:"card"`
+}
+
+type codecsCommand struct {
+	*cmds.CommandDescription
+	svc audio.Service
+	res *resolve.Resolver
+}
+
+func newCodecsCommand(svc audio.Service, res *resolve.Resolver) (*codecsCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &codecsCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			"codecs",
+			cmds.WithShort("List bluetooth codecs per card"),
+			cmds.WithLong("Lists the codecs each bluetooth card offers, the profile that selects each one, and which "+
+				"codec is active. On PulseAudio, where codecs are not split into profiles, only the active codec is shown."),
+			cmds.WithFlags(
+				fields.New("card", fields.TypeString, fields.WithDefault(""), fields.WithHelp("Card index, name, description, alias or unique prefix (default: every bluetooth card)")),
+			),
+			cmds.WithSections(sections...),
+		),
+		svc: svc,
+		res: res,
+	}, nil
+}
+
+func (c *codecsCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &codecsSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	only := ""
+	if s.Card != "" {
+		card, err := c.res.Card(ctx, s.Card)
+		if err != nil {
+			return err
+		}
+		only = card.Name
+	}
+	cards, err := c.svc.ListCardsDetailed(ctx)
+	if err != nil {
+		return err
+	}
+	for _, card := range cards {
+		if only != "" && card.Name != only {
+			continue
+		}
+		if !audio.IsBluetoothCard(card.Name) {
+			continue
+		}
+		emitted := false
+		for _, p := range card.Profiles {
+			if p.Codec == "" {
+				continue
+			}
+			if err := gp.AddRow(ctx, codecRow(card, p.Codec, p.Name, p.Available, p.Description)); err != nil {
+				return err
+			}
+			emitted = true
+		}
+		if !emitted && card.Codec != "" {
+			if err := gp.AddRow(ctx, codecRow(card, card.Codec, card.ActiveProfile, true, "")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func codecRow(card audio.Card, codec, profile string, available bool, description string) types.Row {
+	return types.NewRow(
+		types.MRP("card", card.Name),
+		types.MRP("codec", codec),
+		types.MRP("profile", profile),
+		types.MRP("available", available),
+		types.MRP("active", codec == card.Codec),
+		types.MRP("description", description),
+	)
+}
+
+type setCodecSettings struct {
+	Card  string `glazed:"card"`
+	Codec string `glazed:"codec"`
+}
+
+type setCodecCommand struct {
+	*cmds.CommandDescription
+	svc audio.Service
+	res *resolve.Resolver
+}
+
+func newSetCodecCommand(svc audio.Service, res *resolve.Resolver) (*setCodecCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &setCodecCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			"set-codec",
+			cmds.WithShort("Switch a bluetooth card to another codec"),
+			cmds.WithLong("Activates the card profile that streams with the codec (e.g. a2dp-sink-aac on PipeWire). "+
+				"On PulseAudio the codec is switched within the active profile instead."),
+			cmds.WithFlags(
+				fields.New("card", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Card index, name, description, alias or unique prefix")),
+				fields.New("codec", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Codec, e.g. sbc, sbc_xq, aac, aptx_hd, ldac, msbc")),
+			),
+			cmds.WithSections(sections...),
+		),
+		svc: svc,
+		res: res,
+	}, nil
+}
+
+func (c *setCodecCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &setCodecSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	card, err := c.res.Card(ctx, s.Card)
+	if err != nil {
 		return err
 	}
-	return gp.AddRow(ctx, types.NewRow(types.MRP("operation", "profiles.set"), types.MRP("card", s.Card), types.MRP("profile", s.Profile), types.MRP("ok", true)))
+	profile, err := audio.SetCardCodec(ctx, c.svc, card.Name, s.Codec)
+	if err != nil {
+		return err
+	}
+	return gp.AddRow(ctx, types.NewRow(
+		types.MRP("operation", "profiles.set-codec"),
+		types.MRP("card", card.Name),
+		types.MRP("codec", audio.NormalizeCodec(s.Codec)),
+		types.MRP("profile", profile),
+		types.MRP("ok", true),
+	))
+}
+
+type autoswitchSettings struct {
+	Card        string  `glazed:"card"`
+	SwitchDelay float64 `glazed:"switch-delay"`
+	RevertDelay float64 `glazed:"revert-delay"`
+	DryRun      bool    `glazed:"dry-run"`
 }
 
-func Register(parent *cobra.Command, svc audio.Service) error {
+type autoswitchCommand struct {
+	*cmds.CommandDescription
+	svc      audio.Service
+	res      *resolve.Resolver
+	streamer sexec.Streamer
+}
+
+func newAutoswitchCommand(svc audio.Service, res *resolve.Resolver, streamer sexec.Streamer) (*autoswitchCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &autoswitchCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			"autoswitch",
+			cmds.WithShort("Switch bluetooth headsets between A2DP and headset profiles on mic use"),
+			cmds.WithLong("Runs until interrupted. When an app starts recording from a bluetooth headset (or from the "+
+				"default mic while the headset is the default sink), switches the card to its best headset "+
+				"(HFP/HSP) profile and makes the headset mic the default source; once nothing has recorded for "+
+				"--revert-delay seconds, switches back to A2DP and restores the previous default source. "+
+				"Emits one row per action."),
+			cmds.WithFlags(
+				fields.New("card", fields.TypeString, fields.WithDefault(""), fields.WithHelp("Card name, bluetooth address, alias or unique prefix (default: every bluetooth card)")),
+				fields.New("switch-delay", fields.TypeFloat, fields.WithDefault(autoswitch.DefaultSwitchDelay.Seconds()), fields.WithHelp("Seconds a recording stream must persist before switching to the headset profile")),
+				fields.New("revert-delay", fields.TypeFloat, fields.WithDefault(autoswitch.DefaultRevertDelay.Seconds()), fields.WithHelp("Seconds without recording streams before switching back to A2DP")),
+				fields.New("dry-run", fields.TypeBool, fields.WithDefault(false), fields.WithHelp("Log the switches without performing them")),
+			),
+			cmds.WithSections(sections...),
+		),
+		svc:      svc,
+		res:      res,
+		streamer: streamer,
+	}, nil
+}
+
+func (c *autoswitchCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &autoswitchSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+
+	// The headset need not be connected yet: a card that does not exist
+	// is matched by name or address once it appears.
+	if s.Card != "" {
+		card, err := c.res.Card(ctx, s.Card)
+		var notFound *resolve.NotFoundError
+		switch {
+		case err == nil:
+			s.Card = card.Name
+		case !errors.As(err, &notFound):
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sub := events.SubscribeAudio(ctx, c.streamer, events.Options{Notifier: audio.Notifier(c.svc)})
+	engine := autoswitch.NewEngine(c.svc, autoswitch.Config{
+		Card:        s.Card,
+		SwitchDelay: time.Duration(s.SwitchDelay * float64(time.Second)),
+		RevertDelay: time.Duration(s.RevertDelay * float64(time.Second)),
+		DryRun:      s.DryRun,
+	})
+
+	var rowErr error
+	err := engine.Run(ctx, sub.Events(), func(a autoswitch.Action) {
+		if rowErr != nil {
+			return
+		}
+		errText := ""
+		if a.Err != nil {
+			errText = a.Err.Error()
+		}
+		rowErr = gp.AddRow(ctx, types.NewRow(
+			types.MRP("timestamp", a.Time.Format(time.RFC3339)),
+			types.MRP("card", a.Card),
+			types.MRP("operation", a.Operation),
+			types.MRP("target", a.Target),
+			types.MRP("reason", a.Reason),
+			types.MRP("dry_run", a.DryRun),
+			types.MRP("ok", a.Err == nil),
+			types.MRP("error", errText),
+		))
+		if rowErr != nil {
+			cancel()
+		}
+	})
+	if rowErr != nil {
+		return rowErr
+	}
+	return err
+}
+
+func Register(parent *cobra.Command, svc audio.Service, res *resolve.Resolver, streamer sexec.Streamer) error {
 	listCmd, err := newListCommand(svc)
 	if err != nil {
 		return err
 	}
-	setCmd, err := newSetCommand(svc)
+	setCmd, err := newSetCommand(svc, res)
+	if err != nil {
+		return err
+	}
+	codecsCmd, err := newCodecsCommand(svc, res)
+	if err != nil {
+		return err
+	}
+	setCodecCmd, err := newSetCodecCommand(svc, res)
+	if err != nil {
+		return err
+	}
+	autoswitchCmd, err := newAutoswitchCommand(svc, res, streamer)
 	if err != nil {
 		return err
 	}
-	for _, command := range []cmds.Command{listCmd, setCmd} {
+	for _, command := range []cmds.Command{listCmd, setCmd, codecsCmd, setCodecCmd, autoswitchCmd} {
 		cobraCmd, err := common.BuildCobra(command)
 		if err != nil {
 			return err
@@ -9,17 +9,26 @@ import (
 	"github.com/go-go-golems/glazed/pkg/help"
 	help_cmd "github.com/go-go-golems/glazed/pkg/help/cmd"
 	"github.com/spf13/cobra"
+	"soundctl/pkg/cmd/alias"
+	"soundctl/pkg/cmd/daemon"
 	"soundctl/pkg/cmd/devices"
 	"soundctl/pkg/cmd/mute"
 	"soundctl/pkg/cmd/presets"
 	"soundctl/pkg/cmd/profiles"
+	"soundctl/pkg/cmd/rules"
 	"soundctl/pkg/cmd/scan"
 	"soundctl/pkg/cmd/sinks"
 	"soundctl/pkg/cmd/sources"
+	"soundctl/pkg/cmd/streams"
 	"soundctl/pkg/cmd/volume"
+	"soundctl/pkg/cmd/watch"
+	salias "soundctl/pkg/soundctl/alias"
 	"soundctl/pkg/soundctl/audio"
 	"soundctl/pkg/soundctl/bluetooth"
+	sexec "soundctl/pkg/soundctl/exec"
 	"soundctl/pkg/soundctl/preset"
+	"soundctl/pkg/soundctl/resolve"
+	srules "soundctl/pkg/soundctl/rules"
 	"soundctl/pkg/tui"
 )
 
@@ -27,6 +36,7 @@ type Dependencies struct {
 	Bluetooth   bluetooth.Service
 	Audio       audio.Service
 	PresetStore *preset.Store
+	Streamer    sexec.Streamer
 }
 
 func NewRootCommand(deps Dependencies) (*cobra.Command, error) {
@@ -57,42 +67,64 @@ func NewRootCommand(deps Dependencies) (*cobra.Command, error) {
 		{Use: "volume", Short: "Volume operations"},
 		{Use: "mute", Short: "Mute operations"},
 		{Use: "presets", Short: "Preset management (save/apply/snapshot)"},
+		{Use: "rules", Short: "Automatic routing rules for bluetooth devices"},
+		{Use: "streams", Short: "Per-app playback stream operations"},
+		{Use: "alias", Short: "Friendly names for devices, sinks, sources and cards"},
 	}
 	for _, g := range groups {
 		rootCmd.AddCommand(g)
 	}
 
-	if err := devices.Register(groups[0], deps.Bluetooth); err != nil {
+	aliasStore := salias.NewStore(salias.PathNextTo(deps.PresetStore.Path()))
+	resolver := resolve.New(deps.Audio, deps.Bluetooth).WithAliases(aliasStore.Map)
+
+	if err := devices.Register(groups[0], deps.Bluetooth, resolver); err != nil {
 		return nil, fmt.Errorf("register devices commands: %w", err)
 	}
-	if err := scan.Register(groups[1], deps.Bluetooth); err != nil {
+	if err := scan.Register(groups[1], deps.Bluetooth, resolver); err != nil {
 		return nil, fmt.Errorf("register scan commands: %w", err)
 	}
-	if err := sinks.Register(groups[2], deps.Audio); err != nil {
+	if err := sinks.Register(groups[2], deps.Audio, resolver); err != nil {
 		return nil, fmt.Errorf("register sinks commands: %w", err)
 	}
-	if err := sources.Register(groups[3], deps.Audio); err != nil {
+	if err := sources.Register(groups[3], deps.Audio, resolver); err != nil {
 		return nil, fmt.Errorf("register sources commands: %w", err)
 	}
-	if err := profiles.Register(groups[4], deps.Audio); err != nil {
+	if err := profiles.Register(groups[4], deps.Audio, resolver, deps.Streamer); err != nil {
 		return nil, fmt.Errorf("register profiles commands: %w", err)
 	}
-	if err := volume.Register(groups[5], deps.Audio); err != nil {
+	if err := volume.Register(groups[5], deps.Audio, resolver); err != nil {
 		return nil, fmt.Errorf("register volume commands: %w", err)
 	}
-	if err := mute.Register(groups[6], deps.Audio); err != nil {
+	if err := mute.Register(groups[6], deps.Audio, resolver); err != nil {
 		return nil, fmt.Errorf("register mute commands: %w", err)
 	}
-	if err := presets.Register(groups[7], deps.PresetStore, deps.Audio); err != nil {
+	if err := presets.Register(groups[7], deps.PresetStore, deps.Audio, resolver); err != nil {
 		return nil, fmt.Errorf("register presets commands: %w", err)
 	}
+	if err := rules.Register(groups[8], srules.PathNextTo(deps.PresetStore.Path()), deps.Audio, deps.Streamer); err != nil {
+		return nil, fmt.Errorf("register rules commands: %w", err)
+	}
+	if err := streams.Register(groups[9], deps.Audio); err != nil {
+		return nil, fmt.Errorf("register streams commands: %w", err)
+	}
+	if err := alias.Register(groups[10], aliasStore); err != nil {
+		return nil, fmt.Errorf("register alias commands: %w", err)
+	}
+
+	if err := watch.Register(rootCmd, deps.Audio, deps.Bluetooth, deps.Streamer); err != nil {
+		return nil, fmt.Errorf("register watch command: %w", err)
+	}
+	if err := daemon.Register(rootCmd, deps.Audio, deps.Bluetooth, deps.PresetStore, deps.Streamer, resolver, srules.PathNextTo(deps.PresetStore.Path())); err != nil {
+		return nil, fmt.Errorf("register daemon command: %w", err)
+	}
 
 	// TUI subcommand
 	tuiCmd := &cobra.Command{
 		Use:   "tui",
 		Short: "Launch the interactive Bubble Tea TUI",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			model := tui.NewAppModel(deps.Bluetooth, deps.Audio, deps.PresetStore)
+			model := tui.NewAppModel(deps.Bluetooth, deps.Audio, deps.PresetStore, deps.Streamer)
 			p := tea.NewProgram(model, tea.WithAltScreen())
 			_, err := p.Run()
 			return err
@@ -0,0 +1,153 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/spf13/cobra"
+	"soundctl/pkg/cmd/common"
+	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/events"
+	sexec "soundctl/pkg/soundctl/exec"
+	"soundctl/pkg/soundctl/rules"
+)
+
+// ── list ────────────────────────────────────────────────────────────────────
+
+type listCommand struct {
+	*cmds.CommandDescription
+	path string
+}
+
+func newListCommand(path string) (*listCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &listCommand{
+		CommandDescription: cmds.NewCommandDescription("list",
+			cmds.WithShort("List configured routing rules"),
+			cmds.WithSections(sections...),
+		),
+		path: path,
+	}, nil
+}
+
+func (c *listCommand) RunIntoGlazeProcessor(ctx context.Context, _ *values.Values, gp middlewares.Processor) error {
+	cfg, err := rules.Load(c.path)
+	if err != nil {
+		return err
+	}
+	for _, r := range cfg.Rules {
+		sink := r.Sink
+		if sink == "" {
+			sink = "(device sink)"
+		}
+		if err := gp.AddRow(ctx, types.NewRow(
+			types.MRP("address", r.Address),
+			types.MRP("label", r.Label),
+			types.MRP("sink", sink),
+			types.MRP("source", r.Source),
+			types.MRP("move_streams", r.MoveStreams),
+			types.MRP("restore", r.Restore),
+			types.MRP("enabled", cfg.Enabled),
+		)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ── run ─────────────────────────────────────────────────────────────────────
+
+type runCommand struct {
+	*cmds.CommandDescription
+	path     string
+	au       audio.Service
+	streamer sexec.Streamer
+}
+
+func newRunCommand(path string, au audio.Service, streamer sexec.Streamer) (*runCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &runCommand{
+		CommandDescription: cmds.NewCommandDescription("run",
+			cmds.WithShort("Run the routing rules engine until interrupted"),
+			cmds.WithLong("Listens for bluetooth connects/disconnects and new sinks, and applies the rules in "+
+				"rules.yaml (next to presets.yaml). Emits one row per action taken."),
+			cmds.WithSections(sections...),
+		),
+		path:     path,
+		au:       au,
+		streamer: streamer,
+	}, nil
+}
+
+func (c *runCommand) RunIntoGlazeProcessor(ctx context.Context, _ *values.Values, gp middlewares.Processor) error {
+	cfg, err := rules.Load(c.path)
+	if err != nil {
+		return err
+	}
+	if !cfg.Enabled {
+		return fmt.Errorf("rules are disabled; set enabled: true in %s", c.path)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	audioSub := events.SubscribeAudio(ctx, c.streamer, events.Options{Notifier: audio.Notifier(c.au)})
+	btSub := events.SubscribeBluetooth(ctx, c.streamer, events.Options{})
+
+	var rowErr error
+	err = rules.NewEngine(c.au, cfg).Run(ctx, audioSub.Events(), btSub.Events(), func(a rules.Action) {
+		if rowErr != nil {
+			return
+		}
+		errText := ""
+		if a.Err != nil {
+			errText = a.Err.Error()
+		}
+		rowErr = gp.AddRow(ctx, types.NewRow(
+			types.MRP("timestamp", a.Time.Format(time.RFC3339)),
+			types.MRP("address", a.Address),
+			types.MRP("operation", a.Operation),
+			types.MRP("target", a.Target),
+			types.MRP("ok", a.Err == nil),
+			types.MRP("error", errText),
+		))
+		if rowErr != nil {
+			cancel()
+		}
+	})
+	if rowErr != nil {
+		return rowErr
+	}
+	return err
+}
+
+// ── Registration ────────────────────────────────────────────────────────────
+
+func Register(parent *cobra.Command, path string, au audio.Service, streamer sexec.Streamer) error {
+	listCmd, err := newListCommand(path)
+	if err != nil {
+		return err
+	}
+	runCmd, err := newRunCommand(path, au, streamer)
+	if err != nil {
+		return err
+	}
+	for _, command := range []cmds.Command{listCmd, runCmd} {
+		cobraCmd, err := common.BuildCobra(command)
+		if err != nil {
+			return err
+		}
+		parent.AddCommand(cobraCmd)
+	}
+	return nil
+}
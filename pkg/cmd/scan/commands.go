@@ -15,6 +15,7 @@ import (
 	"github.com/spf13/cobra"
 	"soundctl/pkg/cmd/common"
 	"soundctl/pkg/soundctl/bluetooth"
+	"soundctl/pkg/soundctl/resolve"
 )
 
 type actionCommand struct {
@@ -149,9 +150,10 @@ type pairSettings struct {
 type pairCommand struct {
 	*cmds.CommandDescription
 	svc bluetooth.Service
+	res *resolve.Resolver
 }
 
-func newPairCommand(svc bluetooth.Service) (*pairCommand, error) {
+func newPairCommand(svc bluetooth.Service, res *resolve.Resolver) (*pairCommand, error) {
 	sections, err := common.DefaultSections()
 	if err != nil {
 		return nil, err
@@ -162,7 +164,7 @@ func newPairCommand(svc bluetooth.Service) (*pairCommand, error) {
 			cmds.WithShort("Pair a bluetooth device and optionally trust/connect"),
 			cmds.WithLong("If --wait is set, run timed discovery before pairing. If --addr is omitted, pairing target is chosen from discovered devices (optionally filtered by --name-filter)."),
 			cmds.WithFlags(
-				fields.New("addr", fields.TypeString, fields.WithDefault(""), fields.WithHelp("Bluetooth MAC address")),
+				fields.New("addr", fields.TypeString, fields.WithDefault(""), fields.WithHelp("Bluetooth MAC address, device name, alias or unique prefix")),
 				fields.New("trust", fields.TypeBool, fields.WithDefault(true), fields.WithHelp("Trust after pair")),
 				fields.New("connect", fields.TypeBool, fields.WithDefault(false), fields.WithHelp("Connect after pair")),
 				fields.New("wait", fields.TypeInteger, fields.WithDefault(0), fields.WithHelp("Optional pre-pair scan duration in seconds")),
@@ -247,16 +249,24 @@ func (c *pairCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Va
 	))
 }
 
+// resolveAddress picks the device to pair. --addr goes through the
+// resolver after any discovery, so it may name a device just found; an
+// address bluez does not know yet is used as given.
 func (c *pairCommand) resolveAddress(ctx context.Context, s *pairSettings) (string, []bluetooth.DiscoveredDevice, error) {
 	if s.Addr != "" {
-		if s.Wait <= 0 {
-			return s.Addr, nil, nil
+		var found []bluetooth.DiscoveredDevice
+		if s.Wait > 0 {
+			all, err := c.svc.Discover(ctx, s.Wait)
+			if err != nil {
+				return "", nil, err
+			}
+			found = filterDiscovered(all, s.NameFilter)
 		}
-		found, err := c.svc.Discover(ctx, s.Wait)
+		device, err := c.res.Device(ctx, s.Addr)
 		if err != nil {
-			return "", nil, err
+			return "", found, err
 		}
-		return s.Addr, filterDiscovered(found, s.NameFilter), nil
+		return device.Address, found, nil
 	}
 
 	if s.Wait <= 0 {
@@ -294,7 +304,7 @@ func filterDiscovered(found []bluetooth.DiscoveredDevice, filter string) []bluet
 	return filtered
 }
 
-func Register(parent *cobra.Command, svc bluetooth.Service) error {
+func Register(parent *cobra.Command, svc bluetooth.Service, res *resolve.Resolver) error {
 	startCmd, err := newActionCommand("start", "Start bluetooth scanning (best effort)", "scan.start", "Triggered scan start (best effort; use scan discover --wait N to verify findings).", svc, func(ctx context.Context, s bluetooth.Service) error {
 		return s.StartScan(ctx)
 	})
@@ -311,7 +321,7 @@ func Register(parent *cobra.Command, svc bluetooth.Service) error {
 	if err != nil {
 		return err
 	}
-	pairCmd, err := newPairCommand(svc)
+	pairCmd, err := newPairCommand(svc, res)
 	if err != nil {
 		return err
 	}
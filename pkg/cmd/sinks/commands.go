@@ -13,6 +13,7 @@ import (
 	"github.com/spf13/cobra"
 	"soundctl/pkg/cmd/common"
 	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/resolve"
 )
 
 type listCommand struct {
@@ -29,7 +30,7 @@ func newListCommand(svc audio.Service) (*listCommand, error) {
 }
 
 func (c *listCommand) RunIntoGlazeProcessor(ctx context.Context, _ *values.Values, gp middlewares.Processor) error {
-	sinks, err := c.svc.ListSinks(ctx)
+	sinks, err := c.svc.ListSinksDetailed(ctx)
 	if err != nil {
 		return err
 	}
@@ -37,9 +38,13 @@ func (c *listCommand) RunIntoGlazeProcessor(ctx context.Context, _ *values.Value
 		if err := gp.AddRow(ctx, types.NewRow(
 			types.MRP("id", sink.ID),
 			types.MRP("name", sink.Name),
+			types.MRP("description", sink.Description),
 			types.MRP("driver", sink.Driver),
 			types.MRP("sample_spec", sink.SampleSpec),
 			types.MRP("state", sink.State),
+			types.MRP("volume", sink.VolumePercent()),
+			types.MRP("balance", sink.Balance),
+			types.MRP("muted", sink.Muted),
 		)); err != nil {
 			return err
 		}
@@ -54,9 +59,10 @@ type setDefaultSettings struct {
 type setDefaultCommand struct {
 	*cmds.CommandDescription
 	svc audio.Service
+	res *resolve.Resolver
 }
 
-func newSetDefaultCommand(svc audio.Service) (*setDefaultCommand, error) {
+func newSetDefaultCommand(svc audio.Service, res *resolve.Resolver) (*setDefaultCommand, error) {
 	sections, err := common.DefaultSections()
 	if err != nil {
 		return nil, err
@@ -65,10 +71,11 @@ func newSetDefaultCommand(svc audio.Service) (*setDefaultCommand, error) {
 		CommandDescription: cmds.NewCommandDescription(
 			"set-default",
 			cmds.WithShort("Set default sink"),
-			cmds.WithFlags(fields.New("sink", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Sink name or ID"))),
+			cmds.WithFlags(fields.New("sink", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Sink ID, name, description, alias or unique prefix"))),
 			cmds.WithSections(sections...),
 		),
 		svc: svc,
+		res: res,
 	}, nil
 }
 
@@ -77,10 +84,14 @@ func (c *setDefaultCommand) RunIntoGlazeProcessor(ctx context.Context, vals *val
 	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
 		return errors.Wrap(err, "decode settings")
 	}
-	if err := c.svc.SetDefaultSink(ctx, s.Sink); err != nil {
+	sink, err := c.res.Sink(ctx, s.Sink)
+	if err != nil {
+		return err
+	}
+	if err := c.svc.SetDefaultSink(ctx, sink.Name); err != nil {
 		return err
 	}
-	return gp.AddRow(ctx, types.NewRow(types.MRP("operation", "sinks.set-default"), types.MRP("sink", s.Sink), types.MRP("ok", true)))
+	return gp.AddRow(ctx, types.NewRow(types.MRP("operation", "sinks.set-default"), types.MRP("sink", sink.Name), types.MRP("ok", true)))
 }
 
 type moveSettings struct {
@@ -91,9 +102,10 @@ type moveSettings struct {
 type moveCommand struct {
 	*cmds.CommandDescription
 	svc audio.Service
+	res *resolve.Resolver
 }
 
-func newMoveCommand(svc audio.Service) (*moveCommand, error) {
+func newMoveCommand(svc audio.Service, res *resolve.Resolver) (*moveCommand, error) {
 	sections, err := common.DefaultSections()
 	if err != nil {
 		return nil, err
@@ -104,11 +116,12 @@ func newMoveCommand(svc audio.Service) (*moveCommand, error) {
 			cmds.WithShort("Move sink input stream to another sink"),
 			cmds.WithFlags(
 				fields.New("stream-id", fields.TypeInteger, fields.WithRequired(true), fields.WithHelp("Sink input stream ID")),
-				fields.New("sink", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Sink name or ID")),
+				fields.New("sink", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Sink ID, name, description, alias or unique prefix")),
 			),
 			cmds.WithSections(sections...),
 		),
 		svc: svc,
+		res: res,
 	}, nil
 }
 
@@ -117,22 +130,26 @@ func (c *moveCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Va
 	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
 		return errors.Wrap(err, "decode settings")
 	}
-	if err := c.svc.MoveSinkInput(ctx, s.StreamID, s.Sink); err != nil {
+	sink, err := c.res.Sink(ctx, s.Sink)
+	if err != nil {
+		return err
+	}
+	if err := c.svc.MoveSinkInput(ctx, s.StreamID, sink.Name); err != nil {
 		return err
 	}
-	return gp.AddRow(ctx, types.NewRow(types.MRP("operation", "sinks.move-stream"), types.MRP("stream_id", s.StreamID), types.MRP("sink", s.Sink), types.MRP("ok", true)))
+	return gp.AddRow(ctx, types.NewRow(types.MRP("operation", "sinks.move-stream"), types.MRP("stream_id", s.StreamID), types.MRP("sink", sink.Name), types.MRP("ok", true)))
 }
 
-func Register(parent *cobra.Command, svc audio.Service) error {
+func Register(parent *cobra.Command, svc audio.Service, res *resolve.Resolver) error {
 	listCmd, err := newListCommand(svc)
 	if err != nil {
 		return err
 	}
-	setDefaultCmd, err := newSetDefaultCommand(svc)
+	setDefaultCmd, err := newSetDefaultCommand(svc, res)
 	if err != nil {
 		return err
 	}
-	moveCmd, err := newMoveCommand(svc)
+	moveCmd, err := newMoveCommand(svc, res)
 	if err != nil {
 		return err
 	}
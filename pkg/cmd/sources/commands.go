@@ -13,6 +13,7 @@ import (
 	"github.com/spf13/cobra"
 	"soundctl/pkg/cmd/common"
 	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/resolve"
 )
 
 type listCommand struct {
@@ -29,7 +30,7 @@ func newListCommand(svc audio.Service) (*listCommand, error) {
 }
 
 func (c *listCommand) RunIntoGlazeProcessor(ctx context.Context, _ *values.Values, gp middlewares.Processor) error {
-	sources, err := c.svc.ListSources(ctx)
+	sources, err := c.svc.ListSourcesDetailed(ctx)
 	if err != nil {
 		return err
 	}
@@ -37,9 +38,13 @@ func (c *listCommand) RunIntoGlazeProcessor(ctx context.Context, _ *values.Value
 		if err := gp.AddRow(ctx, types.NewRow(
 			types.MRP("id", source.ID),
 			types.MRP("name", source.Name),
+			types.MRP("description", source.Description),
 			types.MRP("driver", source.Driver),
 			types.MRP("sample_spec", source.SampleSpec),
 			types.MRP("state", source.State),
+			types.MRP("volume", source.VolumePercent()),
+			types.MRP("balance", source.Balance),
+			types.MRP("muted", source.Muted),
 		)); err != nil {
 			return err
 		}
@@ -54,9 +59,10 @@ type setDefaultSettings struct {
 type setDefaultCommand struct {
 	*cmds.CommandDescription
 	svc audio.Service
+	res *resolve.Resolver
 }
 
-func newSetDefaultCommand(svc audio.Service) (*setDefaultCommand, error) {
+func newSetDefaultCommand(svc audio.Service, res *resolve.Resolver) (*setDefaultCommand, error) {
 	sections, err := common.DefaultSections()
 	if err != nil {
 		return nil, err
@@ -65,10 +71,11 @@ func newSetDefaultCommand(svc audio.Service) (*setDefaultCommand, error) {
 		CommandDescription: cmds.NewCommandDescription(
 			"set-default",
 			cmds.WithShort("Set default source"),
-			cmds.WithFlags(fields.New("source", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Source name or ID"))),
+			cmds.WithFlags(fields.New("source", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Source ID, name, description, alias or unique prefix"))),
 			cmds.WithSections(sections...),
 		),
 		svc: svc,
+		res: res,
 	}, nil
 }
 
@@ -77,22 +84,112 @@ func (c *setDefaultCommand) RunIntoGlazeProcessor(ctx context.Context, vals *val
 	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
 		return errors.Wrap(err, "decode settings")
 	}
-	if err := c.svc.SetDefaultSource(ctx, s.Source); err != nil {
+	source, err := c.res.Source(ctx, s.Source)
+	if err != nil {
+		return err
+	}
+	if err := c.svc.SetDefaultSource(ctx, source.Name); err != nil {
 		return err
 	}
-	return gp.AddRow(ctx, types.NewRow(types.MRP("operation", "sources.set-default"), types.MRP("source", s.Source), types.MRP("ok", true)))
+	return gp.AddRow(ctx, types.NewRow(types.MRP("operation", "sources.set-default"), types.MRP("source", source.Name), types.MRP("ok", true)))
+}
+
+type streamsCommand struct {
+	*cmds.CommandDescription
+	svc audio.Service
 }
 
-func Register(parent *cobra.Command, svc audio.Service) error {
+func newStreamsCommand(svc audio.Service) (*streamsCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &streamsCommand{CommandDescription: cmds.NewCommandDescription("streams", cmds.WithShort("List recording streams (source outputs) and the source each reads from"), cmds.WithSections(sections...)), svc: svc}, nil
+}
+
+func (c *streamsCommand) RunIntoGlazeProcessor(ctx context.Context, _ *values.Values, gp middlewares.Processor) error {
+	outputs, err := c.svc.ListSourceOutputs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, out := range outputs {
+		if err := gp.AddRow(ctx, types.NewRow(
+			types.MRP("id", out.Index),
+			types.MRP("app", out.AppName),
+			types.MRP("media", out.MediaName),
+			types.MRP("source_id", out.SourceIndex),
+			types.MRP("source", out.SourceName),
+		)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type moveSettings struct {
+	StreamID int    `glazed:"stream-id"`
+	Source   string `glazed:"source"`
+}
+
+type moveCommand struct {
+	*cmds.CommandDescription
+	svc audio.Service
+	res *resolve.Resolver
+}
+
+func newMoveCommand(svc audio.Service, res *resolve.Resolver) (*moveCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &moveCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			"move",
+			cmds.WithShort("Move a recording stream to another source"),
+			cmds.WithFlags(
+				fields.New("stream-id", fields.TypeInteger, fields.WithRequired(true), fields.WithHelp("Source output stream ID")),
+				fields.New("source", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Source ID, name, description, alias or unique prefix")),
+			),
+			cmds.WithSections(sections...),
+		),
+		svc: svc,
+		res: res,
+	}, nil
+}
+
+func (c *moveCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &moveSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	source, err := c.res.Source(ctx, s.Source)
+	if err != nil {
+		return err
+	}
+	if err := c.svc.MoveSourceOutput(ctx, s.StreamID, source.Name); err != nil {
+		return err
+	}
+	return gp.AddRow(ctx, types.NewRow(types.MRP("operation", "sources.move"), types.MRP("stream_id", s.StreamID), types.MRP("source", source.Name), types.MRP("ok", true)))
+}
+
+func Register(parent *cobra.Command, svc audio.Service, res *resolve.Resolver) error {
 	listCmd, err := newListCommand(svc)
 	if err != nil {
 		return err
 	}
-	setDefaultCmd, err := newSetDefaultCommand(svc)
+	setDefaultCmd, err := newSetDefaultCommand(svc, res)
+	if err != nil {
+		return err
+	}
+	streamsCmd, err := newStreamsCommand(svc)
+	if err != nil {
+		return err
+	}
+	moveCmd, err := newMoveCommand(svc, res)
 	if err != nil {
 		return err
 	}
-	for _, command := range []cmds.Command{listCmd, setDefaultCmd} {
+	for _, command := range []cmds.Command{listCmd, setDefaultCmd, streamsCmd, moveCmd} {
 		cobraCmd, err := common.BuildCobra(command)
 		if err != nil {
 			return err
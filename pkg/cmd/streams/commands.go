@@ -0,0 +1,166 @@
+package streams
+
+import (
+	"context"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"soundctl/pkg/cmd/common"
+	"soundctl/pkg/soundctl/audio"
+)
+
+type listCommand struct {
+	*cmds.CommandDescription
+	svc audio.Service
+}
+
+func newListCommand(svc audio.Service) (*listCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &listCommand{CommandDescription: cmds.NewCommandDescription("list", cmds.WithShort("List playback streams (sink inputs) with their volume"), cmds.WithSections(sections...)), svc: svc}, nil
+}
+
+func (c *listCommand) RunIntoGlazeProcessor(ctx context.Context, _ *values.Values, gp middlewares.Processor) error {
+	inputs, err := c.svc.ListSinkInputs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, si := range inputs {
+		if err := gp.AddRow(ctx, types.NewRow(
+			types.MRP("id", si.Index),
+			types.MRP("app", si.AppName),
+			types.MRP("media", si.MediaName),
+			types.MRP("binary", si.Binary),
+			types.MRP("pid", si.PID),
+			types.MRP("client", si.Client),
+			types.MRP("sink", si.SinkName),
+			types.MRP("volume", si.VolumePercent()),
+			types.MRP("muted", si.Muted),
+			types.MRP("corked", si.Corked),
+		)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type volumeSettings struct {
+	StreamID int `glazed:"stream-id"`
+	Percent  int `glazed:"percent"`
+}
+
+type volumeCommand struct {
+	*cmds.CommandDescription
+	svc audio.Service
+}
+
+func newVolumeCommand(svc audio.Service) (*volumeCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &volumeCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			"volume",
+			cmds.WithShort("Set a playback stream's volume"),
+			cmds.WithFlags(
+				fields.New("stream-id", fields.TypeInteger, fields.WithRequired(true), fields.WithHelp("Sink input stream ID")),
+				fields.New("percent", fields.TypeInteger, fields.WithRequired(true), fields.WithHelp("Volume percent (0-150)")),
+			),
+			cmds.WithSections(sections...),
+		),
+		svc: svc,
+	}, nil
+}
+
+func (c *volumeCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &volumeSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	if err := c.svc.SetSinkInputVolume(ctx, s.StreamID, s.Percent); err != nil {
+		return err
+	}
+	return gp.AddRow(ctx, types.NewRow(
+		types.MRP("operation", "streams.volume"),
+		types.MRP("stream_id", s.StreamID),
+		types.MRP("percent", s.Percent),
+		types.MRP("ok", true),
+	))
+}
+
+type muteSettings struct {
+	StreamID int  `glazed:"stream-id"`
+	Muted    bool `glazed:"muted"`
+}
+
+type muteCommand struct {
+	*cmds.CommandDescription
+	svc audio.Service
+}
+
+func newMuteCommand(svc audio.Service) (*muteCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &muteCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			"mute",
+			cmds.WithShort("Mute or unmute a playback stream"),
+			cmds.WithFlags(
+				fields.New("stream-id", fields.TypeInteger, fields.WithRequired(true), fields.WithHelp("Sink input stream ID")),
+				fields.New("muted", fields.TypeBool, fields.WithDefault(true), fields.WithHelp("Mute (true) or unmute (false)")),
+			),
+			cmds.WithSections(sections...),
+		),
+		svc: svc,
+	}, nil
+}
+
+func (c *muteCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &muteSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	if err := c.svc.SetSinkInputMute(ctx, s.StreamID, s.Muted); err != nil {
+		return err
+	}
+	return gp.AddRow(ctx, types.NewRow(
+		types.MRP("operation", "streams.mute"),
+		types.MRP("stream_id", s.StreamID),
+		types.MRP("muted", s.Muted),
+		types.MRP("ok", true),
+	))
+}
+
+func Register(parent *cobra.Command, svc audio.Service) error {
+	listCmd, err := newListCommand(svc)
+	if err != nil {
+		return err
+	}
+	volumeCmd, err := newVolumeCommand(svc)
+	if err != nil {
+		return err
+	}
+	muteCmd, err := newMuteCommand(svc)
+	if err != nil {
+		return err
+	}
+	for _, command := range []cmds.Command{listCmd, volumeCmd, muteCmd} {
+		cobraCmd, err := common.BuildCobra(command)
+		if err != nil {
+			return err
+		}
+		parent.AddCommand(cobraCmd)
+	}
+	return nil
+}
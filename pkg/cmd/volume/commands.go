@@ -2,6 +2,8 @@ package volume
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/go-go-golems/glazed/pkg/cmds"
 	"github.com/go-go-golems/glazed/pkg/cmds/fields"
@@ -13,20 +15,23 @@ import (
 	"github.com/spf13/cobra"
 	"soundctl/pkg/cmd/common"
 	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/resolve"
 )
 
 type setSettings struct {
 	Target  string `glazed:"target"`
 	Name    string `glazed:"name"`
 	Percent int    `glazed:"percent"`
+	Volume  string `glazed:"volume"`
 }
 
 type setCommand struct {
 	*cmds.CommandDescription
 	svc audio.Service
+	res *resolve.Resolver
 }
 
-func newSetCommand(svc audio.Service) (*setCommand, error) {
+func newSetCommand(svc audio.Service, res *resolve.Resolver) (*setCommand, error) {
 	sections, err := common.DefaultSections()
 	if err != nil {
 		return nil, err
@@ -35,14 +40,19 @@ func newSetCommand(svc audio.Service) (*setCommand, error) {
 		CommandDescription: cmds.NewCommandDescription(
 			"set",
 			cmds.WithShort("Set sink/source volume percentage"),
+			cmds.WithLong("Set a sink or source volume, either as a percentage with --percent or as a\n"+
+				"volume spec with --volume: \"60%\", \"+5%\", \"-6dB\", or one value per\n"+
+				"channel such as \"70%,60%\". Results are clamped to 0-150%."),
 			cmds.WithFlags(
 				fields.New("target", fields.TypeString, fields.WithDefault("sink"), fields.WithHelp("Target type: sink or source")),
-				fields.New("name", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Sink/source name or ID")),
-				fields.New("percent", fields.TypeInteger, fields.WithRequired(true), fields.WithHelp("Volume percent (0-150)")),
+				fields.New("name", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Sink/source ID, name, description, alias or unique prefix")),
+				fields.New("percent", fields.TypeInteger, fields.WithDefault(-1), fields.WithHelp("Volume percent (0-150)")),
+				fields.New("volume", fields.TypeString, fields.WithDefault(""), fields.WithHelp("Volume spec (60%, +5%, -6dB, 70%,60%)")),
 			),
 			cmds.WithSections(sections...),
 		),
 		svc: svc,
+		res: res,
 	}, nil
 }
 
@@ -51,27 +61,195 @@ func (c *setCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Val
 	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
 		return errors.Wrap(err, "decode settings")
 	}
-	if err := c.svc.SetVolume(ctx, s.Target, s.Name, s.Percent); err != nil {
+	dev, err := c.res.Target(ctx, s.Target, s.Name)
+	if err != nil {
+		return err
+	}
+	if s.Volume != "" {
+		if s.Percent >= 0 {
+			return fmt.Errorf("use either --percent or --volume")
+		}
+		spec, err := audio.ParseVolumeSpec(s.Volume)
+		if err != nil {
+			return err
+		}
+		changed, err := audio.ChangeVolume(ctx, c.svc, s.Target, dev.Name, spec)
+		if err != nil {
+			return err
+		}
+		return gp.AddRow(ctx, levelRow("volume.set", s.Target, changed))
+	}
+	if s.Percent < 0 {
+		return fmt.Errorf("--percent or --volume is required")
+	}
+	if err := c.svc.SetVolume(ctx, s.Target, dev.Name, s.Percent); err != nil {
 		return err
 	}
 	return gp.AddRow(ctx, types.NewRow(
 		types.MRP("operation", "volume.set"),
 		types.MRP("target", s.Target),
-		types.MRP("name", s.Name),
+		types.MRP("name", dev.Name),
 		types.MRP("percent", s.Percent),
 		types.MRP("ok", true),
 	))
 }
 
-func Register(parent *cobra.Command, svc audio.Service) error {
-	setCmd, err := newSetCommand(svc)
+type stepSettings struct {
+	Target string `glazed:"target"`
+	Name   string `glazed:"name"`
+	Step   string `glazed:"step"`
+}
+
+// stepCommand implements "volume up" and "volume down".
+type stepCommand struct {
+	*cmds.CommandDescription
+	svc  audio.Service
+	res  *resolve.Resolver
+	sign string
+}
+
+func newStepCommand(svc audio.Service, res *resolve.Resolver, name string, sign string) (*stepCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	direction := "Raise"
+	if sign == "-" {
+		direction = "Lower"
+	}
+	return &stepCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			name,
+			cmds.WithShort(direction+" sink/source volume by a step and print the resulting level"),
+			cmds.WithFlags(
+				fields.New("target", fields.TypeString, fields.WithDefault("sink"), fields.WithHelp("Target type: sink or source")),
+				fields.New("name", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Sink/source ID, name, description, alias or unique prefix")),
+				fields.New("step", fields.TypeString, fields.WithDefault("5%"), fields.WithHelp("Step size in percent (5%) or dB (3dB)")),
+			),
+			cmds.WithSections(sections...),
+		),
+		svc:  svc,
+		res:  res,
+		sign: sign,
+	}, nil
+}
+
+func (c *stepCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &stepSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	step := strings.TrimPrefix(strings.TrimSpace(s.Step), "+")
+	if strings.HasPrefix(step, "-") || strings.Contains(step, ",") {
+		return fmt.Errorf("invalid step %q: expected a single positive value", s.Step)
+	}
+	spec, err := audio.ParseVolumeSpec(c.sign + step)
+	if err != nil {
+		return err
+	}
+	dev, err := c.res.Target(ctx, s.Target, s.Name)
 	if err != nil {
 		return err
 	}
-	cobraCmd, err := common.BuildCobra(setCmd)
+	changed, err := audio.ChangeVolume(ctx, c.svc, s.Target, dev.Name, spec)
 	if err != nil {
 		return err
 	}
-	parent.AddCommand(cobraCmd)
+	return gp.AddRow(ctx, levelRow("volume."+c.Name, s.Target, changed))
+}
+
+type balanceSettings struct {
+	Target  string  `glazed:"target"`
+	Name    string  `glazed:"name"`
+	Balance float64 `glazed:"balance"`
+}
+
+type balanceCommand struct {
+	*cmds.CommandDescription
+	svc audio.Service
+	res *resolve.Resolver
+}
+
+func newBalanceCommand(svc audio.Service, res *resolve.Resolver) (*balanceCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &balanceCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			"balance",
+			cmds.WithShort("Set sink/source left/right balance"),
+			cmds.WithLong("Set the left/right balance from -1 (left only) through 0 (centred) to 1\n"+
+				"(right only). The louder side keeps the current volume."),
+			cmds.WithFlags(
+				fields.New("target", fields.TypeString, fields.WithDefault("sink"), fields.WithHelp("Target type: sink or source")),
+				fields.New("name", fields.TypeString, fields.WithRequired(true), fields.WithHelp("Sink/source ID, name, description, alias or unique prefix")),
+				fields.New("balance", fields.TypeFloat, fields.WithRequired(true), fields.WithHelp("Balance from -1 (left) to 1 (right)")),
+			),
+			cmds.WithSections(sections...),
+		),
+		svc: svc,
+		res: res,
+	}, nil
+}
+
+func (c *balanceCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &balanceSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	dev, err := c.res.Target(ctx, s.Target, s.Name)
+	if err != nil {
+		return err
+	}
+	changed, err := audio.SetBalance(ctx, c.svc, s.Target, dev.Name, s.Balance)
+	if err != nil {
+		return err
+	}
+	return gp.AddRow(ctx, levelRow("volume.balance", s.Target, changed))
+}
+
+// levelRow reports a device's volume after a change.
+func levelRow(operation string, target string, dev audio.Device) types.Row {
+	channels := make([]string, len(dev.Volume))
+	for i, ch := range dev.Volume {
+		channels[i] = fmt.Sprintf("%s=%d%%", ch.Channel, ch.Percent)
+	}
+	return types.NewRow(
+		types.MRP("operation", operation),
+		types.MRP("target", target),
+		types.MRP("name", dev.Name),
+		types.MRP("percent", dev.VolumePercent()),
+		types.MRP("channels", strings.Join(channels, " ")),
+		types.MRP("balance", dev.Balance),
+		types.MRP("muted", dev.Muted),
+		types.MRP("ok", true),
+	)
+}
+
+func Register(parent *cobra.Command, svc audio.Service, res *resolve.Resolver) error {
+	setCmd, err := newSetCommand(svc, res)
+	if err != nil {
+		return err
+	}
+	upCmd, err := newStepCommand(svc, res, "up", "+")
+	if err != nil {
+		return err
+	}
+	downCmd, err := newStepCommand(svc, res, "down", "-")
+	if err != nil {
+		return err
+	}
+	balanceCmd, err := newBalanceCommand(svc, res)
+	if err != nil {
+		return err
+	}
+	for _, command := range []cmds.Command{setCmd, upCmd, downCmd, balanceCmd} {
+		cobraCmd, err := common.BuildCobra(command)
+		if err != nil {
+			return err
+		}
+		parent.AddCommand(cobraCmd)
+	}
 	return nil
 }
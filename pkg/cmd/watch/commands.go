@@ -0,0 +1,252 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-go-golems/glazed/pkg/cmds"
+	"github.com/go-go-golems/glazed/pkg/cmds/fields"
+	"github.com/go-go-golems/glazed/pkg/cmds/schema"
+	"github.com/go-go-golems/glazed/pkg/cmds/values"
+	"github.com/go-go-golems/glazed/pkg/middlewares"
+	"github.com/go-go-golems/glazed/pkg/types"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"soundctl/pkg/cmd/common"
+	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/bluetooth"
+	"soundctl/pkg/soundctl/events"
+	sexec "soundctl/pkg/soundctl/exec"
+)
+
+type watchSettings struct {
+	Audio     bool     `glazed:"audio"`
+	Bluetooth bool     `glazed:"bluetooth"`
+	Facility  []string `glazed:"facility"`
+	Address   []string `glazed:"address"`
+	Count     int      `glazed:"count"`
+}
+
+type watchCommand struct {
+	*cmds.CommandDescription
+	au       audio.Service
+	bt       bluetooth.Service
+	streamer sexec.Streamer
+}
+
+func newWatchCommand(au audio.Service, bt bluetooth.Service, streamer sexec.Streamer) (*watchCommand, error) {
+	sections, err := common.DefaultSections()
+	if err != nil {
+		return nil, err
+	}
+	return &watchCommand{
+		CommandDescription: cmds.NewCommandDescription(
+			"watch",
+			cmds.WithShort("Stream audio and bluetooth events"),
+			cmds.WithLong("Emits one row per audio server or BlueZ event until interrupted. "+
+				"Without --audio or --bluetooth both feeds are watched. "+
+				"Use --output json --stream to get one JSON object per event."),
+			cmds.WithFlags(
+				fields.New("audio", fields.TypeBool, fields.WithDefault(false), fields.WithHelp("Watch audio server events")),
+				fields.New("bluetooth", fields.TypeBool, fields.WithDefault(false), fields.WithHelp("Watch BlueZ events")),
+				fields.New("facility", fields.TypeStringList, fields.WithDefault([]string{}), fields.WithHelp("Only these facilities (sink, source, sink-input, card, device, transport, ...)")),
+				fields.New("address", fields.TypeStringList, fields.WithDefault([]string{}), fields.WithHelp("Only bluetooth events for these device addresses")),
+				fields.New("count", fields.TypeInteger, fields.WithDefault(0), fields.WithHelp("Exit after this many events (0 = run until interrupted)")),
+			),
+			cmds.WithSections(sections...),
+		),
+		au:       au,
+		bt:       bt,
+		streamer: streamer,
+	}, nil
+}
+
+func (c *watchCommand) RunIntoGlazeProcessor(ctx context.Context, vals *values.Values, gp middlewares.Processor) error {
+	s := &watchSettings{}
+	if err := vals.DecodeSectionInto(schema.DefaultSlug, s); err != nil {
+		return errors.Wrap(err, "decode settings")
+	}
+	if !s.Audio && !s.Bluetooth {
+		s.Audio, s.Bluetooth = true, true
+	}
+	filter := newEventFilter(s.Facility, s.Address)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var audioEvents <-chan events.AudioEvent
+	var btEvents <-chan events.BluetoothEvent
+	if s.Audio {
+		audioEvents = events.SubscribeAudio(ctx, c.streamer, events.Options{Notifier: audio.Notifier(c.au)}).Events()
+	}
+	if s.Bluetooth {
+		btEvents = events.SubscribeBluetooth(ctx, c.streamer, events.Options{}).Events()
+	}
+
+	names := newNameResolver(c.au, c.bt)
+	emitted := 0
+	for audioEvents != nil || btEvents != nil {
+		var row types.Row
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-audioEvents:
+			if !ok {
+				audioEvents = nil
+				continue
+			}
+			if !filter.matchAudio(ev) {
+				continue
+			}
+			row = eventRow(ev.Time, "audio", ev.Kind, ev.Facility, ev.Index, names.audio(ctx, ev))
+		case ev, ok := <-btEvents:
+			if !ok {
+				btEvents = nil
+				continue
+			}
+			if !filter.matchBluetooth(ev) {
+				continue
+			}
+			id := ev.Address
+			if id == "" {
+				id = ev.Path
+			}
+			row = eventRow(ev.Time, "bluetooth", ev.Kind, ev.Facility(), id, names.bluetooth(ctx, ev))
+		}
+		if err := gp.AddRow(ctx, row); err != nil {
+			return err
+		}
+		emitted++
+		if s.Count > 0 && emitted >= s.Count {
+			return nil
+		}
+	}
+	return nil
+}
+
+func eventRow(ts time.Time, source, kind, facility string, id any, name string) types.Row {
+	return types.NewRow(
+		types.MRP("timestamp", ts.Format(time.RFC3339Nano)),
+		types.MRP("source", source),
+		types.MRP("kind", kind),
+		types.MRP("facility", facility),
+		types.MRP("id", id),
+		types.MRP("name", name),
+	)
+}
+
+// eventFilter applies --facility and --address. Audio events carry no
+// device address, so an address filter limits output to bluetooth events.
+type eventFilter struct {
+	facilities map[string]bool
+	addresses  map[string]bool
+}
+
+func newEventFilter(facilities, addresses []string) eventFilter {
+	f := eventFilter{facilities: map[string]bool{}, addresses: map[string]bool{}}
+	for _, facility := range facilities {
+		f.facilities[strings.ToLower(facility)] = true
+	}
+	for _, addr := range addresses {
+		f.addresses[audio.NormalizeAddress(addr)] = true
+	}
+	return f
+}
+
+func (f eventFilter) matchAudio(ev events.AudioEvent) bool {
+	if len(f.addresses) > 0 {
+		return false
+	}
+	return len(f.facilities) == 0 || f.facilities[ev.Facility]
+}
+
+func (f eventFilter) matchBluetooth(ev events.BluetoothEvent) bool {
+	if len(f.facilities) > 0 && !f.facilities[ev.Facility()] {
+		return false
+	}
+	return len(f.addresses) == 0 || f.addresses[audio.NormalizeAddress(ev.Address)]
+}
+
+// nameResolver maps event ids to human-readable names, caching lookups so
+// names stay available for "remove" events of objects that are gone.
+type nameResolver struct {
+	au    audio.Service
+	bt    bluetooth.Service
+	names map[string]string
+}
+
+func newNameResolver(au audio.Service, bt bluetooth.Service) *nameResolver {
+	return &nameResolver{au: au, bt: bt, names: map[string]string{}}
+}
+
+func (r *nameResolver) audio(ctx context.Context, ev events.AudioEvent) string {
+	key := fmt.Sprintf("%s#%d", ev.Facility, ev.Index)
+	if name, ok := r.names[key]; ok || ev.Kind == events.KindRemove {
+		return name
+	}
+	switch ev.Facility {
+	case "sink":
+		r.cacheShort(ctx, "sink", r.au.ListSinks)
+	case "source":
+		r.cacheShort(ctx, "source", r.au.ListSources)
+	case "card":
+		r.cacheShort(ctx, "card", r.au.ListCards)
+	case "sink-input":
+		if inputs, err := r.au.ListSinkInputs(ctx); err == nil {
+			for _, in := range inputs {
+				name := in.AppName
+				if name == "" {
+					name = in.MediaName
+				}
+				r.names[fmt.Sprintf("sink-input#%d", in.Index)] = name
+			}
+		}
+	}
+	return r.names[key]
+}
+
+func (r *nameResolver) cacheShort(ctx context.Context, facility string, list func(context.Context) ([]audio.ShortRecord, error)) {
+	recs, err := list(ctx)
+	if err != nil {
+		return
+	}
+	for _, rec := range recs {
+		r.names[fmt.Sprintf("%s#%d", facility, rec.ID)] = rec.Name
+	}
+}
+
+func (r *nameResolver) bluetooth(ctx context.Context, ev events.BluetoothEvent) string {
+	if ev.Address == "" {
+		return ""
+	}
+	key := "bt#" + ev.Address
+	for _, prop := range []string{"Alias", "Name"} {
+		if name := ev.Properties[prop]; name != "" {
+			r.names[key] = name
+		}
+	}
+	if name, ok := r.names[key]; ok {
+		return name
+	}
+	info, err := r.bt.Info(ctx, ev.Address)
+	if err != nil {
+		return ""
+	}
+	r.names[key] = info.Name
+	return info.Name
+}
+
+// Register adds the watch verb to parent.
+func Register(parent *cobra.Command, au audio.Service, bt bluetooth.Service, streamer sexec.Streamer) error {
+	watchCmd, err := newWatchCommand(au, bt, streamer)
+	if err != nil {
+		return err
+	}
+	cobraCmd, err := common.BuildCobra(watchCmd)
+	if err != nil {
+		return err
+	}
+	parent.AddCommand(cobraCmd)
+	return nil
+}
@@ -0,0 +1,225 @@
+// Package alias stores user-defined friendly names ("airpods", "desk")
+// for bluetooth devices, sinks, sources and cards. Aliases live in
+// aliases.yaml next to presets.yaml.
+package alias
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+	"soundctl/pkg/soundctl/atomicfile"
+	"soundctl/pkg/soundctl/audio"
+)
+
+// Alias maps a friendly name to what it stands for: a bluetooth address,
+// or a sink, source or card name.
+type Alias struct {
+	Name   string `yaml:"name"`
+	Target string `yaml:"target"`
+}
+
+// Set is a list of aliases, sorted by name.
+type Set []Alias
+
+// Lookup finds an alias by name, ignoring case.
+func (s Set) Lookup(name string) (Alias, bool) {
+	for _, a := range s {
+		if strings.EqualFold(a.Name, name) {
+			return a, true
+		}
+	}
+	return Alias{}, false
+}
+
+// Map returns the aliases as name → target.
+func (s Set) Map() map[string]string {
+	m := make(map[string]string, len(s))
+	for _, a := range s {
+		m[a.Name] = a.Target
+	}
+	return m
+}
+
+// NameFor returns the alias to display for a sink, source or card name or
+// a bluetooth address, or "" when there is none. An alias of the object
+// itself wins over an alias of the bluetooth device it belongs to
+// ("bluez_output.08_FF_44_2B_4C_90.1" belongs to 08:FF:44:2B:4C:90).
+func (s Set) NameFor(object string) string {
+	for _, a := range s {
+		if a.Target == object || (audio.IsAddress(a.Target) && strings.EqualFold(a.Target, object)) {
+			return a.Name
+		}
+	}
+	addr := audio.ObjectAddress(object, nil)
+	if addr == "" {
+		return ""
+	}
+	for _, a := range s {
+		if audio.IsAddress(a.Target) && audio.NormalizeAddress(a.Target) == addr {
+			return a.Name
+		}
+	}
+	return ""
+}
+
+// PathNextTo returns the aliases.yaml path in the same directory as the
+// given presets file.
+func PathNextTo(presetsPath string) string {
+	return filepath.Join(filepath.Dir(presetsPath), "aliases.yaml")
+}
+
+// Store manages alias persistence to a YAML file. Like the preset store it
+// takes an advisory lock at LockPath around reads and writes, and replaces
+// the file atomically.
+type Store struct {
+	mu   sync.RWMutex
+	path string
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the file path used by this store.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// LockPath returns the advisory lock file guarding the aliases file.
+func (s *Store) LockPath() string {
+	return s.path + ".lock"
+}
+
+// fileData is the YAML root structure.
+type fileData struct {
+	Aliases Set `yaml:"aliases"`
+}
+
+// List returns all aliases. A missing file yields none.
+func (s *Store) List() (Set, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	lock, err := s.lock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+	return s.readFile()
+}
+
+// Map returns the aliases as name → target, for resolve.Resolver.
+func (s *Store) Map() (map[string]string, error) {
+	aliases, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	return aliases.Map(), nil
+}
+
+// Add creates an alias or retargets an existing one with the same name.
+func (s *Store) Add(name string, target string) error {
+	name, target = strings.TrimSpace(name), strings.TrimSpace(target)
+	if name == "" {
+		return fmt.Errorf("alias name is required")
+	}
+	if target == "" {
+		return fmt.Errorf("alias target is required")
+	}
+	if audio.IsAddress(name) {
+		return fmt.Errorf("alias name %q looks like a bluetooth address", name)
+	}
+	if audio.IsAddress(target) {
+		target = audio.NormalizeAddress(target)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, err := s.lock(true)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	aliases, err := s.readFile()
+	if err != nil {
+		return err
+	}
+	found := false
+	for i, a := range aliases {
+		if strings.EqualFold(a.Name, name) {
+			aliases[i] = Alias{Name: name, Target: target}
+			found = true
+		}
+	}
+	if !found {
+		aliases = append(aliases, Alias{Name: name, Target: target})
+	}
+	return s.writeFile(aliases)
+}
+
+// Remove deletes an alias by name, ignoring case.
+func (s *Store) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, err := s.lock(true)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	aliases, err := s.readFile()
+	if err != nil {
+		return err
+	}
+	filtered := make(Set, 0, len(aliases))
+	for _, a := range aliases {
+		if !strings.EqualFold(a.Name, name) {
+			filtered = append(filtered, a)
+		}
+	}
+	if len(filtered) == len(aliases) {
+		return fmt.Errorf("alias %q not found", name)
+	}
+	return s.writeFile(filtered)
+}
+
+// lock takes the cross-process lock; the mutex only covers this process.
+func (s *Store) lock(exclusive bool) (*atomicfile.Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return nil, fmt.Errorf("create config directory: %w", err)
+	}
+	return atomicfile.Acquire(s.LockPath(), exclusive)
+}
+
+func (s *Store) readFile() (Set, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read aliases file: %w", err)
+	}
+	var fd fileData
+	if err := yaml.Unmarshal(data, &fd); err != nil {
+		return nil, fmt.Errorf("parse aliases file: %w", err)
+	}
+	return fd.Aliases, nil
+}
+
+func (s *Store) writeFile(aliases Set) error {
+	sort.Slice(aliases, func(i, j int) bool { return strings.ToLower(aliases[i].Name) < strings.ToLower(aliases[j].Name) })
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+	data, err := yaml.Marshal(&fileData{Aliases: aliases})
+	if err != nil {
+		return fmt.Errorf("marshal aliases: %w", err)
+	}
+	if err := atomicfile.Replace(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write aliases file: %w", err)
+	}
+	return nil
+}
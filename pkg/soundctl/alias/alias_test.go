@@ -0,0 +1,110 @@
+package alias
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestStoreAddListRemove(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "aliases.yaml"))
+	if aliases, err := s.List(); err != nil || len(aliases) != 0 {
+		t.Fatalf("expected no aliases from a missing file, got %v, %v", aliases, err)
+	}
+	if err := s.Add("desk", "alsa_output.usb-Dock_DAC-00.analog-stereo"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := s.Add("airpods", "08:ff:44:2b:4c:90"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := s.Add("Desk", "alsa_output.pci-0000_00_1f.3.analog-stereo"); err != nil {
+		t.Fatalf("Add (retarget): %v", err)
+	}
+	aliases, err := s.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	want := Set{
+		{Name: "airpods", Target: "08:FF:44:2B:4C:90"},
+		{Name: "Desk", Target: "alsa_output.pci-0000_00_1f.3.analog-stereo"},
+	}
+	if !reflect.DeepEqual(aliases, want) {
+		t.Fatalf("unexpected aliases: %+v", aliases)
+	}
+	if err := s.Remove("DESK"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := s.Remove("desk"); err == nil {
+		t.Fatal("expected error removing a missing alias")
+	}
+	if m, _ := s.Map(); len(m) != 1 || m["airpods"] != "08:FF:44:2B:4C:90" {
+		t.Fatalf("unexpected map: %v", m)
+	}
+}
+
+func TestAddRejectsInvalid(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "aliases.yaml"))
+	for _, c := range [][2]string{{"", "x"}, {"x", ""}, {"08:FF:44:2B:4C:90", "x"}} {
+		if err := s.Add(c[0], c[1]); err == nil {
+			t.Errorf("Add(%q, %q): expected error", c[0], c[1])
+		}
+	}
+}
+
+func TestNameFor(t *testing.T) {
+	aliases := Set{
+		{Name: "airpods", Target: "08:FF:44:2B:4C:90"},
+		{Name: "airpods-mic", Target: "bluez_input.08_FF_44_2B_4C_90.0"},
+		{Name: "desk", Target: "alsa_output.usb-Dock_DAC-00.analog-stereo"},
+	}
+	cases := map[string]string{
+		"bluez_output.08_FF_44_2B_4C_90.1":           "airpods",
+		"bluez_input.08_FF_44_2B_4C_90.0":            "airpods-mic",
+		"bluez_card.08_FF_44_2B_4C_90":               "airpods",
+		"08:ff:44:2b:4c:90":                          "airpods",
+		"alsa_output.usb-Dock_DAC-00.analog-stereo":  "desk",
+		"alsa_output.pci-0000_00_1f.3.analog-stereo": "",
+	}
+	for object, want := range cases {
+		if got := aliases.NameFor(object); got != want {
+			t.Errorf("NameFor(%q) = %q, want %q", object, got, want)
+		}
+	}
+}
+
+func TestPathNextTo(t *testing.T) {
+	if got := PathNextTo("/home/u/.config/soundctl/presets.yaml"); got != "/home/u/.config/soundctl/aliases.yaml" {
+		t.Fatalf("unexpected path %q", got)
+	}
+}
+
+func TestConcurrentStoresDoNotLoseUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aliases.yaml")
+	// Separate stores share no mutex, like separate processes; only the
+	// file lock serializes them.
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- NewStore(path).Add(fmt.Sprintf("sink%d", i), fmt.Sprintf("alsa_output.%d", i))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	aliases, err := NewStore(path).List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(aliases) != 20 {
+		t.Fatalf("expected 20 aliases, got %d", len(aliases))
+	}
+}
@@ -0,0 +1,84 @@
+// Package atomicfile guards the YAML stores soundctl keeps next to each
+// other (presets.yaml, aliases.yaml): an advisory lock so the TUI, the
+// daemon and one-shot CLI invocations serialize their read-modify-write
+// cycles, and replacement by rename so readers never see a truncated file.
+package atomicfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Lock is an advisory flock(2) on a file next to the guarded one. The
+// guarded file itself cannot carry the lock because Replace renames over
+// it.
+type Lock struct {
+	f *os.File
+}
+
+// Acquire blocks until it holds the lock at path, shared for readers and
+// exclusive for writers, creating the lock file if needed.
+func Acquire(path string, exclusive bool) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open lock file: %w", err)
+	}
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	for {
+		err = syscall.Flock(int(f.Fd()), how)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("lock %s: %w", path, err)
+	}
+	return &Lock{f: f}, nil
+}
+
+// Unlock releases the lock. Closing the descriptor drops it as well, so
+// the lock cannot outlive the process.
+func (l *Lock) Unlock() {
+	_ = syscall.Flock(int(l.f.Fd()), syscall.LOCK_UN)
+	l.f.Close()
+}
+
+// Replace writes data to a temporary file next to path, syncs it and
+// renames it into place. A symlink at path is followed, so the file it
+// points to is replaced rather than the link.
+func Replace(path string, data []byte, perm os.FileMode) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
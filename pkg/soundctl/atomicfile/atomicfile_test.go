@@ -0,0 +1,131 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReplaceWritesContentAndMode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "presets.yaml")
+	if err := os.WriteFile(path, []byte("old: true\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Replace(path, []byte("new: true\n"), 0o644); err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "new: true\n" {
+		t.Fatalf("unexpected content %q, %v", data, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o644 {
+		t.Fatalf("expected mode 0644, got %o", mode)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected no temporary files left behind, got %v", entries)
+	}
+}
+
+func TestReplaceCreatesMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aliases.yaml")
+	if err := Replace(path, []byte("a: b\n"), 0o644); err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "a: b\n" {
+		t.Fatalf("unexpected content %q, %v", data, err)
+	}
+}
+
+func TestReplaceFollowsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "presets.yaml")
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "presets.yaml")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Replace(link, []byte("new\n"), 0o644); err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+	info, err := os.Lstat(link)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected %s to stay a symlink, got %v, %v", link, info.Mode(), err)
+	}
+	if data, err := os.ReadFile(target); err != nil || string(data) != "new\n" {
+		t.Fatalf("expected the link target replaced, got %q, %v", data, err)
+	}
+}
+
+func TestExclusiveLockBlocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "presets.yaml.lock")
+	held, err := Acquire(path, true)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	acquired := make(chan *Lock)
+	go func() {
+		l, err := Acquire(path, true)
+		if err != nil {
+			t.Errorf("Acquire: %v", err)
+		}
+		acquired <- l
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("second exclusive lock acquired while the first was held")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	held.Unlock()
+	select {
+	case l := <-acquired:
+		if l != nil {
+			l.Unlock()
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("second exclusive lock not acquired after unlock")
+	}
+}
+
+func TestSharedLocksCoexist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "presets.yaml.lock")
+	a, err := Acquire(path, false)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer a.Unlock()
+
+	acquired := make(chan *Lock)
+	go func() {
+		l, err := Acquire(path, false)
+		if err != nil {
+			t.Errorf("Acquire: %v", err)
+		}
+		acquired <- l
+	}()
+	select {
+	case l := <-acquired:
+		if l != nil {
+			l.Unlock()
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("shared lock blocked by another shared lock")
+	}
+}
@@ -0,0 +1,48 @@
+package audio
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	addressRe        = regexp.MustCompile(`^(?i)([0-9a-f]{2}[:_]){5}[0-9a-f]{2}$`)
+	mangledAddressRe = regexp.MustCompile(`(?i)([0-9a-f]{2}_){5}[0-9a-f]{2}`)
+)
+
+// IsAddress reports whether s is a bluetooth address, written
+// "08:FF:44:2B:4C:90" or "08_ff_44_2b_4c_90".
+func IsAddress(s string) bool {
+	return addressRe.MatchString(s)
+}
+
+// NormalizeAddress turns "08_ff_44_2b_4c_90" or "08:ff:44:2b:4c:90" into
+// "08:FF:44:2B:4C:90".
+func NormalizeAddress(addr string) string {
+	return strings.ToUpper(strings.ReplaceAll(addr, "_", ":"))
+}
+
+// ObjectAddress returns the bluetooth address a sink, source or card
+// belongs to, normalized, or "". It uses the bluez properties when present
+// and the bluez_output.AA_BB_... naming otherwise; props may be nil.
+func ObjectAddress(name string, props map[string]string) string {
+	for _, key := range []string{"api.bluez5.address", "device.string"} {
+		if v := props[key]; addressRe.MatchString(v) {
+			return NormalizeAddress(v)
+		}
+	}
+	if !strings.HasPrefix(name, "bluez_") {
+		return ""
+	}
+	if m := mangledAddressRe.FindString(name); m != "" {
+		return NormalizeAddress(m)
+	}
+	return ""
+}
+
+// BelongsTo reports whether the sink or source is the audio endpoint of
+// the bluetooth device with the given address.
+func (d Device) BelongsTo(address string) bool {
+	addr := ObjectAddress(d.Name, d.Properties)
+	return addr != "" && addr == NormalizeAddress(address)
+}
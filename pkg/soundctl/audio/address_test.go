@@ -0,0 +1,30 @@
+package audio
+
+import "testing"
+
+func TestObjectAddress(t *testing.T) {
+	cases := []struct {
+		name  string
+		props map[string]string
+		want  string
+	}{
+		{"bluez_card.08_FF_44_2B_4C_90", nil, "08:FF:44:2B:4C:90"},
+		{"bluez_output.08_ff_44_2b_4c_90.1", nil, "08:FF:44:2B:4C:90"},
+		{"alsa_output.x", map[string]string{"api.bluez5.address": "08:ff:44:2b:4c:90"}, "08:FF:44:2B:4C:90"},
+		{"alsa_card.pci-0000_00_1f.3", nil, ""},
+	}
+	for _, c := range cases {
+		if got := ObjectAddress(c.name, c.props); got != c.want {
+			t.Errorf("ObjectAddress(%q) = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestBelongsToUsesNameFallback(t *testing.T) {
+	if !(Device{Name: "bluez_sink.08_FF_44_2B_4C_90.a2dp_sink"}).BelongsTo("08:ff:44:2b:4c:90") {
+		t.Fatal("expected PulseAudio-style bluez sink name to match")
+	}
+	if (Device{Name: "alsa_output.pci-0000_00_1f.3.analog-stereo"}).BelongsTo("08:FF:44:2B:4C:90") {
+		t.Fatal("did not expect alsa sink to match")
+	}
+}
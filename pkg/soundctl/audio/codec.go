@@ -0,0 +1,179 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// codecProperties are the card and node properties in which PipeWire
+// (api.bluez5.codec) and PulseAudio (bluetooth.codec) report the codec a
+// bluetooth device is streaming with.
+var codecProperties = []string{"api.bluez5.codec", "bluetooth.codec"}
+
+// codecProfilePrefixes are the profile families PipeWire splits into one
+// profile per codec, e.g. "a2dp-sink-aac" or "headset-head-unit-msbc".
+var codecProfilePrefixes = []string{
+	"a2dp-sink-",
+	"a2dp-source-",
+	"headset-head-unit-",
+	"headset-audio-gateway-",
+}
+
+var codecDescriptionRe = regexp.MustCompile(`(?i)\bcodec ([A-Za-z0-9_ -]+?)\)`)
+
+// IsBluetoothCard reports whether card names a BlueZ card.
+func IsBluetoothCard(card string) bool {
+	return strings.HasPrefix(card, "bluez_card.")
+}
+
+// NormalizeCodec turns a codec as printed by the audio server ("SBC-XQ",
+// "aptX HD", "mSBC") into the identifier used in profile names ("sbc_xq",
+// "aptx_hd", "msbc").
+func NormalizeCodec(codec string) string {
+	codec = strings.ToLower(strings.TrimSpace(codec))
+	return strings.NewReplacer("-", "_", " ", "_").Replace(codec)
+}
+
+// ProfileCodec returns the codec a card profile selects, or "" when the
+// profile does not name one. The description ("High Fidelity Playback
+// (A2DP Sink, codec AAC)") wins over the profile name suffix.
+func ProfileCodec(name, description string) string {
+	if m := codecDescriptionRe.FindStringSubmatch(description); m != nil {
+		return NormalizeCodec(m[1])
+	}
+	for _, prefix := range codecProfilePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return NormalizeCodec(strings.TrimPrefix(name, prefix))
+		}
+	}
+	return ""
+}
+
+// profileFamily groups profiles that can stand in for one another when
+// switching codec: "a2dp-sink-aac" and "a2dp_sink" are both "a2dp-sink".
+func profileFamily(profile string) string {
+	p := strings.ReplaceAll(profile, "_", "-")
+	for _, prefix := range codecProfilePrefixes {
+		family := strings.TrimSuffix(prefix, "-")
+		if p == family || strings.HasPrefix(p, prefix) {
+			return family
+		}
+	}
+	return p
+}
+
+func codecFromProperties(props map[string]string) string {
+	for _, key := range codecProperties {
+		if v := props[key]; v != "" {
+			return NormalizeCodec(v)
+		}
+	}
+	return ""
+}
+
+// activeCodec works out the codec card is streaming with: from the card's
+// own properties, then from the properties of its sinks and sources, and
+// finally from the active profile.
+func activeCodec(card Card, nodes ...map[string]string) string {
+	if card.ActiveProfile == "off" {
+		return ""
+	}
+	if codec := codecFromProperties(card.Properties); codec != "" {
+		return codec
+	}
+	for _, props := range nodes {
+		if codec := codecFromProperties(props); codec != "" {
+			return codec
+		}
+	}
+	for _, p := range card.Profiles {
+		if p.Name == card.ActiveProfile {
+			return p.Codec
+		}
+	}
+	return ""
+}
+
+// needsNodeCodec reports whether any bluetooth card's codec can only be
+// found on its sinks and sources.
+func needsNodeCodec(cards []Card) bool {
+	for _, c := range cards {
+		if IsBluetoothCard(c.Name) && c.ActiveProfile != "off" && codecFromProperties(c.Properties) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// bluezNodeOf reports whether a sink or source name belongs to a BlueZ
+// card: both embed the mangled device address
+// ("bluez_card.08_FF_..." / "bluez_output.08_FF_...").
+func bluezNodeOf(card string, node string) bool {
+	id := strings.TrimPrefix(card, "bluez_card.")
+	return IsBluetoothCard(card) && id != "" && strings.Contains(node, id)
+}
+
+// CodecProfile returns the profile of card that streams with codec. A
+// profile in the same family as the active one (A2DP or headset) is
+// preferred, then an available one. It returns "" when no profile selects
+// the codec, e.g. on PulseAudio where codecs are switched within a profile.
+func CodecProfile(card Card, codec string) string {
+	codec = NormalizeCodec(codec)
+	family := profileFamily(card.ActiveProfile)
+	best, bestScore := "", -1
+	for _, p := range card.Profiles {
+		if p.Codec != codec {
+			continue
+		}
+		score := 0
+		if profileFamily(p.Name) == family {
+			score += 2
+		}
+		if p.Available {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = p.Name, score
+		}
+	}
+	return best
+}
+
+// CodecSwitcher is implemented by services that can change the codec of
+// the active profile in place, which is how PulseAudio's bluetooth module
+// switches codecs.
+type CodecSwitcher interface {
+	SwitchCardCodec(ctx context.Context, card string, codec string) error
+}
+
+// SetCardCodec switches card to codec. It activates the matching
+// per-codec profile when the server has one (PipeWire) and otherwise
+// asks svc to switch the codec in place if it can. It returns the
+// profile that was activated, or "" for an in-place switch.
+func SetCardCodec(ctx context.Context, svc Service, card string, codec string) (string, error) {
+	if card == "" {
+		return "", fmt.Errorf("card is required")
+	}
+	if codec == "" {
+		return "", fmt.Errorf("codec is required")
+	}
+	cards, err := svc.ListCardsDetailed(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range cards {
+		if c.Name != card && fmt.Sprint(c.Index) != card {
+			continue
+		}
+		if profile := CodecProfile(c, codec); profile != "" {
+			return profile, svc.SetCardProfile(ctx, c.Name, profile)
+		}
+		if sw, ok := svc.(CodecSwitcher); ok && IsBluetoothCard(c.Name) {
+			return "", sw.SwitchCardCodec(ctx, c.Name, NormalizeCodec(codec))
+		}
+		return "", fmt.Errorf("card %s has no profile for codec %s", c.Name, NormalizeCodec(codec))
+	}
+	return "", fmt.Errorf("card %s not found", card)
+}
@@ -0,0 +1,125 @@
+package audio
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	sexec "soundctl/pkg/soundctl/exec"
+	"soundctl/pkg/soundctl/pulse"
+)
+
+const codecTestCard = "bluez_card.08_FF_44_2B_4C_90"
+
+func TestProfileCodec(t *testing.T) {
+	cases := []struct {
+		name, description, want string
+	}{
+		{"a2dp-sink-aac", "High Fidelity Playback (A2DP Sink, codec AAC)", "aac"},
+		{"a2dp-sink-sbc_xq", "", "sbc_xq"},
+		{"a2dp-sink", "High Fidelity Playback (A2DP Sink, codec aptX HD)", "aptx_hd"},
+		{"headset-head-unit-msbc", "Headset Head Unit (HSP/HFP, codec mSBC)", "msbc"},
+		{"a2dp_sink", "High Fidelity Playback (A2DP Sink)", ""},
+		{"output:analog-stereo", "Analog Stereo Output", ""},
+	}
+	for _, c := range cases {
+		if got := ProfileCodec(c.name, c.description); got != c.want {
+			t.Errorf("ProfileCodec(%q, %q) = %q, want %q", c.name, c.description, got, c.want)
+		}
+	}
+}
+
+func TestNativeCardCodec(t *testing.T) {
+	srv, svc := newFakeService(t, pulse.FakeState{
+		Sinks: []pulse.DeviceInfo{{
+			Index: 5, Name: "bluez_output.08_FF_44_2B_4C_90.1", Card: 9, ChannelMap: []uint8{1, 2},
+			MonitorIndex: pulse.InvalidIndex,
+			Properties:   pulse.PropList{"api.bluez5.codec": "ldac"},
+		}},
+		Cards: []pulse.CardInfo{{
+			Index: 9,
+			Name:  codecTestCard,
+			Profiles: []pulse.CardProfileInfo{
+				{Name: "off", Available: true},
+				{Name: "a2dp-sink", Description: "High Fidelity Playback (A2DP Sink)", Available: true},
+				{Name: "a2dp-sink-aac", Description: "High Fidelity Playback (A2DP Sink, codec AAC)", Available: true},
+				{Name: "a2dp-sink-ldac", Description: "High Fidelity Playback (A2DP Sink, codec LDAC)", Available: true},
+				{Name: "headset-head-unit-msbc", Description: "Headset Head Unit (HSP/HFP, codec mSBC)", Available: true},
+			},
+			ActiveProfile: "a2dp-sink",
+		}},
+	})
+	ctx := context.Background()
+
+	cards, err := svc.ListCardsDetailed(ctx)
+	if err != nil {
+		t.Fatalf("ListCardsDetailed: %v", err)
+	}
+	// The plain a2dp-sink profile names no codec; the sink does.
+	if cards[0].Codec != "ldac" || cards[0].Profiles[2].Codec != "aac" {
+		t.Fatalf("unexpected codecs: %+v", cards[0])
+	}
+
+	profile, err := SetCardCodec(ctx, svc, codecTestCard, "AAC")
+	if err != nil || profile != "a2dp-sink-aac" {
+		t.Fatalf("SetCardCodec: %q, %v", profile, err)
+	}
+	if _, err := SetCardCodec(ctx, svc, codecTestCard, "aptx"); err == nil {
+		t.Fatal("expected error for a codec without a profile")
+	}
+	if got, want := srv.Calls(), []string{"set-card-profile " + codecTestCard + " a2dp-sink-aac"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected server calls: %v", got)
+	}
+}
+
+func TestExecSwitchesCodecInPlace(t *testing.T) {
+	fake := sexec.NewFakeRunner()
+	fake.Set("pactl", []string{"list", "cards"}, sexec.CommandResult{Output: `Card #3
+	Name: bluez_card.08_FF_44_2B_4C_90
+	Driver: module-bluez5-device.c
+	Properties:
+		bluetooth.codec = "sbc"
+	Profiles:
+		a2dp_sink: High Fidelity Playback (A2DP Sink) (sinks: 1, sources: 0, priority: 40, available: yes)
+		handsfree_head_unit: Handsfree Head Unit (HFP) (sinks: 1, sources: 1, priority: 30, available: yes)
+		off: Off (sinks: 0, sources: 0, priority: 0, available: yes)
+	Active Profile: a2dp_sink`})
+	fake.Set("pactl", []string{"send-message", "/card/" + codecTestCard + "/bluez", "switch-codec", `"sbc_xq"`}, sexec.CommandResult{})
+
+	svc := NewExecService(fake)
+	cards, err := svc.ListCardsDetailed(context.Background())
+	if err != nil {
+		t.Fatalf("ListCardsDetailed: %v", err)
+	}
+	if cards[0].Codec != "sbc" {
+		t.Fatalf("expected codec from card properties, got %q", cards[0].Codec)
+	}
+	profile, err := SetCardCodec(context.Background(), svc, codecTestCard, "SBC-XQ")
+	if err != nil || profile != "" {
+		t.Fatalf("SetCardCodec: %q, %v", profile, err)
+	}
+}
+
+func TestNativeSwitchesCodecInPlace(t *testing.T) {
+	srv, svc := newFakeService(t, pulse.FakeState{
+		Cards: []pulse.CardInfo{{
+			Index:      3,
+			Name:       codecTestCard,
+			Driver:     "module-bluez5-device.c",
+			Properties: pulse.PropList{"bluetooth.codec": "sbc"},
+			Profiles: []pulse.CardProfileInfo{
+				{Name: "a2dp_sink", Description: "High Fidelity Playback (A2DP Sink)", Available: true},
+				{Name: "off", Available: true},
+			},
+			ActiveProfile: "a2dp_sink",
+		}},
+	})
+	profile, err := SetCardCodec(context.Background(), svc, codecTestCard, "SBC-XQ")
+	if err != nil || profile != "" {
+		t.Fatalf("SetCardCodec: %q, %v", profile, err)
+	}
+	want := []string{"send-message /card/" + codecTestCard + `/bluez switch-codec "sbc_xq"`}
+	if got := srv.Calls(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected server calls:\n got %v\nwant %v", got, want)
+	}
+}
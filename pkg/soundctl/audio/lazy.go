@@ -0,0 +1,215 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Lazy is a Service that connects on first use, so commands that never
+// touch audio do not pay for (or fail on) reaching the server.
+type Lazy struct {
+	connect func(ctx context.Context) (Service, error)
+
+	mu  sync.Mutex
+	svc Service
+}
+
+var (
+	_ Service       = (*Lazy)(nil)
+	_ CodecSwitcher = (*Lazy)(nil)
+)
+
+// NewLazy returns a Service that calls connect the first time it is used.
+// A failed connect is retried on the next call.
+func NewLazy(connect func(ctx context.Context) (Service, error)) *Lazy {
+	return &Lazy{connect: connect}
+}
+
+// Service returns the service connect returned, connecting if needed.
+func (l *Lazy) Service(ctx context.Context) (Service, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.svc != nil {
+		return l.svc, nil
+	}
+	svc, err := l.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	l.svc = svc
+	return svc, nil
+}
+
+func (l *Lazy) ListSinks(ctx context.Context) ([]ShortRecord, error) {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListSinks(ctx)
+}
+
+func (l *Lazy) ListSources(ctx context.Context) ([]ShortRecord, error) {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListSources(ctx)
+}
+
+func (l *Lazy) ListCards(ctx context.Context) ([]ShortRecord, error) {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListCards(ctx)
+}
+
+func (l *Lazy) GetDefaults(ctx context.Context) (DefaultsInfo, error) {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return DefaultsInfo{}, err
+	}
+	return svc.GetDefaults(ctx)
+}
+
+func (l *Lazy) ListSinkInputs(ctx context.Context) ([]SinkInput, error) {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListSinkInputs(ctx)
+}
+
+func (l *Lazy) ListSourceOutputs(ctx context.Context) ([]SourceOutput, error) {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListSourceOutputs(ctx)
+}
+
+func (l *Lazy) ListCardsDetailed(ctx context.Context) ([]Card, error) {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListCardsDetailed(ctx)
+}
+
+func (l *Lazy) ListSinksDetailed(ctx context.Context) ([]Device, error) {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListSinksDetailed(ctx)
+}
+
+func (l *Lazy) ListSourcesDetailed(ctx context.Context) ([]Device, error) {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListSourcesDetailed(ctx)
+}
+
+func (l *Lazy) SetDefaultSink(ctx context.Context, sink string) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.SetDefaultSink(ctx, sink)
+}
+
+func (l *Lazy) SetDefaultSource(ctx context.Context, source string) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.SetDefaultSource(ctx, source)
+}
+
+func (l *Lazy) MoveSinkInput(ctx context.Context, streamID int, sink string) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.MoveSinkInput(ctx, streamID, sink)
+}
+
+func (l *Lazy) MoveSourceOutput(ctx context.Context, streamID int, source string) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.MoveSourceOutput(ctx, streamID, source)
+}
+
+func (l *Lazy) SetSinkInputVolume(ctx context.Context, streamID int, percent int) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.SetSinkInputVolume(ctx, streamID, percent)
+}
+
+func (l *Lazy) SetSinkInputMute(ctx context.Context, streamID int, muted bool) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.SetSinkInputMute(ctx, streamID, muted)
+}
+
+func (l *Lazy) SetCardProfile(ctx context.Context, card string, profile string) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.SetCardProfile(ctx, card, profile)
+}
+
+func (l *Lazy) SetVolume(ctx context.Context, target string, name string, percent int) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.SetVolume(ctx, target, name, percent)
+}
+
+func (l *Lazy) SetChannelVolumes(ctx context.Context, target string, name string, volumes []uint32) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.SetChannelVolumes(ctx, target, name, volumes)
+}
+
+func (l *Lazy) ToggleMute(ctx context.Context, target string, name string) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.ToggleMute(ctx, target, name)
+}
+
+func (l *Lazy) SetMute(ctx context.Context, target string, name string, muted bool) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.SetMute(ctx, target, name, muted)
+}
+
+// SwitchCardCodec switches in place when the connected service can.
+func (l *Lazy) SwitchCardCodec(ctx context.Context, card string, codec string) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	sw, ok := svc.(CodecSwitcher)
+	if !ok {
+		return fmt.Errorf("card %s has no profile for codec %s", card, codec)
+	}
+	return sw.SwitchCardCodec(ctx, card, codec)
+}
@@ -0,0 +1,45 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"soundctl/pkg/soundctl/pulse"
+)
+
+func TestLazyConnectsOnFirstUse(t *testing.T) {
+	_, native := newFakeService(t, pulse.FakeState{
+		Sinks: []pulse.DeviceInfo{{Index: 1, Name: "speaker", ChannelMap: []uint8{1, 2}, MonitorIndex: pulse.InvalidIndex}},
+	})
+	connects := 0
+	fail := true
+	lazy := NewLazy(func(context.Context) (Service, error) {
+		connects++
+		if fail {
+			return nil, errors.New("server not up yet")
+		}
+		return native, nil
+	})
+	if connects != 0 {
+		t.Fatal("connected before first use")
+	}
+	if _, err := lazy.ListSinks(context.Background()); err == nil {
+		t.Fatal("expected the connect error")
+	}
+
+	// A failed connect is retried; a successful one is kept.
+	fail = false
+	for range 2 {
+		sinks, err := lazy.ListSinks(context.Background())
+		if err != nil || len(sinks) != 1 || sinks[0].Name != "speaker" {
+			t.Fatalf("ListSinks: %+v, %v", sinks, err)
+		}
+	}
+	if connects != 2 {
+		t.Fatalf("expected 2 connects, got %d", connects)
+	}
+	if Notifier(lazy) == nil {
+		t.Fatal("expected a lazy native service to notify natively")
+	}
+}
@@ -0,0 +1,591 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"soundctl/pkg/soundctl/pulse"
+)
+
+// NativeService implements Service over the PulseAudio native protocol
+// (served by both PulseAudio and pipewire-pulse) instead of forking pactl.
+// The connection is re-dialled lazily if the server goes away.
+type NativeService struct {
+	path string
+
+	mu     sync.Mutex
+	client *pulse.Client
+}
+
+// NewNativeService connects to the server socket at path ("" for the
+// default location) and fails if it is unreachable, so callers can fall
+// back to ExecService.
+func NewNativeService(ctx context.Context, path string) (*NativeService, error) {
+	s := &NativeService{path: path}
+	if _, err := s.conn(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close releases the server connection.
+func (s *NativeService) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client == nil {
+		return nil
+	}
+	err := s.client.Close()
+	s.client = nil
+	return err
+}
+
+func (s *NativeService) conn(ctx context.Context) (*pulse.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client != nil && !s.client.Closed() {
+		return s.client, nil
+	}
+	c, err := pulse.Dial(ctx, s.path, "soundctl")
+	if err != nil {
+		return nil, err
+	}
+	s.client = c
+	return c, nil
+}
+
+func (s *NativeService) ListSinks(ctx context.Context) ([]ShortRecord, error) {
+	c, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sinks, err := c.Sinks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return shortFromDevices(sinks), nil
+}
+
+func (s *NativeService) ListSources(ctx context.Context) ([]ShortRecord, error) {
+	c, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := c.Sources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return shortFromDevices(sources), nil
+}
+
+func (s *NativeService) ListCards(ctx context.Context) ([]ShortRecord, error) {
+	c, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cards, err := c.Cards(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]ShortRecord, 0, len(cards))
+	for _, card := range cards {
+		rows = append(rows, ShortRecord{ID: int(card.Index), Name: card.Name, Driver: card.Driver})
+	}
+	return rows, nil
+}
+
+func shortFromDevices(devices []pulse.DeviceInfo) []ShortRecord {
+	rows := make([]ShortRecord, 0, len(devices))
+	for _, d := range devices {
+		rows = append(rows, ShortRecord{
+			ID:         int(d.Index),
+			Name:       d.Name,
+			Driver:     d.Driver,
+			SampleSpec: d.SampleSpec.String(),
+			State:      pulse.StateName(d.State),
+		})
+	}
+	return rows
+}
+
+func (s *NativeService) GetDefaults(ctx context.Context) (DefaultsInfo, error) {
+	c, err := s.conn(ctx)
+	if err != nil {
+		return DefaultsInfo{}, err
+	}
+	info, err := c.ServerInfo(ctx)
+	if err != nil {
+		return DefaultsInfo{}, err
+	}
+	return DefaultsInfo{
+		DefaultSinkName:   info.DefaultSink,
+		DefaultSourceName: info.DefaultSource,
+		ServerName:        info.PackageName,
+	}, nil
+}
+
+func (s *NativeService) ListSinkInputs(ctx context.Context) ([]SinkInput, error) {
+	c, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recs, err := c.SinkInputs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sinks, err := c.Sinks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sinkMap := make(map[uint32]string, len(sinks))
+	for _, sink := range sinks {
+		sinkMap[sink.Index] = sink.Name
+	}
+
+	inputs := make([]SinkInput, 0, len(recs))
+	for _, rec := range recs {
+		client := -1
+		if rec.Client != pulse.InvalidIndex {
+			client = int(rec.Client)
+		}
+		var volumes []ChannelVolume
+		for i, v := range rec.Volume {
+			cv := channelVolumeFromRaw(v)
+			if i < len(rec.ChannelMap) {
+				cv.Channel = pulse.ChannelPositionName(rec.ChannelMap[i])
+			}
+			volumes = append(volumes, cv)
+		}
+		pid, _ := strconv.Atoi(rec.Properties["application.process.id"])
+		inputs = append(inputs, SinkInput{
+			Index:     int(rec.Index),
+			SinkIndex: int(rec.Sink),
+			Client:    client,
+			AppName:   rec.Properties["application.name"],
+			MediaName: rec.Properties["media.name"],
+			Binary:    rec.Properties["application.process.binary"],
+			PID:       pid,
+			Volume:    volumes,
+			Muted:     rec.Mute,
+			Corked:    rec.Corked,
+			SinkName:  sinkMap[rec.Sink],
+		})
+	}
+	return inputs, nil
+}
+
+func (s *NativeService) ListSourceOutputs(ctx context.Context) ([]SourceOutput, error) {
+	c, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recs, err := c.SourceOutputs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := c.Sources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sourceMap := make(map[uint32]string, len(sources))
+	for _, source := range sources {
+		sourceMap[source.Index] = source.Name
+	}
+
+	outputs := make([]SourceOutput, 0, len(recs))
+	for _, rec := range recs {
+		outputs = append(outputs, SourceOutput{
+			Index:       int(rec.Index),
+			SourceIndex: int(rec.Source),
+			AppName:     rec.Properties["application.name"],
+			MediaName:   rec.Properties["media.name"],
+			SourceName:  sourceMap[rec.Source],
+		})
+	}
+	return outputs, nil
+}
+
+func (s *NativeService) ListCardsDetailed(ctx context.Context) ([]Card, error) {
+	c, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recs, err := c.Cards(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cards := make([]Card, 0, len(recs))
+	for _, rec := range recs {
+		profiles := make([]CardProfile, 0, len(rec.Profiles))
+		for _, p := range rec.Profiles {
+			profiles = append(profiles, CardProfile{
+				Name:        p.Name,
+				Description: p.Description,
+				Available:   p.Available,
+				Codec:       ProfileCodec(p.Name, p.Description),
+			})
+		}
+		cards = append(cards, Card{
+			Index:         int(rec.Index),
+			Name:          rec.Name,
+			Driver:        rec.Driver,
+			Profiles:      profiles,
+			ActiveProfile: rec.ActiveProfile,
+			Properties:    map[string]string(rec.Properties),
+		})
+	}
+
+	// The codec is usually only reported on the card's sinks and sources.
+	var nodes []pulse.DeviceInfo
+	if needsNodeCodec(cards) {
+		sinks, err := c.Sinks(ctx)
+		if err != nil {
+			return nil, err
+		}
+		sources, err := c.Sources(ctx)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(sinks, sources...)
+	}
+	for i, card := range cards {
+		if !IsBluetoothCard(card.Name) {
+			continue
+		}
+		var props []map[string]string
+		for _, d := range nodes {
+			if int(d.Card) == card.Index {
+				props = append(props, map[string]string(d.Properties))
+			}
+		}
+		cards[i].Codec = activeCodec(card, props...)
+	}
+	return cards, nil
+}
+
+func (s *NativeService) ListSinksDetailed(ctx context.Context) ([]Device, error) {
+	c, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sinks, err := c.Sinks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	devices := make([]Device, 0, len(sinks))
+	for _, d := range sinks {
+		dev := deviceFromInfo(d)
+		dev.MonitorSource = d.MonitorName
+		devices = append(devices, dev)
+	}
+	return devices, nil
+}
+
+func (s *NativeService) ListSourcesDetailed(ctx context.Context) ([]Device, error) {
+	c, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := c.Sources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	devices := make([]Device, 0, len(sources))
+	for _, d := range sources {
+		dev := deviceFromInfo(d)
+		if d.MonitorIndex != pulse.InvalidIndex {
+			dev.MonitorOfSink = d.MonitorName
+		}
+		devices = append(devices, dev)
+	}
+	return devices, nil
+}
+
+func deviceFromInfo(d pulse.DeviceInfo) Device {
+	channels := make([]string, 0, len(d.ChannelMap))
+	for _, pos := range d.ChannelMap {
+		channels = append(channels, pulse.ChannelPositionName(pos))
+	}
+	volumes := make([]ChannelVolume, 0, len(d.Volume))
+	for i, v := range d.Volume {
+		cv := channelVolumeFromRaw(v)
+		if i < len(channels) {
+			cv.Channel = channels[i]
+		}
+		volumes = append(volumes, cv)
+	}
+	ports := make([]Port, 0, len(d.Ports))
+	for _, p := range d.Ports {
+		ports = append(ports, Port{Name: p.Name, Description: p.Description, Available: p.Available != pulse.AvailableNo})
+	}
+	props := make(map[string]string, len(d.Properties))
+	for k, v := range d.Properties {
+		props[k] = v
+	}
+	return Device{
+		ID:          int(d.Index),
+		Name:        d.Name,
+		Description: d.Description,
+		Driver:      d.Driver,
+		SampleSpec:  d.SampleSpec.String(),
+		ChannelMap:  strings.Join(channels, ","),
+		State:       pulse.StateName(d.State),
+		Muted:       d.Mute,
+		Volume:      volumes,
+		Balance:     balance(volumes),
+		BaseVolume:  channelVolumeFromRaw(d.BaseVolume),
+		Ports:       ports,
+		ActivePort:  d.ActivePort,
+		Properties:  props,
+	}
+}
+
+func channelVolumeFromRaw(v uint32) ChannelVolume {
+	return ChannelVolume{Value: int(v), Percent: pulse.VolumePercent(v), DB: pulse.VolumeDB(v)}
+}
+
+// balance mirrors pa_cvolume_get_balance: -1 is full left, 1 full right.
+func balance(volumes []ChannelVolume) float64 {
+	var left, right float64
+	var nLeft, nRight int
+	for _, v := range volumes {
+		switch {
+		case isLeftChannel(v.Channel):
+			left += float64(v.Value)
+			nLeft++
+		case isRightChannel(v.Channel):
+			right += float64(v.Value)
+			nRight++
+		}
+	}
+	if nLeft == 0 || nRight == 0 {
+		return 0
+	}
+	left /= float64(nLeft)
+	right /= float64(nRight)
+	switch {
+	case left == right:
+		return 0
+	case left > right:
+		return right/left - 1
+	default:
+		return 1 - left/right
+	}
+}
+
+// objectRef splits a pactl-style "index or name" argument.
+func objectRef(nameOrID string) (uint32, string) {
+	if idx, err := strconv.ParseUint(nameOrID, 10, 32); err == nil {
+		return uint32(idx), ""
+	}
+	return pulse.InvalidIndex, nameOrID
+}
+
+func (s *NativeService) SetDefaultSink(ctx context.Context, sink string) error {
+	if sink == "" {
+		return fmt.Errorf("sink is required")
+	}
+	c, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	return c.SetDefaultSink(ctx, sink)
+}
+
+func (s *NativeService) SetDefaultSource(ctx context.Context, source string) error {
+	if source == "" {
+		return fmt.Errorf("source is required")
+	}
+	c, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	return c.SetDefaultSource(ctx, source)
+}
+
+func (s *NativeService) MoveSinkInput(ctx context.Context, streamID int, sink string) error {
+	if sink == "" {
+		return fmt.Errorf("sink is required")
+	}
+	c, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	idx, name := objectRef(sink)
+	return c.MoveSinkInput(ctx, uint32(streamID), idx, name)
+}
+
+func (s *NativeService) SetSinkInputVolume(ctx context.Context, streamID int, percent int) error {
+	if percent < 0 || percent > 150 {
+		return fmt.Errorf("percent must be between 0 and 150")
+	}
+	c, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	inputs, err := c.SinkInputs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, in := range inputs {
+		if int(in.Index) != streamID {
+			continue
+		}
+		volume := make([]uint32, max(len(in.ChannelMap), 1))
+		for i := range volume {
+			volume[i] = pulse.VolumeFromPercent(percent)
+		}
+		return c.SetSinkInputVolume(ctx, in.Index, volume)
+	}
+	return fmt.Errorf("sink input %d not found", streamID)
+}
+
+func (s *NativeService) SetSinkInputMute(ctx context.Context, streamID int, muted bool) error {
+	c, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	return c.SetSinkInputMute(ctx, uint32(streamID), muted)
+}
+
+func (s *NativeService) MoveSourceOutput(ctx context.Context, streamID int, source string) error {
+	if source == "" {
+		return fmt.Errorf("source is required")
+	}
+	c, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	idx, name := objectRef(source)
+	return c.MoveSourceOutput(ctx, uint32(streamID), idx, name)
+}
+
+func (s *NativeService) SetCardProfile(ctx context.Context, card string, profile string) error {
+	if card == "" {
+		return fmt.Errorf("card is required")
+	}
+	if profile == "" {
+		return fmt.Errorf("profile is required")
+	}
+	c, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	idx, name := objectRef(card)
+	return c.SetCardProfile(ctx, idx, name, profile)
+}
+
+// SwitchCardCodec switches the codec of a bluetooth card's active profile
+// through PulseAudio's message API.
+func (s *NativeService) SwitchCardCodec(ctx context.Context, card string, codec string) error {
+	if card == "" {
+		return fmt.Errorf("card is required")
+	}
+	if codec == "" {
+		return fmt.Errorf("codec is required")
+	}
+	c, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = c.SendMessage(ctx, "/card/"+card+"/bluez", "switch-codec", strconv.Quote(codec))
+	return err
+}
+
+func (s *NativeService) SetVolume(ctx context.Context, target string, name string, percent int) error {
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if percent < 0 || percent > 150 {
+		return fmt.Errorf("percent must be between 0 and 150")
+	}
+	c, dev, err := s.device(ctx, target, name)
+	if err != nil {
+		return err
+	}
+	// Like pactl, expand a single value to every channel of the device.
+	volume := make([]uint32, max(len(dev.ChannelMap), 1))
+	for i := range volume {
+		volume[i] = pulse.VolumeFromPercent(percent)
+	}
+	if target == "sink" {
+		return c.SetSinkVolume(ctx, dev.Index, "", volume)
+	}
+	return c.SetSourceVolume(ctx, dev.Index, "", volume)
+}
+
+func (s *NativeService) SetChannelVolumes(ctx context.Context, target string, name string, volumes []uint32) error {
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+	c, dev, err := s.device(ctx, target, name)
+	if err != nil {
+		return err
+	}
+	if len(volumes) != max(len(dev.ChannelMap), 1) {
+		return fmt.Errorf("%s %s has %d channels, got %d volumes", target, dev.Name, len(dev.ChannelMap), len(volumes))
+	}
+	if target == "sink" {
+		return c.SetSinkVolume(ctx, dev.Index, "", volumes)
+	}
+	return c.SetSourceVolume(ctx, dev.Index, "", volumes)
+}
+
+func (s *NativeService) ToggleMute(ctx context.Context, target string, name string) error {
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+	_, dev, err := s.device(ctx, target, name)
+	if err != nil {
+		return err
+	}
+	return s.SetMute(ctx, target, name, !dev.Mute)
+}
+
+func (s *NativeService) SetMute(ctx context.Context, target string, name string, muted bool) error {
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if _, err := muteCommand(target); err != nil {
+		return err
+	}
+	c, err := s.conn(ctx)
+	if err != nil {
+		return err
+	}
+	idx, n := objectRef(name)
+	if target == "sink" {
+		return c.SetSinkMute(ctx, idx, n, muted)
+	}
+	return c.SetSourceMute(ctx, idx, n, muted)
+}
+
+// device looks up a sink or source by index or name.
+func (s *NativeService) device(ctx context.Context, target string, name string) (*pulse.Client, pulse.DeviceInfo, error) {
+	if _, err := volumeCommand(target); err != nil {
+		return nil, pulse.DeviceInfo{}, err
+	}
+	c, err := s.conn(ctx)
+	if err != nil {
+		return nil, pulse.DeviceInfo{}, err
+	}
+	var devices []pulse.DeviceInfo
+	if target == "sink" {
+		devices, err = c.Sinks(ctx)
+	} else {
+		devices, err = c.Sources(ctx)
+	}
+	if err != nil {
+		return nil, pulse.DeviceInfo{}, err
+	}
+	idx, n := objectRef(name)
+	for _, d := range devices {
+		if (idx != pulse.InvalidIndex && d.Index == idx) || (n != "" && d.Name == n) {
+			return c, d, nil
+		}
+	}
+	return nil, pulse.DeviceInfo{}, fmt.Errorf("%s %q not found", target, name)
+}
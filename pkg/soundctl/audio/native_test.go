@@ -0,0 +1,341 @@
+package audio
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"soundctl/pkg/soundctl/events"
+	"soundctl/pkg/soundctl/pulse"
+)
+
+const nativeTestSink = "bluez_output.08_FF_44_2B_4C_90.1"
+
+// newFakeService serves state from a fake server and connects a native
+// service to it, like pulsetest.NewService, which imports this package and
+// so cannot be used from its own tests.
+func newFakeService(t *testing.T, state pulse.FakeState) (*pulse.FakeServer, Service) {
+	t.Helper()
+	srv, err := pulse.NewFakeServer(t.TempDir(), state)
+	if err != nil {
+		t.Fatalf("NewFakeServer: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	svc, err := NewNativeService(context.Background(), srv.Path)
+	if err != nil {
+		t.Fatalf("NewNativeService: %v", err)
+	}
+	t.Cleanup(func() { svc.Close() })
+	return srv, svc
+}
+
+func newNativeFixture(t *testing.T) (*pulse.FakeServer, Service) {
+	t.Helper()
+	return newFakeService(t, pulse.FakeState{
+		Info: pulse.ServerInfo{
+			PackageName:   "PulseAudio (on PipeWire 1.0.5)",
+			DefaultSink:   nativeTestSink,
+			DefaultSource: "alsa_input.pci-0000_00_1f.3.analog-stereo",
+		},
+		Sinks: []pulse.DeviceInfo{{
+			Index:       47,
+			Name:        nativeTestSink,
+			Description: "WH-1000XM4",
+			SampleSpec:  pulse.SampleSpec{Format: 3, Channels: 2, Rate: 48000},
+			ChannelMap:  []uint8{1, 2},
+			Volume:      []uint32{pulse.VolumeFromPercent(65), pulse.VolumeFromPercent(52)},
+			Mute:        true,
+			MonitorName: nativeTestSink + ".monitor",
+			Driver:      "PipeWire",
+			BaseVolume:  pulse.VolumeNorm,
+			State:       pulse.StateRunning,
+			Properties:  pulse.PropList{"device.api": "bluez5"},
+		}},
+		Sources: []pulse.DeviceInfo{
+			{
+				Index:        48,
+				Name:         nativeTestSink + ".monitor",
+				ChannelMap:   []uint8{1, 2},
+				Volume:       []uint32{pulse.VolumeNorm, pulse.VolumeNorm},
+				MonitorIndex: 47,
+				MonitorName:  nativeTestSink,
+			},
+			{
+				Index:        50,
+				Name:         "alsa_input.pci-0000_00_1f.3.analog-stereo",
+				ChannelMap:   []uint8{1, 2},
+				Volume:       []uint32{pulse.VolumeNorm, pulse.VolumeNorm},
+				MonitorIndex: pulse.InvalidIndex,
+				State:        pulse.StateSuspended,
+			},
+		},
+		Cards: []pulse.CardInfo{{
+			Index:  3,
+			Name:   "bluez_card.08_FF_44_2B_4C_90",
+			Driver: "module-bluez5-device.c",
+			Profiles: []pulse.CardProfileInfo{
+				{Name: "a2dp-sink", Description: "High Fidelity Playback (A2DP Sink)", Available: true},
+				{Name: "headset-head-unit", Description: "Headset Head Unit (HSP/HFP)", Available: false},
+			},
+			ActiveProfile: "a2dp-sink",
+		}},
+		SinkInputs: []pulse.SinkInputInfo{{
+			Index:      112,
+			Client:     7,
+			Sink:       47,
+			ChannelMap: []uint8{0},
+			Volume:     []uint32{pulse.VolumeNorm},
+			Mute:       true,
+			Properties: pulse.PropList{"application.name": "Firefox", "media.name": "Video", "application.process.id": "4242", "application.process.binary": "firefox"},
+		}},
+		SourceOutputs: []pulse.SourceOutputInfo{{
+			Index:      61,
+			Source:     50,
+			ChannelMap: []uint8{0},
+			Properties: pulse.PropList{"application.name": "Zoom"},
+		}},
+	})
+}
+
+func TestNativeListSinksDetailed(t *testing.T) {
+	_, svc := newNativeFixture(t)
+	sinks, err := svc.ListSinksDetailed(context.Background())
+	if err != nil {
+		t.Fatalf("ListSinksDetailed failed: %v", err)
+	}
+	if len(sinks) != 1 {
+		t.Fatalf("expected 1 sink, got %d", len(sinks))
+	}
+	s := sinks[0]
+	if s.ID != 47 || s.State != "RUNNING" || s.SampleSpec != "s16le 2ch 48000Hz" || s.ChannelMap != "front-left,front-right" {
+		t.Fatalf("unexpected sink: %+v", s)
+	}
+	if !s.Muted || s.VolumePercent() != 65 || s.Volume[1].Channel != "front-right" || s.Volume[1].Percent != 52 {
+		t.Fatalf("unexpected volume/mute: %+v", s.Volume)
+	}
+	if s.Balance <= -0.3 || s.Balance >= -0.1 {
+		t.Fatalf("expected left-leaning balance, got %v", s.Balance)
+	}
+	if s.MonitorSource != nativeTestSink+".monitor" || s.Properties["device.api"] != "bluez5" {
+		t.Fatalf("unexpected monitor/properties: %+v", s)
+	}
+}
+
+func TestNativeListSourcesMarksMonitors(t *testing.T) {
+	_, svc := newNativeFixture(t)
+	sources, err := svc.ListSourcesDetailed(context.Background())
+	if err != nil {
+		t.Fatalf("ListSourcesDetailed failed: %v", err)
+	}
+	if len(sources) != 2 || sources[0].MonitorOfSink != nativeTestSink || sources[1].MonitorOfSink != "" {
+		t.Fatalf("unexpected sources: %+v", sources)
+	}
+}
+
+func TestNativeListsMatchExecShapes(t *testing.T) {
+	_, svc := newNativeFixture(t)
+	ctx := context.Background()
+
+	defaults, err := svc.GetDefaults(ctx)
+	if err != nil {
+		t.Fatalf("GetDefaults failed: %v", err)
+	}
+	if defaults.DefaultSinkName != nativeTestSink || defaults.ServerName != "PulseAudio (on PipeWire 1.0.5)" {
+		t.Fatalf("unexpected defaults: %+v", defaults)
+	}
+
+	inputs, err := svc.ListSinkInputs(ctx)
+	if err != nil {
+		t.Fatalf("ListSinkInputs failed: %v", err)
+	}
+	want := []SinkInput{{
+		Index: 112, SinkIndex: 47, Client: 7, AppName: "Firefox", MediaName: "Video", Binary: "firefox", PID: 4242,
+		Volume: []ChannelVolume{{Channel: "mono", Value: pulse.VolumeNorm, Percent: 100}}, Muted: true,
+		SinkName: nativeTestSink,
+	}}
+	if !reflect.DeepEqual(inputs, want) {
+		t.Fatalf("unexpected sink inputs: %+v", inputs)
+	}
+
+	outputs, err := svc.ListSourceOutputs(ctx)
+	if err != nil {
+		t.Fatalf("ListSourceOutputs failed: %v", err)
+	}
+	wantOutputs := []SourceOutput{{Index: 61, SourceIndex: 50, AppName: "Zoom", SourceName: "alsa_input.pci-0000_00_1f.3.analog-stereo"}}
+	if !reflect.DeepEqual(outputs, wantOutputs) {
+		t.Fatalf("unexpected source outputs: %+v", outputs)
+	}
+
+	cards, err := svc.ListCardsDetailed(ctx)
+	if err != nil {
+		t.Fatalf("ListCardsDetailed failed: %v", err)
+	}
+	if len(cards) != 1 || cards[0].ActiveProfile != "a2dp-sink" || cards[0].Profiles[1].Available {
+		t.Fatalf("unexpected cards: %+v", cards)
+	}
+}
+
+func TestNativeMutations(t *testing.T) {
+	srv, svc := newNativeFixture(t)
+	ctx := context.Background()
+
+	if err := svc.SetVolume(ctx, "sink", nativeTestSink, 30); err != nil {
+		t.Fatalf("SetVolume failed: %v", err)
+	}
+	if err := svc.ToggleMute(ctx, "sink", "47"); err != nil {
+		t.Fatalf("ToggleMute failed: %v", err)
+	}
+	if err := svc.SetMute(ctx, "source", "alsa_input.pci-0000_00_1f.3.analog-stereo", true); err != nil {
+		t.Fatalf("SetMute failed: %v", err)
+	}
+	if err := svc.MoveSinkInput(ctx, 112, nativeTestSink); err != nil {
+		t.Fatalf("MoveSinkInput failed: %v", err)
+	}
+	if err := svc.SetSinkInputVolume(ctx, 112, 40); err != nil {
+		t.Fatalf("SetSinkInputVolume failed: %v", err)
+	}
+	if err := svc.SetSinkInputMute(ctx, 112, false); err != nil {
+		t.Fatalf("SetSinkInputMute failed: %v", err)
+	}
+	if err := svc.SetSinkInputVolume(ctx, 999, 40); err == nil {
+		t.Fatal("expected error for unknown sink input")
+	}
+	if err := svc.MoveSourceOutput(ctx, 61, "48"); err != nil {
+		t.Fatalf("MoveSourceOutput failed: %v", err)
+	}
+	if err := svc.SetCardProfile(ctx, "bluez_card.08_FF_44_2B_4C_90", "headset-head-unit"); err != nil {
+		t.Fatalf("SetCardProfile failed: %v", err)
+	}
+	if err := svc.SetDefaultSource(ctx, "missing"); err == nil {
+		t.Fatal("expected error for unknown source")
+	}
+
+	want := []string{
+		"set-sink-volume " + nativeTestSink + " 30% 30%",
+		"set-sink-mute " + nativeTestSink + " 0",
+		"set-source-mute alsa_input.pci-0000_00_1f.3.analog-stereo 1",
+		"move-sink-input 112 " + nativeTestSink,
+		"set-sink-input-volume 112 40%",
+		"set-sink-input-mute 112 0",
+		"move-source-output 61 " + nativeTestSink + ".monitor",
+		"set-card-profile bluez_card.08_FF_44_2B_4C_90 headset-head-unit",
+	}
+	if got := srv.Calls(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected calls:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestNativeValidation(t *testing.T) {
+	_, svc := newNativeFixture(t)
+	if err := svc.SetVolume(context.Background(), "invalid", "x", 50); err == nil {
+		t.Fatal("expected validation error for invalid target")
+	}
+	if err := svc.SetVolume(context.Background(), "sink", "x", 200); err == nil {
+		t.Fatal("expected validation error for invalid percent")
+	}
+}
+
+func TestNativeRedialsAfterServerRestart(t *testing.T) {
+	srv, svc := newNativeFixture(t)
+	srv.Disconnect()
+
+	// The first call after a drop may race the client noticing; retry briefly.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		_, err := svc.ListSinks(context.Background())
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("service did not reconnect: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNativeNotifierFeedsSubscribeAudio(t *testing.T) {
+	srv, svc := newNativeFixture(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub := events.SubscribeAudio(ctx, nil, events.Options{Notifier: Notifier(svc), RestartDelay: 10 * time.Millisecond})
+
+	// The subscription is registered asynchronously and survives a server
+	// drop by redialling, so keep emitting until an event comes through.
+	next := func(index uint32) events.AudioEvent {
+		t.Helper()
+		deadline := time.After(2 * time.Second)
+		tick := time.NewTicker(20 * time.Millisecond)
+		defer tick.Stop()
+		for {
+			select {
+			case ev := <-sub.Events():
+				if ev.Index == int(index) {
+					return ev
+				}
+			case <-tick.C:
+				srv.Emit(pulse.SubscribeEvent{Type: pulse.FacilitySinkInput | pulse.EventNew, Index: index})
+			case <-deadline:
+				t.Fatalf("timed out waiting for event #%d", index)
+			}
+		}
+	}
+
+	ev := next(113)
+	if ev.Kind != events.KindNew || ev.Facility != "sink-input" || ev.Time.IsZero() {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+	srv.Disconnect()
+	next(114)
+}
+
+func TestNativeNotifierStopsWhileEventsArrive(t *testing.T) {
+	srv, svc := newNativeFixture(t)
+	sub := events.SubscribeAudio(context.Background(), nil, events.Options{Notifier: Notifier(svc)})
+	for srv.Subscribers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// Events keep arriving while the subscription stops, so the callback
+	// is likely running when it does; closing the channel must wait for it.
+	stop := make(chan struct{})
+	emitted := make(chan struct{})
+	go func() {
+		defer close(emitted)
+		for i := uint32(0); ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				srv.Emit(pulse.SubscribeEvent{Type: pulse.FacilitySink | pulse.EventChange, Index: i})
+			}
+		}
+	}()
+	for range 10 {
+		<-sub.Events()
+	}
+	sub.Stop()
+	defer func() { close(stop); <-emitted }()
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("subscription did not close")
+		}
+	}
+}
+
+func TestNotifierOnlyForNativeService(t *testing.T) {
+	if Notifier(NewExecService(nil)) != nil {
+		t.Fatal("expected no notifier for the pactl backend")
+	}
+}
+
+func TestNewNativeServiceFailsWithoutServer(t *testing.T) {
+	if _, err := NewNativeService(context.Background(), t.TempDir()+"/missing"); err == nil {
+		t.Fatal("expected dial error")
+	}
+}
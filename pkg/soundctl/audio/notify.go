@@ -0,0 +1,57 @@
+package audio
+
+import (
+	"context"
+
+	"soundctl/pkg/soundctl/events"
+	"soundctl/pkg/soundctl/pulse"
+)
+
+// NotifyAudio subscribes to server events on a connection of its own and
+// passes them to fn until ctx is done or the server goes away. It makes
+// NativeService an events.AudioNotifier, so no pactl is needed to watch.
+func (s *NativeService) NotifyAudio(ctx context.Context, fn func(events.AudioEvent)) error {
+	c, err := pulse.Dial(ctx, s.path, "soundctl-events")
+	if err != nil {
+		return err
+	}
+	// Events arrive on the read loop; wait for it so that fn is not called
+	// after NotifyAudio returns.
+	defer func() {
+		c.Close()
+		<-c.Done()
+	}()
+
+	err = c.Subscribe(ctx, pulse.MaskAll, func(ev pulse.SubscribeEvent) {
+		index := int(ev.Index)
+		if ev.Index == pulse.InvalidIndex {
+			index = -1
+		}
+		fn(events.AudioEvent{Kind: ev.KindName(), Facility: ev.FacilityName(), Index: index})
+	})
+	if err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.Done():
+		return pulse.ErrClosed
+	}
+}
+
+// Notifier returns svc as an events.AudioNotifier when it is the native
+// backend, and nil otherwise so events fall back to `pactl subscribe`. A
+// Lazy service is connected to find out.
+func Notifier(svc Service) events.AudioNotifier {
+	if l, ok := svc.(*Lazy); ok {
+		var err error
+		if svc, err = l.Service(context.Background()); err != nil {
+			return nil
+		}
+	}
+	if n, ok := svc.(*NativeService); ok {
+		return n
+	}
+	return nil
+}
@@ -28,11 +28,31 @@ type DefaultsInfo struct {
 type SinkInput struct {
 	Index     int
 	SinkIndex int
+	Client    int // -1 when the stream has no client
 	AppName   string
 	MediaName string
+	Binary    string
+	PID       int // 0 when unknown
+	Volume    []ChannelVolume
+	Muted     bool
+	Corked    bool
 	SinkName  string // resolved from sink index
 }
 
+// VolumePercent returns the loudest channel's volume, as for Device.
+func (si SinkInput) VolumePercent() int {
+	return loudest(si.Volume)
+}
+
+// SourceOutput represents an active recording stream reading from a source.
+type SourceOutput struct {
+	Index       int
+	SourceIndex int
+	AppName     string
+	MediaName   string
+	SourceName  string // resolved from source index
+}
+
 // Card represents an audio card with its available profiles.
 type Card struct {
 	Index         int
@@ -40,6 +60,8 @@ type Card struct {
 	Driver        string
 	Profiles      []CardProfile
 	ActiveProfile string
+	Codec         string // active bluetooth codec, e.g. "aac"; empty for other cards
+	Properties    map[string]string
 }
 
 // CardProfile represents a profile available on a card.
@@ -47,6 +69,59 @@ type CardProfile struct {
 	Name        string
 	Description string
 	Available   bool
+	Codec       string // bluetooth codec the profile streams with, if it names one
+}
+
+// ChannelVolume is the volume of a single channel of a sink or source.
+type ChannelVolume struct {
+	Channel string
+	Value   int // raw PulseAudio volume (65536 = 100%)
+	Percent int
+	DB      float64
+}
+
+// Port is a physical or logical port on a sink or source.
+type Port struct {
+	Name        string
+	Description string
+	Available   bool
+}
+
+// Device is a sink or source with its full volume, mute, port and
+// property details, as reported by `pactl list sinks|sources`.
+type Device struct {
+	ID            int
+	Name          string
+	Description   string
+	Driver        string
+	SampleSpec    string
+	ChannelMap    string
+	State         string
+	Muted         bool
+	Volume        []ChannelVolume
+	Balance       float64
+	BaseVolume    ChannelVolume
+	MonitorSource string // sinks only
+	MonitorOfSink string // sources only; empty when not a monitor
+	Ports         []Port
+	ActivePort    string
+	Properties    map[string]string
+}
+
+// VolumePercent returns the loudest channel's volume, which is what
+// desktop volume sliders display for a multi-channel device.
+func (d Device) VolumePercent() int {
+	return loudest(d.Volume)
+}
+
+func loudest(volumes []ChannelVolume) int {
+	pct := 0
+	for _, ch := range volumes {
+		if ch.Percent > pct {
+			pct = ch.Percent
+		}
+	}
+	return pct
 }
 
 type Service interface {
@@ -55,13 +130,21 @@ type Service interface {
 	ListCards(ctx context.Context) ([]ShortRecord, error)
 	GetDefaults(ctx context.Context) (DefaultsInfo, error)
 	ListSinkInputs(ctx context.Context) ([]SinkInput, error)
+	ListSourceOutputs(ctx context.Context) ([]SourceOutput, error)
 	ListCardsDetailed(ctx context.Context) ([]Card, error)
+	ListSinksDetailed(ctx context.Context) ([]Device, error)
+	ListSourcesDetailed(ctx context.Context) ([]Device, error)
 	SetDefaultSink(ctx context.Context, sink string) error
 	SetDefaultSource(ctx context.Context, source string) error
 	MoveSinkInput(ctx context.Context, streamID int, sink string) error
+	MoveSourceOutput(ctx context.Context, streamID int, source string) error
+	SetSinkInputVolume(ctx context.Context, streamID int, percent int) error
+	SetSinkInputMute(ctx context.Context, streamID int, muted bool) error
 	SetCardProfile(ctx context.Context, card string, profile string) error
 	SetVolume(ctx context.Context, target string, name string, percent int) error
+	SetChannelVolumes(ctx context.Context, target string, name string, volumes []uint32) error
 	ToggleMute(ctx context.Context, target string, name string) error
+	SetMute(ctx context.Context, target string, name string, muted bool) error
 }
 
 type ExecService struct {
@@ -144,17 +227,62 @@ func (s *ExecService) ListSinkInputs(ctx context.Context) ([]SinkInput, error) {
 
 	inputs := make([]SinkInput, 0, len(recs))
 	for _, rec := range recs {
+		var volumes []ChannelVolume
+		for _, v := range rec.Volume {
+			volumes = append(volumes, channelVolumeFromRecord(v))
+		}
 		inputs = append(inputs, SinkInput{
 			Index:     rec.Index,
 			SinkIndex: rec.SinkIndex,
+			Client:    rec.Client,
 			AppName:   rec.AppName,
 			MediaName: rec.MediaName,
+			Binary:    rec.Binary,
+			PID:       rec.PID,
+			Volume:    volumes,
+			Muted:     rec.Muted,
+			Corked:    rec.Corked,
 			SinkName:  sinkMap[rec.SinkIndex],
 		})
 	}
 	return inputs, nil
 }
 
+func (s *ExecService) ListSourceOutputs(ctx context.Context) ([]SourceOutput, error) {
+	out, err := s.runner.Run(ctx, "pactl", "list", "source-outputs")
+	if err != nil {
+		return nil, err
+	}
+	recs, err := parse.ParsePactlSourceOutputs(out)
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 {
+		return []SourceOutput{}, nil
+	}
+
+	sources, err := s.ListSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sourceMap := make(map[int]string, len(sources))
+	for _, source := range sources {
+		sourceMap[source.ID] = source.Name
+	}
+
+	outputs := make([]SourceOutput, 0, len(recs))
+	for _, rec := range recs {
+		outputs = append(outputs, SourceOutput{
+			Index:       rec.Index,
+			SourceIndex: rec.SourceIndex,
+			AppName:     rec.AppName,
+			MediaName:   rec.MediaName,
+			SourceName:  sourceMap[rec.SourceIndex],
+		})
+	}
+	return outputs, nil
+}
+
 func (s *ExecService) ListCardsDetailed(ctx context.Context) ([]Card, error) {
 	out, err := s.runner.Run(ctx, "pactl", "list", "cards")
 	if err != nil {
@@ -172,6 +300,7 @@ func (s *ExecService) ListCardsDetailed(ctx context.Context) ([]Card, error) {
 				Name:        p.Name,
 				Description: p.Description,
 				Available:   p.Available,
+				Codec:       ProfileCodec(p.Name, p.Description),
 			})
 		}
 		cards = append(cards, Card{
@@ -180,11 +309,92 @@ func (s *ExecService) ListCardsDetailed(ctx context.Context) ([]Card, error) {
 			Driver:        rec.Driver,
 			Profiles:      profiles,
 			ActiveProfile: rec.ActiveProfile,
+			Properties:    rec.Properties,
 		})
 	}
+
+	// The codec is usually only reported on the card's sink. Looking it up
+	// is best effort: the card list is still useful without it.
+	var sinks []Device
+	if needsNodeCodec(cards) {
+		sinks, _ = s.ListSinksDetailed(ctx)
+	}
+	for i, card := range cards {
+		if !IsBluetoothCard(card.Name) {
+			continue
+		}
+		var nodes []map[string]string
+		for _, d := range sinks {
+			if bluezNodeOf(card.Name, d.Name) {
+				nodes = append(nodes, d.Properties)
+			}
+		}
+		cards[i].Codec = activeCodec(card, nodes...)
+	}
 	return cards, nil
 }
 
+func (s *ExecService) ListSinksDetailed(ctx context.Context) ([]Device, error) {
+	out, err := s.runner.Run(ctx, "pactl", "list", "sinks")
+	if err != nil {
+		return nil, err
+	}
+	recs, err := parse.ParsePactlSinks(out)
+	if err != nil {
+		return nil, err
+	}
+	return devicesFromRecords(recs), nil
+}
+
+func (s *ExecService) ListSourcesDetailed(ctx context.Context) ([]Device, error) {
+	out, err := s.runner.Run(ctx, "pactl", "list", "sources")
+	if err != nil {
+		return nil, err
+	}
+	recs, err := parse.ParsePactlSources(out)
+	if err != nil {
+		return nil, err
+	}
+	return devicesFromRecords(recs), nil
+}
+
+func devicesFromRecords(recs []parse.PactlDeviceRecord) []Device {
+	devices := make([]Device, 0, len(recs))
+	for _, rec := range recs {
+		volumes := make([]ChannelVolume, 0, len(rec.Volume))
+		for _, v := range rec.Volume {
+			volumes = append(volumes, channelVolumeFromRecord(v))
+		}
+		ports := make([]Port, 0, len(rec.Ports))
+		for _, p := range rec.Ports {
+			ports = append(ports, Port{Name: p.Name, Description: p.Description, Available: p.Available})
+		}
+		devices = append(devices, Device{
+			ID:            rec.Index,
+			Name:          rec.Name,
+			Description:   rec.Description,
+			Driver:        rec.Driver,
+			SampleSpec:    rec.SampleSpec,
+			ChannelMap:    rec.ChannelMap,
+			State:         rec.State,
+			Muted:         rec.Muted,
+			Volume:        volumes,
+			Balance:       rec.Balance,
+			BaseVolume:    channelVolumeFromRecord(rec.BaseVolume),
+			MonitorSource: rec.MonitorSource,
+			MonitorOfSink: rec.MonitorOfSink,
+			Ports:         ports,
+			ActivePort:    rec.ActivePort,
+			Properties:    rec.Properties,
+		})
+	}
+	return devices
+}
+
+func channelVolumeFromRecord(v parse.PactlChannelVolumeRecord) ChannelVolume {
+	return ChannelVolume{Channel: v.Channel, Value: v.Value, Percent: v.Percent, DB: v.DB}
+}
+
 func (s *ExecService) SetDefaultSink(ctx context.Context, sink string) error {
 	if sink == "" {
 		return fmt.Errorf("sink is required")
@@ -209,6 +419,31 @@ func (s *ExecService) MoveSinkInput(ctx context.Context, streamID int, sink stri
 	return err
 }
 
+func (s *ExecService) SetSinkInputVolume(ctx context.Context, streamID int, percent int) error {
+	if percent < 0 || percent > 150 {
+		return fmt.Errorf("percent must be between 0 and 150")
+	}
+	_, err := s.runner.Run(ctx, "pactl", "set-sink-input-volume", strconv.Itoa(streamID), fmt.Sprintf("%d%%", percent))
+	return err
+}
+
+func (s *ExecService) SetSinkInputMute(ctx context.Context, streamID int, muted bool) error {
+	value := "0"
+	if muted {
+		value = "1"
+	}
+	_, err := s.runner.Run(ctx, "pactl", "set-sink-input-mute", strconv.Itoa(streamID), value)
+	return err
+}
+
+func (s *ExecService) MoveSourceOutput(ctx context.Context, streamID int, source string) error {
+	if source == "" {
+		return fmt.Errorf("source is required")
+	}
+	_, err := s.runner.Run(ctx, "pactl", "move-source-output", strconv.Itoa(streamID), source)
+	return err
+}
+
 func (s *ExecService) SetCardProfile(ctx context.Context, card string, profile string) error {
 	if card == "" {
 		return fmt.Errorf("card is required")
@@ -220,6 +455,19 @@ func (s *ExecService) SetCardProfile(ctx context.Context, card string, profile s
 	return err
 }
 
+// SwitchCardCodec switches the codec of a bluetooth card's active profile
+// through PulseAudio's message API.
+func (s *ExecService) SwitchCardCodec(ctx context.Context, card string, codec string) error {
+	if card == "" {
+		return fmt.Errorf("card is required")
+	}
+	if codec == "" {
+		return fmt.Errorf("codec is required")
+	}
+	_, err := s.runner.Run(ctx, "pactl", "send-message", "/card/"+card+"/bluez", "switch-codec", strconv.Quote(codec))
+	return err
+}
+
 func (s *ExecService) SetVolume(ctx context.Context, target string, name string, percent int) error {
 	if name == "" {
 		return fmt.Errorf("name is required")
@@ -235,6 +483,27 @@ func (s *ExecService) SetVolume(ctx context.Context, target string, name string,
 	return err
 }
 
+// SetChannelVolumes sets the raw volume of each channel of a sink or
+// source, in channel map order.
+func (s *ExecService) SetChannelVolumes(ctx context.Context, target string, name string, volumes []uint32) error {
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(volumes) == 0 {
+		return fmt.Errorf("volumes are required")
+	}
+	cmd, err := volumeCommand(target)
+	if err != nil {
+		return err
+	}
+	args := []string{cmd, name}
+	for _, v := range volumes {
+		args = append(args, strconv.FormatUint(uint64(v), 10))
+	}
+	_, err = s.runner.Run(ctx, "pactl", args...)
+	return err
+}
+
 func (s *ExecService) ToggleMute(ctx context.Context, target string, name string) error {
 	if name == "" {
 		return fmt.Errorf("name is required")
@@ -247,6 +516,22 @@ func (s *ExecService) ToggleMute(ctx context.Context, target string, name string
 	return err
 }
 
+func (s *ExecService) SetMute(ctx context.Context, target string, name string, muted bool) error {
+	if name == "" {
+		return fmt.Errorf("name is required")
+	}
+	cmd, err := muteCommand(target)
+	if err != nil {
+		return err
+	}
+	value := "0"
+	if muted {
+		value = "1"
+	}
+	_, err = s.runner.Run(ctx, "pactl", cmd, name, value)
+	return err
+}
+
 func volumeCommand(target string) (string, error) {
 	switch target {
 	case "sink":
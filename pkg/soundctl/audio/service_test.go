@@ -58,3 +58,51 @@ func TestToggleMuteUsesExpectedCommand(t *testing.T) {
 		t.Fatalf("unexpected calls: %#v", calls)
 	}
 }
+
+func TestListSinksDetailed(t *testing.T) {
+	fake := sexec.NewFakeRunner()
+	fake.Set("pactl", []string{"list", "sinks"}, sexec.CommandResult{Output: `Sink #47
+	State: RUNNING
+	Name: bluez_output.08_FF_44_2B_4C_90.1
+	Description: AirPods Max
+	Mute: yes
+	Volume: front-left: 45875 /  70% / -9.29 dB,   front-right: 39322 /  60% / -13.31 dB
+	        balance -0.14`})
+
+	svc := NewExecService(fake)
+	sinks, err := svc.ListSinksDetailed(context.Background())
+	if err != nil {
+		t.Fatalf("ListSinksDetailed failed: %v", err)
+	}
+	if len(sinks) != 1 {
+		t.Fatalf("expected 1 sink, got %d", len(sinks))
+	}
+	if sinks[0].ID != 47 || sinks[0].Description != "AirPods Max" {
+		t.Fatalf("unexpected sink: %+v", sinks[0])
+	}
+	if !sinks[0].Muted {
+		t.Fatal("expected muted=true")
+	}
+	if got := sinks[0].VolumePercent(); got != 70 {
+		t.Fatalf("expected volume 70%%, got %d", got)
+	}
+}
+
+func TestSetMuteUsesExplicitValue(t *testing.T) {
+	fake := sexec.NewFakeRunner()
+	fake.Set("pactl", []string{"set-sink-mute", "bt-sink", "1"}, sexec.CommandResult{})
+	fake.Set("pactl", []string{"set-sink-mute", "bt-sink", "0"}, sexec.CommandResult{})
+
+	svc := NewExecService(fake)
+	if err := svc.SetMute(context.Background(), "sink", "bt-sink", true); err != nil {
+		t.Fatalf("SetMute(true) failed: %v", err)
+	}
+	if err := svc.SetMute(context.Background(), "sink", "bt-sink", false); err != nil {
+		t.Fatalf("SetMute(false) failed: %v", err)
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 2 || calls[0] != "pactl set-sink-mute bt-sink 1" || calls[1] != "pactl set-sink-mute bt-sink 0" {
+		t.Fatalf("unexpected calls: %#v", calls)
+	}
+}
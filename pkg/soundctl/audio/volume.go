@@ -0,0 +1,244 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"soundctl/pkg/soundctl/pulse"
+)
+
+// MaxVolumePercent is the loudest volume soundctl sets, matching the
+// 0–150 range SetVolume accepts.
+const MaxVolumePercent = 150
+
+// VolumeUnit is the unit of a VolumeStep.
+type VolumeUnit int
+
+const (
+	UnitPercent VolumeUnit = iota
+	UnitDB
+)
+
+// VolumeStep is one value of a VolumeSpec.
+type VolumeStep struct {
+	Value    float64
+	Unit     VolumeUnit
+	Relative bool // "+5%" / "-3dB" rather than "60%" / "0dB"
+}
+
+// VolumeSpec is a volume change in the syntax hotkey scripts pass to
+// pactl: an absolute level ("60", "60%", "0dB"), a relative step ("+5%",
+// "-5%", "-6dB"), or one value per channel ("70%,60%"). As with pactl, a
+// leading sign always makes a value relative, and a bare number is a
+// percentage.
+type VolumeSpec struct {
+	Steps []VolumeStep
+}
+
+// ParseVolumeSpec parses a VolumeSpec.
+func ParseVolumeSpec(s string) (VolumeSpec, error) {
+	var spec VolumeSpec
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return VolumeSpec{}, fmt.Errorf("invalid volume %q: empty value", s)
+		}
+		step := VolumeStep{Relative: part[0] == '+' || part[0] == '-'}
+		num := part
+		lower := strings.ToLower(part)
+		switch {
+		case strings.HasSuffix(lower, "db"):
+			step.Unit = UnitDB
+			num = part[:len(part)-2]
+		case strings.HasSuffix(part, "%"):
+			num = part[:len(part)-1]
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return VolumeSpec{}, fmt.Errorf("invalid volume %q", part)
+		}
+		if !step.Relative && step.Unit == UnitPercent && v < 0 {
+			return VolumeSpec{}, fmt.Errorf("invalid volume %q: negative level", part)
+		}
+		step.Value = v
+		spec.Steps = append(spec.Steps, step)
+	}
+	return spec, nil
+}
+
+func (s VolumeSpec) String() string {
+	parts := make([]string, len(s.Steps))
+	for i, st := range s.Steps {
+		unit := "%"
+		if st.Unit == UnitDB {
+			unit = "dB"
+		}
+		num := strconv.FormatFloat(st.Value, 'f', -1, 64)
+		if st.Relative && st.Value >= 0 {
+			num = "+" + num
+		}
+		parts[i] = num + unit
+	}
+	return strings.Join(parts, ",")
+}
+
+// Resolve applies the spec to a device's current channel volumes and
+// returns the new raw volume of every channel, clamped to
+// 0–MaxVolumePercent. A single step applies to every channel; otherwise
+// there must be one step per channel.
+func (s VolumeSpec) Resolve(current []ChannelVolume) ([]uint32, error) {
+	if len(s.Steps) == 0 {
+		return nil, fmt.Errorf("empty volume")
+	}
+	if len(current) == 0 {
+		return nil, fmt.Errorf("device has no volume")
+	}
+	if len(s.Steps) != 1 && len(s.Steps) != len(current) {
+		return nil, fmt.Errorf("volume %s has %d values for %d channels", s, len(s.Steps), len(current))
+	}
+	out := make([]uint32, len(current))
+	for i, ch := range current {
+		step := s.Steps[0]
+		if len(s.Steps) > 1 {
+			step = s.Steps[i]
+		}
+		out[i] = clampVolume(step.apply(ch.Value))
+	}
+	return out, nil
+}
+
+func (st VolumeStep) apply(raw int) float64 {
+	switch {
+	case st.Unit == UnitDB && st.Relative:
+		if raw <= 0 {
+			return 0 // -inf dB plus anything is still silence
+		}
+		return float64(pulse.VolumeFromDB(pulse.VolumeDB(uint32(raw)) + st.Value))
+	case st.Unit == UnitDB:
+		return float64(pulse.VolumeFromDB(st.Value))
+	case st.Relative:
+		return float64(raw) + st.Value*pulse.VolumeNorm/100
+	default:
+		return st.Value * pulse.VolumeNorm / 100
+	}
+}
+
+func clampVolume(v float64) uint32 {
+	max := float64(pulse.VolumeFromPercent(MaxVolumePercent))
+	switch {
+	case v <= 0:
+		return 0
+	case v >= max:
+		return uint32(max)
+	}
+	return uint32(math.Round(v))
+}
+
+// BalanceVolumes returns channel volumes with the given left/right
+// balance (-1 full left … 1 full right), keeping the loudest channel's
+// level, like pa_cvolume_set_balance. Channels that are neither left nor
+// right take the loudest level.
+func BalanceVolumes(current []ChannelVolume, balance float64) ([]uint32, error) {
+	if balance < -1 || balance > 1 {
+		return nil, fmt.Errorf("balance must be between -1 and 1")
+	}
+	if len(current) == 0 {
+		return nil, fmt.Errorf("device has no volume")
+	}
+	level := 0
+	hasLeft, hasRight := false, false
+	for _, ch := range current {
+		level = max(level, ch.Value)
+		hasLeft = hasLeft || isLeftChannel(ch.Channel)
+		hasRight = hasRight || isRightChannel(ch.Channel)
+	}
+	if !hasLeft || !hasRight {
+		return nil, fmt.Errorf("device has no left/right channels")
+	}
+	left, right := float64(level), float64(level)
+	if balance < 0 {
+		right *= 1 + balance
+	} else {
+		left *= 1 - balance
+	}
+	out := make([]uint32, len(current))
+	for i, ch := range current {
+		switch {
+		case isLeftChannel(ch.Channel):
+			out[i] = clampVolume(left)
+		case isRightChannel(ch.Channel):
+			out[i] = clampVolume(right)
+		default:
+			out[i] = clampVolume(float64(level))
+		}
+	}
+	return out, nil
+}
+
+func isLeftChannel(ch string) bool {
+	return strings.HasSuffix(ch, "-left") || strings.Contains(ch, "-left-")
+}
+
+func isRightChannel(ch string) bool {
+	return strings.HasSuffix(ch, "-right") || strings.Contains(ch, "-right-")
+}
+
+// ChangeVolume applies spec to a sink or source and returns the device as
+// it is afterwards.
+func ChangeVolume(ctx context.Context, svc Service, target string, name string, spec VolumeSpec) (Device, error) {
+	dev, err := findDevice(ctx, svc, target, name)
+	if err != nil {
+		return Device{}, err
+	}
+	volumes, err := spec.Resolve(dev.Volume)
+	if err != nil {
+		return Device{}, fmt.Errorf("%s %s: %w", target, dev.Name, err)
+	}
+	if err := svc.SetChannelVolumes(ctx, target, dev.Name, volumes); err != nil {
+		return Device{}, err
+	}
+	return findDevice(ctx, svc, target, dev.Name)
+}
+
+// SetBalance sets a sink or source's left/right balance and returns the
+// device as it is afterwards.
+func SetBalance(ctx context.Context, svc Service, target string, name string, balance float64) (Device, error) {
+	dev, err := findDevice(ctx, svc, target, name)
+	if err != nil {
+		return Device{}, err
+	}
+	volumes, err := BalanceVolumes(dev.Volume, balance)
+	if err != nil {
+		return Device{}, fmt.Errorf("%s %s: %w", target, dev.Name, err)
+	}
+	if err := svc.SetChannelVolumes(ctx, target, dev.Name, volumes); err != nil {
+		return Device{}, err
+	}
+	return findDevice(ctx, svc, target, dev.Name)
+}
+
+// findDevice looks up a sink or source by name or index.
+func findDevice(ctx context.Context, svc Service, target string, name string) (Device, error) {
+	var devices []Device
+	var err error
+	switch target {
+	case "sink":
+		devices, err = svc.ListSinksDetailed(ctx)
+	case "source":
+		devices, err = svc.ListSourcesDetailed(ctx)
+	default:
+		return Device{}, fmt.Errorf("invalid target %q (expected sink or source)", target)
+	}
+	if err != nil {
+		return Device{}, err
+	}
+	for _, d := range devices {
+		if d.Name == name || strconv.Itoa(d.ID) == name {
+			return d, nil
+		}
+	}
+	return Device{}, fmt.Errorf("%s %s not found", target, name)
+}
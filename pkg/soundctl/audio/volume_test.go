@@ -0,0 +1,156 @@
+package audio
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	sexec "soundctl/pkg/soundctl/exec"
+	"soundctl/pkg/soundctl/pulse"
+)
+
+func stereo(left, right int) []ChannelVolume {
+	return []ChannelVolume{
+		{Channel: "front-left", Value: int(pulse.VolumeFromPercent(left))},
+		{Channel: "front-right", Value: int(pulse.VolumeFromPercent(right))},
+	}
+}
+
+func percents(volumes []uint32) []int {
+	out := make([]int, len(volumes))
+	for i, v := range volumes {
+		out[i] = pulse.VolumePercent(v)
+	}
+	return out
+}
+
+func TestParseVolumeSpec(t *testing.T) {
+	cases := map[string]string{
+		"60":      "60%",
+		"60%":     "60%",
+		"+5%":     "+5%",
+		"-5%":     "-5%",
+		"-6dB":    "-6dB",
+		"+3db":    "+3dB",
+		"70%,60%": "70%,60%",
+	}
+	for in, want := range cases {
+		spec, err := ParseVolumeSpec(in)
+		if err != nil {
+			t.Fatalf("ParseVolumeSpec(%q): %v", in, err)
+		}
+		if got := spec.String(); got != want {
+			t.Errorf("ParseVolumeSpec(%q) = %s, want %s", in, got, want)
+		}
+	}
+	// "-6dB" is an absolute level; "-6%" is a step.
+	if spec, _ := ParseVolumeSpec("-6dB"); !spec.Steps[0].Relative {
+		t.Errorf("expected a leading sign to make dB relative")
+	}
+	for _, bad := range []string{"", "loud", "5%,", "dB", "NaN"} {
+		if _, err := ParseVolumeSpec(bad); err == nil {
+			t.Errorf("ParseVolumeSpec(%q): expected error", bad)
+		}
+	}
+}
+
+func TestVolumeSpecResolve(t *testing.T) {
+	cases := []struct {
+		spec    string
+		current []ChannelVolume
+		want    []int
+	}{
+		{"+5%", stereo(60, 40), []int{65, 45}},
+		{"-50%", stereo(60, 40), []int{10, 0}},
+		{"+20%", stereo(140, 100), []int{150, 120}},
+		{"200", stereo(50, 50), []int{150, 150}},
+		{"70%,60%", stereo(50, 50), []int{70, 60}},
+		{"0dB", stereo(50, 50), []int{100, 100}},
+		{"+6dB", stereo(50, 0), []int{63, 0}},
+	}
+	for _, c := range cases {
+		spec, err := ParseVolumeSpec(c.spec)
+		if err != nil {
+			t.Fatalf("ParseVolumeSpec(%q): %v", c.spec, err)
+		}
+		got, err := spec.Resolve(c.current)
+		if err != nil {
+			t.Fatalf("Resolve(%q): %v", c.spec, err)
+		}
+		if !reflect.DeepEqual(percents(got), c.want) {
+			t.Errorf("Resolve(%q) = %v, want %v", c.spec, percents(got), c.want)
+		}
+	}
+	spec, _ := ParseVolumeSpec("70%,60%,50%")
+	if _, err := spec.Resolve(stereo(50, 50)); err == nil {
+		t.Errorf("expected error for a channel count mismatch")
+	}
+}
+
+func TestBalanceVolumes(t *testing.T) {
+	got, err := BalanceVolumes(stereo(80, 60), 0.5)
+	if err != nil {
+		t.Fatalf("BalanceVolumes: %v", err)
+	}
+	if want := []int{40, 80}; !reflect.DeepEqual(percents(got), want) {
+		t.Fatalf("BalanceVolumes(0.5) = %v, want %v", percents(got), want)
+	}
+	got, _ = BalanceVolumes(stereo(80, 60), 0)
+	if want := []int{80, 80}; !reflect.DeepEqual(percents(got), want) {
+		t.Fatalf("BalanceVolumes(0) = %v, want %v", percents(got), want)
+	}
+	if _, err := BalanceVolumes([]ChannelVolume{{Channel: "mono", Value: 100}}, 0.5); err == nil {
+		t.Errorf("expected error for a mono device")
+	}
+	if _, err := BalanceVolumes(stereo(50, 50), 2); err == nil {
+		t.Errorf("expected error for balance out of range")
+	}
+}
+
+func TestNativeChangeVolume(t *testing.T) {
+	srv, svc := newFakeService(t, pulse.FakeState{
+		Sinks: []pulse.DeviceInfo{{
+			Index: 47, Name: nativeTestSink, ChannelMap: []uint8{1, 2},
+			Volume:       []uint32{pulse.VolumeFromPercent(60), pulse.VolumeFromPercent(40)},
+			MonitorIndex: pulse.InvalidIndex,
+		}},
+	})
+	ctx := context.Background()
+
+	dev, err := ChangeVolume(ctx, svc, "sink", "47", VolumeSpec{Steps: []VolumeStep{{Value: 5, Relative: true}}})
+	if err != nil {
+		t.Fatalf("ChangeVolume: %v", err)
+	}
+	if dev.VolumePercent() != 65 || dev.Volume[1].Percent != 45 {
+		t.Fatalf("unexpected volume after step: %+v", dev.Volume)
+	}
+	dev, err = SetBalance(ctx, svc, "sink", nativeTestSink, -1)
+	if err != nil {
+		t.Fatalf("SetBalance: %v", err)
+	}
+	if dev.Balance != -1 {
+		t.Fatalf("unexpected balance %v", dev.Balance)
+	}
+	want := []string{
+		"set-sink-volume " + nativeTestSink + " 65% 45%",
+		"set-sink-volume " + nativeTestSink + " 65% 0%",
+	}
+	if got := srv.Calls(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected server calls: %v", got)
+	}
+	if _, err := ChangeVolume(ctx, svc, "sink", "missing", VolumeSpec{Steps: []VolumeStep{{Value: 5}}}); err == nil {
+		t.Fatal("expected error for a missing sink")
+	}
+}
+
+func TestExecSetChannelVolumes(t *testing.T) {
+	fake := sexec.NewFakeRunner()
+	fake.Set("pactl", []string{"set-source-volume", "mic", "65536", "32768"}, sexec.CommandResult{})
+	svc := NewExecService(fake)
+	if err := svc.SetChannelVolumes(context.Background(), "source", "mic", []uint32{65536, 32768}); err != nil {
+		t.Fatalf("SetChannelVolumes: %v", err)
+	}
+	if err := svc.SetChannelVolumes(context.Background(), "card", "mic", []uint32{65536}); err == nil {
+		t.Fatal("expected error for an invalid target")
+	}
+}
@@ -0,0 +1,437 @@
+// Package autoswitch flips bluetooth headsets between the high-quality
+// A2DP playback profile and a headset (HFP/HSP) profile with a microphone,
+// following whether anything is recording from the headset.
+package autoswitch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/events"
+)
+
+// Default hysteresis delays.
+const (
+	DefaultSwitchDelay = 500 * time.Millisecond
+	DefaultRevertDelay = 5 * time.Second
+)
+
+// Config selects the cards to manage and the hysteresis.
+type Config struct {
+	// Card is a card name or bluetooth address; "" manages every
+	// bluetooth card.
+	Card string
+	// SwitchDelay is how long a recording stream must persist before the
+	// card switches to the headset profile, so that short probes (e.g. a
+	// mixer's peak meter) do not degrade playback.
+	SwitchDelay time.Duration
+	// RevertDelay is how long the card must be idle before it switches
+	// back to A2DP, so that an app reopening its stream does not flap.
+	RevertDelay time.Duration
+	// DryRun logs the actions without executing them.
+	DryRun bool
+}
+
+// Action records one step the switcher took or, in dry-run mode, would
+// have taken.
+type Action struct {
+	Time      time.Time
+	Card      string
+	Operation string // "set-profile", "set-default-source", "inspect"
+	Target    string
+	Reason    string
+	DryRun    bool
+	Err       error
+}
+
+// cardState tracks one card between evaluations.
+type cardState struct {
+	wantSince time.Time // first time the mic was wanted while in A2DP
+	idleSince time.Time // first time nothing recorded while in headset mode
+
+	// Set while the switcher holds the card in headset mode.
+	switched    bool
+	a2dpProfile string // profile to return to
+	prevSource  string // default source before the switch
+	sourceSet   bool   // default source moved to the headset mic
+	dryProfile  string // profile assumed active in dry-run mode
+}
+
+// Engine decides profile switches from snapshots of the audio state.
+// Only cards it switched to headset mode are ever switched back, so a
+// headset profile chosen by hand is left alone. It is not safe for
+// concurrent use; Run serialises evaluation.
+type Engine struct {
+	au    audio.Service
+	cfg   Config
+	now   func() time.Time
+	cards map[string]*cardState
+}
+
+func NewEngine(au audio.Service, cfg Config) *Engine {
+	if cfg.SwitchDelay < 0 {
+		cfg.SwitchDelay = 0
+	}
+	if cfg.RevertDelay < 0 {
+		cfg.RevertDelay = 0
+	}
+	return &Engine{au: au, cfg: cfg, now: time.Now, cards: map[string]*cardState{}}
+}
+
+// relevantFacilities are the audio event facilities that can change a
+// decision.
+var relevantFacilities = map[string]bool{
+	"source-output": true,
+	"source":        true,
+	"card":          true,
+	"server":        true,
+}
+
+// Run evaluates once, then again on every relevant audio event and when a
+// hysteresis delay expires, until ctx is cancelled or the channel closes.
+func (e *Engine) Run(ctx context.Context, audioEvents <-chan events.AudioEvent, onAction func(Action)) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-audioEvents:
+			if !ok {
+				return nil
+			}
+			if !relevantFacilities[ev.Facility] {
+				continue
+			}
+		case <-timer.C:
+		}
+
+		now := e.now()
+		actions, wake, err := e.Evaluate(ctx, now)
+		if err != nil {
+			actions = append(actions, Action{Time: now, Operation: "inspect", Err: err})
+		}
+		for _, a := range actions {
+			onAction(a)
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if !wake.IsZero() {
+			timer.Reset(wake.Sub(now))
+		}
+	}
+}
+
+// snapshot is the audio state one evaluation works from.
+type snapshot struct {
+	cards    []audio.Card
+	sinks    []audio.Device
+	sources  []audio.Device
+	defaults audio.DefaultsInfo
+	outputs  []audio.SourceOutput
+}
+
+func (e *Engine) snapshot(ctx context.Context) (snapshot, error) {
+	var s snapshot
+	var err error
+	if s.cards, err = e.au.ListCardsDetailed(ctx); err != nil {
+		return s, fmt.Errorf("list cards: %w", err)
+	}
+	if s.sinks, err = e.au.ListSinksDetailed(ctx); err != nil {
+		return s, fmt.Errorf("list sinks: %w", err)
+	}
+	if s.sources, err = e.au.ListSourcesDetailed(ctx); err != nil {
+		return s, fmt.Errorf("list sources: %w", err)
+	}
+	if s.defaults, err = e.au.GetDefaults(ctx); err != nil {
+		return s, fmt.Errorf("get defaults: %w", err)
+	}
+	if s.outputs, err = e.au.ListSourceOutputs(ctx); err != nil {
+		return s, fmt.Errorf("list source outputs: %w", err)
+	}
+	return s, nil
+}
+
+// Evaluate takes a snapshot at now and returns the actions taken and the
+// time of the next pending hysteresis deadline (zero if none).
+func (e *Engine) Evaluate(ctx context.Context, now time.Time) ([]Action, time.Time, error) {
+	snap, err := e.snapshot(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var actions []Action
+	var wake time.Time
+	seen := map[string]bool{}
+	for _, card := range snap.cards {
+		address := audio.ObjectAddress(card.Name, card.Properties)
+		if address == "" || !e.manages(card.Name, address) {
+			continue
+		}
+		seen[card.Name] = true
+		st := e.cards[card.Name]
+		if st == nil {
+			st = &cardState{}
+			e.cards[card.Name] = st
+		}
+		acts, deadline := e.evaluateCard(ctx, now, snap, card, address, st)
+		actions = append(actions, acts...)
+		if !deadline.IsZero() && (wake.IsZero() || deadline.Before(wake)) {
+			wake = deadline
+		}
+	}
+	// Forget cards that went away (disconnected headsets).
+	for name := range e.cards {
+		if !seen[name] {
+			delete(e.cards, name)
+		}
+	}
+	return actions, wake, nil
+}
+
+func (e *Engine) manages(cardName, address string) bool {
+	return e.cfg.Card == "" || e.cfg.Card == cardName || strings.EqualFold(e.cfg.Card, address)
+}
+
+func (e *Engine) evaluateCard(ctx context.Context, now time.Time, snap snapshot, card audio.Card, address string, st *cardState) ([]Action, time.Time) {
+	active := card.ActiveProfile
+	if e.cfg.DryRun && st.dryProfile != "" {
+		active = st.dryProfile
+	}
+	recording := recordingStreams(snap, address)
+
+	switch {
+	case IsA2DPProfile(active):
+		st.idleSince = time.Time{}
+		if recording == 0 {
+			st.wantSince = time.Time{}
+			return nil, time.Time{}
+		}
+		if st.wantSince.IsZero() {
+			st.wantSince = now
+		}
+		if deadline := st.wantSince.Add(e.cfg.SwitchDelay); now.Before(deadline) {
+			return nil, deadline
+		}
+		target := BestHeadsetProfile(card.Profiles)
+		if target == "" {
+			return nil, time.Time{}
+		}
+		st.wantSince = time.Time{}
+		reason := fmt.Sprintf("%d recording stream(s)", recording)
+		a := e.setProfile(ctx, now, card.Name, target, reason, st)
+		if a.Err == nil {
+			st.switched = true
+			st.a2dpProfile = active
+			st.prevSource = snap.defaults.DefaultSourceName
+			st.sourceSet = false
+		}
+		return []Action{a}, time.Time{}
+
+	case IsHeadsetProfile(active):
+		st.wantSince = time.Time{}
+		if !st.switched {
+			return nil, time.Time{}
+		}
+		var actions []Action
+		if !st.sourceSet {
+			if mic := cardSource(snap.sources, address); mic != "" {
+				st.sourceSet = true
+				if mic != snap.defaults.DefaultSourceName {
+					actions = append(actions, e.setDefaultSource(ctx, now, card.Name, mic, "headset mic available"))
+				}
+			}
+		}
+		if recording > 0 {
+			st.idleSince = time.Time{}
+			return actions, time.Time{}
+		}
+		if st.idleSince.IsZero() {
+			st.idleSince = now
+		}
+		if deadline := st.idleSince.Add(e.cfg.RevertDelay); now.Before(deadline) {
+			return actions, deadline
+		}
+		target := st.a2dpProfile
+		if !hasAvailableProfile(card.Profiles, target) {
+			target = BestA2DPProfile(card.Profiles)
+		}
+		if target == "" {
+			return actions, time.Time{}
+		}
+		a := e.setProfile(ctx, now, card.Name, target, "no recording streams", st)
+		actions = append(actions, a)
+		if a.Err != nil {
+			return actions, time.Time{}
+		}
+		if st.sourceSet && st.prevSource != "" && sourceExists(snap.sources, st.prevSource) {
+			actions = append(actions, e.setDefaultSource(ctx, now, card.Name, st.prevSource, "restore previous default"))
+		}
+		*st = cardState{}
+		return actions, time.Time{}
+
+	default:
+		// Off or unknown profile: nothing to do, and nothing to revert to.
+		*st = cardState{}
+		return nil, time.Time{}
+	}
+}
+
+func (e *Engine) setProfile(ctx context.Context, now time.Time, card, profile, reason string, st *cardState) Action {
+	a := Action{Time: now, Card: card, Operation: "set-profile", Target: profile, Reason: reason, DryRun: e.cfg.DryRun}
+	if e.cfg.DryRun {
+		st.dryProfile = profile
+		return a
+	}
+	a.Err = e.au.SetCardProfile(ctx, card, profile)
+	return a
+}
+
+func (e *Engine) setDefaultSource(ctx context.Context, now time.Time, card, source, reason string) Action {
+	a := Action{Time: now, Card: card, Operation: "set-default-source", Target: source, Reason: reason, DryRun: e.cfg.DryRun}
+	if !e.cfg.DryRun {
+		a.Err = e.au.SetDefaultSource(ctx, source)
+	}
+	return a
+}
+
+// recordingStreams counts the streams that want the headset's microphone:
+// those already reading from its source, and, while the headset is the
+// default sink, those reading from the default source (which is some other
+// mic as long as the card is in A2DP). Monitor sources never count.
+func recordingStreams(snap snapshot, address string) int {
+	byName := make(map[string]audio.Device, len(snap.sources))
+	for _, src := range snap.sources {
+		byName[src.Name] = src
+	}
+	headsetIsDefault := false
+	for _, sink := range snap.sinks {
+		if sink.Name == snap.defaults.DefaultSinkName && sink.BelongsTo(address) {
+			headsetIsDefault = true
+		}
+	}
+
+	n := 0
+	for _, out := range snap.outputs {
+		src, ok := byName[out.SourceName]
+		if !ok || src.MonitorOfSink != "" || strings.HasSuffix(src.Name, ".monitor") {
+			continue
+		}
+		if src.BelongsTo(address) || (headsetIsDefault && src.Name == snap.defaults.DefaultSourceName) {
+			n++
+		}
+	}
+	return n
+}
+
+func cardSource(sources []audio.Device, address string) string {
+	for _, src := range sources {
+		if src.MonitorOfSink == "" && !strings.HasSuffix(src.Name, ".monitor") && src.BelongsTo(address) {
+			return src.Name
+		}
+	}
+	return ""
+}
+
+func sourceExists(sources []audio.Device, name string) bool {
+	for _, src := range sources {
+		if src.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ── Profiles ────────────────────────────────────────────────────────────────
+
+// IsHeadsetProfile reports whether a card profile has a microphone, e.g.
+// PipeWire's "headset-head-unit-msbc" or PulseAudio's "handsfree_head_unit".
+func IsHeadsetProfile(name string) bool {
+	n := strings.ToLower(name)
+	return strings.Contains(n, "head-unit") || strings.Contains(n, "head_unit") ||
+		strings.HasPrefix(n, "hsp") || strings.HasPrefix(n, "hfp")
+}
+
+// IsA2DPProfile reports whether a card profile is A2DP playback, e.g.
+// "a2dp-sink-aac" or "a2dp_sink".
+func IsA2DPProfile(name string) bool {
+	n := strings.ToLower(name)
+	return strings.HasPrefix(n, "a2dp") && strings.Contains(n, "sink")
+}
+
+// codecRank scores profiles whose name contains marker.
+type codecRank struct {
+	marker string
+	rank   int
+}
+
+// headsetRanks prefers wideband speech codecs.
+var headsetRanks = []codecRank{
+	{"lc3", 4},
+	{"msbc", 3},
+	{"cvsd", 1},
+}
+
+// a2dpRanks prefers higher-quality codecs. The codec-less profile lets the
+// server pick, which is usually a good choice, so it ranks with AAC.
+var a2dpRanks = []codecRank{
+	{"ldac", 6},
+	{"aptx_hd", 5},
+	{"aptx-hd", 5},
+	{"aptx", 4},
+	{"aac", 3},
+	{"sbc_xq", 2},
+	{"sbc-xq", 2},
+	{"sbc", 1},
+}
+
+func rank(name string, ranks []codecRank, fallback int) int {
+	n := strings.ToLower(name)
+	for _, r := range ranks {
+		if strings.Contains(n, r.marker) {
+			return r.rank
+		}
+	}
+	return fallback
+}
+
+// BestHeadsetProfile picks the available headset profile with the best
+// speech codec, or "" if there is none.
+func BestHeadsetProfile(profiles []audio.CardProfile) string {
+	return best(profiles, IsHeadsetProfile, func(name string) int { return rank(name, headsetRanks, 2) })
+}
+
+// BestA2DPProfile picks the available A2DP playback profile with the best
+// codec, or "" if there is none.
+func BestA2DPProfile(profiles []audio.CardProfile) string {
+	return best(profiles, IsA2DPProfile, func(name string) int { return rank(name, a2dpRanks, 3) })
+}
+
+func best(profiles []audio.CardProfile, match func(string) bool, score func(string) int) string {
+	bestName, bestScore := "", -1
+	for _, p := range profiles {
+		if !p.Available || !match(p.Name) {
+			continue
+		}
+		if s := score(p.Name); s > bestScore {
+			bestName, bestScore = p.Name, s
+		}
+	}
+	return bestName
+}
+
+func hasAvailableProfile(profiles []audio.CardProfile, name string) bool {
+	for _, p := range profiles {
+		if p.Name == name && p.Available {
+			return true
+		}
+	}
+	return false
+}
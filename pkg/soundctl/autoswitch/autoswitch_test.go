@@ -0,0 +1,219 @@
+package autoswitch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/pulse"
+	"soundctl/pkg/soundctl/pulse/pulsetest"
+)
+
+const (
+	headsetCard = "bluez_card.08_FF_44_2B_4C_90"
+	headsetSink = "bluez_output.08_FF_44_2B_4C_90.1"
+	headsetMic  = "bluez_input.08_FF_44_2B_4C_90.0"
+	speakerSink = "alsa_output.pci-0000_00_1f.3.analog-stereo"
+	builtinMic  = "alsa_input.pci-0000_00_1f.3.analog-stereo"
+)
+
+func newFixture(t *testing.T) (*pulse.FakeServer, audio.Service) {
+	t.Helper()
+	return pulsetest.NewService(t, pulse.FakeState{
+		Info: pulse.ServerInfo{DefaultSink: headsetSink, DefaultSource: builtinMic},
+		Sinks: []pulse.DeviceInfo{
+			{Index: 1, Name: speakerSink, ChannelMap: []uint8{1, 2}, MonitorIndex: 3, MonitorName: speakerSink + ".monitor"},
+			{Index: 5, Name: headsetSink, ChannelMap: []uint8{1, 2}, MonitorIndex: pulse.InvalidIndex},
+		},
+		Sources: []pulse.DeviceInfo{
+			{Index: 2, Name: builtinMic, ChannelMap: []uint8{0}, MonitorIndex: pulse.InvalidIndex},
+			{Index: 3, Name: speakerSink + ".monitor", ChannelMap: []uint8{1, 2}, MonitorIndex: 1, MonitorName: speakerSink},
+		},
+		Cards: []pulse.CardInfo{{
+			Index: 9,
+			Name:  headsetCard,
+			Profiles: []pulse.CardProfileInfo{
+				{Name: "off", Available: true},
+				{Name: "a2dp-sink", Available: true},
+				{Name: "a2dp-sink-aac", Available: true},
+				{Name: "headset-head-unit-cvsd", Available: true},
+				{Name: "headset-head-unit-msbc", Available: true},
+			},
+			ActiveProfile: "a2dp-sink-aac",
+		}},
+	})
+}
+
+func record(srv *pulse.FakeServer, source uint32) {
+	srv.Update(func(st *pulse.FakeState) {
+		st.SourceOutputs = []pulse.SourceOutputInfo{{
+			Index: 61, Source: source, ChannelMap: []uint8{0},
+			Properties: pulse.PropList{"application.name": "Zoom"},
+		}}
+	})
+}
+
+func stopRecording(srv *pulse.FakeServer) {
+	srv.Update(func(st *pulse.FakeState) { st.SourceOutputs = nil })
+}
+
+func operations(actions []Action) []string {
+	var ops []string
+	for _, a := range actions {
+		if a.Err != nil {
+			ops = append(ops, a.Operation+" "+a.Target+" ERR "+a.Err.Error())
+			continue
+		}
+		ops = append(ops, a.Operation+" "+a.Target)
+	}
+	return ops
+}
+
+func evaluate(t *testing.T, e *Engine, now time.Time) ([]string, time.Time) {
+	t.Helper()
+	actions, wake, err := e.Evaluate(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	return operations(actions), wake
+}
+
+func TestSwitchesToHeadsetAndBackWithHysteresis(t *testing.T) {
+	srv, svc := newFixture(t)
+	e := NewEngine(svc, Config{SwitchDelay: time.Second, RevertDelay: 5 * time.Second})
+	t0 := time.Date(2026, 2, 16, 10, 0, 0, 0, time.UTC)
+
+	if ops, wake := evaluate(t, e, t0); ops != nil || !wake.IsZero() {
+		t.Fatalf("expected nothing while idle, got %v wake %v", ops, wake)
+	}
+
+	// Zoom opens the default (built-in) mic while the headset plays.
+	record(srv, 2)
+	ops, wake := evaluate(t, e, t0)
+	if ops != nil || !wake.Equal(t0.Add(time.Second)) {
+		t.Fatalf("expected to wait out the switch delay, got %v wake %v", ops, wake)
+	}
+	ops, _ = evaluate(t, e, t0.Add(time.Second))
+	if want := []string{"set-profile headset-head-unit-msbc"}; !reflect.DeepEqual(ops, want) {
+		t.Fatalf("unexpected switch actions: got %v want %v", ops, want)
+	}
+
+	// The headset mic appears after the profile change.
+	srv.Update(func(st *pulse.FakeState) {
+		st.Sources = append(st.Sources, pulse.DeviceInfo{Index: 7, Name: headsetMic, ChannelMap: []uint8{0}, MonitorIndex: pulse.InvalidIndex})
+	})
+	ops, _ = evaluate(t, e, t0.Add(2*time.Second))
+	if want := []string{"set-default-source " + headsetMic}; !reflect.DeepEqual(ops, want) {
+		t.Fatalf("unexpected source actions: got %v want %v", ops, want)
+	}
+	record(srv, 7)
+	if ops, _ := evaluate(t, e, t0.Add(3*time.Second)); ops != nil {
+		t.Fatalf("expected nothing while recording, got %v", ops)
+	}
+
+	// A brief gap shorter than the revert delay keeps the headset profile.
+	stopRecording(srv)
+	t1 := t0.Add(10 * time.Second)
+	if ops, wake := evaluate(t, e, t1); ops != nil || !wake.Equal(t1.Add(5*time.Second)) {
+		t.Fatalf("expected to wait out the revert delay, got %v wake %v", ops, wake)
+	}
+	record(srv, 7)
+	if ops, _ := evaluate(t, e, t1.Add(2*time.Second)); ops != nil {
+		t.Fatalf("expected no revert on a short gap, got %v", ops)
+	}
+
+	stopRecording(srv)
+	t2 := t1.Add(20 * time.Second)
+	evaluate(t, e, t2)
+	ops, _ = evaluate(t, e, t2.Add(5*time.Second))
+	want := []string{"set-profile a2dp-sink-aac", "set-default-source " + builtinMic}
+	if !reflect.DeepEqual(ops, want) {
+		t.Fatalf("unexpected revert actions: got %v want %v", ops, want)
+	}
+
+	wantCalls := []string{
+		"set-card-profile " + headsetCard + " headset-head-unit-msbc",
+		"set-default-source " + headsetMic,
+		"set-card-profile " + headsetCard + " a2dp-sink-aac",
+		"set-default-source " + builtinMic,
+	}
+	if got := srv.Calls(); !reflect.DeepEqual(got, wantCalls) {
+		t.Fatalf("unexpected server calls:\n got %v\nwant %v", got, wantCalls)
+	}
+}
+
+func TestDryRunDoesNotTouchServer(t *testing.T) {
+	srv, svc := newFixture(t)
+	e := NewEngine(svc, Config{DryRun: true})
+	t0 := time.Date(2026, 2, 16, 10, 0, 0, 0, time.UTC)
+
+	record(srv, 2)
+	actions, _, err := e.Evaluate(context.Background(), t0)
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if len(actions) != 1 || !actions[0].DryRun || actions[0].Target != "headset-head-unit-msbc" {
+		t.Fatalf("unexpected dry-run actions: %+v", actions)
+	}
+	// The planned profile is assumed active: no repeated switch.
+	if ops, _ := evaluate(t, e, t0.Add(time.Second)); ops != nil {
+		t.Fatalf("expected no repeated switch, got %v", ops)
+	}
+	stopRecording(srv)
+	if ops, _ := evaluate(t, e, t0.Add(2*time.Second)); !reflect.DeepEqual(ops, []string{"set-profile a2dp-sink-aac"}) {
+		t.Fatalf("unexpected dry-run revert: %v", ops)
+	}
+	if calls := srv.Calls(); len(calls) != 0 {
+		t.Fatalf("expected no server calls in dry-run, got %v", calls)
+	}
+}
+
+func TestIgnoresMonitorsOtherSinksAndManualHeadsetMode(t *testing.T) {
+	srv, svc := newFixture(t)
+	e := NewEngine(svc, Config{})
+	t0 := time.Date(2026, 2, 16, 10, 0, 0, 0, time.UTC)
+
+	// Recording the speaker monitor (e.g. a screen recorder) is not mic use.
+	record(srv, 3)
+	if ops, _ := evaluate(t, e, t0); ops != nil {
+		t.Fatalf("expected monitor streams to be ignored, got %v", ops)
+	}
+
+	// With the speakers as default sink, the built-in mic is someone else's.
+	record(srv, 2)
+	srv.Update(func(st *pulse.FakeState) { st.Info.DefaultSink = speakerSink })
+	if ops, _ := evaluate(t, e, t0); ops != nil {
+		t.Fatalf("expected no switch when the headset is not the default sink, got %v", ops)
+	}
+
+	// A headset profile chosen by hand is never reverted.
+	srv.Update(func(st *pulse.FakeState) { st.Cards[0].ActiveProfile = "headset-head-unit-cvsd" })
+	stopRecording(srv)
+	if ops, _ := evaluate(t, e, t0.Add(time.Minute)); ops != nil {
+		t.Fatalf("expected manual headset mode to be left alone, got %v", ops)
+	}
+	if calls := srv.Calls(); len(calls) != 0 {
+		t.Fatalf("expected no server calls, got %v", calls)
+	}
+}
+
+func TestBestProfiles(t *testing.T) {
+	profiles := []audio.CardProfile{
+		{Name: "a2dp_sink", Available: true},
+		{Name: "a2dp-sink-ldac", Available: false},
+		{Name: "a2dp-sink-sbc", Available: true},
+		{Name: "headset_head_unit", Available: true},
+		{Name: "headset-head-unit-cvsd", Available: true},
+	}
+	if got := BestA2DPProfile(profiles); got != "a2dp_sink" {
+		t.Fatalf("BestA2DPProfile = %q", got)
+	}
+	if got := BestHeadsetProfile(profiles); got != "headset_head_unit" {
+		t.Fatalf("BestHeadsetProfile = %q", got)
+	}
+	if got := BestHeadsetProfile(nil); got != "" {
+		t.Fatalf("BestHeadsetProfile(nil) = %q", got)
+	}
+}
@@ -0,0 +1,372 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// BlueZ D-Bus names, see doc/org.bluez.*.rst in the BlueZ tree.
+const (
+	bluezService      = "org.bluez"
+	adapterInterface  = "org.bluez.Adapter1"
+	deviceInterface   = "org.bluez.Device1"
+	errorAlreadyExist = "org.bluez.Error.AlreadyExists"
+)
+
+// ManagedObjects is the result of ObjectManager.GetManagedObjects with
+// variants unwrapped: object path → interface → property → value.
+type ManagedObjects map[string]map[string]map[string]any
+
+// Bus is the subset of the system bus the BlueZ backend needs.
+type Bus interface {
+	ManagedObjects(ctx context.Context) (ManagedObjects, error)
+	Call(ctx context.Context, path string, method string, args ...any) error
+	SetProperty(ctx context.Context, path string, iface string, property string, value any) error
+}
+
+// DBusService implements Service against org.bluez on the system bus.
+type DBusService struct {
+	bus Bus
+}
+
+func NewDBusService(bus Bus) *DBusService {
+	return &DBusService{bus: bus}
+}
+
+func (s *DBusService) ListDevices(ctx context.Context) ([]Device, error) {
+	objects, err := s.bus.ManagedObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	devices := []Device{}
+	for _, path := range sortedPaths(objects) {
+		props, ok := objects[path][deviceInterface]
+		if !ok {
+			continue
+		}
+		info := deviceInfoFromProps(props)
+		devices = append(devices, Device{
+			Address:    info.Address,
+			Name:       info.Name,
+			Paired:     info.Paired,
+			Trusted:    info.Trusted,
+			Connected:  info.Connected,
+			Connection: modeFromInfo(info),
+		})
+	}
+	return devices, nil
+}
+
+func (s *DBusService) ControllerStatus(ctx context.Context) (ControllerStatus, error) {
+	objects, err := s.bus.ManagedObjects(ctx)
+	if err != nil {
+		return ControllerStatus{}, err
+	}
+	path, ok := firstAdapter(objects)
+	if !ok {
+		return ControllerStatus{}, fmt.Errorf("no bluetooth controller found")
+	}
+	props := objects[path][adapterInterface]
+	return ControllerStatus{
+		Address:     stringProp(props, "Address"),
+		Alias:       stringProp(props, "Alias"),
+		Powered:     boolProp(props, "Powered"),
+		Pairable:    boolProp(props, "Pairable"),
+		Discovering: boolProp(props, "Discovering"),
+	}, nil
+}
+
+func (s *DBusService) Info(ctx context.Context, address string) (DeviceInfo, error) {
+	if address == "" {
+		return DeviceInfo{}, fmt.Errorf("address is required")
+	}
+	objects, err := s.bus.ManagedObjects(ctx)
+	if err != nil {
+		return DeviceInfo{}, err
+	}
+	path, err := devicePath(objects, address)
+	if err != nil {
+		return DeviceInfo{}, err
+	}
+	return deviceInfoFromProps(objects[path][deviceInterface]), nil
+}
+
+// Discover runs adapter discovery for the given duration and returns the
+// devices that appeared or were seen (have an RSSI) meanwhile.
+func (s *DBusService) Discover(ctx context.Context, seconds int) ([]DiscoveredDevice, error) {
+	if seconds <= 0 {
+		seconds = 8
+	}
+	objects, err := s.bus.ManagedObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	adapter, ok := firstAdapter(objects)
+	if !ok {
+		return nil, fmt.Errorf("no bluetooth controller found")
+	}
+	if err := s.bus.Call(ctx, adapter, adapterInterface+".StartDiscovery"); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(time.Duration(seconds) * time.Second)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+	// Stop with a fresh context so a cancelled scan does not leave the
+	// adapter discovering.
+	stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stopErr := s.bus.Call(stopCtx, adapter, adapterInterface+".StopDiscovery")
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	after, err := s.bus.ManagedObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	devices := []DiscoveredDevice{}
+	for _, path := range sortedPaths(after) {
+		props, ok := after[path][deviceInterface]
+		if !ok {
+			continue
+		}
+		_, known := objects[path][deviceInterface]
+		_, seen := props["RSSI"]
+		if known && !seen {
+			continue
+		}
+		info := deviceInfoFromProps(props)
+		devices = append(devices, DiscoveredDevice{Address: info.Address, Name: info.Name})
+	}
+	return devices, stopErr
+}
+
+func (s *DBusService) Connect(ctx context.Context, address string) error {
+	return s.callDevice(ctx, address, "Connect")
+}
+
+func (s *DBusService) Disconnect(ctx context.Context, address string) error {
+	return s.callDevice(ctx, address, "Disconnect")
+}
+
+func (s *DBusService) Pair(ctx context.Context, address string) error {
+	err := s.callDevice(ctx, address, "Pair")
+	// Already paired should not abort trust/connect flows.
+	if busErrorName(err) == errorAlreadyExist {
+		return nil
+	}
+	return err
+}
+
+func (s *DBusService) Trust(ctx context.Context, address string) error {
+	if address == "" {
+		return fmt.Errorf("address is required")
+	}
+	objects, err := s.bus.ManagedObjects(ctx)
+	if err != nil {
+		return err
+	}
+	path, err := devicePath(objects, address)
+	if err != nil {
+		return err
+	}
+	return s.bus.SetProperty(ctx, path, deviceInterface, "Trusted", true)
+}
+
+func (s *DBusService) Remove(ctx context.Context, address string) error {
+	if address == "" {
+		return fmt.Errorf("address is required")
+	}
+	objects, err := s.bus.ManagedObjects(ctx)
+	if err != nil {
+		return err
+	}
+	path, err := devicePath(objects, address)
+	if err != nil {
+		return err
+	}
+	adapter := objectPathProp(objects[path][deviceInterface], "Adapter")
+	if adapter == "" {
+		return fmt.Errorf("device %s has no adapter", address)
+	}
+	return s.bus.Call(ctx, adapter, adapterInterface+".RemoveDevice", dbus.ObjectPath(path))
+}
+
+func (s *DBusService) StartScan(ctx context.Context) error {
+	return s.callAdapter(ctx, "StartDiscovery")
+}
+
+func (s *DBusService) StopScan(ctx context.Context) error {
+	return s.callAdapter(ctx, "StopDiscovery")
+}
+
+func (s *DBusService) callDevice(ctx context.Context, address string, method string) error {
+	if address == "" {
+		return fmt.Errorf("address is required")
+	}
+	objects, err := s.bus.ManagedObjects(ctx)
+	if err != nil {
+		return err
+	}
+	path, err := devicePath(objects, address)
+	if err != nil {
+		return err
+	}
+	return s.bus.Call(ctx, path, deviceInterface+"."+method)
+}
+
+func (s *DBusService) callAdapter(ctx context.Context, method string) error {
+	objects, err := s.bus.ManagedObjects(ctx)
+	if err != nil {
+		return err
+	}
+	adapter, ok := firstAdapter(objects)
+	if !ok {
+		return fmt.Errorf("no bluetooth controller found")
+	}
+	return s.bus.Call(ctx, adapter, adapterInterface+"."+method)
+}
+
+func devicePath(objects ManagedObjects, address string) (string, error) {
+	for _, path := range sortedPaths(objects) {
+		props, ok := objects[path][deviceInterface]
+		if ok && strings.EqualFold(stringProp(props, "Address"), address) {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("device %s not found", address)
+}
+
+func firstAdapter(objects ManagedObjects) (string, bool) {
+	for _, path := range sortedPaths(objects) {
+		if _, ok := objects[path][adapterInterface]; ok {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+func deviceInfoFromProps(props map[string]any) DeviceInfo {
+	name := stringProp(props, "Name")
+	alias := stringProp(props, "Alias")
+	if name == "" {
+		name = alias
+	}
+	return DeviceInfo{
+		Address:   stringProp(props, "Address"),
+		Name:      name,
+		Alias:     alias,
+		Paired:    boolProp(props, "Paired"),
+		Trusted:   boolProp(props, "Trusted"),
+		Connected: boolProp(props, "Connected"),
+	}
+}
+
+func sortedPaths(objects ManagedObjects) []string {
+	paths := make([]string, 0, len(objects))
+	for path := range objects {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func stringProp(props map[string]any, name string) string {
+	v, _ := props[name].(string)
+	return v
+}
+
+func boolProp(props map[string]any, name string) bool {
+	v, _ := props[name].(bool)
+	return v
+}
+
+func objectPathProp(props map[string]any, name string) string {
+	switch v := props[name].(type) {
+	case dbus.ObjectPath:
+		return string(v)
+	case string:
+		return v
+	}
+	return ""
+}
+
+// busErrorName returns the D-Bus error name carried by err, if any.
+func busErrorName(err error) string {
+	var derr dbus.Error
+	if errors.As(err, &derr) {
+		return derr.Name
+	}
+	var perr *dbus.Error
+	if errors.As(err, &perr) {
+		return perr.Name
+	}
+	return ""
+}
+
+// ── system bus ─────────────────────────────────────────────────────────────
+
+// SystemBus is a Bus backed by a godbus connection to the system bus.
+type SystemBus struct {
+	conn *dbus.Conn
+}
+
+// ConnectSystemBus opens a private system bus connection and checks that
+// org.bluez answers, so callers can fall back to ExecService.
+func ConnectSystemBus(ctx context.Context) (*SystemBus, error) {
+	conn, err := dbus.ConnectSystemBus(dbus.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	bus := &SystemBus{conn: conn}
+	if _, err := bus.ManagedObjects(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return bus, nil
+}
+
+// Close closes the bus connection.
+func (b *SystemBus) Close() error {
+	return b.conn.Close()
+}
+
+func (b *SystemBus) ManagedObjects(ctx context.Context) (ManagedObjects, error) {
+	var raw map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+	obj := b.conn.Object(bluezService, "/")
+	if err := obj.CallWithContext(ctx, "org.freedesktop.DBus.ObjectManager.GetManagedObjects", 0).Store(&raw); err != nil {
+		return nil, err
+	}
+	objects := make(ManagedObjects, len(raw))
+	for path, ifaces := range raw {
+		unwrapped := make(map[string]map[string]any, len(ifaces))
+		for iface, props := range ifaces {
+			values := make(map[string]any, len(props))
+			for name, v := range props {
+				values[name] = v.Value()
+			}
+			unwrapped[iface] = values
+		}
+		objects[string(path)] = unwrapped
+	}
+	return objects, nil
+}
+
+func (b *SystemBus) Call(ctx context.Context, path string, method string, args ...any) error {
+	return b.conn.Object(bluezService, dbus.ObjectPath(path)).CallWithContext(ctx, method, 0, args...).Err
+}
+
+func (b *SystemBus) SetProperty(ctx context.Context, path string, iface string, property string, value any) error {
+	obj := b.conn.Object(bluezService, dbus.ObjectPath(path))
+	return obj.CallWithContext(ctx, "org.freedesktop.DBus.Properties.Set", 0, iface, property, dbus.MakeVariant(value)).Err
+}
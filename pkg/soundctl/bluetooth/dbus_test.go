@@ -0,0 +1,132 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const (
+	testAdapter = "/org/bluez/hci0"
+	testAddress = "08:FF:44:2B:4C:90"
+	testPath    = testAdapter + "/dev_08_FF_44_2B_4C_90"
+)
+
+func newFakeBluez() *FakeBus {
+	bus := NewFakeBus()
+	bus.AddAdapter(testAdapter, map[string]any{
+		"Address":  "C0:3C:59:A1:00:01",
+		"Alias":    "laptop",
+		"Powered":  true,
+		"Pairable": true,
+	})
+	bus.AddDevice(testAdapter, testAddress, map[string]any{
+		"Name":    "AirPods Max",
+		"Alias":   "AirPods Max",
+		"Paired":  true,
+		"Trusted": false,
+	})
+	bus.AddDevice(testAdapter, "AC:80:0A:11:22:33", map[string]any{
+		"Alias":     "WH-1000XM4",
+		"Connected": true,
+		"Paired":    true,
+	})
+	return bus
+}
+
+func TestDBusListDevices(t *testing.T) {
+	svc := NewDBusService(newFakeBluez())
+	devices, err := svc.ListDevices(context.Background())
+	if err != nil {
+		t.Fatalf("ListDevices failed: %v", err)
+	}
+	want := []Device{
+		{Address: testAddress, Name: "AirPods Max", Paired: true, Connection: "paired"},
+		{Address: "AC:80:0A:11:22:33", Name: "WH-1000XM4", Paired: true, Connected: true, Connection: "connected"},
+	}
+	if !reflect.DeepEqual(devices, want) {
+		t.Fatalf("unexpected devices:\n got %#v\nwant %#v", devices, want)
+	}
+}
+
+func TestDBusControllerStatusAndInfo(t *testing.T) {
+	svc := NewDBusService(newFakeBluez())
+	status, err := svc.ControllerStatus(context.Background())
+	if err != nil {
+		t.Fatalf("ControllerStatus failed: %v", err)
+	}
+	if status.Alias != "laptop" || !status.Powered || status.Discovering {
+		t.Fatalf("unexpected status: %#v", status)
+	}
+
+	info, err := svc.Info(context.Background(), "08:ff:44:2b:4c:90")
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+	if info.Address != testAddress || !info.Paired || info.Trusted {
+		t.Fatalf("unexpected info: %#v", info)
+	}
+	if _, err := svc.Info(context.Background(), "00:00:00:00:00:00"); err == nil {
+		t.Fatal("expected error for unknown device")
+	}
+}
+
+func TestDBusDeviceOperations(t *testing.T) {
+	bus := newFakeBluez()
+	svc := NewDBusService(bus)
+	ctx := context.Background()
+
+	if err := svc.Pair(ctx, testAddress); err != nil {
+		t.Fatalf("Pair on paired device should be ignored: %v", err)
+	}
+	if err := svc.Trust(ctx, testAddress); err != nil {
+		t.Fatalf("Trust failed: %v", err)
+	}
+	if err := svc.Connect(ctx, testAddress); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if bus.Property(testPath, deviceInterface, "Connected") != true || bus.Property(testPath, deviceInterface, "Trusted") != true {
+		t.Fatal("expected device trusted and connected")
+	}
+	if err := svc.Remove(ctx, testAddress); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := svc.Info(ctx, testAddress); err == nil {
+		t.Fatal("expected removed device to be gone")
+	}
+
+	want := []string{
+		testPath + " org.bluez.Device1.Pair",
+		testPath + " org.bluez.Device1.Trusted=true",
+		testPath + " org.bluez.Device1.Connect",
+		testAdapter + " org.bluez.Adapter1.RemoveDevice",
+	}
+	if got := bus.Calls(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected calls:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestDBusConnectPropagatesBusError(t *testing.T) {
+	bus := newFakeBluez()
+	bus.SetError(testPath, deviceInterface+".Connect", errors.New("org.bluez.Error.Failed: br-connection-page-timeout"))
+	if err := NewDBusService(bus).Connect(context.Background(), testAddress); err == nil {
+		t.Fatal("expected connect error")
+	}
+}
+
+func TestDBusDiscoverReportsNewDevices(t *testing.T) {
+	bus := newFakeBluez()
+	bus.AddDiscoverable(testAdapter, "11:22:33:44:55:66", map[string]any{"Name": "JBL Flip 5", "RSSI": int16(-60)})
+	devices, err := NewDBusService(bus).Discover(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	want := []DiscoveredDevice{{Address: "11:22:33:44:55:66", Name: "JBL Flip 5"}}
+	if !reflect.DeepEqual(devices, want) {
+		t.Fatalf("unexpected discovered devices: %#v", devices)
+	}
+	if bus.Property(testAdapter, adapterInterface, "Discovering") != false {
+		t.Fatal("expected discovery to be stopped")
+	}
+}
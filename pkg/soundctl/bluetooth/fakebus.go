@@ -0,0 +1,183 @@
+package bluetooth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// FakeBus is a deterministic in-memory org.bluez for tests. Method calls
+// mutate the object tree the way bluetoothd would and are recorded as
+// "<path> <method>" strings.
+type FakeBus struct {
+	mu      sync.Mutex
+	objects ManagedObjects
+	pending map[string][]map[string]any // adapter path → devices found on discovery
+	errs    map[string]error
+	calls   []string
+}
+
+func NewFakeBus() *FakeBus {
+	return &FakeBus{
+		objects: ManagedObjects{},
+		pending: map[string][]map[string]any{},
+		errs:    map[string]error{},
+	}
+}
+
+// AddAdapter registers an Adapter1 at path (e.g. /org/bluez/hci0).
+func (f *FakeBus) AddAdapter(path string, props map[string]any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[path] = map[string]map[string]any{adapterInterface: copyProps(props)}
+}
+
+// AddDevice registers a Device1 under adapter and returns its path. The
+// Address and Adapter properties are filled in.
+func (f *FakeBus) AddDevice(adapter string, address string, props map[string]any) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path := fakeDevicePath(adapter, address)
+	f.objects[path] = map[string]map[string]any{deviceInterface: fakeDeviceProps(adapter, address, props)}
+	return path
+}
+
+// AddDiscoverable queues a device that appears, with an RSSI, once
+// discovery starts on adapter.
+func (f *FakeBus) AddDiscoverable(adapter string, address string, props map[string]any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending[adapter] = append(f.pending[adapter], fakeDeviceProps(adapter, address, props))
+}
+
+// SetError makes the given "<path> <method>" call fail with err.
+func (f *FakeBus) SetError(path string, method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errs[path+" "+method] = err
+}
+
+// Calls returns the method calls and property writes received so far.
+func (f *FakeBus) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]string, len(f.calls))
+	copy(out, f.calls)
+	return out
+}
+
+// Property returns the current value of an object property.
+func (f *FakeBus) Property(path string, iface string, property string) any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.objects[path][iface][property]
+}
+
+func (f *FakeBus) ManagedObjects(_ context.Context) (ManagedObjects, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make(ManagedObjects, len(f.objects))
+	for path, ifaces := range f.objects {
+		copied := make(map[string]map[string]any, len(ifaces))
+		for iface, props := range ifaces {
+			copied[iface] = copyProps(props)
+		}
+		out[path] = copied
+	}
+	return out, nil
+}
+
+func (f *FakeBus) Call(_ context.Context, path string, method string, args ...any) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := path + " " + method
+	f.calls = append(f.calls, key)
+	if err, ok := f.errs[key]; ok {
+		return err
+	}
+	ifaces, ok := f.objects[path]
+	if !ok {
+		return dbus.Error{Name: "org.freedesktop.DBus.Error.UnknownObject", Body: []any{"no such object " + path}}
+	}
+
+	dot := strings.LastIndex(method, ".")
+	iface, name := method[:max(dot, 0)], method[dot+1:]
+	props, ok := ifaces[iface]
+	if !ok {
+		return unknownMethod(method)
+	}
+	switch iface + "." + name {
+	case deviceInterface + ".Connect":
+		props["Connected"] = true
+	case deviceInterface + ".Disconnect":
+		props["Connected"] = false
+	case deviceInterface + ".Pair":
+		if paired, _ := props["Paired"].(bool); paired {
+			return dbus.Error{Name: errorAlreadyExist, Body: []any{"Already Exists"}}
+		}
+		props["Paired"] = true
+	case adapterInterface + ".StartDiscovery":
+		props["Discovering"] = true
+		for _, dev := range f.pending[path] {
+			address := dev["Address"].(string)
+			f.objects[fakeDevicePath(path, address)] = map[string]map[string]any{deviceInterface: dev}
+		}
+		delete(f.pending, path)
+	case adapterInterface + ".StopDiscovery":
+		props["Discovering"] = false
+	case adapterInterface + ".RemoveDevice":
+		if len(args) != 1 {
+			return dbus.Error{Name: "org.bluez.Error.InvalidArguments", Body: []any{"Invalid arguments"}}
+		}
+		target := fmt.Sprint(args[0])
+		if _, ok := f.objects[target]; !ok {
+			return dbus.Error{Name: "org.bluez.Error.DoesNotExist", Body: []any{"Does Not Exist"}}
+		}
+		delete(f.objects, target)
+	default:
+		return unknownMethod(method)
+	}
+	return nil
+}
+
+func (f *FakeBus) SetProperty(_ context.Context, path string, iface string, property string, value any) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := path + " " + iface + "." + property
+	f.calls = append(f.calls, fmt.Sprintf("%s=%v", key, value))
+	if err, ok := f.errs[key]; ok {
+		return err
+	}
+	props, ok := f.objects[path][iface]
+	if !ok {
+		return dbus.Error{Name: "org.freedesktop.DBus.Error.UnknownObject", Body: []any{"no such object " + path}}
+	}
+	props[property] = value
+	return nil
+}
+
+func unknownMethod(method string) error {
+	return dbus.Error{Name: "org.freedesktop.DBus.Error.UnknownMethod", Body: []any{"unknown method " + method}}
+}
+
+func fakeDevicePath(adapter string, address string) string {
+	return adapter + "/dev_" + strings.ReplaceAll(strings.ToUpper(address), ":", "_")
+}
+
+func fakeDeviceProps(adapter string, address string, props map[string]any) map[string]any {
+	out := copyProps(props)
+	out["Address"] = address
+	out["Adapter"] = dbus.ObjectPath(adapter)
+	return out
+}
+
+func copyProps(props map[string]any) map[string]any {
+	out := make(map[string]any, len(props))
+	for k, v := range props {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,126 @@
+package bluetooth
+
+import (
+	"context"
+	"sync"
+)
+
+// Lazy is a Service that connects on first use, so commands that never
+// touch bluetooth do not pay for (or fail on) reaching BlueZ.
+type Lazy struct {
+	connect func(ctx context.Context) (Service, error)
+
+	mu  sync.Mutex
+	svc Service
+}
+
+var _ Service = (*Lazy)(nil)
+
+// NewLazy returns a Service that calls connect the first time it is used.
+// A failed connect is retried on the next call.
+func NewLazy(connect func(ctx context.Context) (Service, error)) *Lazy {
+	return &Lazy{connect: connect}
+}
+
+// Service returns the service connect returned, connecting if needed.
+func (l *Lazy) Service(ctx context.Context) (Service, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.svc != nil {
+		return l.svc, nil
+	}
+	svc, err := l.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	l.svc = svc
+	return svc, nil
+}
+
+func (l *Lazy) ListDevices(ctx context.Context) ([]Device, error) {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.ListDevices(ctx)
+}
+
+func (l *Lazy) ControllerStatus(ctx context.Context) (ControllerStatus, error) {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return ControllerStatus{}, err
+	}
+	return svc.ControllerStatus(ctx)
+}
+
+func (l *Lazy) Info(ctx context.Context, address string) (DeviceInfo, error) {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return DeviceInfo{}, err
+	}
+	return svc.Info(ctx, address)
+}
+
+func (l *Lazy) Discover(ctx context.Context, seconds int) ([]DiscoveredDevice, error) {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svc.Discover(ctx, seconds)
+}
+
+func (l *Lazy) Connect(ctx context.Context, address string) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.Connect(ctx, address)
+}
+
+func (l *Lazy) Disconnect(ctx context.Context, address string) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.Disconnect(ctx, address)
+}
+
+func (l *Lazy) Trust(ctx context.Context, address string) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.Trust(ctx, address)
+}
+
+func (l *Lazy) Remove(ctx context.Context, address string) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.Remove(ctx, address)
+}
+
+func (l *Lazy) Pair(ctx context.Context, address string) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.Pair(ctx, address)
+}
+
+func (l *Lazy) StartScan(ctx context.Context) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.StartScan(ctx)
+}
+
+func (l *Lazy) StopScan(ctx context.Context) error {
+	svc, err := l.Service(ctx)
+	if err != nil {
+		return err
+	}
+	return svc.StopScan(ctx)
+}
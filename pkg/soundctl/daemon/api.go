@@ -0,0 +1,411 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/bluetooth"
+)
+
+// The RPC receivers below follow net/rpc's method shape; every exported
+// method is part of the wire API, named "<Receiver>.<Method>".
+
+// Empty is the argument or reply of calls that carry none.
+type Empty struct{}
+
+// AddressArgs names a bluetooth device.
+type AddressArgs struct{ Address string }
+
+// DiscoverArgs is the argument of Bluetooth.Discover.
+type DiscoverArgs struct{ Seconds int }
+
+// NameArgs names a sink or source.
+type NameArgs struct{ Name string }
+
+// MoveArgs is the argument of Audio.MoveSinkInput and
+// Audio.MoveSourceOutput.
+type MoveArgs struct {
+	StreamID int
+	Sink     string
+	Source   string
+}
+
+// CardProfileArgs is the argument of Audio.SetCardProfile.
+type CardProfileArgs struct {
+	Card    string
+	Profile string
+}
+
+// CardCodecArgs is the argument of Audio.SwitchCardCodec.
+type CardCodecArgs struct {
+	Card  string
+	Codec string
+}
+
+// VolumeArgs is the argument of Audio.SetVolume.
+type VolumeArgs struct {
+	Target  string
+	Name    string
+	Percent int
+}
+
+// ChannelVolumesArgs is the argument of Audio.SetChannelVolumes.
+type ChannelVolumesArgs struct {
+	Target  string
+	Name    string
+	Volumes []uint32
+}
+
+// MuteArgs is the argument of Audio.ToggleMute and Audio.SetMute.
+type MuteArgs struct {
+	Target string
+	Name   string
+	Muted  bool
+}
+
+// StreamArgs is the argument of Audio.SetSinkInputVolume and
+// Audio.SetSinkInputMute.
+type StreamArgs struct {
+	StreamID int
+	Percent  int
+	Muted    bool
+}
+
+// EventsArgs is the argument of Daemon.Events.
+type EventsArgs struct{ Limit int }
+
+// ── Audio ───────────────────────────────────────────────────────────────────
+
+// AudioAPI serves the daemon's audio.Service.
+type AudioAPI struct{ s *Server }
+
+func (a *AudioAPI) ListSinks(_ Empty, reply *[]audio.ShortRecord) error {
+	return a.list("sinks", reply, a.s.cfg.Audio.ListSinks)
+}
+
+func (a *AudioAPI) ListSources(_ Empty, reply *[]audio.ShortRecord) error {
+	return a.list("sources", reply, a.s.cfg.Audio.ListSources)
+}
+
+func (a *AudioAPI) ListCards(_ Empty, reply *[]audio.ShortRecord) error {
+	return a.list("cards", reply, a.s.cfg.Audio.ListCards)
+}
+
+func (a *AudioAPI) GetDefaults(_ Empty, reply *audio.DefaultsInfo) error {
+	ctx, cancel := a.s.callContext()
+	defer cancel()
+	v, err := cached(a.s.cache, scopeAudio, "defaults", func() (audio.DefaultsInfo, error) {
+		return a.s.cfg.Audio.GetDefaults(ctx)
+	})
+	*reply = v
+	return err
+}
+
+func (a *AudioAPI) ListSinkInputs(_ Empty, reply *[]audio.SinkInput) error {
+	ctx, cancel := a.s.callContext()
+	defer cancel()
+	v, err := cached(a.s.cache, scopeAudio, "sink-inputs", func() ([]audio.SinkInput, error) {
+		return a.s.cfg.Audio.ListSinkInputs(ctx)
+	})
+	*reply = nonNil(encodeSinkInputs(v))
+	return err
+}
+
+func (a *AudioAPI) ListSourceOutputs(_ Empty, reply *[]audio.SourceOutput) error {
+	ctx, cancel := a.s.callContext()
+	defer cancel()
+	v, err := cached(a.s.cache, scopeAudio, "source-outputs", func() ([]audio.SourceOutput, error) {
+		return a.s.cfg.Audio.ListSourceOutputs(ctx)
+	})
+	*reply = nonNil(v)
+	return err
+}
+
+func (a *AudioAPI) ListCardsDetailed(_ Empty, reply *[]audio.Card) error {
+	ctx, cancel := a.s.callContext()
+	defer cancel()
+	v, err := cached(a.s.cache, scopeAudio, "cards-detailed", func() ([]audio.Card, error) {
+		return a.s.cfg.Audio.ListCardsDetailed(ctx)
+	})
+	*reply = nonNil(v)
+	return err
+}
+
+func (a *AudioAPI) ListSinksDetailed(_ Empty, reply *[]audio.Device) error {
+	return a.listDetailed("sinks-detailed", reply, a.s.cfg.Audio.ListSinksDetailed)
+}
+
+func (a *AudioAPI) ListSourcesDetailed(_ Empty, reply *[]audio.Device) error {
+	return a.listDetailed("sources-detailed", reply, a.s.cfg.Audio.ListSourcesDetailed)
+}
+
+func (a *AudioAPI) SetDefaultSink(args NameArgs, _ *Empty) error {
+	return a.mutate(func(ctx context.Context) error { return a.s.cfg.Audio.SetDefaultSink(ctx, args.Name) })
+}
+
+func (a *AudioAPI) SetDefaultSource(args NameArgs, _ *Empty) error {
+	return a.mutate(func(ctx context.Context) error { return a.s.cfg.Audio.SetDefaultSource(ctx, args.Name) })
+}
+
+func (a *AudioAPI) MoveSinkInput(args MoveArgs, _ *Empty) error {
+	return a.mutate(func(ctx context.Context) error { return a.s.cfg.Audio.MoveSinkInput(ctx, args.StreamID, args.Sink) })
+}
+
+func (a *AudioAPI) SetSinkInputVolume(args StreamArgs, _ *Empty) error {
+	return a.mutate(func(ctx context.Context) error {
+		return a.s.cfg.Audio.SetSinkInputVolume(ctx, args.StreamID, args.Percent)
+	})
+}
+
+func (a *AudioAPI) SetSinkInputMute(args StreamArgs, _ *Empty) error {
+	return a.mutate(func(ctx context.Context) error { return a.s.cfg.Audio.SetSinkInputMute(ctx, args.StreamID, args.Muted) })
+}
+
+func (a *AudioAPI) MoveSourceOutput(args MoveArgs, _ *Empty) error {
+	return a.mutate(func(ctx context.Context) error {
+		return a.s.cfg.Audio.MoveSourceOutput(ctx, args.StreamID, args.Source)
+	})
+}
+
+func (a *AudioAPI) SetCardProfile(args CardProfileArgs, _ *Empty) error {
+	return a.mutate(func(ctx context.Context) error { return a.s.cfg.Audio.SetCardProfile(ctx, args.Card, args.Profile) })
+}
+
+func (a *AudioAPI) SwitchCardCodec(args CardCodecArgs, _ *Empty) error {
+	sw, ok := a.s.cfg.Audio.(audio.CodecSwitcher)
+	if !ok {
+		return errors.New("the daemon's audio backend cannot switch codecs in place")
+	}
+	return a.mutate(func(ctx context.Context) error { return sw.SwitchCardCodec(ctx, args.Card, args.Codec) })
+}
+
+func (a *AudioAPI) SetVolume(args VolumeArgs, _ *Empty) error {
+	return a.mutate(func(ctx context.Context) error {
+		return a.s.cfg.Audio.SetVolume(ctx, args.Target, args.Name, args.Percent)
+	})
+}
+
+func (a *AudioAPI) SetChannelVolumes(args ChannelVolumesArgs, _ *Empty) error {
+	return a.mutate(func(ctx context.Context) error {
+		return a.s.cfg.Audio.SetChannelVolumes(ctx, args.Target, args.Name, args.Volumes)
+	})
+}
+
+func (a *AudioAPI) ToggleMute(args MuteArgs, _ *Empty) error {
+	return a.mutate(func(ctx context.Context) error { return a.s.cfg.Audio.ToggleMute(ctx, args.Target, args.Name) })
+}
+
+func (a *AudioAPI) SetMute(args MuteArgs, _ *Empty) error {
+	return a.mutate(func(ctx context.Context) error {
+		return a.s.cfg.Audio.SetMute(ctx, args.Target, args.Name, args.Muted)
+	})
+}
+
+func (a *AudioAPI) list(key string, reply *[]audio.ShortRecord, fn func(context.Context) ([]audio.ShortRecord, error)) error {
+	ctx, cancel := a.s.callContext()
+	defer cancel()
+	v, err := cached(a.s.cache, scopeAudio, key, func() ([]audio.ShortRecord, error) { return fn(ctx) })
+	*reply = nonNil(v)
+	return err
+}
+
+func (a *AudioAPI) listDetailed(key string, reply *[]audio.Device, fn func(context.Context) ([]audio.Device, error)) error {
+	ctx, cancel := a.s.callContext()
+	defer cancel()
+	v, err := cached(a.s.cache, scopeAudio, key, func() ([]audio.Device, error) {
+		devices, err := fn(ctx)
+		return encodeDevices(devices), err
+	})
+	*reply = nonNil(v)
+	return err
+}
+
+// mutate runs a control call and drops the cached audio state, so the
+// caller's next read sees the change even before its event arrives.
+func (a *AudioAPI) mutate(fn func(context.Context) error) error {
+	ctx, cancel := a.s.callContext()
+	defer cancel()
+	defer a.s.cache.invalidate(scopeAudio)
+	return fn(ctx)
+}
+
+// ── Bluetooth ───────────────────────────────────────────────────────────────
+
+// BluetoothAPI serves the daemon's bluetooth.Service.
+type BluetoothAPI struct{ s *Server }
+
+func (b *BluetoothAPI) ListDevices(_ Empty, reply *[]bluetooth.Device) error {
+	ctx, cancel := b.s.callContext()
+	defer cancel()
+	v, err := cached(b.s.cache, scopeBluetooth, "devices", func() ([]bluetooth.Device, error) {
+		return b.s.cfg.Bluetooth.ListDevices(ctx)
+	})
+	*reply = nonNil(v)
+	return err
+}
+
+func (b *BluetoothAPI) ControllerStatus(_ Empty, reply *bluetooth.ControllerStatus) error {
+	ctx, cancel := b.s.callContext()
+	defer cancel()
+	v, err := cached(b.s.cache, scopeBluetooth, "controller", func() (bluetooth.ControllerStatus, error) {
+		return b.s.cfg.Bluetooth.ControllerStatus(ctx)
+	})
+	*reply = v
+	return err
+}
+
+func (b *BluetoothAPI) Info(args AddressArgs, reply *bluetooth.DeviceInfo) error {
+	ctx, cancel := b.s.callContext()
+	defer cancel()
+	v, err := cached(b.s.cache, scopeBluetooth, "info "+args.Address, func() (bluetooth.DeviceInfo, error) {
+		return b.s.cfg.Bluetooth.Info(ctx, args.Address)
+	})
+	*reply = v
+	return err
+}
+
+func (b *BluetoothAPI) Discover(args DiscoverArgs, reply *[]bluetooth.DiscoveredDevice) error {
+	ctx, cancel := b.s.callContext()
+	defer cancel()
+	defer b.s.cache.invalidate(scopeBluetooth)
+	v, err := b.s.cfg.Bluetooth.Discover(ctx, args.Seconds)
+	*reply = nonNil(v)
+	return err
+}
+
+func (b *BluetoothAPI) Connect(args AddressArgs, _ *Empty) error {
+	return b.mutate(func(ctx context.Context) error { return b.s.cfg.Bluetooth.Connect(ctx, args.Address) })
+}
+
+func (b *BluetoothAPI) Disconnect(args AddressArgs, _ *Empty) error {
+	return b.mutate(func(ctx context.Context) error { return b.s.cfg.Bluetooth.Disconnect(ctx, args.Address) })
+}
+
+func (b *BluetoothAPI) Trust(args AddressArgs, _ *Empty) error {
+	return b.mutate(func(ctx context.Context) error { return b.s.cfg.Bluetooth.Trust(ctx, args.Address) })
+}
+
+func (b *BluetoothAPI) Remove(args AddressArgs, _ *Empty) error {
+	return b.mutate(func(ctx context.Context) error { return b.s.cfg.Bluetooth.Remove(ctx, args.Address) })
+}
+
+func (b *BluetoothAPI) Pair(args AddressArgs, _ *Empty) error {
+	return b.mutate(func(ctx context.Context) error { return b.s.cfg.Bluetooth.Pair(ctx, args.Address) })
+}
+
+func (b *BluetoothAPI) StartScan(_ Empty, _ *Empty) error {
+	return b.mutate(b.s.cfg.Bluetooth.StartScan)
+}
+
+func (b *BluetoothAPI) StopScan(_ Empty, _ *Empty) error {
+	return b.mutate(b.s.cfg.Bluetooth.StopScan)
+}
+
+func (b *BluetoothAPI) mutate(fn func(context.Context) error) error {
+	ctx, cancel := b.s.callContext()
+	defer cancel()
+	defer b.s.cache.invalidate(scopeBluetooth)
+	return fn(ctx)
+}
+
+// ── Daemon ──────────────────────────────────────────────────────────────────
+
+// DaemonAPI reports on the daemon itself.
+type DaemonAPI struct{ s *Server }
+
+func (d *DaemonAPI) Status(_ Empty, reply *Status) error {
+	*reply = d.s.status()
+	return nil
+}
+
+func (d *DaemonAPI) Events(args EventsArgs, reply *[]Event) error {
+	*reply = nonNil(d.s.events(args.Limit))
+	return nil
+}
+
+// ── Wire encoding ───────────────────────────────────────────────────────────
+
+// nonNil turns a nil slice into an empty one: net/rpc/jsonrpc clients
+// reject a null result as an invalid error.
+func nonNil[T any](v []T) []T {
+	if v == nil {
+		return []T{}
+	}
+	return v
+}
+
+// silenceDB stands in for -Inf dB (volume 0), which JSON cannot carry.
+const silenceDB = -math.MaxFloat64
+
+func encodeDB(db float64) float64 {
+	if math.IsInf(db, -1) {
+		return silenceDB
+	}
+	return db
+}
+
+func decodeDB(db float64) float64 {
+	if db == silenceDB {
+		return math.Inf(-1)
+	}
+	return db
+}
+
+// encodeDevices returns a copy of devices with infinite dB values replaced
+// by silenceDB.
+func encodeDevices(devices []audio.Device) []audio.Device {
+	return mapDeviceDB(devices, encodeDB)
+}
+
+// decodeDevices undoes encodeDevices.
+func decodeDevices(devices []audio.Device) []audio.Device {
+	return mapDeviceDB(devices, decodeDB)
+}
+
+// encodeSinkInputs is encodeDevices for stream volumes.
+func encodeSinkInputs(inputs []audio.SinkInput) []audio.SinkInput {
+	return mapSinkInputDB(inputs, encodeDB)
+}
+
+// decodeSinkInputs undoes encodeSinkInputs.
+func decodeSinkInputs(inputs []audio.SinkInput) []audio.SinkInput {
+	return mapSinkInputDB(inputs, decodeDB)
+}
+
+func mapDeviceDB(devices []audio.Device, fn func(float64) float64) []audio.Device {
+	if devices == nil {
+		return nil
+	}
+	out := make([]audio.Device, len(devices))
+	for i, d := range devices {
+		d.Volume = mapVolumeDB(d.Volume, fn)
+		d.BaseVolume.DB = fn(d.BaseVolume.DB)
+		out[i] = d
+	}
+	return out
+}
+
+func mapSinkInputDB(inputs []audio.SinkInput, fn func(float64) float64) []audio.SinkInput {
+	if inputs == nil {
+		return nil
+	}
+	out := make([]audio.SinkInput, len(inputs))
+	for i, in := range inputs {
+		in.Volume = mapVolumeDB(in.Volume, fn)
+		out[i] = in
+	}
+	return out
+}
+
+func mapVolumeDB(volumes []audio.ChannelVolume, fn func(float64) float64) []audio.ChannelVolume {
+	if volumes == nil {
+		return nil
+	}
+	out := append([]audio.ChannelVolume(nil), volumes...)
+	for i := range out {
+		out[i].DB = fn(out[i].DB)
+	}
+	return out
+}
@@ -0,0 +1,305 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"sync"
+
+	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/bluetooth"
+)
+
+// Client is a connection to a running daemon. It redials when the
+// connection fails, e.g. because the daemon has restarted since the last
+// call.
+type Client struct {
+	path string
+
+	mu  sync.Mutex
+	rpc *rpc.Client
+}
+
+// Dial connects to the daemon socket at path ("" for DefaultSocketPath).
+func Dial(ctx context.Context, path string) (*Client, error) {
+	if path == "" {
+		path = DefaultSocketPath()
+	}
+	c := &Client{path: path}
+	if _, err := c.conn(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rpc == nil {
+		return nil
+	}
+	err := c.rpc.Close()
+	c.rpc = nil
+	return err
+}
+
+func (c *Client) conn(ctx context.Context) (*rpc.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rpc != nil {
+		return c.rpc, nil
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", c.path)
+	if err != nil {
+		return nil, fmt.Errorf("daemon: dial %s: %w", c.path, err)
+	}
+	c.rpc = jsonrpc.NewClient(conn)
+	return c.rpc, nil
+}
+
+func (c *Client) drop(stale *rpc.Client) {
+	c.mu.Lock()
+	if c.rpc == stale {
+		c.rpc.Close()
+		c.rpc = nil
+	}
+	c.mu.Unlock()
+}
+
+// call invokes method and waits for the reply or ctx. Any error that is
+// not the server's own drops the connection, so the next call redials. A
+// call that was never written, because the connection was already shut
+// down or the write failed, as it does on a daemon that has restarted, is
+// retried once on a fresh connection.
+func (c *Client) call(ctx context.Context, method string, args, reply any) error {
+	for attempt := 0; ; attempt++ {
+		client, err := c.conn(ctx)
+		if err != nil {
+			return err
+		}
+		call := client.Go(method, args, reply, make(chan *rpc.Call, 1))
+		select {
+		case <-call.Done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if call.Error == nil {
+			return nil
+		}
+		var se rpc.ServerError
+		if errors.As(call.Error, &se) {
+			return errors.New(string(se))
+		}
+		c.drop(client)
+		if attempt == 0 && notSent(call.Error) {
+			continue
+		}
+		return fmt.Errorf("daemon: %s: %w", method, call.Error)
+	}
+}
+
+// notSent reports whether a call failed before its request reached the
+// connection, so that retrying it cannot run it twice.
+func notSent(err error) bool {
+	var op *net.OpError
+	return errors.Is(err, rpc.ErrShutdown) || (errors.As(err, &op) && op.Op == "write")
+}
+
+// Status reports on the daemon.
+func (c *Client) Status(ctx context.Context) (Status, error) {
+	var st Status
+	err := c.call(ctx, "Daemon.Status", Empty{}, &st)
+	return st, err
+}
+
+// Events returns up to limit of the most recent events (all when limit <= 0).
+func (c *Client) Events(ctx context.Context, limit int) ([]Event, error) {
+	var evs []Event
+	err := c.call(ctx, "Daemon.Events", EventsArgs{Limit: limit}, &evs)
+	return evs, err
+}
+
+// Audio returns an audio.Service backed by the daemon.
+func (c *Client) Audio() *AudioClient {
+	return &AudioClient{c: c}
+}
+
+// Bluetooth returns a bluetooth.Service backed by the daemon.
+func (c *Client) Bluetooth() *BluetoothClient {
+	return &BluetoothClient{c: c}
+}
+
+// ── Audio ───────────────────────────────────────────────────────────────────
+
+// AudioClient implements audio.Service over the daemon socket.
+type AudioClient struct{ c *Client }
+
+var _ audio.Service = (*AudioClient)(nil)
+
+func (a *AudioClient) ListSinks(ctx context.Context) ([]audio.ShortRecord, error) {
+	var out []audio.ShortRecord
+	err := a.c.call(ctx, "Audio.ListSinks", Empty{}, &out)
+	return out, err
+}
+
+func (a *AudioClient) ListSources(ctx context.Context) ([]audio.ShortRecord, error) {
+	var out []audio.ShortRecord
+	err := a.c.call(ctx, "Audio.ListSources", Empty{}, &out)
+	return out, err
+}
+
+func (a *AudioClient) ListCards(ctx context.Context) ([]audio.ShortRecord, error) {
+	var out []audio.ShortRecord
+	err := a.c.call(ctx, "Audio.ListCards", Empty{}, &out)
+	return out, err
+}
+
+func (a *AudioClient) GetDefaults(ctx context.Context) (audio.DefaultsInfo, error) {
+	var out audio.DefaultsInfo
+	err := a.c.call(ctx, "Audio.GetDefaults", Empty{}, &out)
+	return out, err
+}
+
+func (a *AudioClient) ListSinkInputs(ctx context.Context) ([]audio.SinkInput, error) {
+	var out []audio.SinkInput
+	err := a.c.call(ctx, "Audio.ListSinkInputs", Empty{}, &out)
+	return decodeSinkInputs(out), err
+}
+
+func (a *AudioClient) ListSourceOutputs(ctx context.Context) ([]audio.SourceOutput, error) {
+	var out []audio.SourceOutput
+	err := a.c.call(ctx, "Audio.ListSourceOutputs", Empty{}, &out)
+	return out, err
+}
+
+func (a *AudioClient) ListCardsDetailed(ctx context.Context) ([]audio.Card, error) {
+	var out []audio.Card
+	err := a.c.call(ctx, "Audio.ListCardsDetailed", Empty{}, &out)
+	return out, err
+}
+
+func (a *AudioClient) ListSinksDetailed(ctx context.Context) ([]audio.Device, error) {
+	var out []audio.Device
+	err := a.c.call(ctx, "Audio.ListSinksDetailed", Empty{}, &out)
+	return decodeDevices(out), err
+}
+
+func (a *AudioClient) ListSourcesDetailed(ctx context.Context) ([]audio.Device, error) {
+	var out []audio.Device
+	err := a.c.call(ctx, "Audio.ListSourcesDetailed", Empty{}, &out)
+	return decodeDevices(out), err
+}
+
+func (a *AudioClient) SetDefaultSink(ctx context.Context, sink string) error {
+	return a.c.call(ctx, "Audio.SetDefaultSink", NameArgs{Name: sink}, &Empty{})
+}
+
+func (a *AudioClient) SetDefaultSource(ctx context.Context, source string) error {
+	return a.c.call(ctx, "Audio.SetDefaultSource", NameArgs{Name: source}, &Empty{})
+}
+
+func (a *AudioClient) MoveSinkInput(ctx context.Context, streamID int, sink string) error {
+	return a.c.call(ctx, "Audio.MoveSinkInput", MoveArgs{StreamID: streamID, Sink: sink}, &Empty{})
+}
+
+func (a *AudioClient) SetSinkInputVolume(ctx context.Context, streamID int, percent int) error {
+	return a.c.call(ctx, "Audio.SetSinkInputVolume", StreamArgs{StreamID: streamID, Percent: percent}, &Empty{})
+}
+
+func (a *AudioClient) SetSinkInputMute(ctx context.Context, streamID int, muted bool) error {
+	return a.c.call(ctx, "Audio.SetSinkInputMute", StreamArgs{StreamID: streamID, Muted: muted}, &Empty{})
+}
+
+func (a *AudioClient) MoveSourceOutput(ctx context.Context, streamID int, source string) error {
+	return a.c.call(ctx, "Audio.MoveSourceOutput", MoveArgs{StreamID: streamID, Source: source}, &Empty{})
+}
+
+func (a *AudioClient) SetCardProfile(ctx context.Context, card string, profile string) error {
+	return a.c.call(ctx, "Audio.SetCardProfile", CardProfileArgs{Card: card, Profile: profile}, &Empty{})
+}
+
+// SwitchCardCodec makes AudioClient an audio.CodecSwitcher; the daemon
+// refuses when its own backend is not one.
+func (a *AudioClient) SwitchCardCodec(ctx context.Context, card string, codec string) error {
+	return a.c.call(ctx, "Audio.SwitchCardCodec", CardCodecArgs{Card: card, Codec: codec}, &Empty{})
+}
+
+func (a *AudioClient) SetVolume(ctx context.Context, target string, name string, percent int) error {
+	return a.c.call(ctx, "Audio.SetVolume", VolumeArgs{Target: target, Name: name, Percent: percent}, &Empty{})
+}
+
+func (a *AudioClient) SetChannelVolumes(ctx context.Context, target string, name string, volumes []uint32) error {
+	return a.c.call(ctx, "Audio.SetChannelVolumes", ChannelVolumesArgs{Target: target, Name: name, Volumes: volumes}, &Empty{})
+}
+
+func (a *AudioClient) ToggleMute(ctx context.Context, target string, name string) error {
+	return a.c.call(ctx, "Audio.ToggleMute", MuteArgs{Target: target, Name: name}, &Empty{})
+}
+
+func (a *AudioClient) SetMute(ctx context.Context, target string, name string, muted bool) error {
+	return a.c.call(ctx, "Audio.SetMute", MuteArgs{Target: target, Name: name, Muted: muted}, &Empty{})
+}
+
+// ── Bluetooth ───────────────────────────────────────────────────────────────
+
+// BluetoothClient implements bluetooth.Service over the daemon socket.
+type BluetoothClient struct{ c *Client }
+
+var _ bluetooth.Service = (*BluetoothClient)(nil)
+
+func (b *BluetoothClient) ListDevices(ctx context.Context) ([]bluetooth.Device, error) {
+	var out []bluetooth.Device
+	err := b.c.call(ctx, "Bluetooth.ListDevices", Empty{}, &out)
+	return out, err
+}
+
+func (b *BluetoothClient) ControllerStatus(ctx context.Context) (bluetooth.ControllerStatus, error) {
+	var out bluetooth.ControllerStatus
+	err := b.c.call(ctx, "Bluetooth.ControllerStatus", Empty{}, &out)
+	return out, err
+}
+
+func (b *BluetoothClient) Info(ctx context.Context, address string) (bluetooth.DeviceInfo, error) {
+	var out bluetooth.DeviceInfo
+	err := b.c.call(ctx, "Bluetooth.Info", AddressArgs{Address: address}, &out)
+	return out, err
+}
+
+func (b *BluetoothClient) Discover(ctx context.Context, seconds int) ([]bluetooth.DiscoveredDevice, error) {
+	var out []bluetooth.DiscoveredDevice
+	err := b.c.call(ctx, "Bluetooth.Discover", DiscoverArgs{Seconds: seconds}, &out)
+	return out, err
+}
+
+func (b *BluetoothClient) Connect(ctx context.Context, address string) error {
+	return b.c.call(ctx, "Bluetooth.Connect", AddressArgs{Address: address}, &Empty{})
+}
+
+func (b *BluetoothClient) Disconnect(ctx context.Context, address string) error {
+	return b.c.call(ctx, "Bluetooth.Disconnect", AddressArgs{Address: address}, &Empty{})
+}
+
+func (b *BluetoothClient) Trust(ctx context.Context, address string) error {
+	return b.c.call(ctx, "Bluetooth.Trust", AddressArgs{Address: address}, &Empty{})
+}
+
+func (b *BluetoothClient) Remove(ctx context.Context, address string) error {
+	return b.c.call(ctx, "Bluetooth.Remove", AddressArgs{Address: address}, &Empty{})
+}
+
+func (b *BluetoothClient) Pair(ctx context.Context, address string) error {
+	return b.c.call(ctx, "Bluetooth.Pair", AddressArgs{Address: address}, &Empty{})
+}
+
+func (b *BluetoothClient) StartScan(ctx context.Context) error {
+	return b.c.call(ctx, "Bluetooth.StartScan", Empty{}, &Empty{})
+}
+
+func (b *BluetoothClient) StopScan(ctx context.Context) error {
+	return b.c.call(ctx, "Bluetooth.StopScan", Empty{}, &Empty{})
+}
@@ -0,0 +1,443 @@
+// Package daemon runs soundctl as a long-lived process that owns the audio
+// and bluetooth services and the event subscriptions, and serves them as
+// JSON-RPC (net/rpc/jsonrpc) over a Unix socket. Client implements
+// audio.Service and bluetooth.Service on top of that socket so the CLI and
+// TUI can use a running daemon transparently. Presets are not served:
+// commands read the locked preset file directly.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/bluetooth"
+	"soundctl/pkg/soundctl/events"
+	sexec "soundctl/pkg/soundctl/exec"
+	"soundctl/pkg/soundctl/rules"
+	"soundctl/pkg/soundctl/triggers"
+)
+
+// DefaultHistorySize is the number of events kept when Config.HistorySize
+// is zero.
+const DefaultHistorySize = 512
+
+// tickInterval is how often schedule triggers are checked; well under a
+// minute, their resolution.
+const tickInterval = 15 * time.Second
+
+// triggerQueueSize bounds the events waiting for the trigger engine.
+const triggerQueueSize = 64
+
+// callTimeout bounds a single RPC on the server side. It is generous so
+// that bluetooth discovery and pairing fit.
+const callTimeout = 2 * time.Minute
+
+// DefaultSocketPath returns the control socket path, honouring
+// SOUNDCTL_SOCKET and XDG_RUNTIME_DIR.
+func DefaultSocketPath() string {
+	if path := os.Getenv("SOUNDCTL_SOCKET"); path != "" {
+		return path
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "soundctl", "daemon.sock")
+	}
+	return filepath.Join("/run/user", fmt.Sprint(os.Getuid()), "soundctl", "daemon.sock")
+}
+
+// Event is one entry of the daemon's event history: an audio or bluetooth
+// notification, an action taken by the rules engine, or a trigger firing.
+type Event struct {
+	Time     time.Time
+	Source   string // "audio", "bluetooth", "rules" or "triggers"
+	Kind     string // for triggers, the trigger's kind
+	Facility string
+	Index    int    // audio object index, -1 when not applicable
+	Address  string // bluetooth address, if any
+	Target   string // rules action target, or "trigger → preset"
+	Error    string
+}
+
+// Status describes a running daemon.
+type Status struct {
+	PID           int
+	Socket        string
+	Started       time.Time
+	Subscriptions bool
+	Rules         bool
+	Triggers      bool
+	EventsSeen    int
+}
+
+// Config is what the daemon owns.
+type Config struct {
+	Audio     audio.Service
+	Bluetooth bluetooth.Service
+	// Streamer runs the event subscriptions. When nil the daemon does not
+	// subscribe, and does not cache, since it could not invalidate.
+	Streamer sexec.Streamer
+	// Rules, if set, handles every event.
+	Rules *rules.Engine
+	// Triggers, if set, handles every event and is ticked for its
+	// schedules; its firings are appended to TriggerLog, if set.
+	Triggers    *triggers.Engine
+	TriggerLog  *triggers.Log
+	HistorySize int
+	// OnEvent is called for every event recorded, e.g. for logging.
+	OnEvent func(Event)
+}
+
+// Server serves a Config over net/rpc.
+type Server struct {
+	cfg      Config
+	cache    *cache
+	triggerQ chan func(context.Context) []triggers.Fired
+
+	mu      sync.Mutex
+	ctx     context.Context
+	socket  string
+	started time.Time
+	history []Event
+	seen    int
+	conns   map[net.Conn]struct{}
+}
+
+// NewServer returns a server for cfg; call Serve to run it.
+func NewServer(cfg Config) *Server {
+	if cfg.HistorySize <= 0 {
+		cfg.HistorySize = DefaultHistorySize
+	}
+	return &Server{
+		cfg:      cfg,
+		cache:    newCache(),
+		triggerQ: make(chan func(context.Context) []triggers.Fired, triggerQueueSize),
+		ctx:      context.Background(),
+		conns:    map[net.Conn]struct{}{},
+	}
+}
+
+// Listen creates the control socket at path, refusing if another daemon
+// already answers there and removing a stale socket file otherwise.
+func Listen(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("daemon: create socket dir: %w", err)
+	}
+	if conn, err := net.DialTimeout("unix", path, 200*time.Millisecond); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("daemon: already running on %s", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("daemon: remove stale socket: %w", err)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("daemon: listen %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("daemon: chmod socket: %w", err)
+	}
+	return ln, nil
+}
+
+// Serve accepts connections on ln until ctx is cancelled, then closes ln
+// and every open connection. Event subscriptions run for the same span.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	srv := rpc.NewServer()
+	for name, rcvr := range map[string]any{
+		"Audio":     &AudioAPI{s: s},
+		"Bluetooth": &BluetoothAPI{s: s},
+		"Daemon":    &DaemonAPI{s: s},
+	} {
+		if err := srv.RegisterName(name, rcvr); err != nil {
+			return fmt.Errorf("daemon: register %s: %w", name, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.mu.Lock()
+	s.ctx = ctx
+	s.socket = ln.Addr().String()
+	s.started = time.Now()
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	if s.cfg.Streamer != nil {
+		s.cache.enable()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runEvents(ctx)
+		}()
+	}
+	if s.cfg.Triggers != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runTriggers(ctx)
+		}()
+	}
+	// Connections are closed before Serve returns, so that clients of a
+	// stopped daemon fail their next write instead of queueing requests
+	// nothing will answer.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		<-ctx.Done()
+		ln.Close()
+		s.mu.Lock()
+		for conn := range s.conns {
+			conn.Close()
+		}
+		s.mu.Unlock()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			cancel()
+			<-closed
+			wg.Wait()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("daemon: accept: %w", err)
+		}
+		s.mu.Lock()
+		if ctx.Err() != nil { // accepted as the daemon stopped
+			s.mu.Unlock()
+			conn.Close()
+			continue
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		go func() {
+			srv.ServeCodec(jsonrpc.NewServerCodec(conn))
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// callContext bounds one RPC and ends it when the daemon shuts down.
+func (s *Server) callContext() (context.Context, context.CancelFunc) {
+	s.mu.Lock()
+	ctx := s.ctx
+	s.mu.Unlock()
+	return context.WithTimeout(ctx, callTimeout)
+}
+
+// ── Events ──────────────────────────────────────────────────────────────────
+
+// runEvents consumes both subscriptions, invalidating the cache, recording
+// history and feeding the rules and trigger engines.
+func (s *Server) runEvents(ctx context.Context) {
+	audioCh := events.SubscribeAudio(ctx, s.cfg.Streamer, events.Options{Notifier: audio.Notifier(s.cfg.Audio)}).Events()
+	btCh := events.SubscribeBluetooth(ctx, s.cfg.Streamer, events.Options{}).Events()
+	for audioCh != nil || btCh != nil {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-audioCh:
+			if !ok {
+				audioCh = nil
+				continue
+			}
+			s.cache.invalidate(scopeAudio)
+			s.record(Event{Time: ev.Time, Source: "audio", Kind: ev.Kind, Facility: ev.Facility, Index: ev.Index})
+			if s.cfg.Rules != nil {
+				s.recordActions(s.cfg.Rules.HandleAudio(ctx, ev))
+			}
+			if s.cfg.Triggers != nil {
+				s.queueTriggers(func(ctx context.Context) []triggers.Fired { return s.cfg.Triggers.HandleAudio(ctx, ev) })
+			}
+		case ev, ok := <-btCh:
+			if !ok {
+				btCh = nil
+				continue
+			}
+			// Connecting a headset changes both sides.
+			s.cache.invalidate(scopeBluetooth)
+			s.cache.invalidate(scopeAudio)
+			s.record(Event{Time: ev.Time, Source: "bluetooth", Kind: ev.Kind, Facility: ev.Facility(), Index: -1, Address: ev.Address})
+			if s.cfg.Rules != nil {
+				s.recordActions(s.cfg.Rules.HandleBluetooth(ctx, ev))
+			}
+			if s.cfg.Triggers != nil {
+				s.queueTriggers(func(ctx context.Context) []triggers.Fired { return s.cfg.Triggers.HandleBluetooth(ctx, ev) })
+			}
+		}
+	}
+}
+
+func (s *Server) recordActions(actions []rules.Action) {
+	for _, a := range actions {
+		ev := Event{Time: a.Time, Source: "rules", Kind: a.Operation, Index: -1, Address: a.Address, Target: a.Target}
+		if a.Err != nil {
+			ev.Error = a.Err.Error()
+		}
+		s.record(ev)
+	}
+}
+
+// runTriggers ticks the trigger engine and hands it the events runEvents
+// queued until the daemon shuts down. It has a goroutine of its own since
+// applying a preset waits for the audio server to settle, which must not
+// hold up the event loop.
+func (s *Server) runTriggers(ctx context.Context) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	s.recordFired(s.cfg.Triggers.Tick(ctx))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.recordFired(s.cfg.Triggers.Tick(ctx))
+		case handle := <-s.triggerQ:
+			s.recordFired(handle(ctx))
+		}
+	}
+}
+
+// queueTriggers passes an event handler to runTriggers without waiting. If
+// the trigger engine is that far behind, the event is dropped and recorded
+// as such.
+func (s *Server) queueTriggers(handle func(context.Context) []triggers.Fired) {
+	select {
+	case s.triggerQ <- handle:
+	default:
+		s.record(Event{Time: time.Now(), Source: "triggers", Kind: "dropped", Index: -1, Error: "trigger queue full, event not handled"})
+	}
+}
+
+func (s *Server) recordFired(fired []triggers.Fired) {
+	if len(fired) == 0 {
+		return
+	}
+	for _, f := range fired {
+		// Firings without a trigger are events that could not be evaluated.
+		ev := Event{Time: f.Time, Source: "triggers", Kind: f.Kind, Index: -1, Target: f.Event}
+		if f.Trigger != "" {
+			ev.Target = f.Trigger + " → " + f.Preset
+		}
+		if f.Err != nil {
+			ev.Error = f.Err.Error()
+		}
+		s.record(ev)
+	}
+	if s.cfg.TriggerLog != nil {
+		if err := s.cfg.TriggerLog.Append(fired...); err != nil {
+			s.record(Event{Time: time.Now(), Source: "triggers", Kind: "log", Index: -1, Error: err.Error()})
+		}
+	}
+}
+
+func (s *Server) record(ev Event) {
+	s.mu.Lock()
+	s.seen++
+	s.history = append(s.history, ev)
+	if over := len(s.history) - s.cfg.HistorySize; over > 0 {
+		s.history = append(s.history[:0], s.history[over:]...)
+	}
+	s.mu.Unlock()
+	if s.cfg.OnEvent != nil {
+		s.cfg.OnEvent(ev)
+	}
+}
+
+// events returns up to limit of the most recent events, oldest first; all
+// of them when limit <= 0.
+func (s *Server) events(limit int) []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := s.history
+	if limit > 0 && len(h) > limit {
+		h = h[len(h)-limit:]
+	}
+	return append([]Event(nil), h...)
+}
+
+func (s *Server) status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Status{
+		PID:           os.Getpid(),
+		Socket:        s.socket,
+		Started:       s.started,
+		Subscriptions: s.cfg.Streamer != nil,
+		Rules:         s.cfg.Rules != nil,
+		Triggers:      s.cfg.Triggers != nil,
+		EventsSeen:    s.seen,
+	}
+}
+
+// ── Cache ───────────────────────────────────────────────────────────────────
+
+const (
+	scopeAudio     = "audio"
+	scopeBluetooth = "bluetooth"
+)
+
+// cache memoises list results per scope until an event or a mutation in
+// that scope invalidates them. A generation counter per scope keeps a load
+// that raced with an invalidation from storing stale data.
+type cache struct {
+	mu         sync.Mutex
+	enabled    bool
+	entries    map[string]map[string]any
+	generation map[string]int
+}
+
+func newCache() *cache {
+	return &cache{entries: map[string]map[string]any{}, generation: map[string]int{}}
+}
+
+func (c *cache) enable() {
+	c.mu.Lock()
+	c.enabled = true
+	c.mu.Unlock()
+}
+
+func (c *cache) invalidate(scope string) {
+	c.mu.Lock()
+	delete(c.entries, scope)
+	c.generation[scope]++
+	c.mu.Unlock()
+}
+
+// cached returns the cached value for scope/key, calling load on a miss.
+func cached[T any](c *cache, scope, key string, load func() (T, error)) (T, error) {
+	c.mu.Lock()
+	if v, ok := c.entries[scope][key]; ok && c.enabled {
+		c.mu.Unlock()
+		return v.(T), nil
+	}
+	gen := c.generation[scope]
+	enabled := c.enabled
+	c.mu.Unlock()
+
+	v, err := load()
+	if err != nil || !enabled {
+		return v, err
+	}
+	c.mu.Lock()
+	if c.generation[scope] == gen {
+		if c.entries[scope] == nil {
+			c.entries[scope] = map[string]any{}
+		}
+		c.entries[scope][key] = v
+	}
+	c.mu.Unlock()
+	return v, nil
+}
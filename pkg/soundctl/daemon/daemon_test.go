@@ -0,0 +1,273 @@
+package daemon
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/bluetooth"
+	sexec "soundctl/pkg/soundctl/exec"
+	"soundctl/pkg/soundctl/pulse"
+	"soundctl/pkg/soundctl/pulse/pulsetest"
+)
+
+const (
+	testAdapter = "/org/bluez/hci0"
+	testAddress = "08:FF:44:2B:4C:90"
+	speakerSink = "alsa_output.pci-0000_00_1f.3.analog-stereo"
+	hdmiSink    = "alsa_output.pci-0000_00_1f.3.hdmi-stereo"
+)
+
+type fixture struct {
+	pulse  *pulse.FakeServer
+	bus    *bluetooth.FakeBus
+	client *Client
+}
+
+func startDaemon(t *testing.T, streamer sexec.Streamer) fixture {
+	t.Helper()
+	dir := t.TempDir()
+	srv, au := pulsetest.NewService(t, pulse.FakeState{
+		Info: pulse.ServerInfo{DefaultSink: speakerSink},
+		Sinks: []pulse.DeviceInfo{
+			{Index: 1, Name: speakerSink, ChannelMap: []uint8{1, 2}, Volume: []uint32{0, pulse.VolumeNorm}, MonitorIndex: pulse.InvalidIndex},
+			{Index: 2, Name: hdmiSink, ChannelMap: []uint8{1, 2}, Volume: []uint32{pulse.VolumeNorm, pulse.VolumeNorm}, MonitorIndex: pulse.InvalidIndex},
+		},
+		SinkInputs: []pulse.SinkInputInfo{{
+			Index: 112, Sink: 1, ChannelMap: []uint8{1, 2}, Volume: []uint32{0, 0},
+			Properties: pulse.PropList{"application.name": "Firefox"},
+		}},
+	})
+
+	bus := bluetooth.NewFakeBus()
+	bus.AddAdapter(testAdapter, map[string]any{"Address": "00:1A:7D:DA:71:13", "Powered": true})
+	bus.AddDevice(testAdapter, testAddress, map[string]any{"Name": "WH-1000XM4", "Paired": true})
+
+	server := NewServer(Config{Audio: au, Bluetooth: bluetooth.NewDBusService(bus), Streamer: streamer})
+	ln, err := Listen(filepath.Join(dir, "soundctl", "daemon.sock"))
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, ln) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+
+	client, err := Dial(context.Background(), ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return fixture{pulse: srv, bus: bus, client: client}
+}
+
+func TestAudioClientRoundTrip(t *testing.T) {
+	f := startDaemon(t, nil)
+	ctx := context.Background()
+	au := f.client.Audio()
+
+	sinks, err := au.ListSinksDetailed(ctx)
+	if err != nil {
+		t.Fatalf("ListSinksDetailed: %v", err)
+	}
+	if len(sinks) != 2 || sinks[0].Name != speakerSink {
+		t.Fatalf("unexpected sinks: %+v", sinks)
+	}
+	// A silent channel is -Inf dB, which must survive the JSON transport.
+	if db := sinks[0].Volume[0].DB; !math.IsInf(db, -1) {
+		t.Fatalf("expected -Inf dB for silent channel, got %v", db)
+	}
+
+	inputs, err := au.ListSinkInputs(ctx)
+	if err != nil {
+		t.Fatalf("ListSinkInputs: %v", err)
+	}
+	if len(inputs) != 1 || !math.IsInf(inputs[0].Volume[0].DB, -1) {
+		t.Fatalf("unexpected sink inputs: %+v", inputs)
+	}
+
+	if err := au.SetVolume(ctx, "sink", hdmiSink, 40); err != nil {
+		t.Fatalf("SetVolume: %v", err)
+	}
+	if err := au.SetDefaultSink(ctx, hdmiSink); err != nil {
+		t.Fatalf("SetDefaultSink: %v", err)
+	}
+	if err := au.SetSinkInputVolume(ctx, 112, 25); err != nil {
+		t.Fatalf("SetSinkInputVolume: %v", err)
+	}
+	want := []string{"set-sink-volume " + hdmiSink + " 40% 40%", "set-default-sink " + hdmiSink, "set-sink-input-volume 112 25% 25%"}
+	if got := f.pulse.Calls(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected server calls:\n got %v\nwant %v", got, want)
+	}
+
+	err = au.SetDefaultSink(ctx, "no-such-sink")
+	if err == nil || !strings.Contains(err.Error(), "no such entity") {
+		t.Fatalf("expected server error to propagate, got %v", err)
+	}
+}
+
+func TestAudioClientSwitchesCodec(t *testing.T) {
+	f := startDaemon(t, nil)
+	card := "bluez_card.08_FF_44_2B_4C_90"
+	f.pulse.Update(func(st *pulse.FakeState) {
+		st.Cards = []pulse.CardInfo{{
+			Index: 3, Name: card, Driver: "module-bluez5-device.c",
+			Profiles:      []pulse.CardProfileInfo{{Name: "a2dp_sink", Available: true}},
+			ActiveProfile: "a2dp_sink",
+		}}
+	})
+	if _, err := audio.SetCardCodec(context.Background(), f.client.Audio(), card, "sbc_xq"); err != nil {
+		t.Fatalf("SetCardCodec: %v", err)
+	}
+	want := []string{"send-message /card/" + card + `/bluez switch-codec "sbc_xq"`}
+	if got := f.pulse.Calls(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected server calls:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestBluetoothClientRoundTrip(t *testing.T) {
+	f := startDaemon(t, nil)
+	ctx := context.Background()
+	bt := f.client.Bluetooth()
+
+	devices, err := bt.ListDevices(ctx)
+	if err != nil {
+		t.Fatalf("ListDevices: %v", err)
+	}
+	if len(devices) != 1 || devices[0].Address != testAddress || devices[0].Name != "WH-1000XM4" {
+		t.Fatalf("unexpected devices: %+v", devices)
+	}
+	if err := bt.Connect(ctx, testAddress); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if calls := f.bus.Calls(); len(calls) != 1 || !strings.HasSuffix(calls[0], "org.bluez.Device1.Connect") {
+		t.Fatalf("unexpected bus calls: %v", calls)
+	}
+}
+
+func TestEmptyEventsAreNotNull(t *testing.T) {
+	f := startDaemon(t, nil)
+	// Empty lists must not come back as a null result, which jsonrpc
+	// clients reject.
+	if evs, err := f.client.Events(context.Background(), 0); err != nil || len(evs) != 0 {
+		t.Fatalf("expected no events, got %+v, %v", evs, err)
+	}
+}
+
+func TestCacheInvalidatedByMutation(t *testing.T) {
+	// A subscription that never fires enables the cache.
+	f := startDaemon(t, sexec.NewFakeStreamer())
+	ctx := context.Background()
+	au := f.client.Audio()
+
+	if d, err := au.GetDefaults(ctx); err != nil || d.DefaultSinkName != speakerSink {
+		t.Fatalf("GetDefaults: %+v, %v", d, err)
+	}
+	// Changed behind the daemon's back and without an event: still cached.
+	f.pulse.Update(func(st *pulse.FakeState) { st.Info.DefaultSink = hdmiSink })
+	if d, _ := au.GetDefaults(ctx); d.DefaultSinkName != speakerSink {
+		t.Fatalf("expected cached default, got %q", d.DefaultSinkName)
+	}
+	if err := au.SetVolume(ctx, "sink", speakerSink, 50); err != nil {
+		t.Fatalf("SetVolume: %v", err)
+	}
+	if d, _ := au.GetDefaults(ctx); d.DefaultSinkName != hdmiSink {
+		t.Fatalf("expected fresh default after mutation, got %q", d.DefaultSinkName)
+	}
+}
+
+func TestEventsAreRecorded(t *testing.T) {
+	// The audio service is native, so events come over its subscription
+	// rather than from pactl.
+	f := startDaemon(t, sexec.NewFakeStreamer())
+	ctx := context.Background()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for f.pulse.Subscribers() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("daemon did not subscribe")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	f.pulse.Emit(pulse.SubscribeEvent{Type: pulse.FacilitySink | pulse.EventNew, Index: 5})
+	f.pulse.Emit(pulse.SubscribeEvent{Type: pulse.FacilityServer | pulse.EventChange, Index: pulse.InvalidIndex})
+	for {
+		st, err := f.client.Status(ctx)
+		if err != nil {
+			t.Fatalf("Status: %v", err)
+		}
+		if st.EventsSeen == 2 {
+			if !st.Subscriptions || st.Rules {
+				t.Fatalf("unexpected status: %+v", st)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("events not recorded, status %+v", st)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	evs, err := f.client.Events(ctx, 1)
+	if err != nil {
+		t.Fatalf("Events: %v", err)
+	}
+	if len(evs) != 1 || evs[0].Source != "audio" || evs[0].Kind != "change" || evs[0].Facility != "server" {
+		t.Fatalf("unexpected last event: %+v", evs)
+	}
+	if all, _ := f.client.Events(ctx, 0); len(all) != 2 || all[0].Index != 5 {
+		t.Fatalf("unexpected history: %+v", all)
+	}
+}
+
+func TestListenRefusesRunningDaemon(t *testing.T) {
+	f := startDaemon(t, nil)
+	if _, err := Listen(f.client.path); err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Fatalf("expected already running error, got %v", err)
+	}
+}
+
+func TestClientRedialsAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "daemon.sock")
+	serve := func() context.CancelFunc {
+		ln, err := Listen(path)
+		if err != nil {
+			t.Fatalf("Listen: %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			NewServer(Config{}).Serve(ctx, ln)
+			close(done)
+		}()
+		return func() { cancel(); <-done }
+	}
+
+	stop := serve()
+	client, err := Dial(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer client.Close()
+	if _, err := client.Status(context.Background()); err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	stop()
+
+	stop = serve()
+	defer stop()
+	if _, err := client.Status(context.Background()); err != nil {
+		t.Fatalf("Status after restart: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package events
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	sexec "soundctl/pkg/soundctl/exec"
+)
+
+// AudioNotifier delivers audio change notifications without pactl, as the
+// native audio backend does over a protocol connection. NotifyAudio calls
+// fn for each event until ctx is done or the connection fails, and must not
+// call it once it has returned.
+type AudioNotifier interface {
+	NotifyAudio(ctx context.Context, fn func(AudioEvent)) error
+}
+
+// AudioSubscription streams `pactl subscribe`, or Options.Notifier, as
+// AudioEvents.
+type AudioSubscription struct {
+	cancel context.CancelFunc
+	events chan AudioEvent
+}
+
+// SubscribeAudio starts `pactl subscribe`, or opts.Notifier when set,
+// under supervision. Events stop and the channel closes when ctx is
+// cancelled or Stop is called.
+func SubscribeAudio(ctx context.Context, streamer sexec.Streamer, opts Options) *AudioSubscription {
+	ctx, cancel := context.WithCancel(ctx)
+	opts = opts.withDefaults()
+	sub := &AudioSubscription{cancel: cancel, events: make(chan AudioEvent, 64)}
+	send := func(ev AudioEvent) {
+		ev.Time = opts.Now()
+		select {
+		case sub.events <- ev:
+		case <-ctx.Done():
+		}
+	}
+	go func() {
+		defer close(sub.events)
+		if opts.Notifier != nil {
+			restart(ctx, opts, func(progress func()) error {
+				return opts.Notifier.NotifyAudio(ctx, func(ev AudioEvent) {
+					progress()
+					send(ev)
+				})
+			})
+			return
+		}
+		supervise(ctx, streamer, opts, func(line string) {
+			if ev, ok := ParsePactlSubscribeLine(line); ok {
+				send(ev)
+			}
+		}, "pactl", "subscribe")
+	}()
+	return sub
+}
+
+// Events returns the event channel.
+func (s *AudioSubscription) Events() <-chan AudioEvent {
+	return s.events
+}
+
+// Stop terminates the subscription.
+func (s *AudioSubscription) Stop() {
+	s.cancel()
+}
+
+// ParsePactlSubscribeLine parses lines like "Event 'change' on sink #47".
+func ParsePactlSubscribeLine(line string) (AudioEvent, bool) {
+	line = strings.TrimSpace(line)
+	rest, ok := strings.CutPrefix(line, "Event '")
+	if !ok {
+		return AudioEvent{}, false
+	}
+	kind, rest, ok := strings.Cut(rest, "'")
+	if !ok {
+		return AudioEvent{}, false
+	}
+	rest, ok = strings.CutPrefix(rest, " on ")
+	if !ok {
+		return AudioEvent{}, false
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return AudioEvent{}, false
+	}
+
+	ev := AudioEvent{Kind: kind, Facility: fields[0], Index: -1}
+	if len(fields) > 1 {
+		if idx, err := strconv.Atoi(strings.TrimPrefix(fields[1], "#")); err == nil {
+			ev.Index = idx
+		}
+	}
+	return ev, true
+}
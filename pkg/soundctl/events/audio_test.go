@@ -0,0 +1,79 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sexec "soundctl/pkg/soundctl/exec"
+)
+
+func TestParsePactlSubscribeLine(t *testing.T) {
+	tests := []struct {
+		line   string
+		want   AudioEvent
+		wantOK bool
+	}{
+		{line: "Event 'change' on sink #47", want: AudioEvent{Kind: "change", Facility: "sink", Index: 47}, wantOK: true},
+		{line: "Event 'new' on sink-input #63", want: AudioEvent{Kind: "new", Facility: "sink-input", Index: 63}, wantOK: true},
+		{line: "Event 'remove' on source #2", want: AudioEvent{Kind: "remove", Facility: "source", Index: 2}, wantOK: true},
+		{line: "Event 'change' on card #0", want: AudioEvent{Kind: "change", Facility: "card", Index: 0}, wantOK: true},
+		{line: "Event 'change' on server", want: AudioEvent{Kind: "change", Facility: "server", Index: -1}, wantOK: true},
+		{line: "not an event line"},
+		{line: ""},
+	}
+
+	for _, tt := range tests {
+		ev, ok := ParsePactlSubscribeLine(tt.line)
+		if ok != tt.wantOK || ev != tt.want {
+			t.Errorf("line=%q: got (%+v, %v), want (%+v, %v)", tt.line, ev, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestSubscribeAudioRestartsChild(t *testing.T) {
+	streamer := sexec.NewFakeStreamer()
+	args := []string{"subscribe"}
+	streamer.Add("pactl", args, sexec.StreamResult{Lines: []string{"Event 'new' on sink-input #5"}, Err: errors.New("connection lost")})
+	streamer.Add("pactl", args, sexec.StreamResult{Lines: []string{"Event 'remove' on sink-input #5"}})
+
+	restarts := make(chan error, 4)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	sub := SubscribeAudio(context.Background(), streamer, Options{
+		RestartDelay: time.Millisecond,
+		OnRestart:    func(err error) { restarts <- err },
+		Now:          func() time.Time { return now },
+	})
+	defer sub.Stop()
+
+	for _, want := range []AudioEvent{
+		{Time: now, Kind: "new", Facility: "sink-input", Index: 5},
+		{Time: now, Kind: "remove", Facility: "sink-input", Index: 5},
+	} {
+		select {
+		case ev := <-sub.Events():
+			if ev != want {
+				t.Fatalf("got %+v, want %+v", ev, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %+v", want)
+		}
+	}
+	if err := <-restarts; err == nil || err.Error() != "connection lost" {
+		t.Fatalf("expected first restart to report exit error, got %v", err)
+	}
+}
+
+func TestAudioSubscriptionStopClosesChannel(t *testing.T) {
+	sub := SubscribeAudio(context.Background(), sexec.NewFakeStreamer(), Options{})
+	sub.Stop()
+	select {
+	case _, ok := <-sub.Events():
+		if ok {
+			t.Fatal("expected no events")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("channel not closed after Stop")
+	}
+}
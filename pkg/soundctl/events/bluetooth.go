@@ -0,0 +1,280 @@
+package events
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	sexec "soundctl/pkg/soundctl/exec"
+)
+
+const bluezDeviceInterface = "org.bluez.Device1"
+
+// BluetoothSubscription streams BlueZ signals from `dbus-monitor` as
+// BluetoothEvents.
+type BluetoothSubscription struct {
+	cancel context.CancelFunc
+	events chan BluetoothEvent
+}
+
+// SubscribeBluetooth starts `dbus-monitor --system` filtered to org.bluez
+// under supervision. Events stop and the channel closes when ctx is
+// cancelled or Stop is called.
+func SubscribeBluetooth(ctx context.Context, streamer sexec.Streamer, opts Options) *BluetoothSubscription {
+	ctx, cancel := context.WithCancel(ctx)
+	opts = opts.withDefaults()
+	sub := &BluetoothSubscription{cancel: cancel, events: make(chan BluetoothEvent, 64)}
+	go func() {
+		defer close(sub.events)
+		supervise(ctx, streamer, opts, NewDbusMonitorParser(func(ev BluetoothEvent) {
+			ev.Time = opts.Now()
+			select {
+			case sub.events <- ev:
+			case <-ctx.Done():
+			}
+		}).Feed, "dbus-monitor", "--system", "type='signal',sender='org.bluez'")
+	}()
+	return sub
+}
+
+// Events returns the event channel.
+func (s *BluetoothSubscription) Events() <-chan BluetoothEvent {
+	return s.events
+}
+
+// Stop terminates the subscription.
+func (s *BluetoothSubscription) Stop() {
+	s.cancel()
+}
+
+// ── dbus-monitor parsing ───────────────────────────────────────────────────
+//
+// dbus-monitor prints a header line per signal followed by indented
+// arguments:
+//
+//	signal time=... path=/org/bluez/hci0/dev_AA_BB_CC_DD_EE_FF; interface=org.freedesktop.DBus.Properties; member=PropertiesChanged
+//	   string "org.bluez.Device1"
+//	   array [
+//	      dict entry(
+//	         string "Connected"
+//	         variant             boolean true
+//	      )
+//	   ]
+//	   array [
+//	   ]
+//
+// The parser builds a small value tree from the argument lines and emits
+// an event once all arguments of a known member have been read.
+
+// dbusValue is a scalar or a container (array, dict entry, struct).
+type dbusValue struct {
+	scalar string
+	items  []*dbusValue
+}
+
+func (v *dbusValue) text() string {
+	if v.items == nil {
+		return v.scalar
+	}
+	parts := make([]string, 0, len(v.items))
+	for _, item := range v.items {
+		parts = append(parts, item.text())
+	}
+	return strings.Join(parts, ",")
+}
+
+// expectedArgs is the argument count of each signal member we decode.
+var expectedArgs = map[string]int{
+	"PropertiesChanged": 3,
+	"InterfacesAdded":   2,
+	"InterfacesRemoved": 2,
+}
+
+// DbusMonitorParser incrementally decodes dbus-monitor output.
+type DbusMonitorParser struct {
+	emit   func(BluetoothEvent)
+	member string
+	path   string
+	args   []*dbusValue
+	stack  []*dbusValue
+}
+
+// NewDbusMonitorParser returns a parser that calls emit for every decoded
+// BlueZ signal.
+func NewDbusMonitorParser(emit func(BluetoothEvent)) *DbusMonitorParser {
+	return &DbusMonitorParser{emit: emit}
+}
+
+var dbusHeaderFieldRe = regexp.MustCompile(`\b(path|member)=([^;\s]+)`)
+
+// Feed consumes one output line.
+func (p *DbusMonitorParser) Feed(line string) {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "signal ") || strings.HasPrefix(trimmed, "method ") || strings.HasPrefix(trimmed, "error ") {
+		p.reset()
+		if !strings.HasPrefix(trimmed, "signal ") {
+			return
+		}
+		for _, m := range dbusHeaderFieldRe.FindAllStringSubmatch(trimmed, -1) {
+			switch m[1] {
+			case "path":
+				p.path = m[2]
+			case "member":
+				p.member = m[2]
+			}
+		}
+		if _, ok := expectedArgs[p.member]; !ok {
+			p.member = ""
+		}
+		return
+	}
+	if p.member == "" || trimmed == "" {
+		return
+	}
+
+	trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "variant"))
+	switch {
+	case trimmed == "]" || trimmed == ")" || trimmed == "}":
+		if len(p.stack) > 0 {
+			p.stack = p.stack[:len(p.stack)-1]
+		}
+	case strings.HasSuffix(trimmed, "[") || strings.HasSuffix(trimmed, "(") || strings.HasSuffix(trimmed, "{"):
+		v := &dbusValue{items: []*dbusValue{}}
+		p.add(v)
+		p.stack = append(p.stack, v)
+	default:
+		p.add(&dbusValue{scalar: dbusScalar(trimmed)})
+	}
+
+	if len(p.stack) == 0 && len(p.args) >= expectedArgs[p.member] {
+		if ev, ok := p.event(); ok {
+			p.emit(ev)
+		}
+		p.reset()
+	}
+}
+
+func (p *DbusMonitorParser) add(v *dbusValue) {
+	if len(p.stack) == 0 {
+		p.args = append(p.args, v)
+		return
+	}
+	top := p.stack[len(p.stack)-1]
+	top.items = append(top.items, v)
+}
+
+func (p *DbusMonitorParser) reset() {
+	p.member, p.path, p.args, p.stack = "", "", nil, nil
+}
+
+func (p *DbusMonitorParser) event() (BluetoothEvent, bool) {
+	switch p.member {
+	case "PropertiesChanged":
+		ev := BluetoothEvent{
+			Kind:       KindPropertyChanged,
+			Path:       p.path,
+			Interface:  p.args[0].scalar,
+			Properties: dictEntries(p.args[1]),
+		}
+		ev.Address = AddressFromPath(ev.Path)
+		return ev, true
+	case "InterfacesAdded":
+		ev := BluetoothEvent{Kind: KindInterfacesAdded, Path: p.args[0].scalar}
+		var props map[string]string
+		for _, entry := range p.args[1].items {
+			if len(entry.items) != 2 {
+				continue
+			}
+			iface := entry.items[0].scalar
+			ev.Interfaces = append(ev.Interfaces, iface)
+			if props == nil || iface == bluezDeviceInterface {
+				props = dictEntries(entry.items[1])
+			}
+		}
+		ev.Properties = props
+		ev.Address = AddressFromPath(ev.Path)
+		return ev, true
+	case "InterfacesRemoved":
+		ev := BluetoothEvent{Kind: KindInterfacesRemoved, Path: p.args[0].scalar}
+		for _, item := range p.args[1].items {
+			ev.Interfaces = append(ev.Interfaces, item.scalar)
+		}
+		ev.Address = AddressFromPath(ev.Path)
+		return ev, true
+	}
+	return BluetoothEvent{}, false
+}
+
+// dictEntries flattens an a{sv} array into property → text.
+func dictEntries(v *dbusValue) map[string]string {
+	out := map[string]string{}
+	for _, entry := range v.items {
+		if len(entry.items) == 2 {
+			out[entry.items[0].scalar] = entry.items[1].text()
+		}
+	}
+	return out
+}
+
+// dbusScalar extracts the value from lines like `string "x"`,
+// `object path "/org/bluez"`, `boolean true` or `int16 -60`.
+func dbusScalar(line string) string {
+	if first := strings.IndexByte(line, '"'); first >= 0 {
+		if last := strings.LastIndexByte(line, '"'); last > first {
+			return line[first+1 : last]
+		}
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
+
+// bluezFacilities maps BlueZ interfaces to short facility names.
+var bluezFacilities = map[string]string{
+	"org.bluez.Adapter1":        "adapter",
+	"org.bluez.Device1":         "device",
+	"org.bluez.MediaTransport1": "transport",
+	"org.bluez.MediaEndpoint1":  "endpoint",
+	"org.bluez.MediaPlayer1":    "player",
+	"org.bluez.MediaControl1":   "control",
+	"org.bluez.Battery1":        "battery",
+}
+
+// Facility returns a short name for the object class the event concerns,
+// e.g. "device" or "transport", mirroring AudioEvent.Facility.
+func (e BluetoothEvent) Facility() string {
+	ifaces := e.Interfaces
+	if e.Interface != "" {
+		ifaces = []string{e.Interface}
+	}
+	for _, preferred := range []string{"org.bluez.Device1", "org.bluez.Adapter1"} {
+		for _, iface := range ifaces {
+			if iface == preferred {
+				return bluezFacilities[iface]
+			}
+		}
+	}
+	for _, iface := range ifaces {
+		if name, ok := bluezFacilities[iface]; ok {
+			return name
+		}
+		if rest, ok := strings.CutPrefix(iface, "org.bluez."); ok {
+			return strings.ToLower(strings.TrimRight(rest, "0123456789"))
+		}
+	}
+	return ""
+}
+
+var devicePathRe = regexp.MustCompile(`/dev_([0-9A-Fa-f]{2}(?:_[0-9A-Fa-f]{2}){5})`)
+
+// AddressFromPath extracts the device address from a BlueZ object path,
+// including paths of objects below a device (e.g. media transports).
+func AddressFromPath(path string) string {
+	m := devicePathRe.FindStringSubmatch(path)
+	if m == nil {
+		return ""
+	}
+	return strings.ToUpper(strings.ReplaceAll(m[1], "_", ":"))
+}
@@ -0,0 +1,142 @@
+package events
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	sexec "soundctl/pkg/soundctl/exec"
+)
+
+const propertiesChangedOutput = `signal time=1700000000.123 sender=:1.4 -> destination=(null) serial=42 path=/org/bluez/hci0/dev_08_FF_44_2B_4C_90; interface=org.freedesktop.DBus.Properties; member=PropertiesChanged
+   string "org.bluez.Device1"
+   array [
+      dict entry(
+         string "Connected"
+         variant             boolean true
+      )
+      dict entry(
+         string "RSSI"
+         variant             int16 -58
+      )
+   ]
+   array [
+   ]`
+
+const interfacesAddedOutput = `signal time=1700000001.000 sender=:1.4 -> destination=(null) serial=43 path=/; interface=org.freedesktop.DBus.ObjectManager; member=InterfacesAdded
+   object path "/org/bluez/hci0/dev_AC_80_0A_11_22_33"
+   array [
+      dict entry(
+         string "org.freedesktop.DBus.Introspectable"
+         array [
+         ]
+      )
+      dict entry(
+         string "org.bluez.Device1"
+         array [
+            dict entry(
+               string "Address"
+               variant                   string "AC:80:0A:11:22:33"
+            )
+            dict entry(
+               string "Name"
+               variant                   string "WH-1000XM4"
+            )
+            dict entry(
+               string "UUIDs"
+               variant                   array [
+                     string "0000110b-0000-1000-8000-00805f9b34fb"
+                     string "0000111e-0000-1000-8000-00805f9b34fb"
+                  ]
+            )
+         ]
+      )
+   ]`
+
+const interfacesRemovedOutput = `signal time=1700000002.000 sender=:1.4 -> destination=(null) serial=44 path=/; interface=org.freedesktop.DBus.ObjectManager; member=InterfacesRemoved
+   object path "/org/bluez/hci0/dev_AC_80_0A_11_22_33/sep1/fd0"
+   array [
+      string "org.bluez.MediaTransport1"
+   ]`
+
+func parseAll(output string) []BluetoothEvent {
+	var events []BluetoothEvent
+	p := NewDbusMonitorParser(func(ev BluetoothEvent) { events = append(events, ev) })
+	for _, line := range strings.Split(output, "\n") {
+		p.Feed(line)
+	}
+	return events
+}
+
+func TestDbusMonitorPropertiesChanged(t *testing.T) {
+	events := parseAll(propertiesChangedOutput)
+	want := []BluetoothEvent{{
+		Kind:       KindPropertyChanged,
+		Path:       "/org/bluez/hci0/dev_08_FF_44_2B_4C_90",
+		Address:    "08:FF:44:2B:4C:90",
+		Interface:  "org.bluez.Device1",
+		Properties: map[string]string{"Connected": "true", "RSSI": "-58"},
+	}}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("got %+v\nwant %+v", events, want)
+	}
+}
+
+func TestDbusMonitorInterfacesAddedAndRemoved(t *testing.T) {
+	events := parseAll(interfacesAddedOutput + "\n" + interfacesRemovedOutput)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+	added := events[0]
+	if added.Kind != KindInterfacesAdded || added.Address != "AC:80:0A:11:22:33" {
+		t.Fatalf("unexpected added event: %+v", added)
+	}
+	if !reflect.DeepEqual(added.Interfaces, []string{"org.freedesktop.DBus.Introspectable", "org.bluez.Device1"}) {
+		t.Fatalf("unexpected interfaces: %v", added.Interfaces)
+	}
+	if added.Properties["Name"] != "WH-1000XM4" || !strings.Contains(added.Properties["UUIDs"], "0000110b") {
+		t.Fatalf("unexpected properties: %v", added.Properties)
+	}
+
+	if added.Facility() != "device" {
+		t.Fatalf("expected device facility, got %q", added.Facility())
+	}
+
+	removed := events[1]
+	if removed.Kind != KindInterfacesRemoved || removed.Address != "AC:80:0A:11:22:33" ||
+		!reflect.DeepEqual(removed.Interfaces, []string{"org.bluez.MediaTransport1"}) {
+		t.Fatalf("unexpected removed event: %+v", removed)
+	}
+	if removed.Facility() != "transport" {
+		t.Fatalf("expected transport facility, got %q", removed.Facility())
+	}
+}
+
+func TestDbusMonitorIgnoresOtherSignals(t *testing.T) {
+	events := parseAll(`signal time=1 sender=org.freedesktop.DBus -> destination=:1.9 serial=2 path=/org/freedesktop/DBus; interface=org.freedesktop.DBus; member=NameAcquired
+   string ":1.9"
+just some random output`)
+	if len(events) != 0 {
+		t.Fatalf("expected no events, got %+v", events)
+	}
+}
+
+func TestSubscribeBluetoothStreamsEvents(t *testing.T) {
+	streamer := sexec.NewFakeStreamer()
+	streamer.Add("dbus-monitor", []string{"--system", "type='signal',sender='org.bluez'"},
+		sexec.StreamResult{Lines: strings.Split(propertiesChangedOutput, "\n")})
+
+	sub := SubscribeBluetooth(context.Background(), streamer, Options{RestartDelay: time.Millisecond})
+	defer sub.Stop()
+
+	select {
+	case ev := <-sub.Events():
+		if ev.Address != "08:FF:44:2B:4C:90" || ev.Properties["Connected"] != "true" || ev.Time.IsZero() {
+			t.Fatalf("unexpected event: %+v", ev)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+}
@@ -0,0 +1,121 @@
+// Package events turns the audio server's and BlueZ's change notifications
+// into typed event streams. Each subscription supervises a long-running
+// child process (`pactl subscribe`, `dbus-monitor`) through an
+// exec.Streamer, or an AudioNotifier, and restarts it when it exits.
+package events
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	sexec "soundctl/pkg/soundctl/exec"
+)
+
+// Audio event kinds, as printed by `pactl subscribe`.
+const (
+	KindNew    = "new"
+	KindChange = "change"
+	KindRemove = "remove"
+)
+
+// Bluetooth event kinds, named after the D-Bus signal members.
+const (
+	KindPropertyChanged   = "property-changed"
+	KindInterfacesAdded   = "interfaces-added"
+	KindInterfacesRemoved = "interfaces-removed"
+)
+
+// AudioEvent is one PulseAudio/PipeWire change notification.
+type AudioEvent struct {
+	Time     time.Time
+	Kind     string // KindNew, KindChange or KindRemove
+	Facility string // "sink", "source", "sink-input", "source-output", "card", "server", ...
+	Index    int    // object index; -1 for facilities without one (server)
+}
+
+// BluetoothEvent is one BlueZ D-Bus signal.
+type BluetoothEvent struct {
+	Time      time.Time
+	Kind      string // KindPropertyChanged, KindInterfacesAdded or KindInterfacesRemoved
+	Path      string // object path, e.g. /org/bluez/hci0/dev_AA_BB_CC_DD_EE_FF
+	Address   string // device address derived from Path; empty for adapters
+	Interface string // interface whose properties changed (property-changed only)
+	// Interfaces lists the interfaces added or removed.
+	Interfaces []string
+	// Properties holds changed properties, or the initial properties of an
+	// added org.bluez.Device1 (or first added interface), rendered as text.
+	Properties map[string]string
+}
+
+// Options tunes subscription supervision.
+type Options struct {
+	// RestartDelay is the initial wait before restarting an exited child;
+	// it doubles on consecutive silent failures up to MaxRestartDelay.
+	RestartDelay    time.Duration
+	MaxRestartDelay time.Duration
+	// OnRestart, if set, is called with the child's exit error before each
+	// restart.
+	OnRestart func(err error)
+	// Now stamps events; defaults to time.Now.
+	Now func() time.Time
+	// Notifier, if set, delivers audio events instead of `pactl
+	// subscribe`; see audio.Notifier.
+	Notifier AudioNotifier
+}
+
+func (o Options) withDefaults() Options {
+	if o.RestartDelay <= 0 {
+		o.RestartDelay = time.Second
+	}
+	if o.MaxRestartDelay <= 0 {
+		o.MaxRestartDelay = 30 * time.Second
+	}
+	if o.MaxRestartDelay < o.RestartDelay {
+		o.MaxRestartDelay = o.RestartDelay
+	}
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+	return o
+}
+
+// supervise runs the command until ctx is cancelled, restarting it with
+// backoff whenever it exits. The backoff resets after a run that produced
+// output.
+func supervise(ctx context.Context, streamer sexec.Streamer, opts Options, onLine func(string), name string, args ...string) {
+	restart(ctx, opts, func(progress func()) error {
+		return streamer.Stream(ctx, func(line string) {
+			progress()
+			onLine(line)
+		}, name, args...)
+	})
+}
+
+// restart calls run until ctx is cancelled, again with backoff whenever it
+// returns. The backoff resets after a run that called progress.
+func restart(ctx context.Context, opts Options, run func(progress func()) error) {
+	delay := opts.RestartDelay
+	for {
+		var progressed atomic.Bool // run may report progress from another goroutine
+		err := run(func() { progressed.Store(true) })
+		if ctx.Err() != nil {
+			return
+		}
+		if progressed.Load() {
+			delay = opts.RestartDelay
+		}
+		if opts.OnRestart != nil {
+			opts.OnRestart(err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		delay = min(delay*2, opts.MaxRestartDelay)
+	}
+}
@@ -0,0 +1,103 @@
+package exec
+
+import (
+	"bufio"
+	"context"
+	osexec "os/exec"
+	"sync"
+)
+
+// Streamer abstracts long-running commands whose stdout is consumed line
+// by line, such as `pactl subscribe` or `dbus-monitor`.
+type Streamer interface {
+	// Stream runs the command and calls onLine for every stdout line. It
+	// returns when the process exits or ctx is cancelled.
+	Stream(ctx context.Context, onLine func(line string), name string, args ...string) error
+}
+
+// OSStreamer runs commands on the host system.
+type OSStreamer struct{}
+
+func NewOSStreamer() *OSStreamer {
+	return &OSStreamer{}
+}
+
+func (s *OSStreamer) Stream(ctx context.Context, onLine func(line string), name string, args ...string) error {
+	cmd := osexec.CommandContext(ctx, name, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		onLine(scanner.Text())
+	}
+	if err := cmd.Wait(); err != nil {
+		return err
+	}
+	return scanner.Err()
+}
+
+// StreamResult is one scripted run of a FakeStreamer command.
+type StreamResult struct {
+	Lines []string
+	Err   error
+}
+
+// FakeStreamer replays scripted runs. Each Stream call for a command key
+// consumes the next queued run; once they are exhausted it blocks until ctx
+// is cancelled, like a quiet long-running process.
+type FakeStreamer struct {
+	mu    sync.Mutex
+	runs  map[string][]StreamResult
+	calls []string
+}
+
+func NewFakeStreamer() *FakeStreamer {
+	return &FakeStreamer{runs: map[string][]StreamResult{}}
+}
+
+// Add queues a run for the command.
+func (f *FakeStreamer) Add(name string, args []string, result StreamResult) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := CommandKey(name, args...)
+	f.runs[key] = append(f.runs[key], result)
+}
+
+func (f *FakeStreamer) Stream(ctx context.Context, onLine func(line string), name string, args ...string) error {
+	key := CommandKey(name, args...)
+	f.mu.Lock()
+	f.calls = append(f.calls, key)
+	queue := f.runs[key]
+	var run *StreamResult
+	if len(queue) > 0 {
+		run = &queue[0]
+		f.runs[key] = queue[1:]
+	}
+	f.mu.Unlock()
+
+	if run == nil {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	for _, line := range run.Lines {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		onLine(line)
+	}
+	return run.Err
+}
+
+func (f *FakeStreamer) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]string, len(f.calls))
+	copy(out, f.calls)
+	return out
+}
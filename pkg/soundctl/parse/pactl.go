@@ -2,6 +2,8 @@ package parse
 
 import (
 	"fmt"
+	"math"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -26,11 +28,25 @@ type PactlInfoRecord struct {
 type PactlSinkInputRecord struct {
 	Index     int
 	SinkIndex int
+	Client    int // -1 when the stream has no client
 	AppName   string
 	MediaName string
+	Binary    string // application.process.binary
+	PID       int    // application.process.id; 0 when unknown
+	Volume    []PactlChannelVolumeRecord
+	Muted     bool
+	Corked    bool
 	SinkName  string // populated externally
 }
 
+// PactlSourceOutputRecord captures a source-output (recording stream).
+type PactlSourceOutputRecord struct {
+	Index       int
+	SourceIndex int
+	AppName     string
+	MediaName   string
+}
+
 // PactlCardRecord captures a card with its available profiles from `pactl list cards`.
 type PactlCardRecord struct {
 	Index         int
@@ -38,6 +54,7 @@ type PactlCardRecord struct {
 	Driver        string
 	Profiles      []PactlProfileRecord
 	ActiveProfile string
+	Properties    map[string]string
 }
 
 // PactlProfileRecord captures a profile within a card.
@@ -77,37 +94,116 @@ func ParsePactlSinkInputs(output string) ([]PactlSinkInputRecord, error) {
 
 	for _, raw := range strings.Split(output, "\n") {
 		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
 
 		if strings.HasPrefix(line, "Sink Input #") {
 			if current != nil {
 				records = append(records, *current)
 			}
-			idx, _ := strconv.Atoi(strings.TrimPrefix(line, "Sink Input #"))
-			current = &PactlSinkInputRecord{Index: idx}
+			idx, err := strconv.Atoi(strings.TrimPrefix(line, "Sink Input #"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid pactl index in %q: %w", line, err)
+			}
+			current = &PactlSinkInputRecord{Index: idx, Client: -1}
 			inProperties = false
 			continue
 		}
 		if current == nil {
 			continue
 		}
-		if strings.HasPrefix(line, "Sink:") {
-			current.SinkIndex, _ = strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "Sink:")))
+
+		if leadingTabs(raw) >= 2 {
+			if !inProperties {
+				continue
+			}
+			switch k, v, _ := splitProperty(line); k {
+			case "application.name":
+				current.AppName = v
+			case "media.name":
+				current.MediaName = v
+			case "application.process.binary":
+				current.Binary = v
+			case "application.process.id":
+				current.PID, _ = strconv.Atoi(v)
+			}
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, ":")
+		value = strings.TrimSpace(value)
+		inProperties = key == "Properties"
+		switch key {
+		case "Sink":
+			current.SinkIndex, _ = strconv.Atoi(value)
+		case "Client":
+			if n, err := strconv.Atoi(value); err == nil {
+				current.Client = n
+			}
+		case "Volume":
+			current.Volume = ParsePactlVolume(value)
+		case "Mute":
+			current.Muted = strings.EqualFold(value, "yes")
+		case "Corked":
+			current.Corked = strings.EqualFold(value, "yes")
 		}
-		if line == "Properties:" {
-			inProperties = true
+	}
+	if current != nil {
+		records = append(records, *current)
+	}
+	return records, nil
+}
+
+// ParsePactlSourceOutputs parses `pactl list source-outputs` output.
+func ParsePactlSourceOutputs(output string) ([]PactlSourceOutputRecord, error) {
+	if strings.TrimSpace(output) == "" {
+		return nil, nil
+	}
+
+	var records []PactlSourceOutputRecord
+	var current *PactlSourceOutputRecord
+	inProperties := false
+
+	for _, raw := range strings.Split(output, "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" {
 			continue
 		}
-		if inProperties {
-			if strings.HasPrefix(line, "application.name = ") {
-				current.AppName = trimQuotes(strings.TrimPrefix(line, "application.name = "))
+
+		if strings.HasPrefix(line, "Source Output #") {
+			if current != nil {
+				records = append(records, *current)
 			}
-			if strings.HasPrefix(line, "media.name = ") {
-				current.MediaName = trimQuotes(strings.TrimPrefix(line, "media.name = "))
+			idx, err := strconv.Atoi(strings.TrimPrefix(line, "Source Output #"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid pactl index in %q: %w", line, err)
+			}
+			current = &PactlSourceOutputRecord{Index: idx}
+			inProperties = false
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		if leadingTabs(raw) >= 2 {
+			if !inProperties {
+				continue
 			}
-			// Properties section ends when next non-indented section starts
-			if !strings.HasPrefix(raw, "\t\t") && !strings.HasPrefix(raw, "    ") && line != "" && !strings.Contains(line, "=") {
-				inProperties = false
+			switch k, v, _ := splitProperty(line); k {
+			case "application.name":
+				current.AppName = v
+			case "media.name":
+				current.MediaName = v
 			}
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, ":")
+		inProperties = key == "Properties"
+		if key == "Source" {
+			current.SourceIndex, _ = strconv.Atoi(strings.TrimSpace(value))
 		}
 	}
 	if current != nil {
@@ -125,6 +221,7 @@ func ParsePactlCards(output string) ([]PactlCardRecord, error) {
 	var cards []PactlCardRecord
 	var current *PactlCardRecord
 	inProfiles := false
+	inProperties := false
 
 	for _, raw := range strings.Split(output, "\n") {
 		line := strings.TrimSpace(raw)
@@ -134,13 +231,23 @@ func ParsePactlCards(output string) ([]PactlCardRecord, error) {
 				cards = append(cards, *current)
 			}
 			idx, _ := strconv.Atoi(strings.TrimPrefix(line, "Card #"))
-			current = &PactlCardRecord{Index: idx}
+			current = &PactlCardRecord{Index: idx, Properties: map[string]string{}}
 			inProfiles = false
+			inProperties = false
 			continue
 		}
 		if current == nil {
 			continue
 		}
+		if inProperties {
+			if leadingTabs(raw) >= 2 {
+				if k, v, ok := splitProperty(line); ok {
+					current.Properties[k] = v
+				}
+				continue
+			}
+			inProperties = false
+		}
 
 		if strings.HasPrefix(line, "Name:") {
 			current.Name = strings.TrimSpace(strings.TrimPrefix(line, "Name:"))
@@ -151,6 +258,9 @@ func ParsePactlCards(output string) ([]PactlCardRecord, error) {
 		} else if strings.HasPrefix(line, "Active Profile:") {
 			current.ActiveProfile = strings.TrimSpace(strings.TrimPrefix(line, "Active Profile:"))
 			inProfiles = false
+		} else if line == "Properties:" {
+			inProperties = true
+			inProfiles = false
 		} else if line == "Profiles:" {
 			inProfiles = true
 		} else if inProfiles {
@@ -165,8 +275,14 @@ func ParsePactlCards(output string) ([]PactlCardRecord, error) {
 					desc := rest
 					available := true
 
-					// Extract description (before parenthesized details)
-					if parenIdx := strings.Index(rest, " ("); parenIdx > 0 {
+					// Extract description (before parenthesized details). Bluetooth
+					// descriptions carry their own parentheses, e.g.
+					// "High Fidelity Playback (A2DP Sink, codec AAC) (sinks: ...)".
+					parenIdx := strings.LastIndex(rest, " (sinks:")
+					if parenIdx < 0 {
+						parenIdx = strings.Index(rest, " (")
+					}
+					if parenIdx > 0 {
 						desc = rest[:parenIdx]
 						if strings.Contains(rest, "available: no") {
 							available = false
@@ -233,3 +349,204 @@ func ParsePactlShort(output string) ([]PactlShortRecord, error) {
 	}
 	return rows, nil
 }
+
+// PactlChannelVolumeRecord captures one channel of a pactl `Volume:` line,
+// e.g. `front-left: 39322 /  60% / -13.31 dB`.
+type PactlChannelVolumeRecord struct {
+	Channel string
+	Value   int // raw PulseAudio volume (65536 = 100%)
+	Percent int
+	DB      float64 // math.Inf(-1) when pactl reports -inf
+}
+
+// PactlPortRecord captures a port within a sink or source.
+type PactlPortRecord struct {
+	Name        string
+	Description string
+	Available   bool
+}
+
+// PactlDeviceRecord captures a sink or source from `pactl list sinks` or
+// `pactl list sources` (the long form, including volumes and properties).
+type PactlDeviceRecord struct {
+	Index         int
+	State         string
+	Name          string
+	Description   string
+	Driver        string
+	SampleSpec    string
+	ChannelMap    string
+	Muted         bool
+	Volume        []PactlChannelVolumeRecord
+	Balance       float64
+	BaseVolume    PactlChannelVolumeRecord
+	MonitorSource string // sinks only
+	MonitorOfSink string // sources only; empty when not a monitor
+	Ports         []PactlPortRecord
+	ActivePort    string
+	Properties    map[string]string
+}
+
+var (
+	channelVolumeRe = regexp.MustCompile(`([A-Za-z0-9_-]+):\s*(\d+)\s*/\s*(\d+)%\s*/\s*(-?inf|-?[0-9]+(?:[.,][0-9]+)?)\s*dB`)
+	baseVolumeRe    = regexp.MustCompile(`^(\d+)\s*/\s*(\d+)%\s*/\s*(-?inf|-?[0-9]+(?:[.,][0-9]+)?)\s*dB`)
+)
+
+// ParsePactlSinks parses `pactl list sinks` output.
+func ParsePactlSinks(output string) ([]PactlDeviceRecord, error) {
+	return parsePactlDevices(output, "Sink #")
+}
+
+// ParsePactlSources parses `pactl list sources` output.
+func ParsePactlSources(output string) ([]PactlDeviceRecord, error) {
+	return parsePactlDevices(output, "Source #")
+}
+
+func parsePactlDevices(output string, header string) ([]PactlDeviceRecord, error) {
+	if strings.TrimSpace(output) == "" {
+		return nil, nil
+	}
+
+	var records []PactlDeviceRecord
+	var current *PactlDeviceRecord
+	section := ""
+
+	for _, raw := range strings.Split(output, "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, header) {
+			if current != nil {
+				records = append(records, *current)
+			}
+			idx, err := strconv.Atoi(strings.TrimPrefix(line, header))
+			if err != nil {
+				return nil, fmt.Errorf("invalid pactl index in %q: %w", line, err)
+			}
+			current = &PactlDeviceRecord{Index: idx, Properties: map[string]string{}}
+			section = ""
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		// The balance value is printed on a space-indented continuation
+		// line directly below `Volume:`.
+		if strings.HasPrefix(line, "balance ") {
+			current.Balance, _ = strconv.ParseFloat(strings.TrimPrefix(line, "balance "), 64)
+			continue
+		}
+
+		depth := leadingTabs(raw)
+		if depth >= 2 {
+			switch section {
+			case "Properties":
+				if k, v, ok := splitProperty(line); ok {
+					current.Properties[k] = v
+				}
+			case "Ports":
+				if depth == 2 {
+					current.Ports = append(current.Ports, parsePortLine(line))
+				}
+			}
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, ":")
+		value = strings.TrimSpace(value)
+		section = ""
+		switch key {
+		case "State":
+			current.State = value
+		case "Name":
+			current.Name = value
+		case "Description":
+			current.Description = value
+		case "Driver":
+			current.Driver = value
+		case "Sample Specification":
+			current.SampleSpec = value
+		case "Channel Map":
+			current.ChannelMap = value
+		case "Mute":
+			current.Muted = strings.EqualFold(value, "yes")
+		case "Volume":
+			current.Volume = ParsePactlVolume(value)
+		case "Base Volume":
+			if m := baseVolumeRe.FindStringSubmatch(value); m != nil {
+				current.BaseVolume = channelVolumeFromMatch("", m[1], m[2], m[3])
+			}
+		case "Monitor Source":
+			current.MonitorSource = value
+		case "Monitor of Sink":
+			if value != "n/a" {
+				current.MonitorOfSink = value
+			}
+		case "Active Port":
+			current.ActivePort = value
+		case "Properties", "Ports", "Formats":
+			section = key
+		}
+	}
+	if current != nil {
+		records = append(records, *current)
+	}
+	return records, nil
+}
+
+// ParsePactlVolume parses the value of a pactl `Volume:` line into its
+// per-channel components. Unrecognised input yields an empty slice.
+func ParsePactlVolume(value string) []PactlChannelVolumeRecord {
+	matches := channelVolumeRe.FindAllStringSubmatch(value, -1)
+	volumes := make([]PactlChannelVolumeRecord, 0, len(matches))
+	for _, m := range matches {
+		volumes = append(volumes, channelVolumeFromMatch(m[1], m[2], m[3], m[4]))
+	}
+	return volumes
+}
+
+func channelVolumeFromMatch(channel, raw, percent, db string) PactlChannelVolumeRecord {
+	rec := PactlChannelVolumeRecord{Channel: channel}
+	rec.Value, _ = strconv.Atoi(raw)
+	rec.Percent, _ = strconv.Atoi(percent)
+	if strings.HasSuffix(db, "inf") {
+		rec.DB = math.Inf(-1)
+	} else {
+		// Some locales print a decimal comma.
+		rec.DB, _ = strconv.ParseFloat(strings.ReplaceAll(db, ",", "."), 64)
+	}
+	return rec
+}
+
+// parsePortLine parses a port entry such as:
+//
+//	analog-output-speaker: Speakers (type: Speaker, priority: 10000, availability unknown)
+func parsePortLine(line string) PactlPortRecord {
+	name, rest, _ := strings.Cut(line, ": ")
+	port := PactlPortRecord{Name: name, Description: rest, Available: true}
+	if parenIdx := strings.LastIndex(rest, " ("); parenIdx > 0 {
+		port.Description = rest[:parenIdx]
+		port.Available = !strings.Contains(rest[parenIdx:], "not available")
+	}
+	return port
+}
+
+// splitProperty splits a `key = "value"` property line.
+func splitProperty(line string) (string, string, bool) {
+	k, v, ok := strings.Cut(line, " = ")
+	if !ok {
+		return "", "", false
+	}
+	return strings.TrimSpace(k), trimQuotes(v), true
+}
+
+func leadingTabs(raw string) int {
+	n := 0
+	for n < len(raw) && raw[n] == '\t' {
+		n++
+	}
+	return n
+}
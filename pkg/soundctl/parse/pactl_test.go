@@ -1,6 +1,8 @@
 package parse
 
 import (
+	"math"
+	"reflect"
 	"testing"
 )
 
@@ -47,7 +49,7 @@ func TestParsePactlSinkInputs(t *testing.T) {
 	Format: pcm, format.sample_format = "\"float32le\""
 	Corked: no
 	Mute: no
-	Volume: front-left: 65536 / 100% / 0.00 dB,   front-right: 65536 / 100% / 0.00 dB
+	Volume: front-left: 39322 /  60% / -13.31 dB,   front-right: 39322 /  60% / -13.31 dB
 	        balance 0.00
 	Buffer Latency: 0 usec
 	Sink Latency: 0 usec
@@ -55,10 +57,15 @@ func TestParsePactlSinkInputs(t *testing.T) {
 	Properties:
 		media.name = "Playback"
 		application.name = "Firefox"
+		application.process.id = "4242"
+		application.process.binary = "firefox"
 		node.name = "Firefox"
 Sink Input #63
 	Driver: PipeWire
+	Client: n/a
 	Sink: 47
+	Corked: yes
+	Mute: yes
 	Properties:
 		media.name = "Music"
 		application.name = "Spotify"
@@ -83,6 +90,16 @@ Sink Input #63
 	if records[1].AppName != "Spotify" {
 		t.Fatalf("expected Spotify, got %s", records[1].AppName)
 	}
+	first := records[0]
+	if first.Client != 38 || first.PID != 4242 || first.Binary != "firefox" || first.Muted || first.Corked {
+		t.Fatalf("unexpected stream details: %+v", first)
+	}
+	if len(first.Volume) != 2 || first.Volume[0].Percent != 60 {
+		t.Fatalf("unexpected stream volume: %+v", first.Volume)
+	}
+	if second := records[1]; second.Client != -1 || !second.Muted || !second.Corked || second.Volume != nil {
+		t.Fatalf("unexpected second stream: %+v", second)
+	}
 }
 
 func TestParsePactlCards(t *testing.T) {
@@ -102,6 +119,9 @@ func TestParsePactlCards(t *testing.T) {
 Card #62
 	Name: bluez_card.AA_BB_CC_DD_EE_FF
 	Driver: module-bluez5-device.c
+	Properties:
+		device.description = "WH-1000XM4"
+		api.bluez5.codec = "aac"
 	Profiles:
 		a2dp-sink: High Fidelity Playback (A2DP Sink, codec SBC) (sinks: 1, sources: 0, priority: 40, available: yes)
 		headset-head-unit: Headset Head Unit (HSP/HFP) (sinks: 1, sources: 1, priority: 30, available: yes)
@@ -143,6 +163,15 @@ Card #62
 	if len(cards[1].Profiles) != 3 {
 		t.Fatalf("expected 3 profiles for card2, got %d", len(cards[1].Profiles))
 	}
+	if got := cards[1].Profiles[0].Description; got != "High Fidelity Playback (A2DP Sink, codec SBC)" {
+		t.Fatalf("unexpected card2 profile desc: %s", got)
+	}
+	if got := cards[1].Properties["api.bluez5.codec"]; got != "aac" {
+		t.Fatalf("unexpected card2 codec property: %q", got)
+	}
+	if got := cards[0].Properties["alsa.card"]; got != "0" {
+		t.Fatalf("unexpected card1 property: %q", got)
+	}
 }
 
 func TestParsePactlShort(t *testing.T) {
@@ -164,3 +193,187 @@ func TestParsePactlShort(t *testing.T) {
 		t.Fatalf("unexpected state: %s", rows[0].State)
 	}
 }
+
+func TestParsePactlSourceOutputs(t *testing.T) {
+	input := `Source Output #61
+	Driver: PipeWire
+	Owner Module: n/a
+	Client: 87
+	Source: 55
+	Sample Specification: float32le 1ch 48000Hz
+	Channel Map: mono
+	Format: pcm, format.sample_format = "\"float32le\""  format.rate = "48000"  format.channels = "1"  format.channel_map = "\"mono\""
+	Corked: no
+	Mute: no
+	Volume: mono: 65536 / 100% / 0.00 dB
+	        balance 0.00
+	Buffer Latency: 0 usec
+	Source Latency: 0 usec
+	Resample method: PipeWire
+	Properties:
+		media.name = "ZOOM VoiceEngine"
+		application.name = "ZOOM VoiceEngine"
+		application.process.binary = "zoom"
+Source Output #62
+	Driver: PipeWire
+	Client: 90
+	Source: 49
+	Properties:
+		application.name = "Firefox"
+`
+	rows, err := ParsePactlSourceOutputs(input)
+	if err != nil {
+		t.Fatalf("ParsePactlSourceOutputs returned error: %v", err)
+	}
+	want := []PactlSourceOutputRecord{
+		{Index: 61, SourceIndex: 55, AppName: "ZOOM VoiceEngine", MediaName: "ZOOM VoiceEngine"},
+		{Index: 62, SourceIndex: 49, AppName: "Firefox"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("unexpected rows:\n got %+v\nwant %+v", rows, want)
+	}
+	if rows, err := ParsePactlSourceOutputs(""); err != nil || len(rows) != 0 {
+		t.Fatalf("expected no rows for empty output, got %+v, %v", rows, err)
+	}
+}
+
+func TestParsePactlSinks(t *testing.T) {
+	input := `Sink #47
+	State: RUNNING
+	Name: alsa_output.pci-0000_00_1f.3.analog-stereo
+	Description: Built-in Audio Analog Stereo
+	Driver: PipeWire
+	Sample Specification: s32le 2ch 48000Hz
+	Channel Map: front-left,front-right
+	Owner Module: 4294967295
+	Mute: no
+	Volume: front-left: 39322 /  60% / -13.31 dB,   front-right: 32768 /  50% / -18.06 dB
+	        balance -0.17
+	Base Volume: 65536 / 100% / 0.00 dB
+	Monitor Source: alsa_output.pci-0000_00_1f.3.analog-stereo.monitor
+	Latency: 0 usec, configured 0 usec
+	Flags: HARDWARE HW_MUTE_CTRL HW_VOLUME_CTRL DECIBEL_VOLUME LATENCY
+	Properties:
+		alsa.card = "0"
+		device.description = "Built-in Audio Analog Stereo"
+		device.form_factor = "internal"
+	Ports:
+		analog-output-speaker: Speakers (type: Speaker, priority: 10000, availability unknown)
+		analog-output-headphones: Headphones (type: Headphones, priority: 9900, not available)
+	Active Port: analog-output-speaker
+	Formats:
+		pcm
+Sink #62
+	State: SUSPENDED
+	Name: bluez_output.AA_BB_CC_DD_EE_FF.1
+	Description: AirPods Max
+	Driver: PipeWire
+	Mute: yes
+	Volume: mono: 0 /   0% / -inf dB
+	        balance 0.00
+	Properties:
+		api.bluez5.address = "AA:BB:CC:DD:EE:FF"`
+
+	sinks, err := ParsePactlSinks(input)
+	if err != nil {
+		t.Fatalf("ParsePactlSinks: %v", err)
+	}
+	if len(sinks) != 2 {
+		t.Fatalf("expected 2 sinks, got %d", len(sinks))
+	}
+
+	s := sinks[0]
+	if s.Index != 47 || s.Name != "alsa_output.pci-0000_00_1f.3.analog-stereo" {
+		t.Fatalf("unexpected sink identity: %d %s", s.Index, s.Name)
+	}
+	if s.Description != "Built-in Audio Analog Stereo" || s.State != "RUNNING" {
+		t.Fatalf("unexpected description/state: %q %q", s.Description, s.State)
+	}
+	if s.Muted {
+		t.Fatal("expected sink 47 unmuted")
+	}
+	if len(s.Volume) != 2 {
+		t.Fatalf("expected 2 channel volumes, got %d", len(s.Volume))
+	}
+	if s.Volume[0].Channel != "front-left" || s.Volume[0].Percent != 60 || s.Volume[0].Value != 39322 {
+		t.Fatalf("unexpected front-left volume: %+v", s.Volume[0])
+	}
+	if s.Volume[1].Percent != 50 || s.Volume[1].DB != -18.06 {
+		t.Fatalf("unexpected front-right volume: %+v", s.Volume[1])
+	}
+	if s.Balance != -0.17 {
+		t.Fatalf("unexpected balance: %v", s.Balance)
+	}
+	if s.BaseVolume.Percent != 100 {
+		t.Fatalf("unexpected base volume: %+v", s.BaseVolume)
+	}
+	if s.MonitorSource != "alsa_output.pci-0000_00_1f.3.analog-stereo.monitor" {
+		t.Fatalf("unexpected monitor source: %s", s.MonitorSource)
+	}
+	if s.Properties["device.form_factor"] != "internal" {
+		t.Fatalf("unexpected properties: %v", s.Properties)
+	}
+	if len(s.Ports) != 2 || s.Ports[0].Description != "Speakers" || !s.Ports[0].Available || s.Ports[1].Available {
+		t.Fatalf("unexpected ports: %+v", s.Ports)
+	}
+	if s.ActivePort != "analog-output-speaker" {
+		t.Fatalf("unexpected active port: %s", s.ActivePort)
+	}
+
+	bt := sinks[1]
+	if !bt.Muted {
+		t.Fatal("expected sink 62 muted")
+	}
+	if len(bt.Volume) != 1 || bt.Volume[0].Channel != "mono" || !math.IsInf(bt.Volume[0].DB, -1) {
+		t.Fatalf("unexpected mono volume: %+v", bt.Volume)
+	}
+	if bt.Properties["api.bluez5.address"] != "AA:BB:CC:DD:EE:FF" {
+		t.Fatalf("unexpected bluez properties: %v", bt.Properties)
+	}
+}
+
+func TestParsePactlSources(t *testing.T) {
+	input := `Source #48
+	State: SUSPENDED
+	Name: alsa_output.pci-0000_00_1f.3.analog-stereo.monitor
+	Description: Monitor of Built-in Audio Analog Stereo
+	Mute: no
+	Volume: front-left: 65536 / 100% / 0.00 dB,   front-right: 65536 / 100% / 0.00 dB
+	        balance 0.00
+	Monitor of Sink: alsa_output.pci-0000_00_1f.3.analog-stereo
+Source #49
+	State: RUNNING
+	Name: alsa_input.pci-0000_00_1f.3.analog-stereo
+	Description: Built-in Audio Analog Stereo
+	Mute: yes
+	Volume: front-left: 26214 /  40% / -23.88 dB,   front-right: 26214 /  40% / -23.88 dB
+	        balance 0.00
+	Monitor of Sink: n/a`
+
+	sources, err := ParsePactlSources(input)
+	if err != nil {
+		t.Fatalf("ParsePactlSources: %v", err)
+	}
+	if len(sources) != 2 {
+		t.Fatalf("expected 2 sources, got %d", len(sources))
+	}
+	if sources[0].MonitorOfSink != "alsa_output.pci-0000_00_1f.3.analog-stereo" {
+		t.Fatalf("unexpected monitor-of: %q", sources[0].MonitorOfSink)
+	}
+	if sources[1].MonitorOfSink != "" {
+		t.Fatalf("expected n/a monitor-of to be empty, got %q", sources[1].MonitorOfSink)
+	}
+	if !sources[1].Muted || sources[1].Volume[0].Percent != 40 {
+		t.Fatalf("unexpected mic state: %+v", sources[1])
+	}
+}
+
+func TestParsePactlVolumeDecimalComma(t *testing.T) {
+	vols := ParsePactlVolume("front-left: 39322 /  60% / -13,31 dB,   front-right: 39322 /  60% / -13,31 dB")
+	if len(vols) != 2 {
+		t.Fatalf("expected 2 channels, got %d", len(vols))
+	}
+	if vols[1].DB != -13.31 {
+		t.Fatalf("unexpected dB: %v", vols[1].DB)
+	}
+}
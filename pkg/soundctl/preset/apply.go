@@ -3,81 +3,461 @@ package preset
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"soundctl/pkg/soundctl/audio"
 )
 
 // ApplyResult reports what the apply operation did.
 type ApplyResult struct {
-	Applied []string // human-readable list of changes made
-	Errors  []error  // non-fatal errors (e.g. a missing stream)
+	Applied    []string           // human-readable list of changes made
+	Errors     []error            // non-fatal errors (e.g. a missing stream)
+	Unresolved []UnresolvedTarget // "@target" references that matched no single object; their entries are skipped
+	Steps      []StepResult       // every step in order, successful or not
+	Drift      []DiffLine         // what verification found still differing from the preset
 }
 
-// Apply executes a preset's configuration against the audio service.
-// It sets card profiles, volumes, default sink, and app routing.
+// StepResult is the outcome of one apply step.
+type StepResult struct {
+	Step string // e.g. "Profile bluez_card.sony → a2dp-sink"
+	Err  error  // nil when the step succeeded
+}
+
+// OK reports whether every step succeeded and every target resolved.
+func (r ApplyResult) OK() bool {
+	return len(r.Errors) == 0 && len(r.Unresolved) == 0
+}
+
+// record adds a step's outcome to Steps and to Applied or Errors.
+func (r *ApplyResult) record(step string, err error) {
+	r.Steps = append(r.Steps, StepResult{Step: step, Err: err})
+	if err != nil {
+		r.Errors = append(r.Errors, err)
+	} else {
+		r.Applied = append(r.Applied, step)
+	}
+}
+
+// wrapf annotates a non-nil err like fmt.Errorf(format+": %w", ...).
+func wrapf(err error, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf(format+": %w", append(args, err)...)
+}
+
+// ApplyOptions controls how an apply converges. The zero value applies
+// each step once and does not verify.
+type ApplyOptions struct {
+	// Wait bounds how long, after a card profile switch, to wait for the
+	// preset's sinks and sources to appear and to retry the steps that
+	// depend on them. Without a switch nothing is asynchronous and a
+	// failed step is final.
+	Wait time.Duration
+	// Interval is the polling interval while waiting.
+	Interval time.Duration
+	// Verify diffs the live state against the preset afterwards; residual
+	// drift fails the "Verify" step.
+	Verify bool
+}
+
+// DefaultApplyOptions returns the options Apply uses.
+func DefaultApplyOptions() ApplyOptions {
+	return ApplyOptions{Wait: 5 * time.Second, Interval: 200 * time.Millisecond, Verify: true}
+}
+
+// Apply executes a preset's configuration against the audio service with
+// DefaultApplyOptions.
 func Apply(ctx context.Context, au audio.Service, p Preset) ApplyResult {
+	return ApplyWith(ctx, au, p, DefaultApplyOptions())
+}
+
+// ApplyWith executes a preset's configuration against the audio service.
+// It sets card profiles, then the default sink, volumes, app routing and
+// per-app volume. Card targets are resolved first; sink and source
+// targets after the profiles are set, since switching a profile renames
+// them, and once the renamed objects have appeared. Presets p extends are
+// not looked up; pass the result of Resolve.
+func ApplyWith(ctx context.Context, au audio.Service, p Preset, opts ApplyOptions) ApplyResult {
 	var result ApplyResult
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultApplyOptions().Interval
+	}
+
+	p, result.Unresolved = resolveTargets(ctx, au, p, "card")
 
 	// 1) Set card profiles
-	for card, profile := range p.CardProfiles {
-		if err := au.SetCardProfile(ctx, card, profile); err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("set profile %s→%s: %w", card, profile, err))
+	switched := false
+	for _, step := range profileSteps(p) {
+		err := step.run(ctx, au)
+		result.record(step.desc, err)
+		switched = switched || err == nil
+	}
+
+	// A profile switch removes the card's sinks and sources and adds new
+	// ones asynchronously; give them until the deadline to show up.
+	deadline := time.Now().Add(opts.Wait)
+	converging := switched && opts.Wait > 0
+	if converging {
+		poll(ctx, deadline, opts.Interval, func() bool { return devicesPresent(ctx, au, p) })
+	}
+
+	var unresolved []UnresolvedTarget
+	p, unresolved = resolveTargets(ctx, au, p, "sink", "source")
+	result.Unresolved = append(result.Unresolved, unresolved...)
+
+	// 2) Default sink, volumes, routing and per-app volume depend on the
+	// sinks and sources; retry them while the devices settle.
+	var inputs []audio.SinkInput
+	if len(p.AppRoutes) > 0 || len(p.AppVolumes) > 0 {
+		var err error
+		if inputs, err = au.ListSinkInputs(ctx); err != nil {
+			result.record("List streams", wrapf(err, "list sink inputs"))
+		}
+	}
+	pending := dependentSteps(p, inputs)
+	for {
+		var failed []applyStep
+		for _, step := range pending {
+			if err := step.run(ctx, au); err != nil {
+				step.err = err
+				failed = append(failed, step)
+			} else {
+				result.record(step.desc, nil)
+			}
+		}
+		if len(failed) == 0 || !converging || !sleepUntil(ctx, deadline, opts.Interval) {
+			for _, step := range failed {
+				result.record(step.desc, step.err)
+			}
+			break
+		}
+		pending = failed
+	}
+
+	// 3) Verify the live state matches.
+	if opts.Verify {
+		var drift []DiffLine
+		var err error
+		check := func() bool {
+			drift, err = Verify(ctx, au, p)
+			return err == nil && len(drift) == 0
+		}
+		if converging {
+			poll(ctx, deadline, opts.Interval, check)
 		} else {
-			result.Applied = append(result.Applied, fmt.Sprintf("Profile %s → %s", card, profile))
+			check()
+		}
+		if err == nil && len(drift) > 0 {
+			err = fmt.Errorf("%d setting(s) differ after apply", len(drift))
+		}
+		result.Drift = drift
+		err = wrapf(err, "verify")
+		result.Steps = append(result.Steps, StepResult{Step: "Verify", Err: err})
+		if err != nil {
+			result.Errors = append(result.Errors, err)
 		}
 	}
 
-	// 2) Set default sink
+	return result
+}
+
+// applyStep is one call Apply makes; Plan describes the same steps.
+type applyStep struct {
+	desc    string                   // as reported in ApplyResult
+	command string                   // the equivalent pactl command
+	needs   string                   // "sink NAME" or "source NAME" the step acts on, if any
+	follows bool                     // routes a stream to the default sink
+	from    func(live Preset) string // the current value
+	to      string                   // the value the step sets
+	run     func(ctx context.Context, au audio.Service) error
+	err     error // the last failure, while retrying
+}
+
+// profileSteps lists the card profile switches, in card name order.
+func profileSteps(p Preset) []applyStep {
+	var steps []applyStep
+	for _, card := range sortedKeys(p.CardProfiles) {
+		profile := p.CardProfiles[card]
+		steps = append(steps, applyStep{
+			desc:    fmt.Sprintf("Profile %s → %s", card, profile),
+			command: fmt.Sprintf("pactl set-card-profile %s %s", card, profile),
+			from:    func(live Preset) string { return live.CardProfiles[card] },
+			to:      profile,
+			run: func(ctx context.Context, au audio.Service) error {
+				return wrapf(au.SetCardProfile(ctx, card, profile), "set profile %s→%s", card, profile)
+			},
+		})
+	}
+	return steps
+}
+
+// dependentSteps lists the steps that need the preset's sinks and
+// sources: the default sink, sink and source volumes, and the routing and
+// volume of the given streams.
+func dependentSteps(p Preset, inputs []audio.SinkInput) []applyStep {
+	var steps []applyStep
+
 	if p.DefaultSink != "" {
-		if err := au.SetDefaultSink(ctx, p.DefaultSink); err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("set default sink %s: %w", p.DefaultSink, err))
-		} else {
-			result.Applied = append(result.Applied, fmt.Sprintf("Default sink → %s", p.DefaultSink))
+		steps = append(steps, applyStep{
+			desc:    fmt.Sprintf("Default sink → %s", p.DefaultSink),
+			command: "pactl set-default-sink " + p.DefaultSink,
+			needs:   "sink " + p.DefaultSink,
+			from:    func(live Preset) string { return live.DefaultSink },
+			to:      p.DefaultSink,
+			run: func(ctx context.Context, au audio.Service) error {
+				return wrapf(au.SetDefaultSink(ctx, p.DefaultSink), "set default sink %s", p.DefaultSink)
+			},
+		})
+	}
+
+	// Volumes are set with an explicit mute state, so that re-applying a
+	// preset converges instead of toggling.
+	for _, target := range []string{"sink", "source"} {
+		volumes := p.Volumes
+		if target == "source" {
+			volumes = p.SourceVolumes
+		}
+		current := func(live Preset) map[string]VolumeSpec {
+			if target == "source" {
+				return live.SourceVolumes
+			}
+			return live.Volumes
+		}
+		for _, name := range sortedKeys(volumes) {
+			vol := volumes[name]
+			steps = append(steps, applyStep{
+				desc:    fmt.Sprintf("Volume %s → %d%%", name, vol.Level),
+				command: fmt.Sprintf("pactl set-%s-volume %s %d%%", target, name, vol.Level),
+				needs:   target + " " + name,
+				from: func(live Preset) string {
+					if v, ok := current(live)[name]; ok {
+						return fmt.Sprintf("%d%%", v.Level)
+					}
+					return ""
+				},
+				to: fmt.Sprintf("%d%%", vol.Level),
+				run: func(ctx context.Context, au audio.Service) error {
+					return wrapf(au.SetVolume(ctx, target, name, vol.Level), "set %s volume %s=%d%%", target, name, vol.Level)
+				},
+			}, applyStep{
+				desc:    fmt.Sprintf("Mute %s → %s", name, muteLabel(vol.Muted)),
+				command: fmt.Sprintf("pactl set-%s-mute %s %s", target, name, boolDigit(vol.Muted)),
+				needs:   target + " " + name,
+				from: func(live Preset) string {
+					if v, ok := current(live)[name]; ok {
+						return muteLabel(v.Muted)
+					}
+					return ""
+				},
+				to: muteLabel(vol.Muted),
+				run: func(ctx context.Context, au audio.Service) error {
+					return wrapf(au.SetMute(ctx, target, name, vol.Muted), "set %s mute %s=%t", target, name, vol.Muted)
+				},
+			})
 		}
 	}
 
-	// 3) Set volumes
-	for channel, vol := range p.Volumes {
-		if err := au.SetVolume(ctx, "sink", channel, vol.Level); err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("set volume %s=%d%%: %w", channel, vol.Level, err))
-		} else {
-			result.Applied = append(result.Applied, fmt.Sprintf("Volume %s → %d%%", channel, vol.Level))
+	for _, si := range inputs {
+		if target, ok := routeTarget(p, si); ok {
+			steps = append(steps, applyStep{
+				desc:    fmt.Sprintf("Route %s → %s", si.AppName, target),
+				command: fmt.Sprintf("pactl move-sink-input %d %s", si.Index, target),
+				needs:   "sink " + target,
+				follows: p.AppRoutes[si.AppName] == "follow_default",
+				from:    constant(si.SinkName),
+				to:      target,
+				run: func(ctx context.Context, au audio.Service) error {
+					return wrapf(au.MoveSinkInput(ctx, si.Index, target), "route %s→%s", si.AppName, target)
+				},
+			})
+		}
+		// Every stream of the app is set, so an app with several streams
+		// ends up uniform.
+		if vol, ok := p.AppVolumes[si.AppName]; ok {
+			steps = append(steps, applyStep{
+				desc:    fmt.Sprintf("App volume %s → %d%%", si.AppName, vol.Level),
+				command: fmt.Sprintf("pactl set-sink-input-volume %d %d%%", si.Index, vol.Level),
+				from:    constant(fmt.Sprintf("%d%%", si.VolumePercent())),
+				to:      fmt.Sprintf("%d%%", vol.Level),
+				run: func(ctx context.Context, au audio.Service) error {
+					return wrapf(au.SetSinkInputVolume(ctx, si.Index, vol.Level), "set app volume %s=%d%%", si.AppName, vol.Level)
+				},
+			}, applyStep{
+				desc:    fmt.Sprintf("App mute %s → %s", si.AppName, muteLabel(vol.Muted)),
+				command: fmt.Sprintf("pactl set-sink-input-mute %d %s", si.Index, boolDigit(vol.Muted)),
+				from:    constant(muteLabel(si.Muted)),
+				to:      muteLabel(vol.Muted),
+				run: func(ctx context.Context, au audio.Service) error {
+					return wrapf(au.SetSinkInputMute(ctx, si.Index, vol.Muted), "set app mute %s=%t", si.AppName, vol.Muted)
+				},
+			})
 		}
-		// Toggle mute if needed (we always set it to target state)
-		// Note: pactl set-sink-mute takes 1/0/toggle — we use explicit value
 	}
+	return steps
+}
+
+func constant(v string) func(Preset) string {
+	return func(Preset) string { return v }
+}
+
+func boolDigit(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// routeTarget returns the sink a stream should move to, if it is not
+// already routed correctly.
+func routeTarget(p Preset, si audio.SinkInput) (string, bool) {
+	targetSink, ok := p.AppRoutes[si.AppName]
+	if !ok {
+		return "", false
+	}
+	if targetSink == "follow_default" {
+		targetSink = p.DefaultSink
+	}
+	if targetSink == "" || targetSink == si.SinkName {
+		return "", false
+	}
+	return targetSink, true
+}
 
-	// 4) App routing
-	if len(p.AppRoutes) > 0 {
-		inputs, err := au.ListSinkInputs(ctx)
+// devicesPresent reports whether every sink and source p names is listed.
+// When the lists cannot be read there is nothing to wait for; the steps
+// report the failure.
+func devicesPresent(ctx context.Context, au audio.Service, p Preset) bool {
+	sinks, err := au.ListSinksDetailed(ctx)
+	if err != nil {
+		return true
+	}
+	sources, err := au.ListSourcesDetailed(ctx)
+	if err != nil {
+		return true
+	}
+	present := map[string]bool{}
+	for _, d := range sinks {
+		present["sink "+d.Name] = true
+	}
+	for _, d := range sources {
+		present["source "+d.Name] = true
+	}
+	missing := func(kind, name string) bool {
+		return name != "" && !strings.HasPrefix(name, TargetPrefix) && !present[kind+" "+name]
+	}
+	if missing("sink", p.DefaultSink) {
+		return false
+	}
+	for name := range p.Volumes {
+		if missing("sink", name) {
+			return false
+		}
+	}
+	for name := range p.SourceVolumes {
+		if missing("source", name) {
+			return false
+		}
+	}
+	for _, sink := range p.AppRoutes {
+		if sink != "follow_default" && missing("sink", sink) {
+			return false
+		}
+	}
+	_, unresolved := resolveTargets(ctx, au, p, "sink", "source")
+	return len(unresolved) == 0
+}
+
+// poll calls done every interval until it returns true, the deadline
+// passes or ctx is cancelled.
+func poll(ctx context.Context, deadline time.Time, interval time.Duration, done func() bool) {
+	for !done() && sleepUntil(ctx, deadline, interval) {
+	}
+}
+
+// sleepUntil sleeps for interval and reports whether the caller may try
+// again: false once the deadline has passed or ctx is cancelled.
+func sleepUntil(ctx context.Context, deadline time.Time, interval time.Duration) bool {
+	if !time.Now().Add(interval).Before(deadline) {
+		return false
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(interval):
+		return true
+	}
+}
+
+// NameResolver maps a name written in a preset to the current name of a
+// "sink", "source" or "card", e.g. by expanding a user-defined alias.
+type NameResolver func(ctx context.Context, kind string, name string) (string, error)
+
+// ResolveNames returns a copy of p with every sink, source and card name
+// passed through resolve. A name that fails to resolve is kept as written
+// and reported. "@target" references are left for Apply.
+func ResolveNames(ctx context.Context, p Preset, resolve NameResolver) (Preset, []error) {
+	var errs []error
+	name := func(kind, n string) string {
+		if strings.HasPrefix(n, TargetPrefix) {
+			return n
+		}
+		resolved, err := resolve(ctx, kind, n)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("list sink inputs: %w", err))
-		} else {
-			for _, si := range inputs {
-				targetSink, ok := p.AppRoutes[si.AppName]
-				if !ok {
-					continue
-				}
-				if targetSink == "follow_default" {
-					targetSink = p.DefaultSink
-				}
-				if targetSink == "" || targetSink == si.SinkName {
-					continue // already routed correctly
-				}
-				if err := au.MoveSinkInput(ctx, si.Index, targetSink); err != nil {
-					result.Errors = append(result.Errors, fmt.Errorf("route %s→%s: %w", si.AppName, targetSink, err))
-				} else {
-					result.Applied = append(result.Applied, fmt.Sprintf("Route %s → %s", si.AppName, targetSink))
-				}
-			}
+			errs = append(errs, fmt.Errorf("resolve %s %s: %w", kind, n, err))
+			return n
 		}
+		return resolved
+	}
+	rekey := func(kind string, m map[string]VolumeSpec) map[string]VolumeSpec {
+		if m == nil {
+			return nil
+		}
+		out := make(map[string]VolumeSpec, len(m))
+		for k, v := range m {
+			out[name(kind, k)] = v
+		}
+		return out
 	}
 
-	return result
+	out := p
+	if p.CardProfiles != nil {
+		out.CardProfiles = make(map[string]string, len(p.CardProfiles))
+		for card, profile := range p.CardProfiles {
+			out.CardProfiles[name("card", card)] = profile
+		}
+	}
+	if p.DefaultSink != "" {
+		out.DefaultSink = name("sink", p.DefaultSink)
+	}
+	out.Volumes = rekey("sink", p.Volumes)
+	out.SourceVolumes = rekey("source", p.SourceVolumes)
+	if p.AppRoutes != nil {
+		out.AppRoutes = make(map[string]string, len(p.AppRoutes))
+		for app, sink := range p.AppRoutes {
+			if sink != "follow_default" {
+				sink = name("sink", sink)
+			}
+			out.AppRoutes[app] = sink
+		}
+	}
+	return out, errs
 }
 
 // Diff computes the changes that would occur if the preset were applied,
-// given the current state.
+// given the current state. target is taken as is: resolve the presets it
+// extends first, see Resolve.
 func Diff(current, target Preset) []DiffLine {
 	var diffs []DiffLine
 
@@ -103,28 +483,13 @@ func Diff(current, target Preset) []DiffLine {
 	}
 
 	// Volumes
-	for ch, targetVol := range target.Volumes {
-		currentVol := current.Volumes[ch]
-		if currentVol.Level != targetVol.Level {
-			diffs = append(diffs, DiffLine{
-				Field: fmt.Sprintf("%s volume", ch),
-				From:  fmt.Sprintf("%d%%", currentVol.Level),
-				To:    fmt.Sprintf("%d%%", targetVol.Level),
-			})
-		}
-		if currentVol.Muted != targetVol.Muted {
-			muteLabel := func(m bool) string {
-				if m {
-					return "muted"
-				}
-				return "unmuted"
-			}
-			diffs = append(diffs, DiffLine{
-				Field: fmt.Sprintf("%s mute", ch),
-				From:  muteLabel(currentVol.Muted),
-				To:    muteLabel(targetVol.Muted),
-			})
-		}
+	diffs = append(diffs, diffVolumes(current.Volumes, target.Volumes)...)
+	diffs = append(diffs, diffVolumes(current.SourceVolumes, target.SourceVolumes)...)
+
+	// Per-app volumes
+	for _, d := range diffVolumes(current.AppVolumes, target.AppVolumes) {
+		d.Field = "app " + d.Field
+		diffs = append(diffs, d)
 	}
 
 	// App routes
@@ -141,3 +506,32 @@ func Diff(current, target Preset) []DiffLine {
 
 	return diffs
 }
+
+func diffVolumes(current, target map[string]VolumeSpec) []DiffLine {
+	var diffs []DiffLine
+	for name, targetVol := range target {
+		currentVol := current[name]
+		if currentVol.Level != targetVol.Level {
+			diffs = append(diffs, DiffLine{
+				Field: fmt.Sprintf("%s volume", name),
+				From:  fmt.Sprintf("%d%%", currentVol.Level),
+				To:    fmt.Sprintf("%d%%", targetVol.Level),
+			})
+		}
+		if currentVol.Muted != targetVol.Muted {
+			diffs = append(diffs, DiffLine{
+				Field: fmt.Sprintf("%s mute", name),
+				From:  muteLabel(currentVol.Muted),
+				To:    muteLabel(targetVol.Muted),
+			})
+		}
+	}
+	return diffs
+}
+
+func muteLabel(m bool) string {
+	if m {
+		return "muted"
+	}
+	return "unmuted"
+}
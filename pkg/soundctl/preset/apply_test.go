@@ -2,10 +2,16 @@ package preset
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"soundctl/pkg/soundctl/audio"
 	"soundctl/pkg/soundctl/exec"
+	"soundctl/pkg/soundctl/pulse"
+	"soundctl/pkg/soundctl/pulse/pulsetest"
 )
 
 func fakeAudioService(runner *exec.FakeRunner) audio.Service {
@@ -24,7 +30,7 @@ func TestApplyCardProfiles(t *testing.T) {
 		DefaultSink:  "bt-sink",
 	}
 
-	result := Apply(context.Background(), au, p)
+	result := ApplyWith(context.Background(), au, p, ApplyOptions{})
 	if len(result.Errors) > 0 {
 		t.Fatalf("unexpected errors: %v", result.Errors)
 	}
@@ -36,6 +42,7 @@ func TestApplyCardProfiles(t *testing.T) {
 func TestApplyVolumes(t *testing.T) {
 	runner := exec.NewFakeRunner()
 	runner.Set("pactl", []string{"set-sink-volume", "master", "80%"}, exec.CommandResult{})
+	runner.Set("pactl", []string{"set-sink-mute", "master", "0"}, exec.CommandResult{})
 
 	au := fakeAudioService(runner)
 	p := Preset{
@@ -43,12 +50,71 @@ func TestApplyVolumes(t *testing.T) {
 		Volumes: map[string]VolumeSpec{"master": {Level: 80}},
 	}
 
-	result := Apply(context.Background(), au, p)
+	result := ApplyWith(context.Background(), au, p, ApplyOptions{})
 	if len(result.Errors) > 0 {
 		t.Fatalf("unexpected errors: %v", result.Errors)
 	}
 }
 
+func TestApplySourceVolumesAndMute(t *testing.T) {
+	runner := exec.NewFakeRunner()
+	runner.Set("pactl", []string{"set-sink-volume", "bt-sink", "60%"}, exec.CommandResult{})
+	runner.Set("pactl", []string{"set-sink-mute", "bt-sink", "1"}, exec.CommandResult{})
+	runner.Set("pactl", []string{"set-source-volume", "bt-mic", "90%"}, exec.CommandResult{})
+	runner.Set("pactl", []string{"set-source-mute", "bt-mic", "0"}, exec.CommandResult{})
+
+	au := fakeAudioService(runner)
+	p := Preset{
+		Name:          "Call",
+		Volumes:       map[string]VolumeSpec{"bt-sink": {Level: 60, Muted: true}},
+		SourceVolumes: map[string]VolumeSpec{"bt-mic": {Level: 90, Muted: false}},
+	}
+
+	result := ApplyWith(context.Background(), au, p, ApplyOptions{})
+	if len(result.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+	if len(result.Applied) != 4 {
+		t.Fatalf("expected 4 applied changes, got %d: %v", len(result.Applied), result.Applied)
+	}
+}
+
+func TestApplyTwiceIsNoOp(t *testing.T) {
+	srv, au := pulsetest.NewService(t, pulse.FakeState{
+		Sinks: []pulse.DeviceInfo{{
+			Index: 1, Name: "bt-sink", ChannelMap: []uint8{1, 2},
+			Volume: []uint32{pulse.VolumeNorm, pulse.VolumeNorm}, MonitorIndex: pulse.InvalidIndex,
+		}},
+	})
+	p := Preset{
+		Name:    "Quiet",
+		Volumes: map[string]VolumeSpec{"bt-sink": {Level: 60, Muted: true}},
+	}
+
+	if result := Apply(context.Background(), au, p); len(result.Errors) > 0 {
+		t.Fatalf("first apply: %v", result.Errors)
+	}
+	first := srv.State()
+	if result := Apply(context.Background(), au, p); len(result.Errors) > 0 {
+		t.Fatalf("second apply: %v", result.Errors)
+	}
+	second := srv.State()
+
+	// Both runs must set the same absolute state; a toggle would flip the
+	// mute back.
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("state changed on the second apply:\n first %+v\nsecond %+v", first.Sinks, second.Sinks)
+	}
+	if !second.Sinks[0].Mute {
+		t.Fatal("expected the sink to stay muted")
+	}
+	for _, call := range srv.Calls() {
+		if strings.Contains(call, "toggle") {
+			t.Fatalf("apply must not toggle mute: %q", call)
+		}
+	}
+}
+
 func TestApplyAppRoutes(t *testing.T) {
 	runner := exec.NewFakeRunner()
 
@@ -72,7 +138,7 @@ func TestApplyAppRoutes(t *testing.T) {
 		AppRoutes:   map[string]string{"Firefox": "bt-sink"},
 	}
 
-	result := Apply(context.Background(), au, p)
+	result := ApplyWith(context.Background(), au, p, ApplyOptions{})
 	if len(result.Errors) > 0 {
 		t.Fatalf("unexpected errors: %v", result.Errors)
 	}
@@ -89,6 +155,40 @@ func TestApplyAppRoutes(t *testing.T) {
 	}
 }
 
+func TestApplyAppVolumes(t *testing.T) {
+	runner := exec.NewFakeRunner()
+
+	runner.Set("pactl", []string{"list", "sink-inputs"}, exec.CommandResult{
+		Output: "Sink Input #57\n\tSink: 1\n\tProperties:\n\t\tapplication.name = \"Firefox\"\n" +
+			"Sink Input #58\n\tSink: 1\n\tProperties:\n\t\tapplication.name = \"Spotify\"\n",
+	})
+	runner.Set("pactl", []string{"list", "short", "sinks"}, exec.CommandResult{
+		Output: "1\tsink\tdriver\tspec\tRUNNING",
+	})
+	runner.Set("pactl", []string{"set-sink-input-volume", "57", "30%"}, exec.CommandResult{})
+	runner.Set("pactl", []string{"set-sink-input-mute", "57", "1"}, exec.CommandResult{})
+
+	au := fakeAudioService(runner)
+	p := Preset{
+		Name:       "Quiet",
+		AppVolumes: map[string]VolumeSpec{"Firefox": {Level: 30, Muted: true}},
+	}
+
+	result := ApplyWith(context.Background(), au, p, ApplyOptions{})
+	if len(result.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+	want := []string{
+		"pactl list sink-inputs",
+		"pactl list short sinks",
+		"pactl set-sink-input-volume 57 30%",
+		"pactl set-sink-input-mute 57 1",
+	}
+	if got := runner.Calls(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected calls:\n got %v\nwant %v", got, want)
+	}
+}
+
 func TestApplyFollowDefault(t *testing.T) {
 	runner := exec.NewFakeRunner()
 
@@ -108,12 +208,49 @@ func TestApplyFollowDefault(t *testing.T) {
 		AppRoutes:   map[string]string{"Spotify": "follow_default"},
 	}
 
-	result := Apply(context.Background(), au, p)
+	result := ApplyWith(context.Background(), au, p, ApplyOptions{})
 	if len(result.Errors) > 0 {
 		t.Fatalf("unexpected errors: %v", result.Errors)
 	}
 }
 
+func TestResolveNames(t *testing.T) {
+	aliases := map[string]string{"desk": "alsa_output.usb-dock", "cans": "bluez_output.sony", "cans-card": "bluez_card.sony"}
+	resolve := func(_ context.Context, kind string, name string) (string, error) {
+		if name == "gone" {
+			return "", fmt.Errorf("no %s matches %q", kind, name)
+		}
+		if target, ok := aliases[name]; ok {
+			return target, nil
+		}
+		return name, nil
+	}
+	p := Preset{
+		Name:         "Desk",
+		CardProfiles: map[string]string{"cans-card": "a2dp-sink"},
+		DefaultSink:  "desk",
+		Volumes:      map[string]VolumeSpec{"cans": {Level: 40}, "gone": {Level: 10}},
+		AppRoutes:    map[string]string{"Firefox": "cans", "Zoom": "follow_default"},
+	}
+	got, errs := ResolveNames(context.Background(), p, resolve)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "gone") {
+		t.Fatalf("expected one error for the unresolvable sink, got %v", errs)
+	}
+	want := Preset{
+		Name:         "Desk",
+		CardProfiles: map[string]string{"bluez_card.sony": "a2dp-sink"},
+		DefaultSink:  "alsa_output.usb-dock",
+		Volumes:      map[string]VolumeSpec{"bluez_output.sony": {Level: 40}, "gone": {Level: 10}},
+		AppRoutes:    map[string]string{"Firefox": "bluez_output.sony", "Zoom": "follow_default"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected preset:\n got %+v\nwant %+v", got, want)
+	}
+	if p.DefaultSink != "desk" {
+		t.Fatal("ResolveNames modified its input")
+	}
+}
+
 func TestDiff(t *testing.T) {
 	current := Preset{
 		CardProfiles: map[string]string{"card1": "a2dp"},
@@ -145,6 +282,23 @@ func TestDiff(t *testing.T) {
 	}
 }
 
+func TestDiffSourceVolumes(t *testing.T) {
+	current := Preset{
+		SourceVolumes: map[string]VolumeSpec{"mic": {Level: 100, Muted: true}},
+	}
+	target := Preset{
+		SourceVolumes: map[string]VolumeSpec{"mic": {Level: 80, Muted: false}},
+	}
+
+	diffs := Diff(current, target)
+	if len(diffs) != 2 {
+		t.Fatalf("expected 2 diffs, got %d: %+v", len(diffs), diffs)
+	}
+	if Diff(target, target) != nil {
+		t.Fatal("expected no diffs for identical source volumes")
+	}
+}
+
 func TestDiffNoChanges(t *testing.T) {
 	p := Preset{
 		CardProfiles: map[string]string{"card1": "a2dp"},
@@ -155,3 +309,78 @@ func TestDiffNoChanges(t *testing.T) {
 		t.Fatalf("expected 0 diffs for identical presets, got %d", len(diffs))
 	}
 }
+
+func TestApplyWaitsForSinksAfterProfileSwitch(t *testing.T) {
+	const (
+		card    = "bluez_card.08_FF_44_2B_4C_90"
+		hfpSink = "bluez_output.08_FF_44_2B_4C_90.headset-head-unit"
+		a2dp    = "bluez_output.08_FF_44_2B_4C_90.1"
+	)
+	sink := func(index uint32, name string) pulse.DeviceInfo {
+		return pulse.DeviceInfo{
+			Index:        index,
+			Name:         name,
+			ChannelMap:   []uint8{1, 2},
+			Volume:       []uint32{pulse.VolumeNorm, pulse.VolumeNorm},
+			MonitorIndex: pulse.InvalidIndex,
+			BaseVolume:   pulse.VolumeNorm,
+		}
+	}
+	srv, au := pulsetest.NewService(t, pulse.FakeState{
+		Info:  pulse.ServerInfo{DefaultSink: hfpSink},
+		Sinks: []pulse.DeviceInfo{sink(47, hfpSink)},
+		Cards: []pulse.CardInfo{{
+			Index:         3,
+			Name:          card,
+			Profiles:      []pulse.CardProfileInfo{{Name: "a2dp-sink", Available: true}, {Name: "headset-head-unit", Available: true}},
+			ActiveProfile: "headset-head-unit",
+		}},
+	})
+
+	// Like bluez, replace the card's sink some time after the switch.
+	go func() {
+		for len(srv.Calls()) == 0 {
+			time.Sleep(5 * time.Millisecond)
+		}
+		time.Sleep(50 * time.Millisecond)
+		srv.Update(func(st *pulse.FakeState) { st.Sinks = []pulse.DeviceInfo{sink(48, a2dp)} })
+	}()
+
+	p := Preset{
+		CardProfiles: map[string]string{card: "a2dp-sink"},
+		DefaultSink:  a2dp,
+		Volumes:      map[string]VolumeSpec{a2dp: {Level: 40}},
+	}
+	result := ApplyWith(context.Background(), au, p, ApplyOptions{Wait: 2 * time.Second, Interval: 10 * time.Millisecond, Verify: true})
+	if !result.OK() || len(result.Drift) != 0 {
+		t.Fatalf("expected convergence, got errors %v, drift %v", result.Errors, result.Drift)
+	}
+	if got := srv.State().Info.DefaultSink; got != a2dp {
+		t.Fatalf("default sink = %s, want %s", got, a2dp)
+	}
+}
+
+func TestApplyReportsDrift(t *testing.T) {
+	runner := exec.NewFakeRunner()
+	runner.Set("pactl", []string{"set-sink-volume", "bt-sink", "40%"}, exec.CommandResult{})
+	runner.Set("pactl", []string{"set-sink-mute", "bt-sink", "0"}, exec.CommandResult{})
+	// The sink ignores the new volume.
+	runner.Set("pactl", []string{"info"}, exec.CommandResult{Output: "Default Sink: bt-sink"})
+	runner.Set("pactl", []string{"list", "cards"}, exec.CommandResult{})
+	runner.Set("pactl", []string{"list", "sink-inputs"}, exec.CommandResult{})
+	runner.Set("pactl", []string{"list", "short", "sinks"}, exec.CommandResult{Output: "1\tbt-sink\tbluez5\tspec\tRUNNING"})
+	runner.Set("pactl", []string{"list", "sources"}, exec.CommandResult{})
+	runner.Set("pactl", []string{"list", "sinks"}, exec.CommandResult{
+		Output: "Sink #1\n\tName: bt-sink\n\tMute: no\n\tVolume: front-left: 32768 /  50% / -18.06 dB,   front-right: 32768 /  50% / -18.06 dB",
+	})
+
+	p := Preset{Volumes: map[string]VolumeSpec{"bt-sink": {Level: 40}}}
+	result := ApplyWith(context.Background(), fakeAudioService(runner), p, ApplyOptions{Verify: true})
+	want := []DiffLine{{Field: "bt-sink volume", From: "50%", To: "40%"}}
+	if !reflect.DeepEqual(result.Drift, want) {
+		t.Fatalf("unexpected drift: %+v", result.Drift)
+	}
+	if result.OK() || len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "verify: 1 setting(s) differ") {
+		t.Fatalf("expected a failed verification, got %v", result.Errors)
+	}
+}
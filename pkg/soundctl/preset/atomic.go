@@ -0,0 +1,35 @@
+package preset
+
+import (
+	"context"
+	"fmt"
+
+	"soundctl/pkg/soundctl/audio"
+)
+
+// AtomicResult reports a transactional apply.
+type AtomicResult struct {
+	ApplyResult             // the preset's steps, ending with the verification
+	Before      Preset      // the state captured before applying
+	RolledBack  bool        // whether Before was restored
+	Rollback    ApplyResult // the steps that restored Before
+}
+
+// ApplyAtomic applies p all-or-nothing with the given options, which
+// should verify: it snapshots the live state, applies p and, when a step
+// failed, a target did not resolve or the state drifted, restores the
+// snapshot. An error means the initial snapshot failed and nothing was
+// changed.
+func ApplyAtomic(ctx context.Context, au audio.Service, p Preset, opts ApplyOptions) (AtomicResult, error) {
+	before, err := SnapshotCurrent(ctx, au)
+	if err != nil {
+		return AtomicResult{}, fmt.Errorf("snapshot before apply: %w", err)
+	}
+	result := AtomicResult{Before: before, ApplyResult: ApplyWith(ctx, au, p, opts)}
+	if result.OK() {
+		return result, nil
+	}
+	result.RolledBack = true
+	result.Rollback = ApplyWith(ctx, au, before, opts)
+	return result, nil
+}
@@ -0,0 +1,125 @@
+package preset
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/pulse"
+	"soundctl/pkg/soundctl/pulse/pulsetest"
+)
+
+const (
+	atomicBTSink   = "bluez_output.08_FF_44_2B_4C_90.1"
+	atomicDockSink = "alsa_output.usb-Dock_DAC-00.analog-stereo"
+)
+
+// newAtomicFixture serves a headset and a dock DAC from a stateful fake
+// server, so that verification and rollback observe real changes.
+func newAtomicFixture(t *testing.T) (*pulse.FakeServer, audio.Service) {
+	t.Helper()
+	sink := func(index uint32, name string, percent int) pulse.DeviceInfo {
+		return pulse.DeviceInfo{
+			Index:        index,
+			Name:         name,
+			SampleSpec:   pulse.SampleSpec{Format: 3, Channels: 2, Rate: 48000},
+			ChannelMap:   []uint8{1, 2},
+			Volume:       []uint32{pulse.VolumeFromPercent(percent), pulse.VolumeFromPercent(percent)},
+			MonitorIndex: pulse.InvalidIndex,
+			BaseVolume:   pulse.VolumeNorm,
+		}
+	}
+	return pulsetest.NewService(t, pulse.FakeState{
+		Info:  pulse.ServerInfo{DefaultSink: atomicBTSink},
+		Sinks: []pulse.DeviceInfo{sink(47, atomicBTSink, 50), sink(49, atomicDockSink, 80)},
+		Cards: []pulse.CardInfo{{
+			Index: 3,
+			Name:  "bluez_card.08_FF_44_2B_4C_90",
+			Profiles: []pulse.CardProfileInfo{
+				{Name: "a2dp-sink", Available: true},
+				{Name: "headset-head-unit", Available: true},
+			},
+			ActiveProfile: "a2dp-sink",
+		}},
+	})
+}
+
+func TestApplyAtomicCommits(t *testing.T) {
+	srv, au := newAtomicFixture(t)
+	p := Preset{
+		CardProfiles: map[string]string{"bluez_card.08_FF_44_2B_4C_90": "headset-head-unit"},
+		DefaultSink:  atomicDockSink,
+		Volumes:      map[string]VolumeSpec{atomicDockSink: {Level: 30}},
+	}
+	result, err := ApplyAtomic(context.Background(), au, p, DefaultApplyOptions())
+	if err != nil {
+		t.Fatalf("ApplyAtomic: %v", err)
+	}
+	if !result.OK() || result.RolledBack || len(result.Drift) != 0 {
+		t.Fatalf("expected a clean apply, got %+v", result)
+	}
+	if last := result.Steps[len(result.Steps)-1]; last.Step != "Verify" || last.Err != nil {
+		t.Fatalf("expected a successful verification step last, got %+v", last)
+	}
+	if st := srv.State(); st.Info.DefaultSink != atomicDockSink || st.Cards[0].ActiveProfile != "headset-head-unit" {
+		t.Fatalf("preset not applied: %+v", st.Info)
+	}
+}
+
+func TestApplyAtomicRollsBackOnFailure(t *testing.T) {
+	srv, au := newAtomicFixture(t)
+	p := Preset{
+		CardProfiles: map[string]string{"bluez_card.08_FF_44_2B_4C_90": "bogus"},
+		DefaultSink:  atomicDockSink,
+		Volumes:      map[string]VolumeSpec{atomicBTSink: {Level: 20, Muted: true}},
+	}
+	result, err := ApplyAtomic(context.Background(), au, p, DefaultApplyOptions())
+	if err != nil {
+		t.Fatalf("ApplyAtomic: %v", err)
+	}
+	if result.OK() || !result.RolledBack {
+		t.Fatalf("expected a rollback, got %+v", result)
+	}
+	if len(result.Rollback.Errors) != 0 {
+		t.Fatalf("rollback failed: %v", result.Rollback.Errors)
+	}
+	var failed []string
+	for _, step := range result.Steps {
+		if step.Err != nil {
+			failed = append(failed, step.Step)
+		}
+	}
+	// The profile that could not be set also shows up as drift.
+	if len(failed) != 2 || !strings.HasPrefix(failed[0], "Profile bluez_card.08_FF_44_2B_4C_90 → bogus") || failed[1] != "Verify" {
+		t.Fatalf("unexpected failed steps: %v", failed)
+	}
+
+	st := srv.State()
+	if st.Info.DefaultSink != atomicBTSink || st.Cards[0].ActiveProfile != "a2dp-sink" {
+		t.Fatalf("state not restored: default %s, profile %s", st.Info.DefaultSink, st.Cards[0].ActiveProfile)
+	}
+	if bt := st.Sinks[0]; bt.Mute || bt.Volume[0] != pulse.VolumeFromPercent(50) {
+		t.Fatalf("sink volume not restored: %+v", bt)
+	}
+}
+
+func TestVerifyIgnoresAbsentApps(t *testing.T) {
+	live := Preset{
+		DefaultSink: "a",
+		AppRoutes:   map[string]string{"Firefox": "follow_default"},
+		AppVolumes:  map[string]VolumeSpec{"Firefox": {Level: 80}},
+	}
+	expected := Preset{
+		DefaultSink: "a",
+		AppRoutes:   map[string]string{"Firefox": "a", "Zoom": "b"},
+		AppVolumes:  map[string]VolumeSpec{"Firefox": {Level: 80}, "Zoom": {Level: 10}},
+	}
+	if drift := verifyDiff(live, expected); len(drift) != 0 {
+		t.Fatalf("expected no drift, got %+v", drift)
+	}
+	expected.AppRoutes["Firefox"] = "b"
+	if drift := verifyDiff(live, expected); len(drift) != 1 || drift[0].Field != "Firefox route" {
+		t.Fatalf("expected route drift, got %+v", drift)
+	}
+}
@@ -0,0 +1,63 @@
+package preset
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EditDocument renders p as YAML for editing in a text editor, without
+// timestamps and preceded by comment lines explaining the file.
+func EditDocument(p Preset) ([]byte, error) {
+	data, err := yaml.Marshal(Shareable(p))
+	if err != nil {
+		return nil, err
+	}
+	return Annotate(data, p.Name), nil
+}
+
+// Annotate replaces the leading comment lines of an edited document with
+// the explanation EditDocument writes followed by notes, such as the
+// errors of a previous attempt, keeping the rest of the text as it is.
+func Annotate(doc []byte, name string, notes ...string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Editing preset %q. Lines starting with # are ignored.\n", name)
+	fmt.Fprintf(&buf, "# Save and quit to check and store it; an empty file cancels.\n")
+	for _, n := range notes {
+		for _, line := range strings.Split(n, "\n") {
+			fmt.Fprintf(&buf, "# %s\n", line)
+		}
+	}
+	for len(doc) > 0 && doc[0] == '#' {
+		i := bytes.IndexByte(doc, '\n')
+		if i < 0 {
+			doc = nil
+			break
+		}
+		doc = doc[i+1:]
+	}
+	buf.Write(doc)
+	return buf.Bytes()
+}
+
+// ParseEdited reads back a document written by EditDocument. It returns
+// false when the document is empty, which cancels the edit, and checks the
+// structure like Decode does.
+func ParseEdited(data []byte) (Preset, bool, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var p Preset
+	if err := dec.Decode(&p); err != nil {
+		if err == io.EOF {
+			return Preset{}, false, nil
+		}
+		return Preset{}, true, err
+	}
+	if err := checkShared(p); err != nil {
+		return Preset{}, true, err
+	}
+	return Shareable(p), true, nil
+}
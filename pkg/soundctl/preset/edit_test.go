@@ -0,0 +1,56 @@
+package preset
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEditDocumentRoundTrip(t *testing.T) {
+	p := Preset{
+		Name:         "meeting",
+		Extends:      Parents{"base"},
+		CardProfiles: map[string]string{"bluez_card.X": "headset-head-unit"},
+		Volumes:      map[string]VolumeSpec{"bt": {Level: 40}},
+		CreatedAt:    time.Now(),
+	}
+	doc, err := EditDocument(p)
+	if err != nil {
+		t.Fatalf("EditDocument: %v", err)
+	}
+	if !strings.HasPrefix(string(doc), `# Editing preset "meeting".`) || strings.Contains(string(doc), "created_at") {
+		t.Fatalf("unexpected document:\n%s", doc)
+	}
+	got, ok, err := ParseEdited(doc)
+	if err != nil || !ok {
+		t.Fatalf("ParseEdited: %v, %t", err, ok)
+	}
+	// Empty sections come back as empty maps, which apply the same as nil.
+	if got.Name != p.Name || !reflect.DeepEqual(got.Extends, p.Extends) || !reflect.DeepEqual(got.CardProfiles, p.CardProfiles) ||
+		!reflect.DeepEqual(got.Volumes, p.Volumes) || !got.CreatedAt.IsZero() {
+		t.Fatalf("round trip:\n%+v\nwant\n%+v", got, Shareable(p))
+	}
+}
+
+func TestParseEdited(t *testing.T) {
+	if _, ok, err := ParseEdited([]byte("# name: x\n\n")); ok || err != nil {
+		t.Errorf("expected a comment-only document to cancel, got %t, %v", ok, err)
+	}
+	if _, _, err := ParseEdited([]byte("name: x\nvolumes: {bt: {level: 200}}\n")); err == nil || !strings.Contains(err.Error(), "outside") {
+		t.Errorf("expected a volume error, got %v", err)
+	}
+	if _, _, err := ParseEdited([]byte("name: x\ndefault_snk: bt\n")); err == nil {
+		t.Error("expected an unknown key to be rejected")
+	}
+}
+
+func TestAnnotateReplacesComments(t *testing.T) {
+	doc := Annotate([]byte("# old note\n# another\nname: x\n# kept\n"), "x", "Not saved:", "  bad level")
+	want := "# Editing preset \"x\". Lines starting with # are ignored.\n" +
+		"# Save and quit to check and store it; an empty file cancels.\n" +
+		"# Not saved:\n#   bad level\nname: x\n# kept\n"
+	if string(doc) != want {
+		t.Fatalf("unexpected document:\n%s", doc)
+	}
+}
@@ -0,0 +1,200 @@
+package preset
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Parents lists the presets a preset extends, in the order they are
+// layered. In YAML it may be a single name or a list.
+type Parents []string
+
+func (ps *Parents) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*ps = Parents{n.Value}
+		return nil
+	}
+	var names []string
+	if err := n.Decode(&names); err != nil {
+		return err
+	}
+	*ps = names
+	return nil
+}
+
+// Origins records, for each setting of a resolved preset, the name of the
+// preset it came from. Keys are "kind key", e.g. "volume alsa_output.x"
+// or "default_sink".
+type Origins map[string]string
+
+// Of returns the preset a setting came from, or "".
+func (o Origins) Of(kind, key string) string {
+	return o[originKey(kind, key)]
+}
+
+func originKey(kind, key string) string {
+	if key == "" {
+		return kind
+	}
+	return kind + " " + key
+}
+
+// Resolve returns p with the presets it extends layered underneath it:
+// parents are merged in order, each resolved first, and p's own settings
+// win. Maps merge key by key and an empty default sink inherits. all is
+// the set of presets names are looked up in.
+func Resolve(p Preset, all []Preset) (Preset, error) {
+	resolved, _, err := ResolveOrigins(p, all)
+	return resolved, err
+}
+
+// ResolveOrigins is Resolve that also reports where each setting came
+// from.
+func ResolveOrigins(p Preset, all []Preset) (Preset, Origins, error) {
+	r := &resolver{all: map[string]Preset{}, origins: Origins{}}
+	for _, q := range all {
+		r.all[q.Name] = q
+	}
+	out := Preset{Name: p.Name, CreatedAt: p.CreatedAt, UpdatedAt: p.UpdatedAt}
+	if err := r.layer(&out, p, nil); err != nil {
+		return Preset{}, nil, err
+	}
+	return out, r.origins, nil
+}
+
+// Layer merges the resolved layers over base, later layers winning, for
+// applying partial presets (say, only AppRoutes) on top of another.
+func Layer(base Preset, layers ...Preset) Preset {
+	o := Origins{}
+	out := merge(Preset{Name: base.Name, CreatedAt: base.CreatedAt, UpdatedAt: base.UpdatedAt}, base, o)
+	for _, l := range layers {
+		out = merge(out, l, o)
+	}
+	return out
+}
+
+// CycleError reports presets that extend each other in a loop.
+type CycleError struct {
+	Chain []string // e.g. a, b, a
+}
+
+func (e CycleError) Error() string {
+	return "preset inheritance cycle: " + strings.Join(e.Chain, " → ")
+}
+
+type resolver struct {
+	all     map[string]Preset
+	origins Origins
+}
+
+// layer merges p, after its parents, into out. stack holds the chain of
+// presets being resolved, for cycle detection.
+func (r *resolver) layer(out *Preset, p Preset, stack []string) error {
+	for _, name := range stack {
+		if name == p.Name {
+			return CycleError{Chain: append(append([]string(nil), stack...), p.Name)}
+		}
+	}
+	stack = append(stack, p.Name)
+	for _, parent := range p.Extends {
+		q, ok := r.all[parent]
+		if !ok {
+			return fmt.Errorf("preset %q extends unknown preset %q", p.Name, parent)
+		}
+		if err := r.layer(out, q, stack); err != nil {
+			return err
+		}
+	}
+	*out = merge(*out, p, r.origins)
+	return nil
+}
+
+// merge copies the settings p sets over out, without touching out's
+// maps, and records p as their origin.
+func merge(out, p Preset, o Origins) Preset {
+	out.Extends = nil
+	out.Targets = mergeMap(out.Targets, p.Targets, p.Name, "target", o)
+	out.CardProfiles = mergeMap(out.CardProfiles, p.CardProfiles, p.Name, "profile", o)
+	out.Volumes = mergeMap(out.Volumes, p.Volumes, p.Name, "volume", o)
+	out.SourceVolumes = mergeMap(out.SourceVolumes, p.SourceVolumes, p.Name, "source_volume", o)
+	out.AppRoutes = mergeMap(out.AppRoutes, p.AppRoutes, p.Name, "app_route", o)
+	out.AppVolumes = mergeMap(out.AppVolumes, p.AppVolumes, p.Name, "app_volume", o)
+	if p.DefaultSink != "" {
+		out.DefaultSink = p.DefaultSink
+		o[originKey("default_sink", "")] = p.Name
+	}
+	return out
+}
+
+func mergeMap[V any](dst, src map[string]V, from, kind string, o Origins) map[string]V {
+	if src == nil {
+		return dst
+	}
+	out := make(map[string]V, len(dst)+len(src))
+	for k, v := range dst {
+		out[k] = v
+	}
+	for k, v := range src {
+		out[k] = v
+		o[originKey(kind, k)] = from
+	}
+	return out
+}
+
+// Resolve returns the named preset resolved against the store, with the
+// given presets, each resolved too, layered on top in order.
+func (s *Store) Resolve(name string, layers ...string) (Preset, error) {
+	all, err := s.List()
+	if err != nil {
+		return Preset{}, err
+	}
+	resolved := make([]Preset, 0, 1+len(layers))
+	for _, n := range append([]string{name}, layers...) {
+		p, ok := find(all, n)
+		if !ok {
+			return Preset{}, fmt.Errorf("preset %q not found", n)
+		}
+		r, err := Resolve(p, all)
+		if err != nil {
+			return Preset{}, err
+		}
+		resolved = append(resolved, r)
+	}
+	return Layer(resolved[0], resolved[1:]...), nil
+}
+
+func find(all []Preset, name string) (Preset, bool) {
+	for _, p := range all {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// checkInheritance reports the first preset in all that cannot be
+// resolved because it extends itself through a cycle.
+func checkInheritance(all []Preset) error {
+	for _, p := range all {
+		var cycle CycleError
+		if _, err := Resolve(p, all); errors.As(err, &cycle) {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// extendedBy returns the names of the presets in all that extend name.
+func extendedBy(name string, all []Preset) []string {
+	var names []string
+	for _, p := range all {
+		if slices.Contains(p.Extends, name) {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
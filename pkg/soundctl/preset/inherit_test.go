@@ -0,0 +1,124 @@
+package preset
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveLayersParents(t *testing.T) {
+	all := []Preset{
+		{
+			Name:         "meeting",
+			CardProfiles: map[string]string{"bluez_card.X": "headset-head-unit"},
+			DefaultSink:  "bt",
+			Volumes:      map[string]VolumeSpec{"bt": {Level: 60}},
+			AppRoutes:    map[string]string{"Zoom": "bt"},
+		},
+		{Name: "routes", AppRoutes: map[string]string{"Zoom": "dock", "Slack": "dock"}},
+		{Name: "meeting-quiet", Extends: Parents{"meeting", "routes"}, Volumes: map[string]VolumeSpec{"bt": {Level: 30}}},
+	}
+	got, origins, err := ResolveOrigins(all[2], all)
+	if err != nil {
+		t.Fatalf("ResolveOrigins: %v", err)
+	}
+	want := Preset{
+		Name:         "meeting-quiet",
+		CardProfiles: map[string]string{"bluez_card.X": "headset-head-unit"},
+		DefaultSink:  "bt",
+		Volumes:      map[string]VolumeSpec{"bt": {Level: 30}},
+		AppRoutes:    map[string]string{"Zoom": "dock", "Slack": "dock"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected resolved preset:\n%+v\nwant\n%+v", got, want)
+	}
+	for _, c := range []struct{ kind, key, want string }{
+		{"profile", "bluez_card.X", "meeting"},
+		{"default_sink", "", "meeting"},
+		{"volume", "bt", "meeting-quiet"},
+		{"app_route", "Zoom", "routes"},
+	} {
+		if o := origins.Of(c.kind, c.key); o != c.want {
+			t.Errorf("origin of %s %s = %q, want %q", c.kind, c.key, o, c.want)
+		}
+	}
+	if all[0].Volumes["bt"].Level != 60 {
+		t.Fatal("resolving must not modify the parents")
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	all := []Preset{
+		{Name: "a", Extends: Parents{"b"}},
+		{Name: "b", Extends: Parents{"c"}},
+		{Name: "c", Extends: Parents{"a"}},
+		{Name: "orphan", Extends: Parents{"gone"}},
+	}
+	var cycle CycleError
+	if _, err := Resolve(all[0], all); !errors.As(err, &cycle) || err.Error() != "preset inheritance cycle: a → b → c → a" {
+		t.Errorf("expected a cycle error, got %v", err)
+	}
+	if _, err := Resolve(all[3], all); err == nil || !strings.Contains(err.Error(), `extends unknown preset "gone"`) {
+		t.Errorf("expected an unknown parent error, got %v", err)
+	}
+}
+
+func TestStoreInheritance(t *testing.T) {
+	store := tempStore(t)
+	for _, p := range []Preset{
+		{Name: "base", DefaultSink: "bt", Volumes: map[string]VolumeSpec{"bt": {Level: 50}}},
+		{Name: "quiet", Extends: Parents{"base"}, Volumes: map[string]VolumeSpec{"bt": {Level: 20}}},
+		{Name: "dock-routes", AppRoutes: map[string]string{"Firefox": "dock"}},
+	} {
+		if err := store.Save(p); err != nil {
+			t.Fatalf("Save %s: %v", p.Name, err)
+		}
+	}
+
+	got, err := store.Resolve("quiet", "dock-routes")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got.Name != "quiet" || got.DefaultSink != "bt" || got.Volumes["bt"].Level != 20 || got.AppRoutes["Firefox"] != "dock" || got.Extends != nil {
+		t.Fatalf("unexpected resolved preset %+v", got)
+	}
+
+	if err := store.Save(Preset{Name: "base", Extends: Parents{"quiet"}}); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("expected Save to reject a cycle, got %v", err)
+	}
+	if err := store.Delete("base"); err == nil || !strings.Contains(err.Error(), "extended by quiet") {
+		t.Fatalf("expected Delete to refuse, got %v", err)
+	}
+}
+
+func TestParentsAcceptsScalar(t *testing.T) {
+	presets, err := Decode([]byte("version: 1\npresets:\n  - name: a\n  - name: b\n    extends: a\n  - name: c\n    extends: [a, b]\n"))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !reflect.DeepEqual(presets[1].Extends, Parents{"a"}) || !reflect.DeepEqual(presets[2].Extends, Parents{"a", "b"}) {
+		t.Fatalf("unexpected extends: %v, %v", presets[1].Extends, presets[2].Extends)
+	}
+}
+
+func TestImportRenamesParents(t *testing.T) {
+	store := tempStore(t)
+	if err := store.Save(Preset{Name: "base", DefaultSink: "mine"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	incoming := []Preset{{Name: "base", DefaultSink: "theirs"}, {Name: "child", Extends: Parents{"base"}}}
+	if _, err := store.Import(incoming, ConflictRename); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	child, err := store.Resolve("child")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if child.DefaultSink != "theirs" {
+		t.Fatalf("expected child to extend the imported base, got %+v", child)
+	}
+	if incoming[1].Extends[0] != "base" {
+		t.Fatal("Import must not modify its argument")
+	}
+}
@@ -0,0 +1,259 @@
+package preset
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"soundctl/pkg/soundctl/audio"
+)
+
+// TargetPrefix marks a preset entry that names a target instead of a
+// pactl name: "@headphones" refers to Targets["headphones"].
+const TargetPrefix = "@"
+
+// Match selects a sink, source or card by what survives re-pairing a
+// device or moving to another machine, rather than by a pactl name that
+// embeds MACs and ALSA paths. Every set field must match; text compares
+// ignore case.
+type Match struct {
+	Address    string `yaml:"address,omitempty" json:"address,omitempty"`         // bluetooth address
+	Name       string `yaml:"name,omitempty" json:"name,omitempty"`               // device description or bluetooth alias
+	Product    string `yaml:"product,omitempty" json:"product,omitempty"`         // device.product.name
+	FormFactor string `yaml:"form_factor,omitempty" json:"form_factor,omitempty"` // device.form_factor, e.g. headphone, speaker, internal
+	Bus        string `yaml:"bus,omitempty" json:"bus,omitempty"`                 // device.bus, e.g. bluetooth, usb, pci
+}
+
+// IsZero reports whether no field is set. A zero Match matches nothing.
+func (m Match) IsZero() bool {
+	return m == Match{}
+}
+
+// String renders the set fields, e.g. "bus=usb product=Dock DAC".
+func (m Match) String() string {
+	var parts []string
+	for _, f := range []struct{ key, value string }{
+		{"address", m.Address},
+		{"name", m.Name},
+		{"product", m.Product},
+		{"form_factor", m.FormFactor},
+		{"bus", m.Bus},
+	} {
+		if f.value != "" {
+			parts = append(parts, f.key+"="+f.value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Matches reports whether an object with the given name, description and
+// properties satisfies every set field.
+func (m Match) Matches(name, description string, props map[string]string) bool {
+	if m.IsZero() {
+		return false
+	}
+	if m.Address != "" && !strings.EqualFold(audio.ObjectAddress(name, props), audio.NormalizeAddress(m.Address)) {
+		return false
+	}
+	if m.Name != "" && !strings.EqualFold(m.Name, description) && !strings.EqualFold(m.Name, props["device.alias"]) {
+		return false
+	}
+	if m.Product != "" && !strings.EqualFold(m.Product, props["device.product.name"]) {
+		return false
+	}
+	if m.FormFactor != "" && !strings.EqualFold(m.FormFactor, props["device.form_factor"]) {
+		return false
+	}
+	if m.Bus != "" && !strings.EqualFold(m.Bus, objectBus(name, props)) {
+		return false
+	}
+	return true
+}
+
+// UnresolvedTarget is a target reference in a preset that no current
+// sink, source or card satisfies.
+type UnresolvedTarget struct {
+	Target string // label under targets:, without the "@"
+	Kind   string // "sink", "source" or "card"
+	Err    error
+}
+
+func (u UnresolvedTarget) Error() string {
+	return fmt.Sprintf("unresolved %s target %s%s: %v", u.Kind, TargetPrefix, u.Target, u.Err)
+}
+
+// ResolveTargets returns a copy of p with every "@target" reference
+// replaced by the name of the one object its matcher selects now. A
+// reference that matches nothing, or more than one object, is dropped
+// from the copy and listed.
+func ResolveTargets(ctx context.Context, au audio.Service, p Preset) (Preset, []UnresolvedTarget) {
+	return resolveTargets(ctx, au, p, "card", "sink", "source")
+}
+
+// resolveTargets resolves the references to objects of the given kinds
+// only and leaves the others in place. Apply resolves cards before
+// switching profiles and sinks and sources after, since a profile switch
+// renames them.
+func resolveTargets(ctx context.Context, au audio.Service, p Preset, kinds ...string) (Preset, []UnresolvedTarget) {
+	r := &targetResolver{au: au, targets: p.Targets, kinds: kinds, resolved: map[string]string{}}
+	out := p
+	if p.CardProfiles != nil {
+		out.CardProfiles = make(map[string]string, len(p.CardProfiles))
+		for card, profile := range p.CardProfiles {
+			if name, ok := r.name(ctx, "card", card); ok {
+				out.CardProfiles[name] = profile
+			}
+		}
+	}
+	if p.DefaultSink != "" {
+		out.DefaultSink, _ = r.name(ctx, "sink", p.DefaultSink)
+	}
+	rekey := func(kind string, m map[string]VolumeSpec) map[string]VolumeSpec {
+		if m == nil {
+			return nil
+		}
+		out := make(map[string]VolumeSpec, len(m))
+		for k, v := range m {
+			if name, ok := r.name(ctx, kind, k); ok {
+				out[name] = v
+			}
+		}
+		return out
+	}
+	out.Volumes = rekey("sink", p.Volumes)
+	out.SourceVolumes = rekey("source", p.SourceVolumes)
+	if p.AppRoutes != nil {
+		out.AppRoutes = make(map[string]string, len(p.AppRoutes))
+		for app, sink := range p.AppRoutes {
+			if sink == "follow_default" {
+				out.AppRoutes[app] = sink
+			} else if name, ok := r.name(ctx, "sink", sink); ok {
+				out.AppRoutes[app] = name
+			}
+		}
+	}
+	return out, r.unresolved
+}
+
+// targetResolver resolves references for one preset, listing each kind at
+// most once and reporting each unresolved reference once.
+type targetResolver struct {
+	au         audio.Service
+	targets    map[string]Match
+	kinds      []string
+	objects    map[string][]matchObject
+	resolved   map[string]string // "kind@label" → name
+	unresolved []UnresolvedTarget
+}
+
+type matchObject struct {
+	name        string
+	description string
+	props       map[string]string
+}
+
+// name returns what to use in place of ref: ref itself when it is a plain
+// name or belongs to a kind not being resolved, the matched name, or false
+// when the reference cannot be resolved.
+func (r *targetResolver) name(ctx context.Context, kind string, ref string) (string, bool) {
+	label, ok := strings.CutPrefix(ref, TargetPrefix)
+	if !ok || !slices.Contains(r.kinds, kind) {
+		return ref, true
+	}
+	key := kind + TargetPrefix + label
+	if name, ok := r.resolved[key]; ok {
+		return name, name != ""
+	}
+	name, err := r.match(ctx, kind, label)
+	if err != nil {
+		r.unresolved = append(r.unresolved, UnresolvedTarget{Target: label, Kind: kind, Err: err})
+	}
+	r.resolved[key] = name
+	return name, err == nil
+}
+
+func (r *targetResolver) match(ctx context.Context, kind string, label string) (string, error) {
+	m, ok := r.targets[label]
+	if !ok {
+		return "", fmt.Errorf("no target named %q", label)
+	}
+	if m.IsZero() {
+		return "", fmt.Errorf("target has no match fields")
+	}
+	objects, err := r.list(ctx, kind)
+	if err != nil {
+		return "", err
+	}
+	var names []string
+	for _, o := range objects {
+		if m.Matches(o.name, o.description, o.props) {
+			names = append(names, o.name)
+		}
+	}
+	switch len(names) {
+	case 0:
+		return "", fmt.Errorf("no %s matches %s", kind, m)
+	case 1:
+		return names[0], nil
+	default:
+		sort.Strings(names)
+		return "", fmt.Errorf("%s matches %d %ss: %s", m, len(names), kind, strings.Join(names, ", "))
+	}
+}
+
+func (r *targetResolver) list(ctx context.Context, kind string) ([]matchObject, error) {
+	if objects, ok := r.objects[kind]; ok {
+		return objects, nil
+	}
+	var objects []matchObject
+	switch kind {
+	case "card":
+		cards, err := r.au.ListCardsDetailed(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list cards: %w", err)
+		}
+		for _, c := range cards {
+			objects = append(objects, matchObject{name: c.Name, description: c.Properties["device.description"], props: c.Properties})
+		}
+	case "sink", "source":
+		var devices []audio.Device
+		var err error
+		if kind == "sink" {
+			devices, err = r.au.ListSinksDetailed(ctx)
+		} else {
+			devices, err = r.au.ListSourcesDetailed(ctx)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list %ss: %w", kind, err)
+		}
+		for _, d := range devices {
+			// A monitor carries the properties of its sink and would make
+			// every sink matcher ambiguous among sources.
+			if d.MonitorOfSink != "" {
+				continue
+			}
+			objects = append(objects, matchObject{name: d.Name, description: d.Description, props: d.Properties})
+		}
+	default:
+		return nil, fmt.Errorf("invalid kind %q", kind)
+	}
+	if r.objects == nil {
+		r.objects = map[string][]matchObject{}
+	}
+	r.objects[kind] = objects
+	return objects, nil
+}
+
+// objectBus returns device.bus, falling back to "bluetooth" for bluez
+// objects that do not set it.
+func objectBus(name string, props map[string]string) string {
+	if bus := props["device.bus"]; bus != "" {
+		return bus
+	}
+	if audio.ObjectAddress(name, props) != "" {
+		return "bluetooth"
+	}
+	return ""
+}
@@ -0,0 +1,197 @@
+package preset
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"soundctl/pkg/soundctl/exec"
+)
+
+// newMatchRunner lists a bluetooth headset and a USB DAC whose names
+// differ from the ones a portable preset was written on.
+func newMatchRunner() *exec.FakeRunner {
+	runner := exec.NewFakeRunner()
+	runner.Set("pactl", []string{"list", "cards"}, exec.CommandResult{Output: `Card #1
+	Name: bluez_card.08_FF_44_2B_4C_90
+	Driver: module-bluez5-device.c
+	Properties:
+		device.description = "WH-1000XM4"
+		device.bus = "bluetooth"
+		device.form_factor = "headphone"
+		api.bluez5.address = "08:FF:44:2B:4C:90"
+	Active Profile: headset-head-unit
+
+Card #2
+	Name: alsa_card.usb-Dock_DAC-00
+	Driver: module-alsa-card.c
+	Properties:
+		device.description = "Dock DAC"
+		device.bus = "usb"
+		device.product.name = "USB Audio DAC"
+	Active Profile: output:analog-stereo`})
+	runner.Set("pactl", []string{"list", "sinks"}, exec.CommandResult{Output: `Sink #47
+	Name: bluez_output.08_FF_44_2B_4C_90.1
+	Description: WH-1000XM4
+	Properties:
+		device.bus = "bluetooth"
+		device.form_factor = "headphone"
+
+Sink #49
+	Name: alsa_output.usb-Dock_DAC-00.analog-stereo
+	Description: Dock DAC Analog Stereo
+	Properties:
+		device.bus = "usb"
+		device.product.name = "USB Audio DAC"`})
+	runner.Set("pactl", []string{"list", "sources"}, exec.CommandResult{Output: `Source #50
+	Name: alsa_output.usb-Dock_DAC-00.analog-stereo.monitor
+	Monitor of Sink: alsa_output.usb-Dock_DAC-00.analog-stereo
+	Properties:
+		device.bus = "usb"
+		device.product.name = "USB Audio DAC"
+
+Source #51
+	Name: alsa_input.usb-Dock_DAC-00.mono-fallback
+	Description: Dock DAC Mono
+	Monitor of Sink: n/a
+	Properties:
+		device.bus = "usb"
+		device.product.name = "USB Audio DAC"`})
+	return runner
+}
+
+func TestMatchMatches(t *testing.T) {
+	props := map[string]string{"device.product.name": "USB Audio DAC", "device.bus": "usb"}
+	cases := []struct {
+		match Match
+		name  string
+		want  bool
+	}{
+		{Match{Product: "usb audio dac"}, "alsa_output.x", true},
+		{Match{Product: "USB Audio DAC", Bus: "pci"}, "alsa_output.x", false},
+		{Match{Address: "08:ff:44:2b:4c:90"}, "bluez_output.08_FF_44_2B_4C_90.1", true},
+		{Match{Bus: "bluetooth"}, "bluez_output.08_FF_44_2B_4C_90.1", true},
+		{Match{Name: "dock dac"}, "alsa_output.x", true},
+		{Match{}, "alsa_output.x", false},
+	}
+	for _, c := range cases {
+		p := props
+		if strings.HasPrefix(c.name, "bluez_") {
+			p = nil
+		}
+		if got := c.match.Matches(c.name, "Dock DAC", p); got != c.want {
+			t.Errorf("%+v.Matches(%q) = %t, want %t", c.match, c.name, got, c.want)
+		}
+	}
+}
+
+func TestResolveTargets(t *testing.T) {
+	au := fakeAudioService(newMatchRunner())
+	p := Preset{
+		Targets: map[string]Match{
+			"cans": {Address: "08:FF:44:2B:4C:90"},
+			"dock": {Product: "USB Audio DAC", Bus: "usb"},
+			"hdmi": {Bus: "pci"},
+		},
+		CardProfiles:  map[string]string{"@cans": "a2dp-sink"},
+		DefaultSink:   "@dock",
+		Volumes:       map[string]VolumeSpec{"@cans": {Level: 40}, "@hdmi": {Level: 90}, "plain-sink": {Level: 10}},
+		SourceVolumes: map[string]VolumeSpec{"@dock": {Level: 70}},
+		AppRoutes:     map[string]string{"Spotify": "@cans", "Zoom": "@gone", "Firefox": "follow_default"},
+	}
+	got, unresolved := ResolveTargets(context.Background(), au, p)
+
+	wantVolumes := map[string]VolumeSpec{"bluez_output.08_FF_44_2B_4C_90.1": {Level: 40}, "plain-sink": {Level: 10}}
+	if !reflect.DeepEqual(got.Volumes, wantVolumes) {
+		t.Errorf("unexpected volumes: %v", got.Volumes)
+	}
+	if got.CardProfiles["bluez_card.08_FF_44_2B_4C_90"] != "a2dp-sink" {
+		t.Errorf("unexpected card profiles: %v", got.CardProfiles)
+	}
+	if got.DefaultSink != "alsa_output.usb-Dock_DAC-00.analog-stereo" {
+		t.Errorf("unexpected default sink %q", got.DefaultSink)
+	}
+	// The monitor source shares the DAC's properties but is not a candidate.
+	if _, ok := got.SourceVolumes["alsa_input.usb-Dock_DAC-00.mono-fallback"]; !ok {
+		t.Errorf("unexpected source volumes: %v", got.SourceVolumes)
+	}
+	wantRoutes := map[string]string{"Spotify": "bluez_output.08_FF_44_2B_4C_90.1", "Firefox": "follow_default"}
+	if !reflect.DeepEqual(got.AppRoutes, wantRoutes) {
+		t.Errorf("unexpected routes: %v", got.AppRoutes)
+	}
+
+	if len(unresolved) != 2 {
+		t.Fatalf("expected @hdmi and @gone unresolved, got %v", unresolved)
+	}
+	for _, u := range unresolved {
+		switch u.Target {
+		case "hdmi":
+			if !strings.Contains(u.Error(), "no sink matches bus=pci") {
+				t.Errorf("unexpected error: %v", u)
+			}
+		case "gone":
+			if !strings.Contains(u.Error(), `no target named "gone"`) {
+				t.Errorf("unexpected error: %v", u)
+			}
+		default:
+			t.Errorf("unexpected unresolved target %v", u)
+		}
+	}
+}
+
+func TestResolveTargetsAmbiguous(t *testing.T) {
+	runner := newMatchRunner()
+	runner.Set("pactl", []string{"list", "cards"}, exec.CommandResult{Output: `Card #1
+	Name: bluez_card.08_FF_44_2B_4C_90
+	Properties:
+		device.form_factor = "headphone"
+
+Card #3
+	Name: bluez_card.AA_BB_CC_DD_EE_FF
+	Properties:
+		device.form_factor = "headphone"`})
+	p := Preset{
+		Targets:      map[string]Match{"wireless": {FormFactor: "headphone"}, "empty": {}},
+		CardProfiles: map[string]string{"@wireless": "a2dp-sink", "@empty": "off"},
+	}
+	got, unresolved := ResolveTargets(context.Background(), fakeAudioService(runner), p)
+	if len(got.CardProfiles) != 0 {
+		t.Errorf("expected unresolved entries to be dropped, got %v", got.CardProfiles)
+	}
+	errs := map[string]string{}
+	for _, u := range unresolved {
+		errs[u.Target] = u.Error()
+	}
+	if !strings.Contains(errs["wireless"], "form_factor=headphone matches 2 cards: bluez_card.08_FF_44_2B_4C_90, bluez_card.AA_BB_CC_DD_EE_FF") {
+		t.Errorf("unexpected error for @wireless: %q", errs["wireless"])
+	}
+	if !strings.Contains(errs["empty"], "no match fields") {
+		t.Errorf("unexpected error for @empty: %q", errs["empty"])
+	}
+}
+
+func TestApplyTargets(t *testing.T) {
+	runner := newMatchRunner()
+	runner.Set("pactl", []string{"set-card-profile", "bluez_card.08_FF_44_2B_4C_90", "a2dp-sink"}, exec.CommandResult{})
+	runner.Set("pactl", []string{"set-default-sink", "bluez_output.08_FF_44_2B_4C_90.1"}, exec.CommandResult{})
+	runner.Set("pactl", []string{"set-sink-volume", "bluez_output.08_FF_44_2B_4C_90.1", "40%"}, exec.CommandResult{})
+	runner.Set("pactl", []string{"set-sink-mute", "bluez_output.08_FF_44_2B_4C_90.1", "0"}, exec.CommandResult{})
+
+	p := Preset{
+		Targets:      map[string]Match{"cans": {Address: "08:FF:44:2B:4C:90"}, "tv": {Bus: "hdmi"}},
+		CardProfiles: map[string]string{"@cans": "a2dp-sink"},
+		DefaultSink:  "@cans",
+		Volumes:      map[string]VolumeSpec{"@cans": {Level: 40}, "@tv": {Level: 80}},
+	}
+	result := ApplyWith(context.Background(), fakeAudioService(runner), p, ApplyOptions{})
+	if len(result.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+	if len(result.Applied) != 4 {
+		t.Fatalf("expected 4 applied changes, got %v", result.Applied)
+	}
+	if len(result.Unresolved) != 1 || result.Unresolved[0].Target != "tv" || result.Unresolved[0].Kind != "sink" {
+		t.Fatalf("expected @tv unresolved, got %v", result.Unresolved)
+	}
+}
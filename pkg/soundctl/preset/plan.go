@@ -0,0 +1,105 @@
+package preset
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"soundctl/pkg/soundctl/audio"
+)
+
+// PlanStep is one call applying a preset would make.
+type PlanStep struct {
+	ID        int    // 1-based position in execution order
+	Step      string // as ApplyResult reports it
+	Command   string // the equivalent pactl command
+	DependsOn []int  // IDs of the steps that must succeed first
+	From      string // current value; "" when the object does not exist yet
+	To        string // value the step sets
+}
+
+// Changes reports whether the step changes the live state.
+func (s PlanStep) Changes() bool {
+	return s.From != s.To
+}
+
+// Plan is what applying a preset would do, computed without changing
+// anything.
+type Plan struct {
+	Preset     string
+	Steps      []PlanStep
+	Diff       []DiffLine         // changes against the live state
+	Unresolved []UnresolvedTarget // targets no single object matches now
+}
+
+// MakePlan snapshots the live state and lists, in execution order, the
+// steps Apply would take for p. Sink and source targets are resolved
+// against the current devices; a profile switch in the plan may rename
+// them by the time Apply resolves them.
+func MakePlan(ctx context.Context, au audio.Service, p Preset) (Plan, error) {
+	live, err := SnapshotCurrent(ctx, au)
+	if err != nil {
+		return Plan{}, fmt.Errorf("snapshot: %w", err)
+	}
+	resolved, unresolved := ResolveTargets(ctx, au, p)
+	var inputs []audio.SinkInput
+	if len(resolved.AppRoutes) > 0 || len(resolved.AppVolumes) > 0 {
+		if inputs, err = au.ListSinkInputs(ctx); err != nil {
+			return Plan{}, fmt.Errorf("list sink inputs: %w", err)
+		}
+	}
+
+	plan := Plan{Preset: p.Name, Diff: verifyDiff(live, resolved), Unresolved: unresolved}
+	profiles := profileSteps(resolved)
+	dependents := dependentSteps(resolved, inputs)
+	defaultID := 0 // the default sink step comes first among the dependents
+	if resolved.DefaultSink != "" {
+		defaultID = len(profiles) + 1
+	}
+	for i, step := range append(profiles, dependents...) {
+		ps := PlanStep{ID: i + 1, Step: step.desc, Command: step.command, From: step.from(live), To: step.to}
+		if i < len(profiles) {
+			plan.Steps = append(plan.Steps, ps)
+			continue
+		}
+		// A step on a sink or source waits for the profile switch of the
+		// card it belongs to; a stream following the default sink also
+		// waits for the default to be set.
+		if _, name, ok := strings.Cut(step.needs, " "); ok {
+			for j, card := range sortedKeys(resolved.CardProfiles) {
+				if cardOwns(card, name) {
+					ps.DependsOn = append(ps.DependsOn, j+1)
+				}
+			}
+		}
+		if step.follows && defaultID != 0 {
+			ps.DependsOn = append(ps.DependsOn, defaultID)
+		}
+		plan.Steps = append(plan.Steps, ps)
+	}
+	return plan, nil
+}
+
+// Changes counts the steps that change the live state.
+func (p Plan) Changes() int {
+	n := 0
+	for _, s := range p.Steps {
+		if s.Changes() {
+			n++
+		}
+	}
+	return n
+}
+
+// cardOwns reports whether a sink or source belongs to a card by pactl
+// naming: "bluez_card.08_FF_44_2B_4C_90" owns
+// "bluez_output.08_FF_44_2B_4C_90.1", "alsa_card.usb-Dock_DAC-00" owns
+// "alsa_output.usb-Dock_DAC-00.analog-stereo".
+func cardOwns(card, object string) bool {
+	_, device, ok := strings.Cut(card, ".")
+	if !ok {
+		return false
+	}
+	_, rest, ok := strings.Cut(object, ".")
+	return ok && (rest == device || strings.HasPrefix(rest, device+"."))
+}
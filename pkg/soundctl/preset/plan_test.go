@@ -0,0 +1,67 @@
+package preset
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"soundctl/pkg/soundctl/pulse"
+)
+
+func TestMakePlan(t *testing.T) {
+	srv, au := newAtomicFixture(t)
+	srv.Update(func(st *pulse.FakeState) {
+		st.SinkInputs = []pulse.SinkInputInfo{{
+			Index:      112,
+			Sink:       47,
+			ChannelMap: []uint8{1, 2},
+			Volume:     []uint32{pulse.VolumeNorm, pulse.VolumeNorm},
+			Properties: pulse.PropList{"application.name": "Firefox"},
+		}}
+	})
+	p := Preset{
+		Name:         "Desk",
+		CardProfiles: map[string]string{"bluez_card.08_FF_44_2B_4C_90": "headset-head-unit"},
+		DefaultSink:  atomicDockSink,
+		Volumes:      map[string]VolumeSpec{atomicBTSink: {Level: 50}},
+		AppRoutes:    map[string]string{"Firefox": "follow_default"},
+	}
+	plan, err := MakePlan(context.Background(), au, p)
+	if err != nil {
+		t.Fatalf("MakePlan: %v", err)
+	}
+	want := []PlanStep{
+		{ID: 1, Step: "Profile bluez_card.08_FF_44_2B_4C_90 → headset-head-unit", Command: "pactl set-card-profile bluez_card.08_FF_44_2B_4C_90 headset-head-unit", From: "a2dp-sink", To: "headset-head-unit"},
+		{ID: 2, Step: "Default sink → " + atomicDockSink, Command: "pactl set-default-sink " + atomicDockSink, From: atomicBTSink, To: atomicDockSink},
+		{ID: 3, Step: "Volume " + atomicBTSink + " → 50%", Command: "pactl set-sink-volume " + atomicBTSink + " 50%", DependsOn: []int{1}, From: "50%", To: "50%"},
+		{ID: 4, Step: "Mute " + atomicBTSink + " → unmuted", Command: "pactl set-sink-mute " + atomicBTSink + " 0", DependsOn: []int{1}, From: "unmuted", To: "unmuted"},
+		{ID: 5, Step: "Route Firefox → " + atomicDockSink, Command: "pactl move-sink-input 112 " + atomicDockSink, DependsOn: []int{2}, From: atomicBTSink, To: atomicDockSink},
+	}
+	if !reflect.DeepEqual(plan.Steps, want) {
+		t.Fatalf("unexpected plan:\n%+v\nwant\n%+v", plan.Steps, want)
+	}
+	if plan.Changes() != 3 || len(plan.Diff) != 3 {
+		t.Fatalf("expected 3 changes, got %d steps and diff %+v", plan.Changes(), plan.Diff)
+	}
+	if calls := srv.Calls(); len(calls) != 0 {
+		t.Fatalf("planning must not change anything, got %v", calls)
+	}
+}
+
+func TestCardOwns(t *testing.T) {
+	cases := []struct {
+		card, object string
+		want         bool
+	}{
+		{"bluez_card.08_FF_44_2B_4C_90", "bluez_output.08_FF_44_2B_4C_90.1", true},
+		{"bluez_card.08_FF_44_2B_4C_90", "bluez_input.08_FF_44_2B_4C_90.0", true},
+		{"alsa_card.pci-0000_00_1f.3", "alsa_output.pci-0000_00_1f.3.analog-stereo", true},
+		{"alsa_card.usb-Dock_DAC-00", "alsa_output.usb-Dock_DAC-001.analog-stereo", false},
+		{"alsa_card.usb-Dock_DAC-00", "bt-sink", false},
+	}
+	for _, c := range cases {
+		if got := cardOwns(c.card, c.object); got != c.want {
+			t.Errorf("cardOwns(%q, %q) = %t, want %t", c.card, c.object, got, c.want)
+		}
+	}
+}
@@ -3,30 +3,46 @@
 package preset
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"gopkg.in/yaml.v3"
+	"soundctl/pkg/soundctl/atomicfile"
 )
 
-// VolumeSpec defines volume+mute state for a single channel.
+// VolumeSpec defines volume+mute state for a single sink or source.
 type VolumeSpec struct {
-	Level int  `yaml:"level"`
-	Muted bool `yaml:"muted"`
+	Level int  `yaml:"level" json:"level"`
+	Muted bool `yaml:"muted" json:"muted"`
 }
 
 // Preset captures a named audio configuration snapshot.
+//
+// Sink, source and card names may be written as "@label" to refer to
+// Targets[label], which is resolved against the live devices when the
+// preset is applied, so the preset keeps working after re-pairing or on
+// another machine.
+//
+// A preset only changes what it sets, so it may be partial, say only
+// AppRoutes, and may extend other presets; see Resolve.
 type Preset struct {
-	Name         string                `yaml:"name"`
-	CardProfiles map[string]string     `yaml:"card_profiles"` // card name → profile name
-	Volumes      map[string]VolumeSpec `yaml:"volumes"`       // channel name → {level, muted}
-	DefaultSink  string                `yaml:"default_sink"`
-	AppRoutes    map[string]string     `yaml:"app_routes"` // app name → sink name | "follow_default"
-	CreatedAt    time.Time             `yaml:"created_at"`
-	UpdatedAt    time.Time             `yaml:"updated_at"`
+	Name          string                `yaml:"name" json:"name"`
+	Extends       Parents               `yaml:"extends,omitempty" json:"extends,omitempty"`               // presets layered underneath, in order
+	Targets       map[string]Match      `yaml:"targets,omitempty" json:"targets,omitempty"`               // label → matcher
+	CardProfiles  map[string]string     `yaml:"card_profiles" json:"card_profiles,omitempty"`             // card name → profile name
+	Volumes       map[string]VolumeSpec `yaml:"volumes" json:"volumes,omitempty"`                         // sink name → {level, muted}
+	SourceVolumes map[string]VolumeSpec `yaml:"source_volumes,omitempty" json:"source_volumes,omitempty"` // source name → {level, muted}
+	DefaultSink   string                `yaml:"default_sink" json:"default_sink,omitempty"`
+	AppRoutes     map[string]string     `yaml:"app_routes" json:"app_routes,omitempty"`             // app name → sink name | "follow_default"
+	AppVolumes    map[string]VolumeSpec `yaml:"app_volumes,omitempty" json:"app_volumes,omitempty"` // app name → {level, muted}
+	CreatedAt     time.Time             `yaml:"created_at,omitempty" json:"created_at,omitzero"`
+	UpdatedAt     time.Time             `yaml:"updated_at,omitempty" json:"updated_at,omitzero"`
 }
 
 // DiffLine describes a single change when applying a preset.
@@ -62,15 +78,45 @@ func (s *Store) Path() string {
 	return s.path
 }
 
+// CurrentVersion is the presets file format this build writes. Files
+// without a version field predate versioning and are version 0.
+const CurrentVersion = 1
+
 // fileData is the YAML root structure.
 type fileData struct {
-	Presets []Preset `yaml:"presets"`
+	Version int      `yaml:"version" json:"version"`
+	Presets []Preset `yaml:"presets" json:"presets"`
+}
+
+// migrations[v] upgrades a decoded version v document to version v+1.
+// They work on the generic document so that they can rename or reshape
+// keys the current Preset type no longer has.
+var migrations = []func(doc map[string]any) error{
+	// 0 → 1: the unversioned format is the first versioned one; only
+	// the version field is new.
+	func(doc map[string]any) error { return nil },
+}
+
+// LockPath returns the advisory lock file guarding the presets file.
+func (s *Store) LockPath() string {
+	return s.path + ".lock"
+}
+
+// BackupPath returns where the previous contents of the presets file are
+// kept after each write.
+func (s *Store) BackupPath() string {
+	return s.path + ".bak"
 }
 
 // List returns all saved presets.
 func (s *Store) List() ([]Preset, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	lock, err := s.lock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
 	return s.readFile()
 }
 
@@ -80,10 +126,8 @@ func (s *Store) Get(name string) (Preset, error) {
 	if err != nil {
 		return Preset{}, err
 	}
-	for _, p := range presets {
-		if p.Name == name {
-			return p, nil
-		}
+	if p, ok := find(presets, name); ok {
+		return p, nil
 	}
 	return Preset{}, fmt.Errorf("preset %q not found", name)
 }
@@ -100,6 +144,12 @@ func (s *Store) Save(p Preset) error {
 	}
 	p.UpdatedAt = now
 
+	lock, err := s.lock(true)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
 	presets, err := s.readFile()
 	if err != nil && !os.IsNotExist(err) {
 		return err
@@ -117,6 +167,9 @@ func (s *Store) Save(p Preset) error {
 	if !found {
 		presets = append(presets, p)
 	}
+	if err := checkInheritance(presets); err != nil {
+		return err
+	}
 
 	return s.writeFile(presets)
 }
@@ -126,6 +179,12 @@ func (s *Store) Delete(name string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	lock, err := s.lock(true)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
 	presets, err := s.readFile()
 	if err != nil {
 		return err
@@ -143,10 +202,21 @@ func (s *Store) Delete(name string) error {
 	if !found {
 		return fmt.Errorf("preset %q not found", name)
 	}
+	if children := extendedBy(name, filtered); len(children) > 0 {
+		return fmt.Errorf("preset %q is extended by %s", name, strings.Join(children, ", "))
+	}
 
 	return s.writeFile(filtered)
 }
 
+// lock takes the cross-process lock; the mutex only covers this process.
+func (s *Store) lock(exclusive bool) (*atomicfile.Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return nil, fmt.Errorf("create config directory: %w", err)
+	}
+	return atomicfile.Acquire(s.LockPath(), exclusive)
+}
+
 func (s *Store) readFile() ([]Preset, error) {
 	data, err := os.ReadFile(s.path)
 	if err != nil {
@@ -158,25 +228,93 @@ func (s *Store) readFile() ([]Preset, error) {
 	if len(data) == 0 {
 		return nil, nil
 	}
-	var fd fileData
-	if err := yaml.Unmarshal(data, &fd); err != nil {
+	fd, err := decodeFile(data, false)
+	if err != nil {
 		return nil, fmt.Errorf("parse presets file: %w", err)
 	}
 	return fd.Presets, nil
 }
 
+// decodeFile parses a presets document, migrating it from older versions.
+// Strict decoding rejects keys that no Preset field takes.
+func decodeFile(data []byte, strict bool) (fileData, error) {
+	var head struct {
+		Version int `yaml:"version"`
+	}
+	if err := yaml.Unmarshal(data, &head); err != nil {
+		return fileData{}, err
+	}
+	switch {
+	case head.Version > CurrentVersion:
+		return fileData{}, fmt.Errorf("version %d is newer than supported version %d", head.Version, CurrentVersion)
+	case head.Version < 0:
+		return fileData{}, fmt.Errorf("invalid version %d", head.Version)
+	case head.Version < CurrentVersion:
+		var err error
+		if data, err = migrate(data, head.Version); err != nil {
+			return fileData{}, err
+		}
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(strict)
+	var fd fileData
+	if err := dec.Decode(&fd); err != nil && err != io.EOF {
+		return fileData{}, err
+	}
+	return fd, nil
+}
+
+// migrate upgrades a document from version to CurrentVersion. A migrated
+// store file is written at the current version on the next save.
+func migrate(data []byte, version int) ([]byte, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+	for v := version; v < CurrentVersion; v++ {
+		if err := migrations[v](doc); err != nil {
+			return nil, fmt.Errorf("migrate from version %d: %w", v, err)
+		}
+		doc["version"] = v + 1
+	}
+	return yaml.Marshal(doc)
+}
+
+// writeFile replaces the presets file atomically: the new contents go to
+// a temporary file in the same directory, which is synced and renamed over
+// the old one, so readers see either the old or the new file and never a
+// truncated one. The previous contents are kept at BackupPath.
 func (s *Store) writeFile(presets []Preset) error {
 	dir := filepath.Dir(s.path)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return fmt.Errorf("create config directory: %w", err)
 	}
-	fd := fileData{Presets: presets}
+	fd := fileData{Version: CurrentVersion, Presets: presets}
 	data, err := yaml.Marshal(&fd)
 	if err != nil {
 		return fmt.Errorf("marshal presets: %w", err)
 	}
-	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+	if err := backupFile(s.path, s.BackupPath()); err != nil {
+		return fmt.Errorf("back up presets file: %w", err)
+	}
+	if err := atomicfile.Replace(s.path, data, 0o644); err != nil {
 		return fmt.Errorf("write presets file: %w", err)
 	}
 	return nil
 }
+
+// backupFile copies path to backup, replacing any older backup. A missing
+// path leaves the backup alone.
+func backupFile(path, backup string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return atomicfile.Replace(backup, data, 0o644)
+}
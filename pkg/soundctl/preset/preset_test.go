@@ -1,8 +1,12 @@
 package preset
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -208,3 +212,93 @@ func TestMultiplePresets(t *testing.T) {
 		t.Fatalf("expected 4 presets, got %d", len(presets))
 	}
 }
+
+func TestReadUnversionedFile(t *testing.T) {
+	store := tempStore(t)
+	legacy := "presets:\n  - name: Old\n    default_sink: sink-a\n"
+	if err := os.WriteFile(store.Path(), []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	presets, err := store.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(presets) != 1 || presets[0].DefaultSink != "sink-a" {
+		t.Fatalf("unexpected presets: %+v", presets)
+	}
+
+	if err := store.Save(Preset{Name: "New"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	data, _ := os.ReadFile(store.Path())
+	if !strings.HasPrefix(string(data), "version: 1\n") {
+		t.Fatalf("expected the file to be written at version 1:\n%s", data)
+	}
+	backup, err := os.ReadFile(store.BackupPath())
+	if err != nil || string(backup) != legacy {
+		t.Fatalf("expected the previous file as backup, got %q (%v)", backup, err)
+	}
+}
+
+func TestReadNewerFileFails(t *testing.T) {
+	store := tempStore(t)
+	if err := os.WriteFile(store.Path(), []byte("version: 99\npresets: []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.List(); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("expected a version error, got %v", err)
+	}
+	if err := store.Save(Preset{Name: "X"}); err == nil {
+		t.Fatal("expected Save to refuse overwriting a newer file")
+	}
+}
+
+func TestWriteLeavesNoTempFiles(t *testing.T) {
+	store := tempStore(t)
+	for _, name := range []string{"A", "B"} {
+		if err := store.Save(Preset{Name: name}); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	entries, err := os.ReadDir(filepath.Dir(store.Path()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	want := []string{"presets.yaml", "presets.yaml.bak", "presets.yaml.lock"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("unexpected files %v, want %v", names, want)
+	}
+}
+
+func TestConcurrentStoresDoNotLoseUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "presets.yaml")
+	// Separate stores share no mutex, like separate processes; only the
+	// file lock serializes them.
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- NewStore(path).Save(Preset{Name: fmt.Sprintf("P%d", i)})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	presets, err := NewStore(path).List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(presets) != 20 {
+		t.Fatalf("expected 20 presets, got %d", len(presets))
+	}
+}
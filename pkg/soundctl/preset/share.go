@@ -0,0 +1,269 @@
+package preset
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"soundctl/pkg/soundctl/audio"
+)
+
+// Export and import use the presets file format, so a single preset, a
+// set of them, or a whole presets.yaml can be shared the same way. JSON
+// documents carry the same keys.
+
+// Conflict decides what Import does with a preset whose name is taken.
+type Conflict string
+
+const (
+	ConflictRename    Conflict = "rename"    // import under "Name 2", "Name 3", …
+	ConflictOverwrite Conflict = "overwrite" // replace the existing preset
+	ConflictSkip      Conflict = "skip"      // keep the existing preset
+)
+
+// ParseConflict validates a conflict mode given on the command line.
+func ParseConflict(s string) (Conflict, error) {
+	switch c := Conflict(s); c {
+	case ConflictRename, ConflictOverwrite, ConflictSkip:
+		return c, nil
+	}
+	return "", fmt.Errorf("invalid conflict mode %q (want rename, overwrite or skip)", s)
+}
+
+// Shareable returns p without the fields that only make sense in the
+// store it came from, so that exporting it twice gives the same file.
+func Shareable(p Preset) Preset {
+	p.CreatedAt = time.Time{}
+	p.UpdatedAt = time.Time{}
+	return p
+}
+
+// Encode renders presets as a shareable document in format "yaml" or
+// "json".
+func Encode(presets []Preset, format string) ([]byte, error) {
+	fd := fileData{Version: CurrentVersion, Presets: make([]Preset, len(presets))}
+	for i, p := range presets {
+		fd.Presets[i] = Shareable(p)
+	}
+	switch format {
+	case "yaml", "yml", "":
+		return yaml.Marshal(&fd)
+	case "json":
+		data, err := json.MarshalIndent(&fd, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+	return nil, fmt.Errorf("invalid format %q (want yaml or json)", format)
+}
+
+// Decode parses a shared YAML or JSON document and checks its structure.
+// Unknown keys are rejected rather than silently dropped, and timestamps
+// are stripped.
+func Decode(data []byte) ([]Preset, error) {
+	// JSON is valid YAML, so one decoder reads both.
+	fd, err := decodeFile(data, true)
+	if err != nil {
+		return nil, fmt.Errorf("parse presets: %w", err)
+	}
+	if len(fd.Presets) == 0 {
+		return nil, fmt.Errorf("no presets in document")
+	}
+	seen := map[string]bool{}
+	for i, p := range fd.Presets {
+		if err := checkShared(p); err != nil {
+			if p.Name == "" {
+				return nil, fmt.Errorf("preset #%d: %w", i+1, err)
+			}
+			return nil, fmt.Errorf("preset %q: %w", p.Name, err)
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("preset %q appears twice", p.Name)
+		}
+		seen[p.Name] = true
+		fd.Presets[i] = Shareable(p)
+	}
+	return fd.Presets, nil
+}
+
+// checkShared rejects presets that could not have been saved by soundctl
+// and would fail in confusing ways when applied.
+func checkShared(p Preset) error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("missing name")
+	}
+	for _, parent := range p.Extends {
+		if parent == "" || parent == p.Name {
+			return fmt.Errorf("invalid extends entry %q", parent)
+		}
+	}
+	for label, m := range p.Targets {
+		if label == "" || strings.HasPrefix(label, TargetPrefix) {
+			return fmt.Errorf("invalid target label %q", label)
+		}
+		if m.IsZero() {
+			return fmt.Errorf("target %q has no match fields", label)
+		}
+	}
+	ref := func(what, name string) error {
+		if name == "" {
+			return fmt.Errorf("empty %s name", what)
+		}
+		if label, ok := strings.CutPrefix(name, TargetPrefix); ok {
+			if _, ok := p.Targets[label]; !ok {
+				return fmt.Errorf("%s %q refers to undefined target", what, name)
+			}
+		}
+		return nil
+	}
+	for card, profile := range p.CardProfiles {
+		if err := ref("card", card); err != nil {
+			return err
+		}
+		if profile == "" {
+			return fmt.Errorf("card %q has an empty profile", card)
+		}
+	}
+	if p.DefaultSink != "" {
+		if err := ref("default sink", p.DefaultSink); err != nil {
+			return err
+		}
+	}
+	levels := func(what string, m map[string]VolumeSpec, refs bool) error {
+		for name, v := range m {
+			if refs {
+				if err := ref(what, name); err != nil {
+					return err
+				}
+			} else if name == "" {
+				return fmt.Errorf("empty %s name", what)
+			}
+			if v.Level < 0 || v.Level > audio.MaxVolumePercent {
+				return fmt.Errorf("%s %q volume %d%% is outside 0–%d%%", what, name, v.Level, audio.MaxVolumePercent)
+			}
+		}
+		return nil
+	}
+	if err := levels("sink", p.Volumes, true); err != nil {
+		return err
+	}
+	if err := levels("source", p.SourceVolumes, true); err != nil {
+		return err
+	}
+	if err := levels("app", p.AppVolumes, false); err != nil {
+		return err
+	}
+	for app, sink := range p.AppRoutes {
+		if app == "" {
+			return fmt.Errorf("empty app name")
+		}
+		if sink != "follow_default" {
+			if err := ref("route target for "+app, sink); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ImportResult reports what Import did with one preset.
+type ImportResult struct {
+	Name    string // name in the document
+	SavedAs string // name in the store; "" when skipped
+	Action  string // "created", "renamed", "overwritten" or "skipped"
+}
+
+// Import adds presets to the store in one locked write, resolving name
+// clashes with existing presets as c says. Imported presets get fresh
+// timestamps; overwriting keeps the original creation time.
+func (s *Store) Import(presets []Preset, c Conflict) ([]ImportResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, err := s.lock(true)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	existing, err := s.readFile()
+	if err != nil {
+		return nil, err
+	}
+	index := map[string]int{}
+	for i, p := range existing {
+		index[p.Name] = i
+	}
+	now := time.Now()
+	changed := false
+	renamed := map[string]string{}
+	var added []int // indices in existing of the presets written
+	results := make([]ImportResult, 0, len(presets))
+	for _, p := range presets {
+		r := ImportResult{Name: p.Name, SavedAs: p.Name, Action: "created"}
+		p.CreatedAt, p.UpdatedAt = now, now
+		if i, taken := index[p.Name]; taken {
+			switch c {
+			case ConflictSkip:
+				r.SavedAs, r.Action = "", "skipped"
+				results = append(results, r)
+				continue
+			case ConflictOverwrite:
+				p.CreatedAt = existing[i].CreatedAt
+				existing[i] = p
+				added = append(added, i)
+				r.Action = "overwritten"
+				changed = true
+				results = append(results, r)
+				continue
+			case ConflictRename:
+				p.Name = freeName(p.Name, index)
+				renamed[r.Name] = p.Name
+				r.SavedAs, r.Action = p.Name, "renamed"
+			default:
+				return nil, fmt.Errorf("invalid conflict mode %q", c)
+			}
+		}
+		index[p.Name] = len(existing)
+		added = append(added, len(existing))
+		existing = append(existing, p)
+		changed = true
+		results = append(results, r)
+	}
+	if !changed {
+		return results, nil
+	}
+	// Presets in the document extend each other by their names there.
+	for _, i := range added {
+		extends := make(Parents, len(existing[i].Extends))
+		for j, parent := range existing[i].Extends {
+			if name, ok := renamed[parent]; ok {
+				parent = name
+			}
+			extends[j] = parent
+		}
+		if len(extends) > 0 {
+			existing[i].Extends = extends
+		}
+	}
+	if err := checkInheritance(existing); err != nil {
+		return nil, err
+	}
+	if err := s.writeFile(existing); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// freeName returns "name N" for the smallest N ≥ 2 not in taken.
+func freeName(name string, taken map[string]int) string {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s %d", name, n)
+		if _, ok := taken[candidate]; !ok {
+			return candidate
+		}
+	}
+}
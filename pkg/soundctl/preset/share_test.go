@@ -0,0 +1,98 @@
+package preset
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	p := Preset{
+		Name:         "Desk",
+		Targets:      map[string]Match{"dock": {Product: "USB Audio DAC", Bus: "usb"}},
+		CardProfiles: map[string]string{"bluez_card.08_FF_44_2B_4C_90": "a2dp-sink"},
+		Volumes:      map[string]VolumeSpec{"@dock": {Level: 40, Muted: true}},
+		DefaultSink:  "@dock",
+		AppRoutes:    map[string]string{"Firefox": "follow_default"},
+		CreatedAt:    time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		UpdatedAt:    time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	for _, format := range []string{"yaml", "json"} {
+		data, err := Encode([]Preset{p}, format)
+		if err != nil {
+			t.Fatalf("Encode %s: %v", format, err)
+		}
+		if strings.Contains(string(data), "created_at") || strings.Contains(string(data), "2025") {
+			t.Errorf("%s export kept timestamps:\n%s", format, data)
+		}
+		got, err := Decode(data)
+		if err != nil {
+			t.Fatalf("Decode %s: %v\n%s", format, err, data)
+		}
+		if want := []Preset{Shareable(p)}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s round trip:\n%+v\nwant\n%+v", format, got, want)
+		}
+	}
+}
+
+func TestDecodeRejectsInvalid(t *testing.T) {
+	cases := []struct {
+		doc, want string
+	}{
+		{"version: 1\npresets:\n  - name: A\n    volume: {}\n", "field volume not found"},
+		{"version: 1\npresets: []\n", "no presets"},
+		{"version: 1\npresets:\n  - default_sink: x\n", "preset #1: missing name"},
+		{"version: 1\npresets:\n  - name: A\n  - name: A\n", `"A" appears twice`},
+		{"version: 1\npresets:\n  - name: A\n    default_sink: \"@dock\"\n", "undefined target"},
+		{`{"version": 1, "presets": [{"name": "A", "volumes": {"s": {"level": 400}}}]}`, "outside 0–150%"},
+		{"version: 7\npresets:\n  - name: A\n", "newer than supported"},
+	}
+	for _, c := range cases {
+		if _, err := Decode([]byte(c.doc)); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("Decode(%q) = %v, want error containing %q", c.doc, err, c.want)
+		}
+	}
+}
+
+func TestImportConflicts(t *testing.T) {
+	store := tempStore(t)
+	if err := store.Save(Preset{Name: "Desk", DefaultSink: "old"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	incoming := []Preset{{Name: "Desk", DefaultSink: "new"}, {Name: "Couch"}}
+
+	results, err := store.Import(incoming, ConflictSkip)
+	if err != nil {
+		t.Fatalf("Import skip: %v", err)
+	}
+	if results[0].Action != "skipped" || results[1].Action != "created" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	results, err = store.Import(incoming[:1], ConflictRename)
+	if err != nil {
+		t.Fatalf("Import rename: %v", err)
+	}
+	if results[0].Action != "renamed" || results[0].SavedAs != "Desk 2" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	before, _ := store.Get("Desk")
+	if _, err := store.Import(incoming[:1], ConflictOverwrite); err != nil {
+		t.Fatalf("Import overwrite: %v", err)
+	}
+	after, _ := store.Get("Desk")
+	if after.DefaultSink != "new" || !after.CreatedAt.Equal(before.CreatedAt) {
+		t.Fatalf("expected overwrite keeping CreatedAt, got %+v (before %+v)", after, before)
+	}
+
+	presets, _ := store.List()
+	var names []string
+	for _, p := range presets {
+		names = append(names, p.Name)
+	}
+	if want := []string{"Desk", "Couch", "Desk 2"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("unexpected presets %v, want %v", names, want)
+	}
+}
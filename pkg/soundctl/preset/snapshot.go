@@ -2,6 +2,9 @@ package preset
 
 import (
 	"context"
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"soundctl/pkg/soundctl/audio"
@@ -12,11 +15,13 @@ import (
 func SnapshotCurrent(ctx context.Context, au audio.Service) (Preset, error) {
 	now := time.Now()
 	p := Preset{
-		CreatedAt:    now,
-		UpdatedAt:    now,
-		CardProfiles: make(map[string]string),
-		Volumes:      make(map[string]VolumeSpec),
-		AppRoutes:    make(map[string]string),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		CardProfiles:  make(map[string]string),
+		Volumes:       make(map[string]VolumeSpec),
+		SourceVolumes: make(map[string]VolumeSpec),
+		AppRoutes:     make(map[string]string),
+		AppVolumes:    make(map[string]VolumeSpec),
 	}
 
 	// Get defaults
@@ -37,7 +42,7 @@ func SnapshotCurrent(ctx context.Context, au audio.Service) (Preset, error) {
 		}
 	}
 
-	// Get current sink inputs for app routing
+	// Get current sink inputs for app routing and per-app volume
 	inputs, err := au.ListSinkInputs(ctx)
 	if err != nil {
 		return p, err
@@ -49,20 +54,156 @@ func SnapshotCurrent(ctx context.Context, au audio.Service) (Preset, error) {
 			} else {
 				p.AppRoutes[si.AppName] = si.SinkName
 			}
+			// Streams without a volume (e.g. passthrough) are not captured.
+			if len(si.Volume) > 0 {
+				p.AppVolumes[si.AppName] = VolumeSpec{Level: si.VolumePercent(), Muted: si.Muted}
+			}
 		}
 	}
 
-	// Note: actual per-sink volume levels would require `pactl list sinks`
-	// with volume parsing. For now, we capture sinks with a placeholder.
-	// This is a known limitation documented in the diary.
-	sinks, err := au.ListSinks(ctx)
+	sinks, err := au.ListSinksDetailed(ctx)
 	if err != nil {
 		return p, err
 	}
 	for _, sink := range sinks {
-		// Use sink name as channel name for now
-		p.Volumes[sink.Name] = VolumeSpec{Level: 100, Muted: false}
+		p.Volumes[sink.Name] = VolumeSpec{Level: sink.VolumePercent(), Muted: sink.Muted}
+	}
+
+	// Monitor sources mirror their sink and are not captured.
+	sources, err := au.ListSourcesDetailed(ctx)
+	if err != nil {
+		return p, err
+	}
+	for _, source := range sources {
+		if source.MonitorOfSink != "" {
+			continue
+		}
+		p.SourceVolumes[source.Name] = VolumeSpec{Level: source.VolumePercent(), Muted: source.Muted}
 	}
 
 	return p, nil
 }
+
+// MakePortable rewrites the sink, source and card names in p as "@target"
+// references with matchers derived from the live objects: the bluetooth
+// address for bluetooth devices, otherwise the product name and bus, or
+// the description. A name is only rewritten when its matcher selects that
+// one object now; the sink, source and card of one device share a target.
+func MakePortable(ctx context.Context, au audio.Service, p Preset) (Preset, error) {
+	r := &targetResolver{au: au}
+	refs := map[string]map[string]string{} // kind → name → "@label"
+	labels := map[Match]string{}
+	matches := map[string]Match{} // label → matcher
+	for label, m := range p.Targets {
+		labels[m] = label
+		matches[label] = m
+	}
+	for _, kind := range []string{"card", "sink", "source"} {
+		objects, err := r.list(ctx, kind)
+		if err != nil {
+			return p, err
+		}
+		refs[kind] = map[string]string{}
+		for _, o := range objects {
+			m, label := portableMatch(o)
+			if m.IsZero() || !matchesOnly(m, o, objects) {
+				continue
+			}
+			if existing, ok := labels[m]; ok {
+				label = existing
+			} else {
+				base := label
+				for i := 2; !matches[label].IsZero(); i++ {
+					label = fmt.Sprintf("%s-%d", base, i)
+				}
+				matches[label] = m
+				labels[m] = label
+			}
+			refs[kind][o.name] = TargetPrefix + label
+		}
+	}
+
+	out := p
+	out.Targets = make(map[string]Match, len(p.Targets))
+	for label, m := range p.Targets {
+		out.Targets[label] = m
+	}
+	ref := func(kind, name string) string {
+		if r, ok := refs[kind][name]; ok {
+			label := strings.TrimPrefix(r, TargetPrefix)
+			out.Targets[label] = matches[label]
+			return r
+		}
+		return name
+	}
+	if p.CardProfiles != nil {
+		out.CardProfiles = make(map[string]string, len(p.CardProfiles))
+		for card, profile := range p.CardProfiles {
+			out.CardProfiles[ref("card", card)] = profile
+		}
+	}
+	if p.DefaultSink != "" {
+		out.DefaultSink = ref("sink", p.DefaultSink)
+	}
+	rekey := func(kind string, m map[string]VolumeSpec) map[string]VolumeSpec {
+		if m == nil {
+			return nil
+		}
+		out := make(map[string]VolumeSpec, len(m))
+		for k, v := range m {
+			out[ref(kind, k)] = v
+		}
+		return out
+	}
+	out.Volumes = rekey("sink", p.Volumes)
+	out.SourceVolumes = rekey("source", p.SourceVolumes)
+	if p.AppRoutes != nil {
+		out.AppRoutes = make(map[string]string, len(p.AppRoutes))
+		for app, sink := range p.AppRoutes {
+			if sink != "follow_default" {
+				sink = ref("sink", sink)
+			}
+			out.AppRoutes[app] = sink
+		}
+	}
+	if len(out.Targets) == 0 {
+		out.Targets = nil
+	}
+	return out, nil
+}
+
+// portableMatch derives a matcher and a suggested label for an object.
+func portableMatch(o matchObject) (Match, string) {
+	if addr := audio.ObjectAddress(o.name, o.props); addr != "" {
+		return Match{Address: addr}, targetLabel(o.description, addr)
+	}
+	if product := o.props["device.product.name"]; product != "" {
+		return Match{Product: product, Bus: o.props["device.bus"]}, targetLabel(product, o.name)
+	}
+	if o.description != "" {
+		return Match{Name: o.description}, targetLabel(o.description, o.name)
+	}
+	return Match{}, ""
+}
+
+func matchesOnly(m Match, o matchObject, objects []matchObject) bool {
+	for _, other := range objects {
+		if other.name != o.name && m.Matches(other.name, other.description, other.props) {
+			return false
+		}
+	}
+	return true
+}
+
+var labelRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// targetLabel turns "WH-1000XM4" into "wh-1000xm4", falling back to
+// fallback when text has nothing usable.
+func targetLabel(text, fallback string) string {
+	for _, s := range []string{text, fallback} {
+		if label := strings.Trim(labelRe.ReplaceAllString(strings.ToLower(s), "-"), "-"); label != "" {
+			return label
+		}
+	}
+	return "target"
+}
@@ -2,6 +2,7 @@ package preset
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"soundctl/pkg/soundctl/audio"
@@ -23,14 +24,24 @@ func TestSnapshotCurrent(t *testing.T) {
 
 	// pactl list sink-inputs
 	runner.Set("pactl", []string{"list", "sink-inputs"}, exec.CommandResult{
-		Output: "Sink Input #57\n\tSink: 1\n\tProperties:\n\t\tapplication.name = \"Firefox\"\n",
+		Output: "Sink Input #57\n\tSink: 1\n\tMute: no\n\tVolume: mono: 32768 /  50% / -18.06 dB\n\tProperties:\n\t\tapplication.name = \"Firefox\"\n",
 	})
 
-	// pactl list short sinks (for sink name resolution + volume snapshot)
+	// pactl list short sinks (for sink name resolution)
 	runner.Set("pactl", []string{"list", "short", "sinks"}, exec.CommandResult{
 		Output: "1\tbt-sink\tbluez5\tspec\tRUNNING",
 	})
 
+	// pactl list sinks (for volume + mute snapshot)
+	runner.Set("pactl", []string{"list", "sinks"}, exec.CommandResult{
+		Output: "Sink #1\n\tName: bt-sink\n\tMute: yes\n\tVolume: front-left: 42598 /  65% / -11.23 dB,   front-right: 42598 /  65% / -11.23 dB\n\t        balance 0.00",
+	})
+
+	// pactl list sources (monitor sources are skipped)
+	runner.Set("pactl", []string{"list", "sources"}, exec.CommandResult{
+		Output: "Source #2\n\tName: bt-sink.monitor\n\tMute: no\n\tVolume: mono: 65536 / 100% / 0.00 dB\n\tMonitor of Sink: bt-sink\nSource #3\n\tName: bt-source\n\tMute: no\n\tVolume: mono: 52429 /  80% / -5.81 dB\n\tMonitor of Sink: n/a",
+	})
+
 	au := audio.NewExecService(runner)
 
 	p, err := SnapshotCurrent(context.Background(), au)
@@ -50,7 +61,59 @@ func TestSnapshotCurrent(t *testing.T) {
 		t.Fatalf("expected Firefox→follow_default, got %q", p.AppRoutes["Firefox"])
 	}
 
-	if _, ok := p.Volumes["bt-sink"]; !ok {
+	if got := p.AppVolumes["Firefox"]; got.Level != 50 || got.Muted {
+		t.Fatalf("expected Firefox at 50%% unmuted, got %+v", got)
+	}
+
+	vol, ok := p.Volumes["bt-sink"]
+	if !ok {
 		t.Fatal("expected bt-sink volume entry")
 	}
+	if vol.Level != 65 || !vol.Muted {
+		t.Fatalf("expected bt-sink at 65%% muted, got %+v", vol)
+	}
+
+	if len(p.SourceVolumes) != 1 || p.SourceVolumes["bt-source"].Level != 80 {
+		t.Fatalf("expected only bt-source at 80%%, got %+v", p.SourceVolumes)
+	}
+}
+
+func TestMakePortable(t *testing.T) {
+	au := audio.NewExecService(newMatchRunner())
+	p := Preset{
+		CardProfiles:  map[string]string{"bluez_card.08_FF_44_2B_4C_90": "a2dp-sink"},
+		DefaultSink:   "bluez_output.08_FF_44_2B_4C_90.1",
+		Volumes:       map[string]VolumeSpec{"alsa_output.usb-Dock_DAC-00.analog-stereo": {Level: 30}, "gone-sink": {Level: 5}},
+		SourceVolumes: map[string]VolumeSpec{"alsa_input.usb-Dock_DAC-00.mono-fallback": {Level: 70}},
+		AppRoutes:     map[string]string{"Spotify": "bluez_output.08_FF_44_2B_4C_90.1"},
+	}
+	got, err := MakePortable(context.Background(), au, p)
+	if err != nil {
+		t.Fatalf("MakePortable: %v", err)
+	}
+	wantTargets := map[string]Match{
+		"wh-1000xm4":    {Address: "08:FF:44:2B:4C:90"},
+		"usb-audio-dac": {Product: "USB Audio DAC", Bus: "usb"},
+	}
+	if !reflect.DeepEqual(got.Targets, wantTargets) {
+		t.Fatalf("unexpected targets: %+v", got.Targets)
+	}
+	if got.CardProfiles["@wh-1000xm4"] != "a2dp-sink" || got.DefaultSink != "@wh-1000xm4" || got.AppRoutes["Spotify"] != "@wh-1000xm4" {
+		t.Fatalf("bluetooth names not rewritten: %+v", got)
+	}
+	if _, ok := got.Volumes["@usb-audio-dac"]; !ok {
+		t.Fatalf("unexpected volumes: %v", got.Volumes)
+	}
+	if _, ok := got.Volumes["gone-sink"]; !ok {
+		t.Fatalf("names of absent objects must be kept: %v", got.Volumes)
+	}
+	if _, ok := got.SourceVolumes["@usb-audio-dac"]; !ok {
+		t.Fatalf("unexpected source volumes: %v", got.SourceVolumes)
+	}
+
+	// The portable preset resolves back to the same names.
+	resolved, unresolved := ResolveTargets(context.Background(), au, got)
+	if len(unresolved) != 0 || resolved.DefaultSink != p.DefaultSink || !reflect.DeepEqual(resolved.Volumes, p.Volumes) {
+		t.Fatalf("round trip failed: %+v, %v", resolved, unresolved)
+	}
 }
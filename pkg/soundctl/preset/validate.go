@@ -0,0 +1,212 @@
+package preset
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"soundctl/pkg/soundctl/audio"
+)
+
+// Severity ranks a Finding. Errors make applying the preset fail in part;
+// warnings are worth a look but apply fine.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding codes.
+const (
+	FindingMissingCard        = "missing-card"
+	FindingUnknownProfile     = "unknown-profile"
+	FindingUnavailableProfile = "unavailable-profile"
+	FindingUnknownSink        = "unknown-sink"
+	FindingUnknownSource      = "unknown-source"
+	FindingUnresolvedTarget   = "unresolved-target"
+	FindingInvalidVolume      = "invalid-volume"
+	FindingLoudVolume         = "loud-volume"
+)
+
+// Finding is one problem Validate found with a preset.
+type Finding struct {
+	Severity Severity
+	Code     string // one of the Finding* codes
+	Field    string // the setting, named like DiffLine.Field, e.g. "bt-sink volume"
+	Message  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s", f.Severity, f.Field, f.Message)
+}
+
+// HasErrors reports whether any finding is an error.
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks p against the live system: that its cards exist and
+// have its profiles, available ones, that its sinks and sources exist,
+// and that volumes are in range, warning above 100%. p is taken as is:
+// resolve the presets it extends and the aliases it names first. @target
+// references are resolved here.
+//
+// A sink or source of a card whose profile p switches usually appears
+// only after the switch, so its absence is only a warning. The error is
+// for failing to list what is there.
+func Validate(ctx context.Context, au audio.Service, p Preset) ([]Finding, error) {
+	v := &validator{}
+
+	p, unresolved := resolveTargets(ctx, au, p, "card")
+	for _, u := range unresolved {
+		v.add(SeverityError, FindingUnresolvedTarget, TargetPrefix+u.Target, u.Err.Error())
+	}
+	var cards []audio.Card
+	if len(p.CardProfiles) > 0 {
+		var err error
+		if cards, err = au.ListCardsDetailed(ctx); err != nil {
+			return nil, fmt.Errorf("list cards: %w", err)
+		}
+	}
+	// switching lists the cards whose profile p changes.
+	var switching []string
+	for _, name := range sortedKeys(p.CardProfiles) {
+		profile := p.CardProfiles[name]
+		field := name + " profile"
+		card, ok := findCard(cards, name)
+		if !ok {
+			v.add(SeverityError, FindingMissingCard, field, fmt.Sprintf("card %q not found", name))
+			continue
+		}
+		var have []string
+		var found *audio.CardProfile
+		for i, cp := range card.Profiles {
+			have = append(have, cp.Name)
+			if cp.Name == profile {
+				found = &card.Profiles[i]
+			}
+		}
+		switch {
+		case found == nil:
+			v.add(SeverityError, FindingUnknownProfile, field,
+				fmt.Sprintf("card has no profile %q (has %s)", profile, strings.Join(have, ", ")))
+		case !found.Available:
+			v.add(SeverityError, FindingUnavailableProfile, field, fmt.Sprintf("profile %q is not available", profile))
+		}
+		if card.ActiveProfile != profile {
+			switching = append(switching, name)
+		}
+	}
+
+	p, unresolved = resolveTargets(ctx, au, p, "sink", "source")
+	for _, u := range unresolved {
+		severity := SeverityError
+		if len(switching) > 0 {
+			severity = SeverityWarning // may match once the profiles are switched
+		}
+		v.add(severity, FindingUnresolvedTarget, TargetPrefix+u.Target, u.Err.Error())
+	}
+	pending := func(object string) string {
+		for _, card := range switching {
+			if cardOwns(card, object) {
+				return card
+			}
+		}
+		return ""
+	}
+	present := func(kind, code string, list func(context.Context) ([]audio.ShortRecord, error), names []fieldName) error {
+		if len(names) == 0 {
+			return nil
+		}
+		records, err := list(ctx)
+		if err != nil {
+			return fmt.Errorf("list %ss: %w", kind, err)
+		}
+		have := map[string]bool{}
+		for _, r := range records {
+			have[r.Name] = true
+		}
+		for _, n := range names {
+			if have[n.name] {
+				continue
+			}
+			if card := pending(n.name); card != "" {
+				v.add(SeverityWarning, code, n.field,
+					fmt.Sprintf("%s %q not present yet; expected after switching %s to %s", kind, n.name, card, p.CardProfiles[card]))
+			} else {
+				v.add(SeverityError, code, n.field, fmt.Sprintf("%s %q not found", kind, n.name))
+			}
+		}
+		return nil
+	}
+
+	var sinks []fieldName
+	if p.DefaultSink != "" {
+		sinks = append(sinks, fieldName{"Default sink", p.DefaultSink})
+	}
+	for _, name := range sortedKeys(p.Volumes) {
+		sinks = append(sinks, fieldName{name + " volume", name})
+	}
+	for _, app := range sortedKeys(p.AppRoutes) {
+		if sink := p.AppRoutes[app]; sink != "follow_default" {
+			sinks = append(sinks, fieldName{app + " route", sink})
+		}
+	}
+	if err := present("sink", FindingUnknownSink, au.ListSinks, sinks); err != nil {
+		return nil, err
+	}
+	var sources []fieldName
+	for _, name := range sortedKeys(p.SourceVolumes) {
+		sources = append(sources, fieldName{name + " volume", name})
+	}
+	if err := present("source", FindingUnknownSource, au.ListSources, sources); err != nil {
+		return nil, err
+	}
+
+	v.volumes("", p.Volumes)
+	v.volumes("", p.SourceVolumes)
+	v.volumes("app ", p.AppVolumes)
+	return v.findings, nil
+}
+
+type fieldName struct {
+	field string
+	name  string
+}
+
+type validator struct {
+	findings []Finding
+}
+
+func (v *validator) add(severity Severity, code, field, message string) {
+	v.findings = append(v.findings, Finding{Severity: severity, Code: code, Field: field, Message: message})
+}
+
+func (v *validator) volumes(prefix string, m map[string]VolumeSpec) {
+	for _, name := range sortedKeys(m) {
+		level := m[name].Level
+		field := prefix + name + " volume"
+		switch {
+		case level < 0 || level > audio.MaxVolumePercent:
+			v.add(SeverityError, FindingInvalidVolume, field,
+				fmt.Sprintf("%d%% is outside 0–%d%%", level, audio.MaxVolumePercent))
+		case level > 100:
+			v.add(SeverityWarning, FindingLoudVolume, field, fmt.Sprintf("%d%% is above 100%% and may distort", level))
+		}
+	}
+}
+
+func findCard(cards []audio.Card, name string) (audio.Card, bool) {
+	for _, c := range cards {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return audio.Card{}, false
+}
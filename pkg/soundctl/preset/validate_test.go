@@ -0,0 +1,72 @@
+package preset
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"soundctl/pkg/soundctl/pulse"
+)
+
+func findingCodes(findings []Finding) map[string]Severity {
+	codes := map[string]Severity{}
+	for _, f := range findings {
+		codes[f.Field+" "+f.Code] = f.Severity
+	}
+	return codes
+}
+
+func TestValidate(t *testing.T) {
+	_, au := newAtomicFixture(t)
+	p := Preset{
+		CardProfiles:  map[string]string{"bluez_card.08_FF_44_2B_4C_90": "bogus", "alsa_card.gone": "output:analog-stereo"},
+		DefaultSink:   "nope",
+		Volumes:       map[string]VolumeSpec{atomicDockSink: {Level: 120}, atomicBTSink: {Level: 200}},
+		SourceVolumes: map[string]VolumeSpec{"mic": {Level: 50}},
+	}
+	findings, err := Validate(context.Background(), au, p)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	want := map[string]Severity{
+		"alsa_card.gone profile missing-card":                  SeverityError,
+		"bluez_card.08_FF_44_2B_4C_90 profile unknown-profile": SeverityError,
+		"Default sink unknown-sink":                            SeverityError,
+		"mic volume unknown-source":                            SeverityError,
+		atomicDockSink + " volume loud-volume":                 SeverityWarning,
+		atomicBTSink + " volume invalid-volume":                SeverityError,
+	}
+	if got := findingCodes(findings); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected findings:\n%v\nwant\n%v", got, want)
+	}
+	if !HasErrors(findings) {
+		t.Fatal("expected errors")
+	}
+}
+
+func TestValidateProfileSwitch(t *testing.T) {
+	srv, au := newAtomicFixture(t)
+	// The headset's HFP sink only appears once the profile is switched.
+	p := Preset{
+		CardProfiles: map[string]string{"bluez_card.08_FF_44_2B_4C_90": "headset-head-unit"},
+		Volumes:      map[string]VolumeSpec{"bluez_output.08_FF_44_2B_4C_90.2": {Level: 40}},
+	}
+	findings, err := Validate(context.Background(), au, p)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Severity != SeverityWarning || findings[0].Code != FindingUnknownSink {
+		t.Fatalf("expected a warning for the pending sink, got %v", findings)
+	}
+
+	srv.Update(func(st *pulse.FakeState) {
+		st.Cards[0].Profiles[1].Available = false
+	})
+	findings, err = Validate(context.Background(), au, p)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if got := findingCodes(findings); got["bluez_card.08_FF_44_2B_4C_90 profile unavailable-profile"] != SeverityError {
+		t.Fatalf("expected an unavailable profile error, got %v", findings)
+	}
+}
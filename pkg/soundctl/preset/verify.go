@@ -0,0 +1,54 @@
+package preset
+
+import (
+	"context"
+	"fmt"
+
+	"soundctl/pkg/soundctl/audio"
+)
+
+// Verify compares p with a fresh snapshot of the live state and returns
+// what still differs. Routes and volumes of apps that are not running are
+// not checked, and a route is satisfied by the sink the stream is on, however
+// the preset and the snapshot spell it.
+func Verify(ctx context.Context, au audio.Service, p Preset) ([]DiffLine, error) {
+	live, err := SnapshotCurrent(ctx, au)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	// Unresolved targets are reported by Apply; they cannot drift.
+	expected, _ := ResolveTargets(ctx, au, p)
+	return verifyDiff(live, expected), nil
+}
+
+// verifyDiff diffs live against the parts of expected that can be checked.
+func verifyDiff(live, expected Preset) []DiffLine {
+	defaultSink := expected.DefaultSink
+	if defaultSink == "" {
+		defaultSink = live.DefaultSink
+	}
+	want, got := expected, live
+	want.AppRoutes, got.AppRoutes = map[string]string{}, map[string]string{}
+	for app, route := range expected.AppRoutes {
+		liveRoute, running := live.AppRoutes[app]
+		if !running {
+			continue
+		}
+		want.AppRoutes[app] = routeSink(route, defaultSink)
+		got.AppRoutes[app] = routeSink(liveRoute, live.DefaultSink)
+	}
+	want.AppVolumes = map[string]VolumeSpec{}
+	for app, vol := range expected.AppVolumes {
+		if _, running := live.AppVolumes[app]; running {
+			want.AppVolumes[app] = vol
+		}
+	}
+	return Diff(got, want)
+}
+
+func routeSink(route, defaultSink string) string {
+	if route == "follow_default" {
+		return defaultSink
+	}
+	return route
+}
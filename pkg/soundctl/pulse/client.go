@@ -0,0 +1,319 @@
+// Package pulse is a minimal client for the PulseAudio native protocol, as
+// served on the local socket by both PulseAudio and pipewire-pulse. It covers
+// the introspection and control commands soundctl needs, nothing more.
+package pulse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ProtocolVersion is the native protocol version this client speaks.
+// The effective version is the minimum of this and the server's.
+const ProtocolVersion = 32
+
+// Command codes, see pulsecore/native-common.h.
+const (
+	commandError                = 0
+	commandReply                = 2
+	commandAuth                 = 8
+	commandSetClientName        = 9
+	commandGetServerInfo        = 20
+	commandGetSinkInfoList      = 22
+	commandGetSourceInfoList    = 24
+	commandGetSinkInputInfoList = 30
+	commandGetSourceOutputList  = 32
+	commandSubscribe            = 35
+	commandSetSinkVolume        = 36
+	commandSetSinkInputVolume   = 37
+	commandSetSourceVolume      = 38
+	commandSetSinkMute          = 39
+	commandSetSourceMute        = 40
+	commandSetDefaultSink       = 44
+	commandSetDefaultSource     = 45
+	commandSubscribeEvent       = 66
+	commandMoveSinkInput        = 67
+	commandMoveSourceOutput     = 68
+	commandSetSinkInputMute     = 69
+	commandGetCardInfoList      = 89
+	commandSetCardProfile       = 90
+	commandSendObjectMessage    = 102
+)
+
+// ErrClosed is returned for requests on a connection that has gone away.
+var ErrClosed = errors.New("pulse: connection closed")
+
+// ServerError is an error reply from the server.
+type ServerError struct {
+	Command uint32
+	Code    uint32
+}
+
+func (e *ServerError) Error() string {
+	if name, ok := errorNames[e.Code]; ok {
+		return fmt.Sprintf("pulse: command %d failed: %s", e.Command, name)
+	}
+	return fmt.Sprintf("pulse: command %d failed: error %d", e.Command, e.Code)
+}
+
+// errorNames mirrors the pa_error_code_t strings of libpulse's pa_strerror.
+var errorNames = map[uint32]string{
+	1:  "access denied",
+	2:  "unknown command",
+	3:  "invalid argument",
+	4:  "entity exists",
+	5:  "no such entity",
+	6:  "connection refused",
+	7:  "protocol error",
+	8:  "timeout",
+	9:  "no authentication key",
+	10: "internal error",
+	11: "connection terminated",
+	12: "entity killed",
+	13: "invalid server",
+	14: "module initialization failed",
+	15: "bad state",
+	16: "no data",
+	17: "incompatible protocol version",
+	18: "too large",
+	19: "not supported",
+	20: "unknown error code",
+	21: "no such extension",
+	22: "obsolete functionality",
+	23: "missing implementation",
+	24: "client forked",
+	25: "input/output error",
+	26: "device or resource busy",
+}
+
+type reply struct {
+	payload *reader
+	err     error
+}
+
+// Client is a connection to a PulseAudio-compatible server. It is safe for
+// concurrent use; replies are matched to requests by tag.
+type Client struct {
+	conn    net.Conn
+	version uint32
+	wmu     sync.Mutex // serialises packet writes
+
+	mu      sync.Mutex
+	nextTag uint32
+	pending map[uint32]chan reply
+	closed  error
+	done    chan struct{} // closed when readLoop returns
+	onEvent func(SubscribeEvent)
+}
+
+// DefaultSocketPath returns the native socket path, honouring PULSE_SERVER
+// (unix: form only) and XDG_RUNTIME_DIR.
+func DefaultSocketPath() string {
+	if server := os.Getenv("PULSE_SERVER"); server != "" {
+		for _, part := range strings.Fields(server) {
+			if strings.HasPrefix(part, "unix:") {
+				return strings.TrimPrefix(part, "unix:")
+			}
+			if strings.HasPrefix(part, "/") {
+				return part
+			}
+		}
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "pulse", "native")
+	}
+	return filepath.Join("/run/user", fmt.Sprint(os.Getuid()), "pulse", "native")
+}
+
+// Dial connects to the server socket at path ("" for DefaultSocketPath),
+// authenticates and registers the client name.
+func Dial(ctx context.Context, path string, clientName string) (*Client, error) {
+	if path == "" {
+		path = DefaultSocketPath()
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("pulse: dial %s: %w", path, err)
+	}
+	c := &Client{conn: conn, pending: map[uint32]chan reply{}, done: make(chan struct{})}
+	go c.readLoop()
+
+	w := &writer{}
+	w.u32(ProtocolVersion)
+	w.arbitrary(readCookie())
+	r, err := c.request(ctx, commandAuth, w)
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("pulse: auth: %w", err)
+	}
+	serverVersion := r.u32() & 0xFFFF // upper bits carry shm/memfd flags
+	if r.err != nil {
+		c.Close()
+		return nil, r.err
+	}
+	c.version = min(serverVersion, ProtocolVersion)
+
+	w = &writer{}
+	w.propList(PropList{"application.name": clientName})
+	if _, err := c.request(ctx, commandSetClientName, w); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("pulse: set client name: %w", err)
+	}
+	return c, nil
+}
+
+// Version returns the negotiated protocol version.
+func (c *Client) Version() uint32 {
+	return c.version
+}
+
+// Close terminates the connection and fails any in-flight requests.
+func (c *Client) Close() error {
+	err := c.conn.Close()
+	c.shutdown(ErrClosed)
+	return err
+}
+
+// Closed reports whether the connection has gone away.
+func (c *Client) Closed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed != nil
+}
+
+// Done returns a channel that is closed once the connection has gone away
+// and the subscription callback will not be called again.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *Client) shutdown(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed != nil {
+		return
+	}
+	c.closed = err
+	for tag, ch := range c.pending {
+		ch <- reply{err: err}
+		delete(c.pending, tag)
+	}
+}
+
+// request sends a command and waits for its reply.
+func (c *Client) request(ctx context.Context, command uint32, args *writer) (*reader, error) {
+	c.mu.Lock()
+	if c.closed != nil {
+		c.mu.Unlock()
+		return nil, c.closed
+	}
+	tag := c.nextTag
+	c.nextTag++
+	ch := make(chan reply, 1)
+	c.pending[tag] = ch
+
+	c.mu.Unlock()
+
+	w := &writer{}
+	w.u32(command)
+	w.u32(tag)
+	if args != nil {
+		w.buf = append(w.buf, args.buf...)
+	}
+	c.wmu.Lock()
+	err := writePacket(c.conn, w.buf)
+	c.wmu.Unlock()
+	if err != nil {
+		c.shutdown(ErrClosed)
+		return nil, fmt.Errorf("pulse: write: %w", err)
+	}
+
+	select {
+	case rep := <-ch:
+		var se *ServerError
+		if errors.As(rep.err, &se) {
+			se.Command = command
+		}
+		return rep.payload, rep.err
+	case <-ctx.Done():
+		c.mu.Lock()
+		delete(c.pending, tag)
+		c.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (c *Client) readLoop() {
+	defer close(c.done)
+	for {
+		payload, ok, err := readPacket(c.conn)
+		if err != nil {
+			c.shutdown(ErrClosed)
+			return
+		}
+		if !ok {
+			continue
+		}
+		r := &reader{buf: payload}
+		command := r.u32()
+		tag := r.u32()
+		if r.err != nil {
+			continue
+		}
+
+		switch command {
+		case commandReply, commandError:
+			var rep reply
+			if command == commandError {
+				rep.err = &ServerError{Code: r.u32()}
+			} else {
+				rep.payload = r
+			}
+			c.mu.Lock()
+			ch, found := c.pending[tag]
+			delete(c.pending, tag)
+			c.mu.Unlock()
+			if found {
+				ch <- rep
+			}
+		case commandSubscribeEvent:
+			ev := SubscribeEvent{Type: r.u32(), Index: r.u32()}
+			c.mu.Lock()
+			fn := c.onEvent
+			c.mu.Unlock()
+			if r.err == nil && fn != nil {
+				fn(ev)
+			}
+		}
+	}
+}
+
+// readCookie loads the auth cookie. pipewire-pulse ignores it, so a missing
+// cookie falls back to zeros rather than failing.
+func readCookie() []byte {
+	candidates := []string{os.Getenv("PULSE_COOKIE")}
+	if cfg, err := os.UserConfigDir(); err == nil {
+		candidates = append(candidates, filepath.Join(cfg, "pulse", "cookie"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".pulse-cookie"))
+	}
+	for _, path := range candidates {
+		if path == "" {
+			continue
+		}
+		if data, err := os.ReadFile(path); err == nil && len(data) == cookieLength {
+			return data
+		}
+	}
+	return make([]byte, cookieLength)
+}
+
+const cookieLength = 256
@@ -0,0 +1,283 @@
+package pulse
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testState() FakeState {
+	return FakeState{
+		Info: ServerInfo{
+			PackageName:    "pulseaudio",
+			PackageVersion: "15.0.0",
+			HostName:       "laptop",
+			DefaultSink:    "alsa_output.pci-0000_00_1f.3.analog-stereo",
+			DefaultSource:  "alsa_input.pci-0000_00_1f.3.analog-stereo",
+		},
+		Sinks: []DeviceInfo{{
+			Index:        1,
+			Name:         "alsa_output.pci-0000_00_1f.3.analog-stereo",
+			Description:  "Built-in Audio Analog Stereo",
+			SampleSpec:   SampleSpec{Format: 3, Channels: 2, Rate: 48000},
+			ChannelMap:   []uint8{1, 2},
+			Volume:       []uint32{VolumeFromPercent(40), VolumeFromPercent(40)},
+			MonitorIndex: 2,
+			MonitorName:  "alsa_output.pci-0000_00_1f.3.analog-stereo.monitor",
+			Driver:       "module-alsa-card.c",
+			Properties:   PropList{"device.bus": "pci"},
+			BaseVolume:   VolumeNorm,
+			State:        StateRunning,
+			Ports:        []PortInfo{{Name: "analog-output-speaker", Description: "Speakers", Available: AvailableUnknown}},
+			ActivePort:   "analog-output-speaker",
+			Formats:      []FormatInfo{{Encoding: 1, Properties: PropList{}}},
+		}},
+		Sources: []DeviceInfo{{
+			Index:        3,
+			Name:         "alsa_input.pci-0000_00_1f.3.analog-stereo",
+			Description:  "Built-in Audio Analog Stereo",
+			SampleSpec:   SampleSpec{Format: 3, Channels: 2, Rate: 48000},
+			ChannelMap:   []uint8{1, 2},
+			Volume:       []uint32{VolumeNorm, VolumeNorm},
+			MonitorIndex: InvalidIndex,
+			State:        StateSuspended,
+		}},
+		Cards: []CardInfo{{
+			Index:  0,
+			Name:   "bluez_card.AA_BB_CC_DD_EE_FF",
+			Driver: "module-bluez5-device.c",
+			Profiles: []CardProfileInfo{
+				{Name: "a2dp-sink", Description: "High Fidelity Playback (A2DP Sink)", Sinks: 1, Priority: 40, Available: true},
+				{Name: "headset-head-unit", Description: "Headset Head Unit (HSP/HFP)", Sinks: 1, Sources: 1, Priority: 30, Available: true},
+				{Name: "off", Description: "Off", Available: true},
+			},
+			ActiveProfile: "a2dp-sink",
+			Properties:    PropList{"device.description": "WH-1000XM4"},
+			Ports: []CardPortInfo{{
+				Name: "headset-output", Description: "Headset", Available: AvailableYes,
+				Direction: 1, Properties: PropList{}, Profiles: []string{"a2dp-sink", "headset-head-unit"},
+			}},
+		}},
+		SinkInputs: []SinkInputInfo{{
+			Index:      42,
+			Name:       "Playback",
+			Client:     7,
+			Sink:       1,
+			SampleSpec: SampleSpec{Format: 5, Channels: 2, Rate: 44100},
+			ChannelMap: []uint8{1, 2},
+			Volume:     []uint32{VolumeNorm, VolumeNorm},
+			Properties: PropList{"application.name": "Firefox", "media.name": "Video"},
+			HasVolume:  true,
+			Format:     FormatInfo{Encoding: 1, Properties: PropList{}},
+		}},
+		SourceOutputs: []SourceOutputInfo{{
+			Index:      61,
+			Name:       "Recording",
+			Client:     9,
+			Source:     2,
+			SampleSpec: SampleSpec{Format: 3, Channels: 1, Rate: 16000},
+			ChannelMap: []uint8{0},
+			Properties: PropList{"application.name": "Zoom", "media.name": "Mic"},
+			Volume:     []uint32{VolumeNorm},
+			HasVolume:  true,
+			Format:     FormatInfo{Encoding: 1, Properties: PropList{}},
+		}},
+	}
+}
+
+func startFake(t *testing.T) (*FakeServer, *Client) {
+	t.Helper()
+	srv, err := NewFakeServer(t.TempDir(), testState())
+	if err != nil {
+		t.Fatalf("NewFakeServer: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	c, err := Dial(context.Background(), srv.Path, "soundctl-test")
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return srv, c
+}
+
+func TestDialNegotiatesVersion(t *testing.T) {
+	_, c := startFake(t)
+	if c.Version() != ProtocolVersion {
+		t.Fatalf("expected version %d, got %d", ProtocolVersion, c.Version())
+	}
+}
+
+func TestIntrospectionRoundTrip(t *testing.T) {
+	_, c := startFake(t)
+	ctx := context.Background()
+	want := testState()
+
+	info, err := c.ServerInfo(ctx)
+	if err != nil {
+		t.Fatalf("ServerInfo: %v", err)
+	}
+	if info.DefaultSink != want.Info.DefaultSink || info.HostName != "laptop" {
+		t.Fatalf("unexpected server info: %+v", info)
+	}
+
+	sinks, err := c.Sinks(ctx)
+	if err != nil {
+		t.Fatalf("Sinks: %v", err)
+	}
+	if !reflect.DeepEqual(sinks, want.Sinks) {
+		t.Fatalf("sinks mismatch:\n got %+v\nwant %+v", sinks, want.Sinks)
+	}
+
+	sources, err := c.Sources(ctx)
+	if err != nil {
+		t.Fatalf("Sources: %v", err)
+	}
+	if len(sources) != 1 || sources[0].MonitorIndex != InvalidIndex || sources[0].State != StateSuspended {
+		t.Fatalf("unexpected sources: %+v", sources)
+	}
+
+	cards, err := c.Cards(ctx)
+	if err != nil {
+		t.Fatalf("Cards: %v", err)
+	}
+	if !reflect.DeepEqual(cards, want.Cards) {
+		t.Fatalf("cards mismatch:\n got %+v\nwant %+v", cards, want.Cards)
+	}
+
+	inputs, err := c.SinkInputs(ctx)
+	if err != nil {
+		t.Fatalf("SinkInputs: %v", err)
+	}
+	if !reflect.DeepEqual(inputs, want.SinkInputs) {
+		t.Fatalf("sink inputs mismatch:\n got %+v\nwant %+v", inputs, want.SinkInputs)
+	}
+
+	outputs, err := c.SourceOutputs(ctx)
+	if err != nil {
+		t.Fatalf("SourceOutputs: %v", err)
+	}
+	if !reflect.DeepEqual(outputs, want.SourceOutputs) {
+		t.Fatalf("source outputs mismatch:\n got %+v\nwant %+v", outputs, want.SourceOutputs)
+	}
+}
+
+func TestControlCommandsMutateServer(t *testing.T) {
+	srv, c := startFake(t)
+	ctx := context.Background()
+	sink := "alsa_output.pci-0000_00_1f.3.analog-stereo"
+
+	if err := c.SetSinkVolume(ctx, InvalidIndex, sink, []uint32{VolumeFromPercent(55)}); err != nil {
+		t.Fatalf("SetSinkVolume: %v", err)
+	}
+	if err := c.SetSinkMute(ctx, 1, "", true); err != nil {
+		t.Fatalf("SetSinkMute: %v", err)
+	}
+	if err := c.MoveSinkInput(ctx, 42, InvalidIndex, sink); err != nil {
+		t.Fatalf("MoveSinkInput: %v", err)
+	}
+	if err := c.SetCardProfile(ctx, InvalidIndex, "bluez_card.AA_BB_CC_DD_EE_FF", "headset-head-unit"); err != nil {
+		t.Fatalf("SetCardProfile: %v", err)
+	}
+
+	st := srv.State()
+	if got := st.Sinks[0].Volume; len(got) != 2 || VolumePercent(got[1]) != 55 {
+		t.Fatalf("expected both channels at 55%%, got %v", got)
+	}
+	if !st.Sinks[0].Mute {
+		t.Fatalf("expected sink muted")
+	}
+	if st.Cards[0].ActiveProfile != "headset-head-unit" {
+		t.Fatalf("expected profile switch, got %q", st.Cards[0].ActiveProfile)
+	}
+
+	want := []string{
+		"set-sink-volume " + sink + " 55%",
+		"set-sink-mute " + sink + " 1",
+		"move-sink-input 42 " + sink,
+		"set-card-profile bluez_card.AA_BB_CC_DD_EE_FF headset-head-unit",
+	}
+	if got := srv.Calls(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected calls:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestServerErrorReply(t *testing.T) {
+	_, c := startFake(t)
+	err := c.SetDefaultSink(context.Background(), "missing")
+	if !IsNoSuchEntity(err) {
+		t.Fatalf("expected no such entity, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "no such entity") {
+		t.Fatalf("unexpected message: %v", err)
+	}
+}
+
+func TestServerErrorNames(t *testing.T) {
+	for code, want := range map[uint32]string{
+		17: "pulse: command 1 failed: incompatible protocol version",
+		19: "pulse: command 1 failed: not supported",
+		99: "pulse: command 1 failed: error 99",
+	} {
+		if got := (&ServerError{Command: 1, Code: code}).Error(); got != want {
+			t.Errorf("code %d: got %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestSubscribeDeliversEvents(t *testing.T) {
+	srv, c := startFake(t)
+	ctx := context.Background()
+
+	events := make(chan SubscribeEvent, 4)
+	if err := c.Subscribe(ctx, MaskSink|MaskCard, func(ev SubscribeEvent) { events <- ev }); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	srv.Emit(SubscribeEvent{Type: FacilitySinkInput | EventNew, Index: 9}) // filtered by mask
+	if err := c.SetSinkMute(ctx, 1, "", true); err != nil {
+		t.Fatalf("SetSinkMute: %v", err)
+	}
+
+	select {
+	case ev := <-events:
+		if ev.FacilityName() != "sink" || ev.KindName() != "change" || ev.Index != 1 {
+			t.Fatalf("unexpected event: %+v", ev)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for event")
+	}
+}
+
+func TestClientReportsClosedAfterDisconnect(t *testing.T) {
+	srv, c := startFake(t)
+	srv.Disconnect()
+	deadline := time.Now().Add(2 * time.Second)
+	for !c.Closed() {
+		if time.Now().After(deadline) {
+			t.Fatalf("client did not notice disconnect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := c.Sinks(context.Background()); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestVolumeConversions(t *testing.T) {
+	if VolumeFromPercent(100) != VolumeNorm || VolumePercent(VolumeNorm) != 100 {
+		t.Fatalf("100%% must map to VolumeNorm")
+	}
+	if got := VolumePercent(VolumeFromPercent(37)); got != 37 {
+		t.Fatalf("expected 37%% round trip, got %d", got)
+	}
+	if db := VolumeDB(VolumeNorm); db != 0 {
+		t.Fatalf("expected 0 dB at norm, got %v", db)
+	}
+	if got := (SampleSpec{Format: 3, Channels: 2, Rate: 48000}).String(); got != "s16le 2ch 48000Hz" {
+		t.Fatalf("unexpected sample spec string %q", got)
+	}
+	if ChannelPositionName(1) != "front-left" || ChannelPositionName(12) != "aux0" {
+		t.Fatalf("unexpected channel names")
+	}
+}
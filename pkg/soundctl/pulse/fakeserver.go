@@ -0,0 +1,429 @@
+package pulse
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Error codes FakeServer replies with, see pa_error_code_t.
+const (
+	errorCodeUnknownCommand = 2
+	errorCodeInvalid        = 3
+	errorCodeNoEntity       = 5
+)
+
+// FakeState is the object graph served by a FakeServer.
+type FakeState struct {
+	Info          ServerInfo
+	Sinks         []DeviceInfo
+	Sources       []DeviceInfo
+	Cards         []CardInfo
+	SinkInputs    []SinkInputInfo
+	SourceOutputs []SourceOutputInfo
+}
+
+// FakeServer is a deterministic stand-in for a PulseAudio server, listening
+// on a unix socket. Mutating commands update its state, notify subscribers
+// and are recorded as pactl-style call strings.
+type FakeServer struct {
+	Path string
+
+	ln    net.Listener
+	mu    sync.Mutex
+	state FakeState
+	calls []string
+	conns map[*fakeConn]struct{}
+	wg    sync.WaitGroup
+}
+
+type fakeConn struct {
+	conn net.Conn
+	wmu  sync.Mutex
+	mask uint32 // guarded by FakeServer.mu
+}
+
+// NewFakeServer listens on dir/native and serves state until Close.
+func NewFakeServer(dir string, state FakeState) (*FakeServer, error) {
+	path := filepath.Join(dir, "native")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	s := &FakeServer{Path: path, ln: ln, state: state, conns: map[*fakeConn]struct{}{}}
+	s.wg.Add(1)
+	go s.accept()
+	return s, nil
+}
+
+// Close stops listening and drops all connections.
+func (s *FakeServer) Close() error {
+	err := s.ln.Close()
+	s.Disconnect()
+	s.wg.Wait()
+	return err
+}
+
+// Disconnect drops all client connections but keeps listening, simulating
+// a server restart.
+func (s *FakeServer) Disconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for fc := range s.conns {
+		fc.conn.Close()
+	}
+}
+
+// Calls returns the mutating commands received so far.
+func (s *FakeServer) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+// State returns the current state.
+func (s *FakeServer) State() FakeState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// Update mutates the state under the server lock.
+func (s *FakeServer) Update(fn func(*FakeState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.state)
+}
+
+// Subscribers returns how many connected clients have subscribed to events,
+// so tests can wait for a subscription before emitting.
+func (s *FakeServer) Subscribers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for fc := range s.conns {
+		if fc.mask != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// Emit sends a subscription event to every client whose mask matches it.
+func (s *FakeServer) Emit(ev SubscribeEvent) {
+	s.mu.Lock()
+	var targets []*fakeConn
+	for fc := range s.conns {
+		if fc.mask&(1<<ev.Facility()) != 0 {
+			targets = append(targets, fc)
+		}
+	}
+	s.mu.Unlock()
+
+	w := &writer{}
+	w.u32(commandSubscribeEvent)
+	w.u32(invalidIndex)
+	w.u32(ev.Type)
+	w.u32(ev.Index)
+	for _, fc := range targets {
+		fc.send(w.buf)
+	}
+}
+
+func (s *FakeServer) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		fc := &fakeConn{conn: conn}
+		s.mu.Lock()
+		s.conns[fc] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go s.serve(fc)
+	}
+}
+
+func (fc *fakeConn) send(payload []byte) {
+	fc.wmu.Lock()
+	defer fc.wmu.Unlock()
+	_ = writePacket(fc.conn, payload)
+}
+
+func (s *FakeServer) serve(fc *fakeConn) {
+	defer s.wg.Done()
+	defer func() {
+		fc.conn.Close()
+		s.mu.Lock()
+		delete(s.conns, fc)
+		s.mu.Unlock()
+	}()
+	for {
+		payload, ok, err := readPacket(fc.conn)
+		if err != nil {
+			return
+		}
+		if !ok {
+			continue
+		}
+		r := &reader{buf: payload}
+		command := r.u32()
+		tag := r.u32()
+		if r.err != nil {
+			return
+		}
+
+		out := &writer{}
+		out.u32(commandReply)
+		out.u32(tag)
+		code, ev := s.handle(fc, command, r, out)
+		if code != 0 {
+			out = &writer{}
+			out.u32(commandError)
+			out.u32(tag)
+			out.u32(code)
+		}
+		fc.send(out.buf)
+		if ev != nil {
+			s.Emit(*ev)
+		}
+	}
+}
+
+// handle executes one command, appending the reply body to out. It returns
+// a non-zero error code on failure and the change event to broadcast, if any.
+func (s *FakeServer) handle(fc *fakeConn, command uint32, r *reader, out *writer) (uint32, *SubscribeEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := &s.state
+
+	switch command {
+	case commandAuth:
+		r.u32()
+		r.arbitrary()
+		out.u32(ProtocolVersion)
+	case commandSetClientName:
+		r.propList()
+		out.u32(0)
+	case commandGetServerInfo:
+		writeServerInfo(out, ProtocolVersion, st.Info)
+	case commandGetSinkInfoList:
+		for _, d := range st.Sinks {
+			writeDeviceInfo(out, ProtocolVersion, true, d)
+		}
+	case commandGetSourceInfoList:
+		for _, d := range st.Sources {
+			writeDeviceInfo(out, ProtocolVersion, false, d)
+		}
+	case commandGetCardInfoList:
+		for _, c := range st.Cards {
+			writeCardInfo(out, ProtocolVersion, c)
+		}
+	case commandGetSinkInputInfoList:
+		for _, si := range st.SinkInputs {
+			writeSinkInputInfo(out, ProtocolVersion, si)
+		}
+	case commandGetSourceOutputList:
+		for _, so := range st.SourceOutputs {
+			writeSourceOutputInfo(out, ProtocolVersion, so)
+		}
+	case commandSubscribe:
+		fc.mask = r.u32()
+
+	case commandSetDefaultSink, commandSetDefaultSource:
+		name := r.str()
+		list, verb := st.Sinks, "set-default-sink"
+		if command == commandSetDefaultSource {
+			list, verb = st.Sources, "set-default-source"
+		}
+		i := findDevice(list, invalidIndex, name)
+		if i < 0 {
+			return errorCodeNoEntity, nil
+		}
+		if command == commandSetDefaultSink {
+			st.Info.DefaultSink = name
+		} else {
+			st.Info.DefaultSource = name
+		}
+		s.calls = append(s.calls, verb+" "+name)
+		return 0, &SubscribeEvent{Type: FacilityServer | EventChange, Index: invalidIndex}
+
+	case commandSetSinkVolume, commandSetSourceVolume:
+		index, name, volume := r.u32(), r.str(), r.cvolume()
+		list, verb, facility := st.Sinks, "set-sink-volume", uint32(FacilitySink)
+		if command == commandSetSourceVolume {
+			list, verb, facility = st.Sources, "set-source-volume", FacilitySource
+		}
+		i := findDevice(list, index, name)
+		if i < 0 {
+			return errorCodeNoEntity, nil
+		}
+		if len(volume) == 0 {
+			return errorCodeInvalid, nil
+		}
+		d := &list[i]
+		d.Volume = spreadVolume(volume, len(d.ChannelMap))
+		s.calls = append(s.calls, fmt.Sprintf("%s %s %s", verb, d.Name, percentList(volume)))
+		return 0, &SubscribeEvent{Type: facility | EventChange, Index: d.Index}
+
+	case commandSetSinkMute, commandSetSourceMute:
+		index, name, mute := r.u32(), r.str(), r.boolean()
+		list, verb, facility := st.Sinks, "set-sink-mute", uint32(FacilitySink)
+		if command == commandSetSourceMute {
+			list, verb, facility = st.Sources, "set-source-mute", FacilitySource
+		}
+		i := findDevice(list, index, name)
+		if i < 0 {
+			return errorCodeNoEntity, nil
+		}
+		d := &list[i]
+		d.Mute = mute
+		s.calls = append(s.calls, fmt.Sprintf("%s %s %s", verb, d.Name, boolDigit(mute)))
+		return 0, &SubscribeEvent{Type: facility | EventChange, Index: d.Index}
+
+	case commandSetSinkInputVolume:
+		index, volume := r.u32(), r.cvolume()
+		if len(volume) == 0 {
+			return errorCodeInvalid, nil
+		}
+		for i := range st.SinkInputs {
+			in := &st.SinkInputs[i]
+			if in.Index == index {
+				in.Volume = spreadVolume(volume, len(in.ChannelMap))
+				s.calls = append(s.calls, fmt.Sprintf("set-sink-input-volume %d %s", index, percentList(volume)))
+				return 0, &SubscribeEvent{Type: FacilitySinkInput | EventChange, Index: index}
+			}
+		}
+		return errorCodeNoEntity, nil
+
+	case commandSetSinkInputMute:
+		index, mute := r.u32(), r.boolean()
+		for i := range st.SinkInputs {
+			in := &st.SinkInputs[i]
+			if in.Index == index {
+				in.Mute = mute
+				s.calls = append(s.calls, fmt.Sprintf("set-sink-input-mute %d %s", index, boolDigit(mute)))
+				return 0, &SubscribeEvent{Type: FacilitySinkInput | EventChange, Index: index}
+			}
+		}
+		return errorCodeNoEntity, nil
+
+	case commandMoveSinkInput:
+		index, sinkIndex, sinkName := r.u32(), r.u32(), r.str()
+		sink := findDevice(st.Sinks, sinkIndex, sinkName)
+		if sink < 0 {
+			return errorCodeNoEntity, nil
+		}
+		for i := range st.SinkInputs {
+			if st.SinkInputs[i].Index == index {
+				st.SinkInputs[i].Sink = st.Sinks[sink].Index
+				s.calls = append(s.calls, fmt.Sprintf("move-sink-input %d %s", index, st.Sinks[sink].Name))
+				return 0, &SubscribeEvent{Type: FacilitySinkInput | EventChange, Index: index}
+			}
+		}
+		return errorCodeNoEntity, nil
+
+	case commandMoveSourceOutput:
+		index, sourceIndex, sourceName := r.u32(), r.u32(), r.str()
+		source := findDevice(st.Sources, sourceIndex, sourceName)
+		if source < 0 {
+			return errorCodeNoEntity, nil
+		}
+		for i := range st.SourceOutputs {
+			if st.SourceOutputs[i].Index == index {
+				st.SourceOutputs[i].Source = st.Sources[source].Index
+				s.calls = append(s.calls, fmt.Sprintf("move-source-output %d %s", index, st.Sources[source].Name))
+				return 0, &SubscribeEvent{Type: FacilitySourceOutput | EventChange, Index: index}
+			}
+		}
+		return errorCodeNoEntity, nil
+
+	case commandSetCardProfile:
+		index, name, profile := r.u32(), r.str(), r.str()
+		for i := range st.Cards {
+			card := &st.Cards[i]
+			if (index != invalidIndex && card.Index != index) || (index == invalidIndex && card.Name != name) {
+				continue
+			}
+			for _, p := range card.Profiles {
+				if p.Name == profile {
+					card.ActiveProfile = profile
+					s.calls = append(s.calls, fmt.Sprintf("set-card-profile %s %s", card.Name, profile))
+					return 0, &SubscribeEvent{Type: FacilityCard | EventChange, Index: card.Index}
+				}
+			}
+			return errorCodeNoEntity, nil
+		}
+		return errorCodeNoEntity, nil
+
+	case commandSendObjectMessage:
+		// Only PulseAudio's bluetooth module answers switch-codec.
+		path, message, params := r.str(), r.str(), r.str()
+		for _, card := range st.Cards {
+			if path == "/card/"+card.Name+"/bluez" && message == "switch-codec" && card.Driver == "module-bluez5-device.c" {
+				s.calls = append(s.calls, fmt.Sprintf("send-message %s %s %s", path, message, params))
+				out.str("")
+				return 0, &SubscribeEvent{Type: FacilityCard | EventChange, Index: card.Index}
+			}
+		}
+		return errorCodeNoEntity, nil
+
+	default:
+		return errorCodeUnknownCommand, nil
+	}
+	if r.err != nil {
+		return errorCodeInvalid, nil
+	}
+	return 0, nil
+}
+
+func findDevice(list []DeviceInfo, index uint32, name string) int {
+	for i, d := range list {
+		if index != invalidIndex && d.Index == index {
+			return i
+		}
+		if index == invalidIndex && d.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// spreadVolume applies a single-channel volume to every channel, as the
+// server does for pactl's "set-sink-volume name 50%".
+func spreadVolume(volume []uint32, channels int) []uint32 {
+	if len(volume) != 1 || channels <= 1 {
+		return append([]uint32(nil), volume...)
+	}
+	out := make([]uint32, channels)
+	for i := range out {
+		out[i] = volume[0]
+	}
+	return out
+}
+
+func percentList(volume []uint32) string {
+	parts := make([]string, len(volume))
+	for i, v := range volume {
+		parts[i] = fmt.Sprintf("%d%%", VolumePercent(v))
+	}
+	return strings.Join(parts, " ")
+}
+
+func boolDigit(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
+
+// IsNoSuchEntity reports whether err is a server "no such entity" reply.
+func IsNoSuchEntity(err error) bool {
+	var se *ServerError
+	return errors.As(err, &se) && se.Code == errorCodeNoEntity
+}
@@ -0,0 +1,780 @@
+package pulse
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// InvalidIndex is PA_INVALID_INDEX, used when addressing objects by name.
+const InvalidIndex = invalidIndex
+
+// VolumeNorm is PA_VOLUME_NORM (100%).
+const VolumeNorm = volumeNorm
+
+// Device states as reported in DeviceInfo.State.
+const (
+	StateRunning   = 0
+	StateIdle      = 1
+	StateSuspended = 2
+)
+
+// Port availability as reported in PortInfo.Available.
+const (
+	AvailableUnknown = 0
+	AvailableNo      = 1
+	AvailableYes     = 2
+)
+
+// ServerInfo mirrors pa_server_info.
+type ServerInfo struct {
+	PackageName    string
+	PackageVersion string
+	UserName       string
+	HostName       string
+	SampleSpec     SampleSpec
+	DefaultSink    string
+	DefaultSource  string
+	Cookie         uint32
+	ChannelMap     []uint8
+}
+
+// PortInfo mirrors pa_sink_port_info / pa_source_port_info.
+type PortInfo struct {
+	Name        string
+	Description string
+	Priority    uint32
+	Available   uint32
+}
+
+// DeviceInfo mirrors pa_sink_info and pa_source_info, which share a layout.
+// MonitorIndex/MonitorName name the monitor source of a sink, or the sink
+// a source monitors (InvalidIndex when it is not a monitor).
+type DeviceInfo struct {
+	Index             uint32
+	Name              string
+	Description       string
+	SampleSpec        SampleSpec
+	ChannelMap        []uint8
+	OwnerModule       uint32
+	Volume            []uint32
+	Mute              bool
+	MonitorIndex      uint32
+	MonitorName       string
+	Latency           uint64
+	Driver            string
+	Flags             uint32
+	Properties        PropList
+	ConfiguredLatency uint64
+	BaseVolume        uint32
+	State             uint32
+	VolumeSteps       uint32
+	Card              uint32
+	Ports             []PortInfo
+	ActivePort        string
+	Formats           []FormatInfo
+}
+
+// CardProfileInfo mirrors pa_card_profile_info2.
+type CardProfileInfo struct {
+	Name        string
+	Description string
+	Sinks       uint32
+	Sources     uint32
+	Priority    uint32
+	Available   bool
+}
+
+// CardPortInfo mirrors pa_card_port_info.
+type CardPortInfo struct {
+	Name          string
+	Description   string
+	Priority      uint32
+	Available     uint32
+	Direction     uint8
+	Properties    PropList
+	Profiles      []string
+	LatencyOffset int64
+}
+
+// CardInfo mirrors pa_card_info.
+type CardInfo struct {
+	Index         uint32
+	Name          string
+	OwnerModule   uint32
+	Driver        string
+	Profiles      []CardProfileInfo
+	ActiveProfile string
+	Properties    PropList
+	Ports         []CardPortInfo
+}
+
+// SinkInputInfo mirrors pa_sink_input_info.
+type SinkInputInfo struct {
+	Index          uint32
+	Name           string
+	OwnerModule    uint32
+	Client         uint32
+	Sink           uint32
+	SampleSpec     SampleSpec
+	ChannelMap     []uint8
+	Volume         []uint32
+	BufferUsec     uint64
+	SinkUsec       uint64
+	ResampleMethod string
+	Driver         string
+	Mute           bool
+	Properties     PropList
+	Corked         bool
+	HasVolume      bool
+	VolumeWritable bool
+	Format         FormatInfo
+}
+
+// SourceOutputInfo mirrors pa_source_output_info.
+type SourceOutputInfo struct {
+	Index          uint32
+	Name           string
+	OwnerModule    uint32
+	Client         uint32
+	Source         uint32
+	SampleSpec     SampleSpec
+	ChannelMap     []uint8
+	BufferUsec     uint64
+	SourceUsec     uint64
+	ResampleMethod string
+	Driver         string
+	Properties     PropList
+	Corked         bool
+	Volume         []uint32
+	Mute           bool
+	HasVolume      bool
+	VolumeWritable bool
+	Format         FormatInfo
+}
+
+// ── Client requests ────────────────────────────────────────────────────────
+
+// ServerInfo returns server defaults and identity.
+func (c *Client) ServerInfo(ctx context.Context) (ServerInfo, error) {
+	r, err := c.request(ctx, commandGetServerInfo, nil)
+	if err != nil {
+		return ServerInfo{}, err
+	}
+	info := readServerInfo(r, c.version)
+	return info, r.err
+}
+
+// Sinks lists all sinks.
+func (c *Client) Sinks(ctx context.Context) ([]DeviceInfo, error) {
+	return c.devices(ctx, commandGetSinkInfoList, true)
+}
+
+// Sources lists all sources, including monitors.
+func (c *Client) Sources(ctx context.Context) ([]DeviceInfo, error) {
+	return c.devices(ctx, commandGetSourceInfoList, false)
+}
+
+func (c *Client) devices(ctx context.Context, command uint32, sink bool) ([]DeviceInfo, error) {
+	r, err := c.request(ctx, command, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out []DeviceInfo
+	for !r.eof() {
+		d := readDeviceInfo(r, c.version, sink)
+		if r.err != nil {
+			return nil, r.err
+		}
+		out = append(out, d)
+	}
+	return out, r.err
+}
+
+// Cards lists all cards with their profiles.
+func (c *Client) Cards(ctx context.Context) ([]CardInfo, error) {
+	r, err := c.request(ctx, commandGetCardInfoList, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out []CardInfo
+	for !r.eof() {
+		card := readCardInfo(r, c.version)
+		if r.err != nil {
+			return nil, r.err
+		}
+		out = append(out, card)
+	}
+	return out, r.err
+}
+
+// SinkInputs lists all playback streams.
+func (c *Client) SinkInputs(ctx context.Context) ([]SinkInputInfo, error) {
+	r, err := c.request(ctx, commandGetSinkInputInfoList, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out []SinkInputInfo
+	for !r.eof() {
+		si := readSinkInputInfo(r, c.version)
+		if r.err != nil {
+			return nil, r.err
+		}
+		out = append(out, si)
+	}
+	return out, r.err
+}
+
+// SourceOutputs lists all recording streams.
+func (c *Client) SourceOutputs(ctx context.Context) ([]SourceOutputInfo, error) {
+	r, err := c.request(ctx, commandGetSourceOutputList, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out []SourceOutputInfo
+	for !r.eof() {
+		so := readSourceOutputInfo(r, c.version)
+		if r.err != nil {
+			return nil, r.err
+		}
+		out = append(out, so)
+	}
+	return out, r.err
+}
+
+// SetDefaultSink sets the default sink by name.
+func (c *Client) SetDefaultSink(ctx context.Context, name string) error {
+	w := &writer{}
+	w.str(name)
+	_, err := c.request(ctx, commandSetDefaultSink, w)
+	return err
+}
+
+// SetDefaultSource sets the default source by name.
+func (c *Client) SetDefaultSource(ctx context.Context, name string) error {
+	w := &writer{}
+	w.str(name)
+	_, err := c.request(ctx, commandSetDefaultSource, w)
+	return err
+}
+
+// SetSinkVolume sets a sink's volume, addressed by index or (with
+// InvalidIndex) by name. A single-channel volume applies to all channels.
+func (c *Client) SetSinkVolume(ctx context.Context, index uint32, name string, volume []uint32) error {
+	return c.setVolume(ctx, commandSetSinkVolume, index, name, volume)
+}
+
+// SetSourceVolume is SetSinkVolume for sources.
+func (c *Client) SetSourceVolume(ctx context.Context, index uint32, name string, volume []uint32) error {
+	return c.setVolume(ctx, commandSetSourceVolume, index, name, volume)
+}
+
+func (c *Client) setVolume(ctx context.Context, command uint32, index uint32, name string, volume []uint32) error {
+	w := &writer{}
+	w.u32(index)
+	w.str(name)
+	w.cvolume(volume)
+	_, err := c.request(ctx, command, w)
+	return err
+}
+
+// SetSinkMute sets a sink's mute state.
+func (c *Client) SetSinkMute(ctx context.Context, index uint32, name string, mute bool) error {
+	return c.setMute(ctx, commandSetSinkMute, index, name, mute)
+}
+
+// SetSourceMute sets a source's mute state.
+func (c *Client) SetSourceMute(ctx context.Context, index uint32, name string, mute bool) error {
+	return c.setMute(ctx, commandSetSourceMute, index, name, mute)
+}
+
+func (c *Client) setMute(ctx context.Context, command uint32, index uint32, name string, mute bool) error {
+	w := &writer{}
+	w.u32(index)
+	w.str(name)
+	w.boolean(mute)
+	_, err := c.request(ctx, command, w)
+	return err
+}
+
+// SetSinkInputVolume sets the per-channel volume of a playback stream.
+func (c *Client) SetSinkInputVolume(ctx context.Context, index uint32, volume []uint32) error {
+	w := &writer{}
+	w.u32(index)
+	w.cvolume(volume)
+	_, err := c.request(ctx, commandSetSinkInputVolume, w)
+	return err
+}
+
+// SetSinkInputMute sets a playback stream's mute state.
+func (c *Client) SetSinkInputMute(ctx context.Context, index uint32, mute bool) error {
+	w := &writer{}
+	w.u32(index)
+	w.boolean(mute)
+	_, err := c.request(ctx, commandSetSinkInputMute, w)
+	return err
+}
+
+// MoveSinkInput moves a playback stream to the sink given by index or name.
+func (c *Client) MoveSinkInput(ctx context.Context, index uint32, sinkIndex uint32, sinkName string) error {
+	w := &writer{}
+	w.u32(index)
+	w.u32(sinkIndex)
+	w.str(sinkName)
+	_, err := c.request(ctx, commandMoveSinkInput, w)
+	return err
+}
+
+// MoveSourceOutput moves a recording stream to the source given by index
+// or name.
+func (c *Client) MoveSourceOutput(ctx context.Context, index uint32, sourceIndex uint32, sourceName string) error {
+	w := &writer{}
+	w.u32(index)
+	w.u32(sourceIndex)
+	w.str(sourceName)
+	_, err := c.request(ctx, commandMoveSourceOutput, w)
+	return err
+}
+
+// SetCardProfile activates a card profile; the card is addressed by index
+// or (with InvalidIndex) by name.
+func (c *Client) SetCardProfile(ctx context.Context, index uint32, card string, profile string) error {
+	w := &writer{}
+	w.u32(index)
+	w.str(card)
+	w.str(profile)
+	_, err := c.request(ctx, commandSetCardProfile, w)
+	return err
+}
+
+// SendMessage sends message with params to the object at path through the
+// message API (PulseAudio 15), e.g. "switch-codec" to
+// "/card/<card>/bluez", and returns the object's response.
+func (c *Client) SendMessage(ctx context.Context, path string, message string, params string) (string, error) {
+	w := &writer{}
+	w.str(path)
+	w.str(message)
+	w.str(params)
+	r, err := c.request(ctx, commandSendObjectMessage, w)
+	if err != nil {
+		return "", err
+	}
+	response := r.str()
+	return response, r.err
+}
+
+// ── Wire layouts ───────────────────────────────────────────────────────────
+// Each read* has a matching write* so FakeServer produces exactly what the
+// client parses. Field order follows pulse/introspect.c.
+
+func readServerInfo(r *reader, version uint32) ServerInfo {
+	info := ServerInfo{
+		PackageName:    r.str(),
+		PackageVersion: r.str(),
+		UserName:       r.str(),
+		HostName:       r.str(),
+		SampleSpec:     r.sampleSpec(),
+		DefaultSink:    r.str(),
+		DefaultSource:  r.str(),
+		Cookie:         r.u32(),
+	}
+	if version >= 15 {
+		info.ChannelMap = r.channelMap()
+	}
+	return info
+}
+
+func writeServerInfo(w *writer, version uint32, info ServerInfo) {
+	w.str(info.PackageName)
+	w.str(info.PackageVersion)
+	w.str(info.UserName)
+	w.str(info.HostName)
+	w.sampleSpec(info.SampleSpec)
+	w.str(info.DefaultSink)
+	w.str(info.DefaultSource)
+	w.u32(info.Cookie)
+	if version >= 15 {
+		w.channelMap(info.ChannelMap)
+	}
+}
+
+func readDeviceInfo(r *reader, version uint32, sink bool) DeviceInfo {
+	d := DeviceInfo{
+		Index:        r.u32(),
+		Name:         r.str(),
+		Description:  r.str(),
+		SampleSpec:   r.sampleSpec(),
+		ChannelMap:   r.channelMap(),
+		OwnerModule:  r.u32(),
+		Volume:       r.cvolume(),
+		Mute:         r.boolean(),
+		MonitorIndex: r.u32(),
+		MonitorName:  r.str(),
+		Latency:      r.usec(),
+		Driver:       r.str(),
+		Flags:        r.u32(),
+	}
+	if version >= 13 {
+		d.Properties = r.propList()
+		d.ConfiguredLatency = r.usec()
+	}
+	if version >= 15 {
+		d.BaseVolume = r.volume()
+		d.State = r.u32()
+		d.VolumeSteps = r.u32()
+		d.Card = r.u32()
+	}
+	if version >= 16 {
+		n := r.u32()
+		for i := uint32(0); i < n && r.err == nil; i++ {
+			p := PortInfo{Name: r.str(), Description: r.str(), Priority: r.u32()}
+			if version >= 24 {
+				p.Available = r.u32()
+			}
+			d.Ports = append(d.Ports, p)
+		}
+		d.ActivePort = r.str()
+	}
+	if (sink && version >= 21) || (!sink && version >= 22) {
+		n := r.u8()
+		for i := uint8(0); i < n && r.err == nil; i++ {
+			d.Formats = append(d.Formats, r.formatInfo())
+		}
+	}
+	return d
+}
+
+func writeDeviceInfo(w *writer, version uint32, sink bool, d DeviceInfo) {
+	w.u32(d.Index)
+	w.str(d.Name)
+	w.str(d.Description)
+	w.sampleSpec(d.SampleSpec)
+	w.channelMap(d.ChannelMap)
+	w.u32(d.OwnerModule)
+	w.cvolume(d.Volume)
+	w.boolean(d.Mute)
+	w.u32(d.MonitorIndex)
+	w.str(d.MonitorName)
+	w.usec(d.Latency)
+	w.str(d.Driver)
+	w.u32(d.Flags)
+	if version >= 13 {
+		w.propList(d.Properties)
+		w.usec(d.ConfiguredLatency)
+	}
+	if version >= 15 {
+		w.volume(d.BaseVolume)
+		w.u32(d.State)
+		w.u32(d.VolumeSteps)
+		w.u32(d.Card)
+	}
+	if version >= 16 {
+		w.u32(uint32(len(d.Ports)))
+		for _, p := range d.Ports {
+			w.str(p.Name)
+			w.str(p.Description)
+			w.u32(p.Priority)
+			if version >= 24 {
+				w.u32(p.Available)
+			}
+		}
+		w.str(d.ActivePort)
+	}
+	if (sink && version >= 21) || (!sink && version >= 22) {
+		w.u8(uint8(len(d.Formats)))
+		for _, f := range d.Formats {
+			w.formatInfo(f)
+		}
+	}
+}
+
+func readCardInfo(r *reader, version uint32) CardInfo {
+	card := CardInfo{
+		Index:       r.u32(),
+		Name:        r.str(),
+		OwnerModule: r.u32(),
+		Driver:      r.str(),
+	}
+	n := r.u32()
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		p := CardProfileInfo{
+			Name:        r.str(),
+			Description: r.str(),
+			Sinks:       r.u32(),
+			Sources:     r.u32(),
+			Priority:    r.u32(),
+			Available:   true,
+		}
+		if version >= 29 {
+			p.Available = r.u32() != 0
+		}
+		card.Profiles = append(card.Profiles, p)
+	}
+	card.ActiveProfile = r.str()
+	card.Properties = r.propList()
+	if version >= 26 {
+		n := r.u32()
+		for i := uint32(0); i < n && r.err == nil; i++ {
+			p := CardPortInfo{
+				Name:        r.str(),
+				Description: r.str(),
+				Priority:    r.u32(),
+				Available:   r.u32(),
+				Direction:   r.u8(),
+				Properties:  r.propList(),
+			}
+			np := r.u32()
+			for j := uint32(0); j < np && r.err == nil; j++ {
+				p.Profiles = append(p.Profiles, r.str())
+			}
+			if version >= 27 {
+				p.LatencyOffset = r.s64()
+			}
+			card.Ports = append(card.Ports, p)
+		}
+	}
+	return card
+}
+
+func writeCardInfo(w *writer, version uint32, card CardInfo) {
+	w.u32(card.Index)
+	w.str(card.Name)
+	w.u32(card.OwnerModule)
+	w.str(card.Driver)
+	w.u32(uint32(len(card.Profiles)))
+	for _, p := range card.Profiles {
+		w.str(p.Name)
+		w.str(p.Description)
+		w.u32(p.Sinks)
+		w.u32(p.Sources)
+		w.u32(p.Priority)
+		if version >= 29 {
+			available := uint32(0)
+			if p.Available {
+				available = 1
+			}
+			w.u32(available)
+		}
+	}
+	w.str(card.ActiveProfile)
+	w.propList(card.Properties)
+	if version >= 26 {
+		w.u32(uint32(len(card.Ports)))
+		for _, p := range card.Ports {
+			w.str(p.Name)
+			w.str(p.Description)
+			w.u32(p.Priority)
+			w.u32(p.Available)
+			w.u8(p.Direction)
+			w.propList(p.Properties)
+			w.u32(uint32(len(p.Profiles)))
+			for _, name := range p.Profiles {
+				w.str(name)
+			}
+			if version >= 27 {
+				w.s64(p.LatencyOffset)
+			}
+		}
+	}
+}
+
+func readSinkInputInfo(r *reader, version uint32) SinkInputInfo {
+	si := SinkInputInfo{
+		Index:          r.u32(),
+		Name:           r.str(),
+		OwnerModule:    r.u32(),
+		Client:         r.u32(),
+		Sink:           r.u32(),
+		SampleSpec:     r.sampleSpec(),
+		ChannelMap:     r.channelMap(),
+		Volume:         r.cvolume(),
+		BufferUsec:     r.usec(),
+		SinkUsec:       r.usec(),
+		ResampleMethod: r.str(),
+		Driver:         r.str(),
+	}
+	if version >= 11 {
+		si.Mute = r.boolean()
+	}
+	if version >= 13 {
+		si.Properties = r.propList()
+	}
+	if version >= 19 {
+		si.Corked = r.boolean()
+	}
+	if version >= 20 {
+		si.HasVolume = r.boolean()
+		si.VolumeWritable = r.boolean()
+	}
+	if version >= 21 {
+		si.Format = r.formatInfo()
+	}
+	return si
+}
+
+func writeSinkInputInfo(w *writer, version uint32, si SinkInputInfo) {
+	w.u32(si.Index)
+	w.str(si.Name)
+	w.u32(si.OwnerModule)
+	w.u32(si.Client)
+	w.u32(si.Sink)
+	w.sampleSpec(si.SampleSpec)
+	w.channelMap(si.ChannelMap)
+	w.cvolume(si.Volume)
+	w.usec(si.BufferUsec)
+	w.usec(si.SinkUsec)
+	w.str(si.ResampleMethod)
+	w.str(si.Driver)
+	if version >= 11 {
+		w.boolean(si.Mute)
+	}
+	if version >= 13 {
+		w.propList(si.Properties)
+	}
+	if version >= 19 {
+		w.boolean(si.Corked)
+	}
+	if version >= 20 {
+		w.boolean(si.HasVolume)
+		w.boolean(si.VolumeWritable)
+	}
+	if version >= 21 {
+		w.formatInfo(si.Format)
+	}
+}
+
+func readSourceOutputInfo(r *reader, version uint32) SourceOutputInfo {
+	so := SourceOutputInfo{
+		Index:          r.u32(),
+		Name:           r.str(),
+		OwnerModule:    r.u32(),
+		Client:         r.u32(),
+		Source:         r.u32(),
+		SampleSpec:     r.sampleSpec(),
+		ChannelMap:     r.channelMap(),
+		BufferUsec:     r.usec(),
+		SourceUsec:     r.usec(),
+		ResampleMethod: r.str(),
+		Driver:         r.str(),
+	}
+	if version >= 13 {
+		so.Properties = r.propList()
+	}
+	if version >= 19 {
+		so.Corked = r.boolean()
+	}
+	if version >= 22 {
+		so.Volume = r.cvolume()
+		so.Mute = r.boolean()
+		so.HasVolume = r.boolean()
+		so.VolumeWritable = r.boolean()
+		so.Format = r.formatInfo()
+	}
+	return so
+}
+
+func writeSourceOutputInfo(w *writer, version uint32, so SourceOutputInfo) {
+	w.u32(so.Index)
+	w.str(so.Name)
+	w.u32(so.OwnerModule)
+	w.u32(so.Client)
+	w.u32(so.Source)
+	w.sampleSpec(so.SampleSpec)
+	w.channelMap(so.ChannelMap)
+	w.usec(so.BufferUsec)
+	w.usec(so.SourceUsec)
+	w.str(so.ResampleMethod)
+	w.str(so.Driver)
+	if version >= 13 {
+		w.propList(so.Properties)
+	}
+	if version >= 19 {
+		w.boolean(so.Corked)
+	}
+	if version >= 22 {
+		w.cvolume(so.Volume)
+		w.boolean(so.Mute)
+		w.boolean(so.HasVolume)
+		w.boolean(so.VolumeWritable)
+		w.formatInfo(so.Format)
+	}
+}
+
+// ── Value helpers ──────────────────────────────────────────────────────────
+
+var sampleFormatNames = []string{
+	"u8", "aLaw", "uLaw", "s16le", "s16be", "float32le", "float32be",
+	"s32le", "s32be", "s24le", "s24be", "s24-32le", "s24-32be",
+}
+
+// String formats a sample spec the way pactl does, e.g. "s16le 2ch 48000Hz".
+func (s SampleSpec) String() string {
+	format := "invalid"
+	if int(s.Format) < len(sampleFormatNames) {
+		format = sampleFormatNames[s.Format]
+	}
+	return fmt.Sprintf("%s %dch %dHz", format, s.Channels, s.Rate)
+}
+
+var channelPositionNames = []string{
+	"mono", "front-left", "front-right", "front-center", "rear-center",
+	"rear-left", "rear-right", "lfe", "front-left-of-center",
+	"front-right-of-center", "side-left", "side-right",
+}
+
+// ChannelPositionName returns pactl's name for a channel map position.
+func ChannelPositionName(pos uint8) string {
+	switch {
+	case int(pos) < len(channelPositionNames):
+		return channelPositionNames[pos]
+	case pos >= 12 && pos < 44:
+		return fmt.Sprintf("aux%d", pos-12)
+	}
+	top := []string{"top-center", "top-front-left", "top-front-right", "top-front-center", "top-rear-left", "top-rear-right", "top-rear-center"}
+	if pos >= 44 && int(pos-44) < len(top) {
+		return top[pos-44]
+	}
+	return fmt.Sprintf("pos%d", pos)
+}
+
+// VolumePercent converts a raw volume to a rounded percentage.
+func VolumePercent(v uint32) int {
+	return int((uint64(v)*100 + volumeNorm/2) / volumeNorm)
+}
+
+// VolumeFromPercent converts a percentage to a raw volume.
+func VolumeFromPercent(percent int) uint32 {
+	if percent <= 0 {
+		return 0
+	}
+	return uint32((uint64(percent)*volumeNorm + 50) / 100)
+}
+
+// VolumeDB converts a raw (software, cubic) volume to decibels.
+func VolumeDB(v uint32) float64 {
+	if v == 0 {
+		return math.Inf(-1)
+	}
+	return 60 * math.Log10(float64(v)/volumeNorm)
+}
+
+// VolumeFromDB converts decibels to a raw (software, cubic) volume.
+func VolumeFromDB(db float64) uint32 {
+	if math.IsInf(db, -1) {
+		return 0
+	}
+	return uint32(math.Round(volumeNorm * math.Pow(10, db/60)))
+}
+
+// StateName returns pactl's name for a sink/source state.
+func StateName(state uint32) string {
+	switch state {
+	case StateRunning:
+		return "RUNNING"
+	case StateIdle:
+		return "IDLE"
+	case StateSuspended:
+		return "SUSPENDED"
+	}
+	return "INVALID"
+}
@@ -0,0 +1,53 @@
+package pulse
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+)
+
+const (
+	descriptorSize = 20
+	controlChannel = 0xFFFFFFFF
+	maxPacketSize  = 16 * 1024 * 1024
+)
+
+// writePacket frames a control payload with the 20-byte pstream descriptor
+// (length, channel, offset hi/lo, flags).
+func writePacket(w io.Writer, payload []byte) error {
+	frame := make([]byte, descriptorSize, descriptorSize+len(payload))
+	binary.BigEndian.PutUint32(frame[0:], uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[4:], controlChannel)
+	frame = append(frame, payload...)
+	_, err := w.Write(frame)
+	return err
+}
+
+// readPacket reads one frame. Memblock frames (channel != control) are
+// returned with ok=false so callers can skip them.
+func readPacket(r io.Reader) (payload []byte, ok bool, err error) {
+	var desc [descriptorSize]byte
+	if _, err := io.ReadFull(r, desc[:]); err != nil {
+		return nil, false, err
+	}
+	length := binary.BigEndian.Uint32(desc[0:])
+	channel := binary.BigEndian.Uint32(desc[4:])
+	if length > maxPacketSize {
+		return nil, false, fmt.Errorf("pulse: packet too large (%d bytes)", length)
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, false, err
+	}
+	return payload, channel == controlChannel, nil
+}
+
+func sortedKeys(p PropList) []string {
+	keys := make([]string, 0, len(p))
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,36 @@
+// Package pulsetest sets up a pulse.FakeServer and a native audio service
+// on top of it for tests, so each package only has to describe its state.
+package pulsetest
+
+import (
+	"context"
+	"testing"
+
+	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/pulse"
+)
+
+// NewServer serves state from a fake server in a temporary directory,
+// closed when the test ends.
+func NewServer(t testing.TB, state pulse.FakeState) *pulse.FakeServer {
+	t.Helper()
+	srv, err := pulse.NewFakeServer(t.TempDir(), state)
+	if err != nil {
+		t.Fatalf("NewFakeServer: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	return srv
+}
+
+// NewService serves state like NewServer and connects the native audio
+// service to it, closed when the test ends.
+func NewService(t testing.TB, state pulse.FakeState) (*pulse.FakeServer, audio.Service) {
+	t.Helper()
+	srv := NewServer(t, state)
+	svc, err := audio.NewNativeService(context.Background(), srv.Path)
+	if err != nil {
+		t.Fatalf("NewNativeService: %v", err)
+	}
+	t.Cleanup(func() { svc.Close() })
+	return srv, svc
+}
@@ -0,0 +1,102 @@
+package pulse
+
+import "context"
+
+// Subscription masks for Subscribe, see pa_subscription_mask_t.
+const (
+	MaskSink         = 0x0001
+	MaskSource       = 0x0002
+	MaskSinkInput    = 0x0004
+	MaskSourceOutput = 0x0008
+	MaskModule       = 0x0010
+	MaskClient       = 0x0020
+	MaskServer       = 0x0080
+	MaskCard         = 0x0200
+	MaskAll          = 0x02ff
+)
+
+// Event facilities and kinds packed into SubscribeEvent.Type.
+const (
+	FacilitySink         = 0x00
+	FacilitySource       = 0x01
+	FacilitySinkInput    = 0x02
+	FacilitySourceOutput = 0x03
+	FacilityModule       = 0x04
+	FacilityClient       = 0x05
+	FacilitySampleCache  = 0x06
+	FacilityServer       = 0x07
+	FacilityCard         = 0x09
+	facilityMask         = 0x0f
+
+	EventNew    = 0x00
+	EventChange = 0x10
+	EventRemove = 0x20
+	eventMask   = 0x30
+)
+
+// SubscribeEvent is a server-side change notification.
+type SubscribeEvent struct {
+	Type  uint32
+	Index uint32
+}
+
+// Facility returns the object class the event refers to.
+func (e SubscribeEvent) Facility() uint32 {
+	return e.Type & facilityMask
+}
+
+// Kind returns EventNew, EventChange or EventRemove.
+func (e SubscribeEvent) Kind() uint32 {
+	return e.Type & eventMask
+}
+
+// FacilityName returns the pactl spelling of the facility, e.g. "sink-input".
+func (e SubscribeEvent) FacilityName() string {
+	switch e.Facility() {
+	case FacilitySink:
+		return "sink"
+	case FacilitySource:
+		return "source"
+	case FacilitySinkInput:
+		return "sink-input"
+	case FacilitySourceOutput:
+		return "source-output"
+	case FacilityModule:
+		return "module"
+	case FacilityClient:
+		return "client"
+	case FacilitySampleCache:
+		return "sample-cache"
+	case FacilityServer:
+		return "server"
+	case FacilityCard:
+		return "card"
+	}
+	return "unknown"
+}
+
+// KindName returns the pactl spelling of the kind: "new", "change" or "remove".
+func (e SubscribeEvent) KindName() string {
+	switch e.Kind() {
+	case EventNew:
+		return "new"
+	case EventChange:
+		return "change"
+	case EventRemove:
+		return "remove"
+	}
+	return "unknown"
+}
+
+// Subscribe enables change notifications for mask and delivers them to fn
+// from the connection's read goroutine; fn must not block. Calling it again
+// replaces the mask and handler.
+func (c *Client) Subscribe(ctx context.Context, mask uint32, fn func(SubscribeEvent)) error {
+	c.mu.Lock()
+	c.onEvent = fn
+	c.mu.Unlock()
+	w := &writer{}
+	w.u32(mask)
+	_, err := c.request(ctx, commandSubscribe, w)
+	return err
+}
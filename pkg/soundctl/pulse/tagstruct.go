@@ -0,0 +1,381 @@
+package pulse
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Tagstruct type tags, see pulsecore/tagstruct.h.
+const (
+	tagString      = 't'
+	tagStringNull  = 'N'
+	tagU32         = 'L'
+	tagU8          = 'B'
+	tagU64         = 'R'
+	tagS64         = 'r'
+	tagSampleSpec  = 'a'
+	tagArbitrary   = 'x'
+	tagBoolTrue    = '1'
+	tagBoolFalse   = '0'
+	tagTimeval     = 'T'
+	tagUsec        = 'U'
+	tagChannelMap  = 'm'
+	tagCVolume     = 'v'
+	tagPropList    = 'P'
+	tagVolume      = 'V'
+	tagFormatInfo  = 'f'
+	invalidIndex   = math.MaxUint32
+	volumeNorm     = 0x10000
+	maxTagChannels = 32
+)
+
+// SampleSpec mirrors pa_sample_spec.
+type SampleSpec struct {
+	Format   uint8
+	Channels uint8
+	Rate     uint32
+}
+
+// PropList mirrors pa_proplist with string values only.
+type PropList map[string]string
+
+// FormatInfo mirrors pa_format_info.
+type FormatInfo struct {
+	Encoding   uint8
+	Properties PropList
+}
+
+// writer builds a tagstruct payload.
+type writer struct {
+	buf []byte
+}
+
+func (w *writer) u8(v uint8) {
+	w.buf = append(w.buf, tagU8, v)
+}
+
+func (w *writer) u32(v uint32) {
+	w.buf = append(w.buf, tagU32)
+	w.buf = binary.BigEndian.AppendUint32(w.buf, v)
+}
+
+func (w *writer) u64(v uint64) {
+	w.buf = append(w.buf, tagU64)
+	w.buf = binary.BigEndian.AppendUint64(w.buf, v)
+}
+
+func (w *writer) s64(v int64) {
+	w.buf = append(w.buf, tagS64)
+	w.buf = binary.BigEndian.AppendUint64(w.buf, uint64(v))
+}
+
+func (w *writer) usec(v uint64) {
+	w.buf = append(w.buf, tagUsec)
+	w.buf = binary.BigEndian.AppendUint64(w.buf, v)
+}
+
+func (w *writer) boolean(v bool) {
+	if v {
+		w.buf = append(w.buf, tagBoolTrue)
+	} else {
+		w.buf = append(w.buf, tagBoolFalse)
+	}
+}
+
+// str writes a string; the empty string is encoded as NULL, matching how
+// libpulse treats optional names.
+func (w *writer) str(s string) {
+	if s == "" {
+		w.buf = append(w.buf, tagStringNull)
+		return
+	}
+	w.buf = append(w.buf, tagString)
+	w.buf = append(w.buf, s...)
+	w.buf = append(w.buf, 0)
+}
+
+func (w *writer) arbitrary(b []byte) {
+	w.buf = append(w.buf, tagArbitrary)
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+func (w *writer) sampleSpec(ss SampleSpec) {
+	w.buf = append(w.buf, tagSampleSpec, ss.Format, ss.Channels)
+	w.buf = binary.BigEndian.AppendUint32(w.buf, ss.Rate)
+}
+
+func (w *writer) channelMap(m []uint8) {
+	w.buf = append(w.buf, tagChannelMap, uint8(len(m)))
+	w.buf = append(w.buf, m...)
+}
+
+func (w *writer) cvolume(v []uint32) {
+	w.buf = append(w.buf, tagCVolume, uint8(len(v)))
+	for _, c := range v {
+		w.buf = binary.BigEndian.AppendUint32(w.buf, c)
+	}
+}
+
+func (w *writer) volume(v uint32) {
+	w.buf = append(w.buf, tagVolume)
+	w.buf = binary.BigEndian.AppendUint32(w.buf, v)
+}
+
+func (w *writer) propList(p PropList) {
+	w.buf = append(w.buf, tagPropList)
+	for _, k := range sortedKeys(p) {
+		v := append([]byte(p[k]), 0)
+		w.str(k)
+		w.u32(uint32(len(v)))
+		w.arbitrary(v)
+	}
+	w.buf = append(w.buf, tagStringNull)
+}
+
+func (w *writer) formatInfo(f FormatInfo) {
+	w.buf = append(w.buf, tagFormatInfo)
+	w.u8(f.Encoding)
+	w.propList(f.Properties)
+}
+
+// reader decodes a tagstruct payload. The first error sticks; callers check
+// err once after a sequence of reads.
+type reader struct {
+	buf []byte
+	err error
+}
+
+func (r *reader) fail(format string, args ...any) {
+	if r.err == nil {
+		r.err = fmt.Errorf("tagstruct: "+format, args...)
+	}
+}
+
+func (r *reader) tag(want byte) bool {
+	if r.err != nil {
+		return false
+	}
+	if len(r.buf) == 0 {
+		r.fail("unexpected end of data, want %q", want)
+		return false
+	}
+	if r.buf[0] != want {
+		r.fail("unexpected tag %q, want %q", r.buf[0], want)
+		return false
+	}
+	r.buf = r.buf[1:]
+	return true
+}
+
+func (r *reader) take(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.buf) < n {
+		r.fail("short read: need %d bytes, have %d", n, len(r.buf))
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *reader) eof() bool {
+	return r.err == nil && len(r.buf) == 0
+}
+
+func (r *reader) u8() uint8 {
+	if !r.tag(tagU8) {
+		return 0
+	}
+	if b := r.take(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *reader) u32() uint32 {
+	if !r.tag(tagU32) {
+		return 0
+	}
+	if b := r.take(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *reader) u64() uint64 {
+	if !r.tag(tagU64) {
+		return 0
+	}
+	if b := r.take(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *reader) s64() int64 {
+	if !r.tag(tagS64) {
+		return 0
+	}
+	if b := r.take(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (r *reader) usec() uint64 {
+	if !r.tag(tagUsec) {
+		return 0
+	}
+	if b := r.take(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *reader) boolean() bool {
+	if r.err != nil {
+		return false
+	}
+	if len(r.buf) == 0 {
+		r.fail("unexpected end of data, want boolean")
+		return false
+	}
+	switch r.buf[0] {
+	case tagBoolTrue:
+		r.buf = r.buf[1:]
+		return true
+	case tagBoolFalse:
+		r.buf = r.buf[1:]
+		return false
+	}
+	r.fail("unexpected tag %q, want boolean", r.buf[0])
+	return false
+}
+
+func (r *reader) str() string {
+	if r.err != nil {
+		return ""
+	}
+	if len(r.buf) > 0 && r.buf[0] == tagStringNull {
+		r.buf = r.buf[1:]
+		return ""
+	}
+	if !r.tag(tagString) {
+		return ""
+	}
+	for i, c := range r.buf {
+		if c == 0 {
+			s := string(r.buf[:i])
+			r.buf = r.buf[i+1:]
+			return s
+		}
+	}
+	r.fail("unterminated string")
+	return ""
+}
+
+func (r *reader) arbitrary() []byte {
+	if !r.tag(tagArbitrary) {
+		return nil
+	}
+	b := r.take(4)
+	if b == nil {
+		return nil
+	}
+	return r.take(int(binary.BigEndian.Uint32(b)))
+}
+
+func (r *reader) sampleSpec() SampleSpec {
+	if !r.tag(tagSampleSpec) {
+		return SampleSpec{}
+	}
+	b := r.take(6)
+	if b == nil {
+		return SampleSpec{}
+	}
+	return SampleSpec{Format: b[0], Channels: b[1], Rate: binary.BigEndian.Uint32(b[2:])}
+}
+
+func (r *reader) channelMap() []uint8 {
+	if !r.tag(tagChannelMap) {
+		return nil
+	}
+	n := r.take(1)
+	if n == nil {
+		return nil
+	}
+	if n[0] > maxTagChannels {
+		r.fail("too many channels: %d", n[0])
+		return nil
+	}
+	return append([]uint8(nil), r.take(int(n[0]))...)
+}
+
+func (r *reader) cvolume() []uint32 {
+	if !r.tag(tagCVolume) {
+		return nil
+	}
+	n := r.take(1)
+	if n == nil {
+		return nil
+	}
+	if n[0] > maxTagChannels {
+		r.fail("too many channels: %d", n[0])
+		return nil
+	}
+	vols := make([]uint32, 0, n[0])
+	for i := 0; i < int(n[0]); i++ {
+		b := r.take(4)
+		if b == nil {
+			return nil
+		}
+		vols = append(vols, binary.BigEndian.Uint32(b))
+	}
+	return vols
+}
+
+func (r *reader) volume() uint32 {
+	if !r.tag(tagVolume) {
+		return 0
+	}
+	if b := r.take(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *reader) propList() PropList {
+	if !r.tag(tagPropList) {
+		return nil
+	}
+	p := PropList{}
+	for r.err == nil {
+		if len(r.buf) > 0 && r.buf[0] == tagStringNull {
+			r.buf = r.buf[1:]
+			return p
+		}
+		key := r.str()
+		n := r.u32()
+		v := r.arbitrary()
+		if r.err == nil && uint32(len(v)) != n {
+			r.fail("proplist value length mismatch for %q", key)
+		}
+		// String values carry a trailing NUL.
+		if len(v) > 0 && v[len(v)-1] == 0 {
+			v = v[:len(v)-1]
+		}
+		p[key] = string(v)
+	}
+	return nil
+}
+
+func (r *reader) formatInfo() FormatInfo {
+	if !r.tag(tagFormatInfo) {
+		return FormatInfo{}
+	}
+	return FormatInfo{Encoding: r.u8(), Properties: r.propList()}
+}
@@ -0,0 +1,319 @@
+// Package resolve turns what a user types for a sink, source, card or
+// bluetooth device — an index, an exact name, a description, an alias, a
+// bluetooth device name or a unique prefix — into the object the audio and
+// bluetooth services expect.
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/bluetooth"
+)
+
+// Kind is the kind of object a query resolves to.
+type Kind string
+
+const (
+	Sink   Kind = "sink"
+	Source Kind = "source"
+	Card   Kind = "card"
+	Device Kind = "device" // bluetooth device
+)
+
+// Candidate is an object a query can resolve to.
+type Candidate struct {
+	Kind        Kind
+	ID          int    // sink/source/card index; -1 for bluetooth devices
+	Name        string // pactl name, or the address of a bluetooth device
+	Description string // description, or the name of a bluetooth device
+	Address     string // bluetooth address, when the object belongs to a bluetooth device
+}
+
+// NotFoundError is returned when nothing matches a query.
+type NotFoundError struct {
+	Kind  Kind
+	Query string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("no %s matches %q", e.Kind, e.Query)
+}
+
+// AmbiguousError is returned when a query matches more than one object at
+// the same level of precision.
+type AmbiguousError struct {
+	Kind       Kind
+	Query      string
+	Candidates []Candidate
+}
+
+func (e *AmbiguousError) Error() string {
+	names := make([]string, len(e.Candidates))
+	for i, c := range e.Candidates {
+		names[i] = c.Name
+		if c.Description != "" && c.Description != c.Name {
+			names[i] += " (" + c.Description + ")"
+		}
+	}
+	return fmt.Sprintf("%s %q is ambiguous, candidates: %s", e.Kind, e.Query, strings.Join(names, ", "))
+}
+
+// Resolver resolves user input against the current sinks, sources, cards
+// and bluetooth devices. The bluetooth service is optional and only
+// consulted when a query needs it.
+type Resolver struct {
+	audio   audio.Service
+	bt      bluetooth.Service
+	aliases func() (map[string]string, error)
+}
+
+func New(au audio.Service, bt bluetooth.Service) *Resolver {
+	return &Resolver{audio: au, bt: bt}
+}
+
+// WithAliases sets where user-defined aliases come from. load is called
+// on every resolution and returns friendly names mapped to the query they
+// stand for: a bluetooth address, a sink, source or card name, or
+// anything else the resolver accepts.
+func (r *Resolver) WithAliases(load func() (map[string]string, error)) *Resolver {
+	r.aliases = load
+	return r
+}
+
+func (r *Resolver) Sink(ctx context.Context, query string) (Candidate, error) {
+	return r.Resolve(ctx, Sink, query)
+}
+
+func (r *Resolver) Source(ctx context.Context, query string) (Candidate, error) {
+	return r.Resolve(ctx, Source, query)
+}
+
+func (r *Resolver) Card(ctx context.Context, query string) (Candidate, error) {
+	return r.Resolve(ctx, Card, query)
+}
+
+// Target resolves query as a sink or source, as named by the --target flag
+// of the volume and mute verbs.
+func (r *Resolver) Target(ctx context.Context, target string, query string) (Candidate, error) {
+	switch Kind(target) {
+	case Sink, Source:
+		return r.Resolve(ctx, Kind(target), query)
+	default:
+		return Candidate{}, fmt.Errorf("invalid target %q: expected sink or source", target)
+	}
+}
+
+func (r *Resolver) Device(ctx context.Context, query string) (Candidate, error) {
+	return r.Resolve(ctx, Device, query)
+}
+
+// Resolve finds the one object of kind that query names. It tries, in
+// order: an alias, the index, the exact name, the bluetooth address, the
+// description, the name of a bluetooth device, a prefix and finally a
+// substring of the name or description. The first step with any match
+// decides: one match is the result, several are an *AmbiguousError.
+func (r *Resolver) Resolve(ctx context.Context, kind Kind, query string) (Candidate, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return Candidate{}, fmt.Errorf("%s is required", kind)
+	}
+	if r.aliases != nil {
+		aliases, err := r.aliases()
+		if err != nil {
+			return Candidate{}, err
+		}
+		if target, ok := lookupAlias(aliases, query); ok {
+			query = target
+		}
+	}
+	address := ""
+	if audio.IsAddress(query) {
+		address = audio.NormalizeAddress(query)
+	}
+	// An address needs no lookup: it may name a device bluez has not
+	// listed yet, or bluez may not be reachable at all.
+	passthrough := Candidate{Kind: Device, ID: -1, Name: address, Address: address}
+	cands, err := r.Candidates(ctx, kind)
+	if err != nil {
+		if kind == Device && address != "" {
+			return passthrough, nil
+		}
+		return Candidate{}, err
+	}
+
+	lower := strings.ToLower(query)
+	stages := []func(Candidate) bool{
+		func(c Candidate) bool { return kind != Device && strconv.Itoa(c.ID) == query },
+		func(c Candidate) bool { return c.Name == query },
+		func(c Candidate) bool { return address != "" && c.Address == address },
+		func(c Candidate) bool { return strings.EqualFold(c.Description, query) },
+	}
+	btNamed := func(match func(name string) bool) func(Candidate) bool {
+		var addrs map[string]bool
+		return func(c Candidate) bool {
+			if addrs == nil {
+				addrs = r.bluetoothAddresses(ctx, match)
+			}
+			return c.Address != "" && addrs[c.Address]
+		}
+	}
+	if kind != Device {
+		stages = append(stages, btNamed(func(name string) bool { return strings.EqualFold(name, query) }))
+	}
+	stages = append(stages, func(c Candidate) bool {
+		return strings.HasPrefix(strings.ToLower(c.Name), lower) ||
+			strings.HasPrefix(strings.ToLower(shortName(c.Name)), lower) ||
+			strings.HasPrefix(strings.ToLower(c.Description), lower)
+	})
+	if kind != Device {
+		stages = append(stages, btNamed(func(name string) bool { return strings.HasPrefix(strings.ToLower(name), lower) }))
+	}
+	stages = append(stages, func(c Candidate) bool {
+		return strings.Contains(strings.ToLower(c.Name), lower) ||
+			strings.Contains(strings.ToLower(c.Description), lower)
+	})
+
+	for _, match := range stages {
+		var matched []Candidate
+		for _, c := range cands {
+			if match(c) {
+				matched = append(matched, c)
+			}
+		}
+		switch {
+		case len(matched) == 1:
+			return matched[0], nil
+		case len(matched) > 1:
+			return Candidate{}, &AmbiguousError{Kind: kind, Query: query, Candidates: matched}
+		}
+	}
+	if kind == Device && address != "" {
+		return passthrough, nil
+	}
+	return Candidate{}, &NotFoundError{Kind: kind, Query: query}
+}
+
+// ResolveAlias resolves name when it is a user-defined alias and returns
+// anything else unchanged. Presets use it: a name saved in a preset must
+// never fuzzy-match some other device.
+func (r *Resolver) ResolveAlias(ctx context.Context, kind Kind, name string) (string, error) {
+	if r.aliases == nil {
+		return name, nil
+	}
+	aliases, err := r.aliases()
+	if err != nil {
+		return "", err
+	}
+	if _, ok := lookupAlias(aliases, name); !ok {
+		return name, nil
+	}
+	c, err := r.Resolve(ctx, kind, name)
+	if err != nil {
+		return "", err
+	}
+	return c.Name, nil
+}
+
+// Candidates lists every object of kind.
+func (r *Resolver) Candidates(ctx context.Context, kind Kind) ([]Candidate, error) {
+	switch kind {
+	case Sink, Source:
+		var devices []audio.Device
+		var err error
+		if kind == Sink {
+			devices, err = r.audio.ListSinksDetailed(ctx)
+		} else {
+			devices, err = r.audio.ListSourcesDetailed(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+		cands := make([]Candidate, 0, len(devices))
+		for _, d := range devices {
+			cands = append(cands, Candidate{
+				Kind:        kind,
+				ID:          d.ID,
+				Name:        d.Name,
+				Description: d.Description,
+				Address:     audio.ObjectAddress(d.Name, d.Properties),
+			})
+		}
+		return cands, nil
+	case Card:
+		cards, err := r.audio.ListCardsDetailed(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cands := make([]Candidate, 0, len(cards))
+		for _, c := range cards {
+			cands = append(cands, Candidate{
+				Kind:        Card,
+				ID:          c.Index,
+				Name:        c.Name,
+				Description: c.Properties["device.description"],
+				Address:     audio.ObjectAddress(c.Name, c.Properties),
+			})
+		}
+		return cands, nil
+	case Device:
+		if r.bt == nil {
+			return nil, fmt.Errorf("bluetooth is not available")
+		}
+		devices, err := r.bt.ListDevices(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cands := make([]Candidate, 0, len(devices))
+		for _, d := range devices {
+			addr := audio.NormalizeAddress(d.Address)
+			cands = append(cands, Candidate{Kind: Device, ID: -1, Name: addr, Description: d.Name, Address: addr})
+		}
+		return cands, nil
+	default:
+		return nil, fmt.Errorf("invalid kind %q: expected sink, source, card or device", kind)
+	}
+}
+
+// bluetoothAddresses returns the addresses of the bluetooth devices whose
+// name matches. Bluetooth is best-effort here: without an adapter, audio
+// objects still resolve by everything else.
+func (r *Resolver) bluetoothAddresses(ctx context.Context, match func(name string) bool) map[string]bool {
+	addrs := map[string]bool{}
+	if r.bt == nil {
+		return addrs
+	}
+	devices, err := r.bt.ListDevices(ctx)
+	if err != nil {
+		return addrs
+	}
+	for _, d := range devices {
+		if match(d.Name) {
+			addrs[audio.NormalizeAddress(d.Address)] = true
+		}
+	}
+	return addrs
+}
+
+func lookupAlias(aliases map[string]string, query string) (string, bool) {
+	if target, ok := aliases[query]; ok {
+		return target, true
+	}
+	for name, target := range aliases {
+		if strings.EqualFold(name, query) {
+			return target, true
+		}
+	}
+	return "", false
+}
+
+// shortName drops the "alsa_output." / "bluez_card." style prefix.
+func shortName(name string) string {
+	if _, rest, ok := strings.Cut(name, "."); ok {
+		return rest
+	}
+	return name
+}
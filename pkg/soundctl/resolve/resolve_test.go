@@ -0,0 +1,108 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/bluetooth"
+	sexec "soundctl/pkg/soundctl/exec"
+)
+
+const (
+	builtinSink = "alsa_output.pci-0000_00_1f.3.analog-stereo"
+	airpodsSink = "bluez_output.08_FF_44_2B_4C_90.1"
+	dockSink    = "alsa_output.usb-Dock_DAC-00.analog-stereo"
+)
+
+func newTestResolver() *Resolver {
+	fake := sexec.NewFakeRunner()
+	fake.Set("pactl", []string{"list", "sinks"}, sexec.CommandResult{Output: `Sink #47
+	Name: alsa_output.pci-0000_00_1f.3.analog-stereo
+	Description: Built-in Audio Analog Stereo
+
+Sink #48
+	Name: bluez_output.08_FF_44_2B_4C_90.1
+	Description: AirPods Max
+
+Sink #49
+	Name: alsa_output.usb-Dock_DAC-00.analog-stereo
+	Description: Dock DAC Analog Stereo`})
+	fake.Set("bluetoothctl", []string{"devices"}, sexec.CommandResult{Output: "Device 08:FF:44:2B:4C:90 Wesen's Headphones"})
+	fake.Set("bluetoothctl", []string{"info", "08:FF:44:2B:4C:90"}, sexec.CommandResult{Output: `Device 08:FF:44:2B:4C:90 (public)
+	Name: Wesen's Headphones
+	Paired: yes
+	Connected: yes`})
+	return New(audio.NewExecService(fake), bluetooth.NewExecService(fake))
+}
+
+func TestResolveSink(t *testing.T) {
+	r := newTestResolver()
+	cases := map[string]string{
+		"48":                 airpodsSink,
+		builtinSink:          builtinSink,
+		"08:ff:44:2b:4c:90":  airpodsSink,
+		"airpods max":        airpodsSink,
+		"Wesen's Headphones": airpodsSink,
+		"wesen":              airpodsSink,
+		"Dock":               dockSink,
+		"usb-dock":           dockSink,
+		"built-in":           builtinSink,
+		"DAC":                dockSink,
+	}
+	for query, want := range cases {
+		got, err := r.Sink(context.Background(), query)
+		if err != nil {
+			t.Fatalf("Sink(%q): %v", query, err)
+		}
+		if got.Name != want {
+			t.Errorf("Sink(%q) = %s, want %s", query, got.Name, want)
+		}
+	}
+}
+
+func TestResolveAmbiguousAndMissing(t *testing.T) {
+	r := newTestResolver()
+	_, err := r.Sink(context.Background(), "alsa")
+	var ambiguous *AmbiguousError
+	if !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 {
+		t.Fatalf("expected ambiguity between the two alsa sinks, got %v", err)
+	}
+	_, err = r.Sink(context.Background(), "hdmi")
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) || notFound.Kind != Sink {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestResolveAliases(t *testing.T) {
+	r := newTestResolver().WithAliases(func() (map[string]string, error) {
+		return map[string]string{"desk": dockSink, "cans": "08:FF:44:2B:4C:90"}, nil
+	})
+	if got, err := r.Sink(context.Background(), "Desk"); err != nil || got.Name != dockSink {
+		t.Fatalf("Sink(Desk) = %+v, %v", got, err)
+	}
+	if got, err := r.Sink(context.Background(), "cans"); err != nil || got.Name != airpodsSink {
+		t.Fatalf("Sink(cans) = %+v, %v", got, err)
+	}
+	// Only aliases are expanded; other names are left alone.
+	for query, want := range map[string]string{"desk": dockSink, "Dock": "Dock", "gone": "gone"} {
+		if got, err := r.ResolveAlias(context.Background(), Sink, query); err != nil || got != want {
+			t.Errorf("ResolveAlias(%q) = %q, %v, want %q", query, got, err, want)
+		}
+	}
+}
+
+func TestResolveDevice(t *testing.T) {
+	r := newTestResolver()
+	got, err := r.Device(context.Background(), "wesen")
+	if err != nil || got.Address != "08:FF:44:2B:4C:90" {
+		t.Fatalf("Device(wesen) = %+v, %v", got, err)
+	}
+	// Unknown addresses pass through so unlisted devices can be connected.
+	got, err = r.Device(context.Background(), "aa:bb:cc:dd:ee:ff")
+	if err != nil || got.Address != "AA:BB:CC:DD:EE:FF" {
+		t.Fatalf("Device(address) = %+v, %v", got, err)
+	}
+}
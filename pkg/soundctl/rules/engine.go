@@ -0,0 +1,251 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/events"
+)
+
+// Action records one step the engine took, for logging.
+type Action struct {
+	Time      time.Time
+	Address   string
+	Operation string // "set-default-sink", "set-default-source", "move-stream", "pending"
+	Target    string
+	Err       error
+}
+
+// savedDefaults are the defaults in place before a rule switched them.
+type savedDefaults struct {
+	sink   string
+	source string // empty when the rule did not touch the source
+}
+
+// Engine reacts to bluetooth and audio events according to Config.
+// It is not safe for concurrent use; Run serialises event handling.
+type Engine struct {
+	au  audio.Service
+	cfg Config
+	now func() time.Time
+
+	pending map[string]Rule          // connected, waiting for the sink to appear
+	saved   map[string]savedDefaults // active rules, keyed by address
+}
+
+func NewEngine(au audio.Service, cfg Config) *Engine {
+	return &Engine{
+		au:      au,
+		cfg:     cfg,
+		now:     time.Now,
+		pending: map[string]Rule{},
+		saved:   map[string]savedDefaults{},
+	}
+}
+
+// Run handles events until ctx is cancelled or both channels close,
+// reporting every action to onAction.
+func (e *Engine) Run(ctx context.Context, audioEvents <-chan events.AudioEvent, btEvents <-chan events.BluetoothEvent, onAction func(Action)) error {
+	if !e.cfg.Enabled {
+		return fmt.Errorf("rules are disabled")
+	}
+	for audioEvents != nil || btEvents != nil {
+		var actions []Action
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-audioEvents:
+			if !ok {
+				audioEvents = nil
+				continue
+			}
+			actions = e.HandleAudio(ctx, ev)
+		case ev, ok := <-btEvents:
+			if !ok {
+				btEvents = nil
+				continue
+			}
+			actions = e.HandleBluetooth(ctx, ev)
+		}
+		for _, a := range actions {
+			onAction(a)
+		}
+	}
+	return nil
+}
+
+// HandleBluetooth reacts to connect and disconnect signals of configured
+// devices.
+func (e *Engine) HandleBluetooth(ctx context.Context, ev events.BluetoothEvent) []Action {
+	if !e.cfg.Enabled || ev.Address == "" || ev.Facility() != "device" {
+		return nil
+	}
+	rule, ok := e.cfg.RuleFor(ev.Address)
+	if !ok {
+		return nil
+	}
+	switch {
+	case ev.Kind == events.KindPropertyChanged && ev.Properties["Connected"] == "true":
+		return e.connect(ctx, rule)
+	case ev.Kind == events.KindPropertyChanged && ev.Properties["Connected"] == "false",
+		ev.Kind == events.KindInterfacesRemoved:
+		return e.disconnect(ctx, rule)
+	}
+	return nil
+}
+
+// HandleAudio retries pending devices when a sink or source appears; the
+// bluetooth sink usually shows up a moment after the device connects.
+func (e *Engine) HandleAudio(ctx context.Context, ev events.AudioEvent) []Action {
+	if !e.cfg.Enabled || ev.Kind != events.KindNew || (ev.Facility != "sink" && ev.Facility != "source") {
+		return nil
+	}
+	var actions []Action
+	for _, rule := range e.pending {
+		actions = append(actions, e.connect(ctx, rule)...)
+	}
+	return actions
+}
+
+func (e *Engine) connect(ctx context.Context, rule Rule) []Action {
+	addr := strings.ToUpper(rule.Address)
+	sink := rule.Sink
+	if sink == "" {
+		found, err := e.deviceObject(ctx, "sink", addr)
+		if err != nil {
+			return []Action{e.action(addr, "set-default-sink", "", err)}
+		}
+		if found == "" {
+			e.pending[addr] = rule
+			return []Action{e.action(addr, "pending", "sink", nil)}
+		}
+		sink = found
+	}
+	source := rule.Source
+	if source == SourceAuto {
+		found, err := e.deviceObject(ctx, "source", addr)
+		if err != nil {
+			return []Action{e.action(addr, "set-default-source", "", err)}
+		}
+		// The input may not exist (e.g. A2DP only); do not hold up the
+		// sink switch for it.
+		source = found
+	}
+	delete(e.pending, addr)
+
+	defaults, err := e.au.GetDefaults(ctx)
+	if err != nil {
+		return []Action{e.action(addr, "set-default-sink", sink, err)}
+	}
+	if _, active := e.saved[addr]; !active {
+		saved := savedDefaults{sink: defaults.DefaultSinkName}
+		if source != "" {
+			saved.source = defaults.DefaultSourceName
+		}
+		e.saved[addr] = saved
+	}
+
+	var actions []Action
+	if defaults.DefaultSinkName != sink {
+		actions = append(actions, e.action(addr, "set-default-sink", sink, e.au.SetDefaultSink(ctx, sink)))
+	}
+	if source != "" && defaults.DefaultSourceName != source {
+		actions = append(actions, e.action(addr, "set-default-source", source, e.au.SetDefaultSource(ctx, source)))
+	}
+	if rule.MoveStreams {
+		actions = append(actions, e.moveStreams(ctx, addr, sink)...)
+	}
+	return actions
+}
+
+func (e *Engine) disconnect(ctx context.Context, rule Rule) []Action {
+	addr := strings.ToUpper(rule.Address)
+	delete(e.pending, addr)
+	saved, active := e.saved[addr]
+	delete(e.saved, addr)
+	if !active || !rule.Restore {
+		return nil
+	}
+
+	var actions []Action
+	if saved.sink != "" {
+		exists, err := e.exists(ctx, "sink", saved.sink)
+		switch {
+		case err != nil:
+			actions = append(actions, e.action(addr, "set-default-sink", saved.sink, err))
+		case exists:
+			actions = append(actions, e.action(addr, "set-default-sink", saved.sink, e.au.SetDefaultSink(ctx, saved.sink)))
+			if rule.MoveStreams {
+				actions = append(actions, e.moveStreams(ctx, addr, saved.sink)...)
+			}
+		}
+	}
+	if saved.source != "" {
+		exists, err := e.exists(ctx, "source", saved.source)
+		switch {
+		case err != nil:
+			actions = append(actions, e.action(addr, "set-default-source", saved.source, err))
+		case exists:
+			actions = append(actions, e.action(addr, "set-default-source", saved.source, e.au.SetDefaultSource(ctx, saved.source)))
+		}
+	}
+	return actions
+}
+
+func (e *Engine) moveStreams(ctx context.Context, addr, sink string) []Action {
+	inputs, err := e.au.ListSinkInputs(ctx)
+	if err != nil {
+		return []Action{e.action(addr, "move-stream", sink, err)}
+	}
+	var actions []Action
+	for _, in := range inputs {
+		if in.SinkName == sink {
+			continue
+		}
+		target := fmt.Sprintf("%d → %s", in.Index, sink)
+		actions = append(actions, e.action(addr, "move-stream", target, e.au.MoveSinkInput(ctx, in.Index, sink)))
+	}
+	return actions
+}
+
+// deviceObject finds the sink or source belonging to a bluetooth address,
+// or "" if it has not appeared yet.
+func (e *Engine) deviceObject(ctx context.Context, target, addr string) (string, error) {
+	devices, err := e.list(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	for _, d := range devices {
+		if d.MonitorOfSink == "" && d.BelongsTo(addr) {
+			return d.Name, nil
+		}
+	}
+	return "", nil
+}
+
+func (e *Engine) exists(ctx context.Context, target, name string) (bool, error) {
+	devices, err := e.list(ctx, target)
+	if err != nil {
+		return false, err
+	}
+	for _, d := range devices {
+		if d.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (e *Engine) list(ctx context.Context, target string) ([]audio.Device, error) {
+	if target == "sink" {
+		return e.au.ListSinksDetailed(ctx)
+	}
+	return e.au.ListSourcesDetailed(ctx)
+}
+
+func (e *Engine) action(addr, op, target string, err error) Action {
+	return Action{Time: e.now(), Address: addr, Operation: op, Target: target, Err: err}
+}
@@ -0,0 +1,146 @@
+package rules
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/events"
+	"soundctl/pkg/soundctl/pulse"
+	"soundctl/pkg/soundctl/pulse/pulsetest"
+)
+
+const (
+	headsetAddr = "08:FF:44:2B:4C:90"
+	headsetSink = "bluez_output.08_FF_44_2B_4C_90.1"
+	speakerSink = "alsa_output.pci-0000_00_1f.3.analog-stereo"
+	builtinMic  = "alsa_input.pci-0000_00_1f.3.analog-stereo"
+	headsetMic  = "bluez_input.08_FF_44_2B_4C_90.0"
+)
+
+func newRulesFixture(t *testing.T) (*pulse.FakeServer, audio.Service) {
+	t.Helper()
+	return pulsetest.NewService(t, pulse.FakeState{
+		Info:    pulse.ServerInfo{DefaultSink: speakerSink, DefaultSource: builtinMic},
+		Sinks:   []pulse.DeviceInfo{{Index: 1, Name: speakerSink, ChannelMap: []uint8{1, 2}, MonitorIndex: pulse.InvalidIndex}},
+		Sources: []pulse.DeviceInfo{{Index: 2, Name: builtinMic, ChannelMap: []uint8{1, 2}, MonitorIndex: pulse.InvalidIndex}},
+		SinkInputs: []pulse.SinkInputInfo{
+			{Index: 10, Sink: 1, Properties: pulse.PropList{"application.name": "Firefox"}},
+			{Index: 11, Sink: 1, Properties: pulse.PropList{"application.name": "Spotify"}},
+		},
+	})
+}
+
+func addHeadset(srv *pulse.FakeServer) {
+	srv.Update(func(st *pulse.FakeState) {
+		st.Sinks = append(st.Sinks, pulse.DeviceInfo{
+			Index: 5, Name: headsetSink, ChannelMap: []uint8{1, 2}, MonitorIndex: pulse.InvalidIndex,
+			Properties: pulse.PropList{"api.bluez5.address": headsetAddr},
+		})
+		st.Sources = append(st.Sources, pulse.DeviceInfo{
+			Index: 6, Name: headsetMic, ChannelMap: []uint8{0}, MonitorIndex: pulse.InvalidIndex,
+		})
+	})
+}
+
+func connected(value string) events.BluetoothEvent {
+	return events.BluetoothEvent{
+		Kind:       events.KindPropertyChanged,
+		Path:       "/org/bluez/hci0/dev_08_FF_44_2B_4C_90",
+		Address:    headsetAddr,
+		Interface:  "org.bluez.Device1",
+		Properties: map[string]string{"Connected": value},
+	}
+}
+
+func operations(actions []Action) []string {
+	var ops []string
+	for _, a := range actions {
+		if a.Err != nil {
+			ops = append(ops, a.Operation+" "+a.Target+" ERR "+a.Err.Error())
+			continue
+		}
+		ops = append(ops, a.Operation+" "+a.Target)
+	}
+	return ops
+}
+
+func TestEngineSwitchesWhenSinkAppearsAndRestores(t *testing.T) {
+	srv, svc := newRulesFixture(t)
+	engine := NewEngine(svc, Config{Enabled: true, Rules: []Rule{{
+		Address: headsetAddr, Source: SourceAuto, MoveStreams: true, Restore: true,
+	}}})
+	ctx := context.Background()
+
+	// The sink is not there yet right after connect.
+	if got := operations(engine.HandleBluetooth(ctx, connected("true"))); !reflect.DeepEqual(got, []string{"pending sink"}) {
+		t.Fatalf("unexpected connect actions: %v", got)
+	}
+
+	addHeadset(srv)
+	got := operations(engine.HandleAudio(ctx, events.AudioEvent{Kind: events.KindNew, Facility: "sink", Index: 5}))
+	want := []string{
+		"set-default-sink " + headsetSink,
+		"set-default-source " + headsetMic,
+		"move-stream 10 → " + headsetSink,
+		"move-stream 11 → " + headsetSink,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected switch actions:\n got %v\nwant %v", got, want)
+	}
+	if st := srv.State(); st.Info.DefaultSink != headsetSink || st.SinkInputs[0].Sink != 5 {
+		t.Fatalf("server not switched: %+v", st.Info)
+	}
+
+	// A second sink appearing must not re-trigger the rule.
+	if got := engine.HandleAudio(ctx, events.AudioEvent{Kind: events.KindNew, Facility: "sink", Index: 7}); len(got) != 0 {
+		t.Fatalf("expected no actions, got %v", operations(got))
+	}
+
+	got = operations(engine.HandleBluetooth(ctx, connected("false")))
+	want = []string{
+		"set-default-sink " + speakerSink,
+		"move-stream 10 → " + speakerSink,
+		"move-stream 11 → " + speakerSink,
+		"set-default-source " + builtinMic,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected restore actions:\n got %v\nwant %v", got, want)
+	}
+	if st := srv.State(); st.Info.DefaultSink != speakerSink || st.Info.DefaultSource != builtinMic {
+		t.Fatalf("defaults not restored: %+v", st.Info)
+	}
+}
+
+func TestEngineIgnoresUnconfiguredAndDisabled(t *testing.T) {
+	srv, svc := newRulesFixture(t)
+	addHeadset(srv)
+	ctx := context.Background()
+
+	other := NewEngine(svc, Config{Enabled: true, Rules: []Rule{{Address: "AA:BB:CC:DD:EE:FF"}}})
+	if got := other.HandleBluetooth(ctx, connected("true")); len(got) != 0 {
+		t.Fatalf("expected no actions for unconfigured device, got %v", operations(got))
+	}
+	disabled := NewEngine(svc, Config{Rules: []Rule{{Address: headsetAddr}}})
+	if got := disabled.HandleBluetooth(ctx, connected("true")); len(got) != 0 {
+		t.Fatalf("expected no actions when disabled, got %v", operations(got))
+	}
+	if calls := srv.Calls(); len(calls) != 0 {
+		t.Fatalf("expected no server calls, got %v", calls)
+	}
+}
+
+func TestEngineWithoutRestoreKeepsDefaults(t *testing.T) {
+	srv, svc := newRulesFixture(t)
+	addHeadset(srv)
+	engine := NewEngine(svc, Config{Enabled: true, Rules: []Rule{{Address: headsetAddr}}})
+	ctx := context.Background()
+
+	if got := operations(engine.HandleBluetooth(ctx, connected("true"))); !reflect.DeepEqual(got, []string{"set-default-sink " + headsetSink}) {
+		t.Fatalf("unexpected connect actions: %v", got)
+	}
+	if got := engine.HandleBluetooth(ctx, connected("false")); len(got) != 0 {
+		t.Fatalf("expected no restore actions, got %v", operations(got))
+	}
+}
@@ -0,0 +1,87 @@
+// Package rules implements opt-in automatic routing: when a configured
+// bluetooth device connects, its sink becomes the default and streams
+// follow it; when it disconnects, the previous defaults come back.
+// Rules live in rules.yaml next to presets.yaml.
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SourceAuto selects the device's own bluetooth input as default source.
+const SourceAuto = "auto"
+
+// Rule describes what to do when one device connects or disconnects.
+type Rule struct {
+	Address string `yaml:"address"`
+	Label   string `yaml:"label,omitempty"`
+	// Sink to make default on connect; empty selects the device's own
+	// bluetooth sink once it appears.
+	Sink string `yaml:"sink,omitempty"`
+	// Source to make default on connect; empty leaves the default source
+	// alone and SourceAuto selects the device's bluetooth input.
+	Source      string `yaml:"source,omitempty"`
+	MoveStreams bool   `yaml:"move_streams"`
+	Restore     bool   `yaml:"restore_on_disconnect"`
+}
+
+// Config is the rules.yaml root. Nothing happens unless Enabled is set.
+type Config struct {
+	Enabled bool   `yaml:"enabled"`
+	Rules   []Rule `yaml:"rules"`
+}
+
+// PathNextTo returns the rules.yaml path in the same directory as the
+// given presets file.
+func PathNextTo(presetsPath string) string {
+	return filepath.Join(filepath.Dir(presetsPath), "rules.yaml")
+}
+
+// Load reads a rules file. A missing file yields an empty, disabled config.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Config{}, nil
+		}
+		return Config{}, fmt.Errorf("read rules file: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parse rules file: %w", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func (c Config) validate() error {
+	seen := map[string]bool{}
+	for i, r := range c.Rules {
+		if r.Address == "" {
+			return fmt.Errorf("rule %d: address is required", i+1)
+		}
+		addr := strings.ToUpper(r.Address)
+		if seen[addr] {
+			return fmt.Errorf("rule %d: duplicate address %s", i+1, r.Address)
+		}
+		seen[addr] = true
+	}
+	return nil
+}
+
+// RuleFor returns the rule for a device address.
+func (c Config) RuleFor(address string) (Rule, bool) {
+	for _, r := range c.Rules {
+		if strings.EqualFold(r.Address, address) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMissingFileIsDisabled(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "rules.yaml"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Enabled || len(cfg.Rules) != 0 {
+		t.Fatalf("expected empty disabled config, got %+v", cfg)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	data := `enabled: true
+rules:
+  - address: 08:FF:44:2B:4C:90
+    label: AirPods Max
+    source: auto
+    move_streams: true
+    restore_on_disconnect: true
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	rule, ok := cfg.RuleFor("08:ff:44:2b:4c:90")
+	if !cfg.Enabled || !ok || rule.Source != SourceAuto || !rule.MoveStreams || !rule.Restore {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadRejectsDuplicateAddress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	data := "rules:\n  - address: AA:BB:CC:DD:EE:FF\n  - address: aa:bb:cc:dd:ee:ff\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected duplicate address error")
+	}
+}
+
+func TestPathNextTo(t *testing.T) {
+	if got := PathNextTo("/home/u/.config/soundctl/presets.yaml"); got != "/home/u/.config/soundctl/rules.yaml" {
+		t.Fatalf("unexpected path %q", got)
+	}
+}
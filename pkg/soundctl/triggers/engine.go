@@ -0,0 +1,237 @@
+package triggers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/events"
+	"soundctl/pkg/soundctl/preset"
+)
+
+// Fired records one trigger firing, for logging.
+type Fired struct {
+	Time    time.Time
+	Trigger string
+	Kind    string // the trigger's Kind
+	Preset  string
+	Event   string // what set it off, e.g. "sink alsa_output.x appeared"
+	Err     error  // why applying the preset failed, in part or whole
+}
+
+// Engine fires the triggers of a Config, applying their presets from a
+// store. It is safe for concurrent use; firings are serialised.
+type Engine struct {
+	au    audio.Service
+	store *preset.Store
+	cfg   Config
+	names preset.NameResolver
+	now   func() time.Time
+
+	mu   sync.Mutex
+	last map[string]time.Time // when each trigger last fired
+}
+
+func NewEngine(au audio.Service, store *preset.Store, cfg Config) *Engine {
+	return &Engine{
+		au:    au,
+		store: store,
+		cfg:   cfg,
+		now:   time.Now,
+		last:  map[string]time.Time{},
+	}
+}
+
+// WithNames makes the engine expand the aliases presets name before
+// applying them.
+func (e *Engine) WithNames(names preset.NameResolver) *Engine {
+	e.names = names
+	return e
+}
+
+// Tick fires the schedule triggers due in the current minute, each at most
+// once per minute. Call it at least every minute; a minute without a tick,
+// say while suspended, is skipped.
+func (e *Engine) Tick(ctx context.Context) []Fired {
+	if !e.cfg.Enabled {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.now()
+	minute := now.Truncate(time.Minute)
+	var fired []Fired
+	for _, t := range e.triggers(KindSchedule) {
+		if !t.schedule.Matches(now) {
+			continue
+		}
+		if last, ok := e.last[t.Name]; ok && !last.Truncate(time.Minute).Before(minute) {
+			continue
+		}
+		fired = append(fired, e.fire(ctx, t, "schedule "+t.Schedule))
+	}
+	return fired
+}
+
+// HandleBluetooth fires the device triggers of a device that connected or
+// disconnected.
+func (e *Engine) HandleBluetooth(ctx context.Context, ev events.BluetoothEvent) []Fired {
+	if !e.cfg.Enabled || ev.Address == "" || ev.Facility() != "device" {
+		return nil
+	}
+	var kind, what string
+	switch {
+	case ev.Kind == events.KindPropertyChanged && ev.Properties["Connected"] == "true":
+		kind, what = KindDeviceConnected, "connected"
+	case ev.Kind == events.KindPropertyChanged && ev.Properties["Connected"] == "false",
+		ev.Kind == events.KindInterfacesRemoved:
+		kind, what = KindDeviceDisconnected, "disconnected"
+	default:
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var fired []Fired
+	for _, t := range e.triggers(kind) {
+		address := t.DeviceConnected
+		if kind == KindDeviceDisconnected {
+			address = t.DeviceDisconnected
+		}
+		if strings.EqualFold(address, ev.Address) && e.cooled(t) {
+			fired = append(fired, e.fire(ctx, t, fmt.Sprintf("device %s %s", ev.Address, what)))
+		}
+	}
+	return fired
+}
+
+// HandleAudio fires the sink triggers matching a new sink and the stream
+// triggers of the app owning a new playback stream.
+func (e *Engine) HandleAudio(ctx context.Context, ev events.AudioEvent) []Fired {
+	if !e.cfg.Enabled || ev.Kind != events.KindNew {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	switch ev.Facility {
+	case "sink":
+		triggers := e.triggers(KindSinkAppeared)
+		if len(triggers) == 0 {
+			return nil
+		}
+		sinks, err := e.au.ListSinksDetailed(ctx)
+		if err != nil {
+			return []Fired{e.failed(fmt.Sprintf("sink #%d appeared", ev.Index), fmt.Errorf("list sinks: %w", err))}
+		}
+		var fired []Fired
+		for _, d := range sinks {
+			if d.ID != ev.Index {
+				continue
+			}
+			for _, t := range triggers {
+				if t.SinkAppeared.Matches(d.Name, d.Description, d.Properties) && e.cooled(t) {
+					fired = append(fired, e.fire(ctx, t, fmt.Sprintf("sink %s appeared", d.Name)))
+				}
+			}
+		}
+		return fired
+	case "sink-input":
+		triggers := e.triggers(KindStreamStarted)
+		if len(triggers) == 0 {
+			return nil
+		}
+		inputs, err := e.au.ListSinkInputs(ctx)
+		if err != nil {
+			return []Fired{e.failed(fmt.Sprintf("stream #%d started", ev.Index), fmt.Errorf("list streams: %w", err))}
+		}
+		var fired []Fired
+		for _, in := range inputs {
+			if in.Index != ev.Index {
+				continue
+			}
+			for _, t := range triggers {
+				match := strings.EqualFold(t.StreamStarted, in.AppName) || strings.EqualFold(t.StreamStarted, in.Binary)
+				if match && e.cooled(t) {
+					fired = append(fired, e.fire(ctx, t, fmt.Sprintf("stream #%d of %s started", in.Index, in.AppName)))
+				}
+			}
+		}
+		return fired
+	}
+	return nil
+}
+
+func (e *Engine) triggers(kind string) []Trigger {
+	var out []Trigger
+	for _, t := range e.cfg.Triggers {
+		if t.Kind() == kind {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// cooled reports whether an event trigger's cooldown has passed.
+func (e *Engine) cooled(t Trigger) bool {
+	last, ok := e.last[t.Name]
+	return !ok || e.now().Sub(last) >= t.cooldown()
+}
+
+func (e *Engine) fire(ctx context.Context, t Trigger, event string) Fired {
+	now := e.now()
+	e.last[t.Name] = now
+	return Fired{Time: now, Trigger: t.Name, Kind: t.Kind(), Preset: t.Preset, Event: event, Err: e.apply(ctx, t)}
+}
+
+// failed records an event the engine could not evaluate.
+func (e *Engine) failed(event string, err error) Fired {
+	return Fired{Time: e.now(), Event: event, Err: err}
+}
+
+// apply applies a trigger's preset like presets apply does: resolved with
+// its parents and layers, aliases expanded, and not at all if the
+// pre-flight check finds errors.
+func (e *Engine) apply(ctx context.Context, t Trigger) error {
+	p, err := e.store.Resolve(t.Preset, t.Layers...)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	if e.names != nil {
+		p, errs = preset.ResolveNames(ctx, p, e.names)
+	}
+	findings, err := preset.Validate(ctx, e.au, p)
+	if err != nil {
+		return fmt.Errorf("pre-flight check: %w", err)
+	}
+	if preset.HasErrors(findings) {
+		var problems []string
+		for _, f := range findings {
+			if f.Severity == preset.SeverityError {
+				problems = append(problems, f.Field+": "+f.Message)
+			}
+		}
+		return fmt.Errorf("not applied, pre-flight check failed: %s", strings.Join(problems, "; "))
+	}
+	result := preset.Apply(ctx, e.au, p)
+	for _, u := range result.Unresolved {
+		errs = append(errs, u)
+	}
+	return joinErrors(append(errs, result.Errors...))
+}
+
+// joinErrors combines errs into one error reading on a single line, for
+// the log.
+func joinErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
@@ -0,0 +1,159 @@
+package triggers
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"soundctl/pkg/soundctl/audio"
+	"soundctl/pkg/soundctl/events"
+	"soundctl/pkg/soundctl/preset"
+	"soundctl/pkg/soundctl/pulse"
+	"soundctl/pkg/soundctl/pulse/pulsetest"
+)
+
+const (
+	headsetAddr = "08:FF:44:2B:4C:90"
+	speakerSink = "alsa_output.pci-0000_00_1f.3.analog-stereo"
+	dockSink    = "alsa_output.usb-Dock_DAC-00.analog-stereo"
+)
+
+func newTriggersFixture(t *testing.T) (*pulse.FakeServer, audio.Service, *preset.Store) {
+	t.Helper()
+	srv, svc := pulsetest.NewService(t, pulse.FakeState{
+		Info:  pulse.ServerInfo{DefaultSink: speakerSink},
+		Sinks: []pulse.DeviceInfo{{Index: 1, Name: speakerSink, ChannelMap: []uint8{1, 2}, MonitorIndex: pulse.InvalidIndex}},
+		SinkInputs: []pulse.SinkInputInfo{
+			{Index: 10, Sink: 1, Properties: pulse.PropList{"application.name": "ZOOM VoiceEngine", "application.process.binary": "zoom"}},
+		},
+	})
+
+	store := preset.NewStore(filepath.Join(t.TempDir(), "presets.yaml"))
+	for _, p := range []preset.Preset{
+		{Name: "music", DefaultSink: dockSink},
+		{Name: "meeting", DefaultSink: speakerSink},
+	} {
+		if err := store.Save(p); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	return srv, svc, store
+}
+
+func defaultSink(t *testing.T, svc audio.Service) string {
+	t.Helper()
+	d, err := svc.GetDefaults(context.Background())
+	if err != nil {
+		t.Fatalf("GetDefaults: %v", err)
+	}
+	return d.DefaultSinkName
+}
+
+func TestEngineAppliesPresetWhenSinkAppears(t *testing.T) {
+	srv, svc, store := newTriggersFixture(t)
+	engine := NewEngine(svc, store, Config{Enabled: true, Triggers: []Trigger{
+		{Name: "dock", Preset: "music", SinkAppeared: preset.Match{Product: "Dock DAC"}},
+	}})
+	ctx := context.Background()
+
+	srv.Update(func(st *pulse.FakeState) {
+		st.Sinks = append(st.Sinks, pulse.DeviceInfo{
+			Index: 2, Name: dockSink, ChannelMap: []uint8{1, 2}, MonitorIndex: pulse.InvalidIndex,
+			Properties: pulse.PropList{"device.product.name": "Dock DAC", "device.bus": "usb"},
+		})
+	})
+	fired := engine.HandleAudio(ctx, events.AudioEvent{Kind: events.KindNew, Facility: "sink", Index: 2})
+	if len(fired) != 1 || fired[0].Trigger != "dock" || fired[0].Preset != "music" || fired[0].Err != nil {
+		t.Fatalf("unexpected firings %+v", fired)
+	}
+	if fired[0].Event != "sink "+dockSink+" appeared" {
+		t.Fatalf("unexpected event %q", fired[0].Event)
+	}
+	if got := defaultSink(t, svc); got != dockSink {
+		t.Fatalf("default sink = %s, want %s", got, dockSink)
+	}
+
+	// Within the cooldown the same sink reappearing does nothing.
+	if fired := engine.HandleAudio(ctx, events.AudioEvent{Kind: events.KindNew, Facility: "sink", Index: 2}); len(fired) != 0 {
+		t.Fatalf("expected the cooldown to hold, got %+v", fired)
+	}
+	// Other sinks do not match.
+	engine.now = func() time.Time { return time.Now().Add(time.Hour) }
+	if fired := engine.HandleAudio(ctx, events.AudioEvent{Kind: events.KindNew, Facility: "sink", Index: 1}); len(fired) != 0 {
+		t.Fatalf("expected no firing for another sink, got %+v", fired)
+	}
+}
+
+func TestEngineStreamAndDeviceTriggers(t *testing.T) {
+	_, svc, store := newTriggersFixture(t)
+	engine := NewEngine(svc, store, Config{Enabled: true, Triggers: []Trigger{
+		{Name: "call", Preset: "meeting", StreamStarted: "zoom"},
+		{Name: "headset-off", Preset: "meeting", DeviceDisconnected: strings.ToLower(headsetAddr)},
+		{Name: "headset-on", Preset: "music", DeviceConnected: headsetAddr},
+	}})
+	ctx := context.Background()
+
+	fired := engine.HandleAudio(ctx, events.AudioEvent{Kind: events.KindNew, Facility: "sink-input", Index: 10})
+	if len(fired) != 1 || fired[0].Trigger != "call" || fired[0].Err != nil {
+		t.Fatalf("unexpected stream firings %+v", fired)
+	}
+
+	fired = engine.HandleBluetooth(ctx, events.BluetoothEvent{
+		Kind:       events.KindPropertyChanged,
+		Path:       "/org/bluez/hci0/dev_08_FF_44_2B_4C_90",
+		Address:    headsetAddr,
+		Interface:  "org.bluez.Device1",
+		Properties: map[string]string{"Connected": "false"},
+	})
+	if len(fired) != 1 || fired[0].Trigger != "headset-off" || fired[0].Kind != KindDeviceDisconnected {
+		t.Fatalf("unexpected device firings %+v", fired)
+	}
+
+	// The music preset names a sink that is not there: the pre-flight
+	// check stops it and the firing records why.
+	fired = engine.HandleBluetooth(ctx, events.BluetoothEvent{
+		Kind:       events.KindPropertyChanged,
+		Path:       "/org/bluez/hci0/dev_08_FF_44_2B_4C_90",
+		Address:    headsetAddr,
+		Interface:  "org.bluez.Device1",
+		Properties: map[string]string{"Connected": "true"},
+	})
+	if len(fired) != 1 || fired[0].Err == nil || !strings.Contains(fired[0].Err.Error(), "pre-flight") {
+		t.Fatalf("expected a failed firing, got %+v", fired)
+	}
+	if got := defaultSink(t, svc); got != speakerSink {
+		t.Fatalf("default sink = %s, want it unchanged", got)
+	}
+}
+
+func TestEngineTickFiresSchedulesOncePerMinute(t *testing.T) {
+	_, svc, store := newTriggersFixture(t)
+	cfg := Config{Enabled: true, Triggers: []Trigger{{Name: "standup", Preset: "meeting", Schedule: "55 9 * * mon-fri"}}}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	engine := NewEngine(svc, store, cfg)
+	ctx := context.Background()
+
+	at := time.Date(2026, 10, 19, 9, 54, 50, 0, time.Local) // a Monday
+	engine.now = func() time.Time { return at }
+	if fired := engine.Tick(ctx); len(fired) != 0 {
+		t.Fatalf("fired early: %+v", fired)
+	}
+	at = at.Add(15 * time.Second)
+	fired := engine.Tick(ctx)
+	if len(fired) != 1 || fired[0].Trigger != "standup" || fired[0].Err != nil {
+		t.Fatalf("unexpected firings %+v", fired)
+	}
+	at = at.Add(30 * time.Second)
+	if fired := engine.Tick(ctx); len(fired) != 0 {
+		t.Fatalf("fired twice in one minute: %+v", fired)
+	}
+
+	at = time.Date(2026, 10, 26, 9, 55, 0, 0, time.Local) // the next Monday
+	if fired := engine.Tick(ctx); len(fired) != 1 {
+		t.Fatalf("expected the schedule to fire again a week later, got %+v", fired)
+	}
+}
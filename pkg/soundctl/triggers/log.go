@@ -0,0 +1,136 @@
+package triggers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"soundctl/pkg/soundctl/atomicfile"
+)
+
+// maxLogEntries bounds the log; older entries are dropped when it grows to
+// twice this.
+const maxLogEntries = 1000
+
+// Entry is one line of the trigger log.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Trigger string    `json:"trigger,omitempty"`
+	Kind    string    `json:"kind,omitempty"`
+	Preset  string    `json:"preset,omitempty"`
+	Event   string    `json:"event"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// Log is the record of trigger firings, one JSON object per line. It is
+// safe for concurrent use.
+type Log struct {
+	path string
+
+	mu sync.Mutex // serialises appending and trimming
+}
+
+// LogPathNextTo returns the triggers.log path in the same directory as the
+// given presets file.
+func LogPathNextTo(presetsPath string) string {
+	return filepath.Join(filepath.Dir(presetsPath), "triggers.log")
+}
+
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append adds firings to the log.
+func (l *Log) Append(fired ...Fired) error {
+	if len(fired) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	for _, f := range fired {
+		e := Entry{Time: f.Time, Trigger: f.Trigger, Kind: f.Kind, Preset: f.Preset, Event: f.Event}
+		if f.Err != nil {
+			e.Error = f.Err.Error()
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("create trigger log dir: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open trigger log: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("write trigger log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write trigger log: %w", err)
+	}
+	return l.trim()
+}
+
+// Read returns up to limit of the most recent entries, oldest first; all
+// of them when limit <= 0. A missing log is empty.
+func (l *Log) Read(limit int) ([]Entry, error) {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read trigger log: %w", err)
+	}
+	var entries []Entry
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("parse trigger log line %d: %w", n, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read trigger log: %w", err)
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, nil
+}
+
+// trim drops the oldest entries once the log holds twice maxLogEntries.
+func (l *Log) trim() error {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return fmt.Errorf("read trigger log: %w", err)
+	}
+	lines := bytes.SplitAfter(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+	if len(lines) <= 2*maxLogEntries {
+		return nil
+	}
+	kept := append(bytes.Join(lines[len(lines)-maxLogEntries:], nil), '\n')
+	if err := atomicfile.Replace(l.path, kept, 0o644); err != nil {
+		return fmt.Errorf("write trigger log: %w", err)
+	}
+	return nil
+}
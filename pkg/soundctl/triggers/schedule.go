@@ -0,0 +1,185 @@
+package triggers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression: minute, hour, day of month, month
+// and day of week, in local time. Fields take *, numbers, ranges (1-5),
+// lists (1,15), steps (*/15, 0-30/10) and, for months and days, names
+// (jan, mon-fri). As in cron, when both day fields are restricted a day
+// matching either one matches.
+type Schedule struct {
+	text                          string
+	minute, hour, dom, month, dow uint64 // bit n set when value n matches
+	domAny, dowAny                bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // names[i] is value min+i
+}
+
+var (
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12,
+		names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	// 7 is Sunday too, as in cron.
+	dowField = cronField{name: "day of week", min: 0, max: 7,
+		names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// ParseSchedule parses a five-field cron expression, or the shorthand
+// "HH:MM" optionally followed by a day-of-week field, e.g. "09:55 mon-fri".
+func ParseSchedule(text string) (Schedule, error) {
+	fields := strings.Fields(text)
+	if len(fields) >= 1 && len(fields) <= 2 && strings.Contains(fields[0], ":") {
+		hh, mm, _ := strings.Cut(fields[0], ":")
+		h, herr := strconv.Atoi(hh)
+		m, merr := strconv.Atoi(mm)
+		if herr != nil || merr != nil || h < 0 || h > 23 || m < 0 || m > 59 {
+			return Schedule{}, fmt.Errorf("schedule %q: %q is not a time of day (HH:MM)", text, fields[0])
+		}
+		days := "*"
+		if len(fields) == 2 {
+			days = fields[1]
+		}
+		fields = []string{strconv.Itoa(m), strconv.Itoa(h), "*", "*", days}
+	}
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf("schedule %q: want five cron fields (minute hour day month weekday) or HH:MM", text)
+	}
+	s := Schedule{text: text, domAny: fields[2] == "*", dowAny: fields[4] == "*"}
+	for i, f := range []struct {
+		def cronField
+		out *uint64
+	}{
+		{minuteField, &s.minute},
+		{hourField, &s.hour},
+		{domField, &s.dom},
+		{monthField, &s.month},
+		{dowField, &s.dow},
+	} {
+		bits, err := f.def.parse(fields[i])
+		if err != nil {
+			return Schedule{}, fmt.Errorf("schedule %q: %w", text, err)
+		}
+		*f.out = bits
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+func (s Schedule) String() string {
+	return s.text
+}
+
+// Matches reports whether the schedule fires in the minute of t.
+func (s Schedule) Matches(t time.Time) bool {
+	return has(s.minute, t.Minute()) && has(s.hour, t.Hour()) && s.matchesDay(t)
+}
+
+// Next returns the first minute after t that the schedule fires in, or the
+// zero time if there is none within a year (e.g. "0 0 31 2 *").
+func (s Schedule) Next(t time.Time) time.Time {
+	if s.minute == 0 {
+		return time.Time{} // the zero Schedule
+	}
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	for end := t.AddDate(1, 0, 1); t.Before(end); {
+		y, m, d := t.Date()
+		if !s.matchesDay(t) {
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(s.hour, t.Hour()) {
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if has(s.minute, t.Minute()) {
+			return t
+		}
+		t = t.Add(time.Minute)
+	}
+	return time.Time{}
+}
+
+func (s Schedule) matchesDay(t time.Time) bool {
+	if !has(s.month, int(t.Month())) {
+		return false
+	}
+	dom, dow := has(s.dom, t.Day()), has(s.dow, int(t.Weekday()))
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	}
+	return dom || dow
+}
+
+func has(bits uint64, n int) bool {
+	return bits&(1<<uint(n)) != 0
+}
+
+// parse turns one field into a bit set.
+func (f cronField) parse(text string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(text, ",") {
+		rng, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%s: bad step %q", f.name, stepText)
+			}
+			step = n
+		}
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(a); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = f.value(b); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = f.max // "5/15" means 5-max/15, as in cron
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("%s: range %q runs backwards", f.name, rng)
+			}
+		}
+		for n := lo; n <= hi; n += step {
+			bits |= 1 << uint(n)
+		}
+	}
+	return bits, nil
+}
+
+func (f cronField) value(text string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(text, name) {
+			return f.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("%s: %q is not between %d and %d", f.name, text, f.min, f.max)
+	}
+	return n, nil
+}
@@ -0,0 +1,184 @@
+// Package triggers applies presets automatically: on a schedule, when a
+// bluetooth device connects or disconnects, when a matching sink appears
+// or when an app starts a playback stream. The daemon evaluates them.
+// Triggers live in triggers.yaml next to presets.yaml, and every firing is
+// appended to triggers.log beside it.
+package triggers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"soundctl/pkg/soundctl/preset"
+)
+
+// Trigger kinds, one per condition.
+const (
+	KindSchedule           = "schedule"
+	KindDeviceConnected    = "device_connected"
+	KindDeviceDisconnected = "device_disconnected"
+	KindSinkAppeared       = "sink_appeared"
+	KindStreamStarted      = "stream_started"
+)
+
+// DefaultCooldown is how long an event trigger stays quiet after firing,
+// so that e.g. an app opening three streams at once applies its preset
+// once.
+const DefaultCooldown = time.Minute
+
+// Trigger applies a preset when its condition is met. Exactly one
+// condition is set.
+type Trigger struct {
+	Name   string   `yaml:"name"`
+	Preset string   `yaml:"preset"`
+	Layers []string `yaml:"layers,omitempty"` // presets layered on top, as with apply --layer
+
+	// Schedule is a cron expression ("55 9 * * mon-fri"), or "HH:MM",
+	// optionally followed by days of the week ("09:55 mon-fri").
+	Schedule           string       `yaml:"schedule,omitempty"`
+	DeviceConnected    string       `yaml:"device_connected,omitempty"`    // bluetooth address
+	DeviceDisconnected string       `yaml:"device_disconnected,omitempty"` // bluetooth address
+	SinkAppeared       preset.Match `yaml:"sink_appeared,omitempty"`
+	StreamStarted      string       `yaml:"stream_started,omitempty"` // application name or binary
+
+	// Cooldown overrides DefaultCooldown for event triggers.
+	Cooldown time.Duration `yaml:"cooldown,omitempty"`
+
+	schedule Schedule // parsed Schedule, set by Load
+}
+
+// Kind returns which condition the trigger has, one of the Kind* values.
+func (t Trigger) Kind() string {
+	switch {
+	case t.Schedule != "":
+		return KindSchedule
+	case t.DeviceConnected != "":
+		return KindDeviceConnected
+	case t.DeviceDisconnected != "":
+		return KindDeviceDisconnected
+	case !t.SinkAppeared.IsZero():
+		return KindSinkAppeared
+	case t.StreamStarted != "":
+		return KindStreamStarted
+	}
+	return ""
+}
+
+// Condition renders the trigger's condition, e.g. "bus=usb product=Dock DAC".
+func (t Trigger) Condition() string {
+	switch t.Kind() {
+	case KindSchedule:
+		return t.Schedule
+	case KindDeviceConnected:
+		return t.DeviceConnected
+	case KindDeviceDisconnected:
+		return t.DeviceDisconnected
+	case KindSinkAppeared:
+		return t.SinkAppeared.String()
+	case KindStreamStarted:
+		return t.StreamStarted
+	}
+	return ""
+}
+
+// Next returns the first time after after that a schedule trigger fires,
+// or the zero time for other triggers and schedules that never match.
+func (t Trigger) Next(after time.Time) time.Time {
+	if t.Kind() != KindSchedule {
+		return time.Time{}
+	}
+	return t.schedule.Next(after)
+}
+
+func (t Trigger) cooldown() time.Duration {
+	if t.Cooldown > 0 {
+		return t.Cooldown
+	}
+	return DefaultCooldown
+}
+
+// Config is the triggers.yaml root. Nothing fires unless Enabled is set.
+type Config struct {
+	Enabled  bool      `yaml:"enabled"`
+	Triggers []Trigger `yaml:"triggers"`
+}
+
+// PathNextTo returns the triggers.yaml path in the same directory as the
+// given presets file.
+func PathNextTo(presetsPath string) string {
+	return filepath.Join(filepath.Dir(presetsPath), "triggers.yaml")
+}
+
+// Load reads a triggers file. A missing file yields an empty, disabled
+// config.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Config{}, nil
+		}
+		return Config{}, fmt.Errorf("read triggers file: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parse triggers file: %w", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// validate checks each trigger and parses its schedule.
+func (c *Config) validate() error {
+	seen := map[string]bool{}
+	for i := range c.Triggers {
+		t := &c.Triggers[i]
+		if t.Name == "" {
+			return fmt.Errorf("trigger %d: name is required", i+1)
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("trigger %d: duplicate name %q", i+1, t.Name)
+		}
+		seen[t.Name] = true
+		if t.Preset == "" {
+			return fmt.Errorf("trigger %q: preset is required", t.Name)
+		}
+		var conditions []string
+		for _, cond := range []struct {
+			kind string
+			set  bool
+		}{
+			{KindSchedule, t.Schedule != ""},
+			{KindDeviceConnected, t.DeviceConnected != ""},
+			{KindDeviceDisconnected, t.DeviceDisconnected != ""},
+			{KindSinkAppeared, !t.SinkAppeared.IsZero()},
+			{KindStreamStarted, t.StreamStarted != ""},
+		} {
+			if cond.set {
+				conditions = append(conditions, cond.kind)
+			}
+		}
+		if len(conditions) == 0 {
+			return fmt.Errorf("trigger %q: needs one of schedule, device_connected, device_disconnected, sink_appeared or stream_started", t.Name)
+		}
+		if len(conditions) > 1 {
+			return fmt.Errorf("trigger %q: has several conditions (%s); use one trigger for each", t.Name, strings.Join(conditions, ", "))
+		}
+		if t.Schedule != "" {
+			s, err := ParseSchedule(t.Schedule)
+			if err != nil {
+				return fmt.Errorf("trigger %q: %w", t.Name, err)
+			}
+			t.schedule = s
+		}
+		if t.Cooldown < 0 {
+			return fmt.Errorf("trigger %q: cooldown must not be negative", t.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package triggers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingFileIsDisabled(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "triggers.yaml"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Enabled || len(cfg.Triggers) != 0 {
+		t.Fatalf("expected empty disabled config, got %+v", cfg)
+	}
+}
+
+func TestLoadTriggers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triggers.yaml")
+	data := `enabled: true
+triggers:
+  - name: standup
+    preset: meeting
+    schedule: "55 9 * * mon-fri"
+  - name: dock
+    preset: music
+    sink_appeared: {product: Dock DAC}
+    cooldown: 5m
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !cfg.Enabled || len(cfg.Triggers) != 2 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	standup, dock := cfg.Triggers[0], cfg.Triggers[1]
+	if standup.Kind() != KindSchedule || dock.Kind() != KindSinkAppeared || dock.Condition() != "product=Dock DAC" || dock.Cooldown != 5*time.Minute {
+		t.Fatalf("unexpected triggers: %+v", cfg.Triggers)
+	}
+	friday := time.Date(2026, 10, 16, 10, 0, 0, 0, time.Local)
+	if next := standup.Next(friday); !next.Equal(time.Date(2026, 10, 19, 9, 55, 0, 0, time.Local)) {
+		t.Fatalf("next standup after Friday 10:00 = %v, want Monday 09:55", next)
+	}
+}
+
+func TestLoadRejectsInvalid(t *testing.T) {
+	for _, c := range []struct {
+		yaml, want string
+	}{
+		{"triggers: [{preset: x, schedule: '09:00'}]", "name is required"},
+		{"triggers: [{name: a, schedule: '09:00'}]", "preset is required"},
+		{"triggers: [{name: a, preset: x}]", "needs one of"},
+		{"triggers: [{name: a, preset: x, schedule: '09:00', stream_started: zoom}]", "several conditions"},
+		{"triggers: [{name: a, preset: x, schedule: '9 * *'}]", "five cron fields"},
+		{"triggers: [{name: a, preset: x, stream_started: zoom}, {name: a, preset: y, stream_started: vlc}]", "duplicate name"},
+	} {
+		path := filepath.Join(t.TempDir(), "triggers.yaml")
+		if err := os.WriteFile(path, []byte(c.yaml), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: expected error containing %q, got %v", c.yaml, c.want, err)
+		}
+	}
+}
+
+func TestScheduleMatches(t *testing.T) {
+	monday955 := time.Date(2026, 10, 19, 9, 55, 30, 0, time.Local)
+	saturday955 := time.Date(2026, 10, 24, 9, 55, 0, 0, time.Local)
+	cases := []struct {
+		schedule string
+		at       time.Time
+		want     bool
+	}{
+		{"55 9 * * mon-fri", monday955, true},
+		{"55 9 * * mon-fri", saturday955, false},
+		{"09:55", saturday955, true},
+		{"09:55 sat,sun", monday955, false},
+		{"*/5 * * * *", monday955, true},
+		{"*/10 * * * *", monday955, false},
+		{"55 9 1 * 1", monday955, true}, // either day field matches, as in cron
+		{"55 9 19 oct *", monday955, true},
+		{"55 9 * * 7", time.Date(2026, 10, 25, 9, 55, 0, 0, time.Local), true}, // 7 is Sunday
+	}
+	for _, c := range cases {
+		s, err := ParseSchedule(c.schedule)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q): %v", c.schedule, err)
+		}
+		if got := s.Matches(c.at); got != c.want {
+			t.Errorf("%q matches %v = %t, want %t", c.schedule, c.at, got, c.want)
+		}
+	}
+	for _, bad := range []string{"60 * * * *", "5-1 * * * *", "* * * * funday", "*/0 * * * *", "25:00"} {
+		if _, err := ParseSchedule(bad); err == nil {
+			t.Errorf("expected ParseSchedule(%q) to fail", bad)
+		}
+	}
+	never, _ := ParseSchedule("0 0 31 feb *")
+	if next := never.Next(monday955); !next.IsZero() {
+		t.Errorf("expected no next time for 31 February, got %v", next)
+	}
+}
+
+func TestLogAppendAndRead(t *testing.T) {
+	log := NewLog(filepath.Join(t.TempDir(), "triggers.log"))
+	if entries, err := log.Read(0); err != nil || len(entries) != 0 {
+		t.Fatalf("expected an empty log, got %v, %v", entries, err)
+	}
+	now := time.Now()
+	if err := log.Append(
+		Fired{Time: now, Trigger: "standup", Kind: KindSchedule, Preset: "meeting", Event: "schedule 09:55"},
+		Fired{Time: now, Trigger: "dock", Kind: KindSinkAppeared, Preset: "music", Event: "sink x appeared", Err: errors.New("boom")},
+	); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	entries, err := log.Read(1)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Trigger != "dock" || entries[0].Preset != "music" || entries[0].Error != "boom" || !entries[0].Time.Equal(now) {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+}
+
+func TestLogConcurrentAppendsTrimOnce(t *testing.T) {
+	log := NewLog(filepath.Join(t.TempDir(), "triggers.log"))
+	const writers, each = 4, 510 // 2040 entries: one trim, at the 2001st
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range each {
+				if err := log.Append(Fired{Time: time.Now(), Trigger: fmt.Sprintf("t%d", w), Event: fmt.Sprint(i)}); err != nil {
+					t.Errorf("Append: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	entries, err := log.Read(0)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if want := maxLogEntries + writers*each - 2*maxLogEntries - 1; len(entries) != want {
+		t.Fatalf("expected %d entries after trimming, got %d", want, len(entries))
+	}
+}
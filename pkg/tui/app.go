@@ -1,16 +1,18 @@
 package tui
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"soundctl/pkg/soundctl/alias"
 	"soundctl/pkg/soundctl/audio"
 	"soundctl/pkg/soundctl/bluetooth"
+	sexec "soundctl/pkg/soundctl/exec"
 	"soundctl/pkg/soundctl/preset"
+	"soundctl/pkg/soundctl/resolve"
 )
 
 var tabNames = []string{"Devices", "Sinks", "Profiles", "Presets"}
@@ -34,46 +36,53 @@ type AppModel struct {
 	isError    bool
 	keys       KeyMap
 
-	// Live subscriptions (nil until Init runs).
-	paSub *PulseAudioSubscription
-	btSub *BluetoothSubscription
+	// Live subscriptions (empty until Init runs).
+	streamer sexec.Streamer
+	subs     *liveSubscriptions
 
 	// Service refs for refresh commands.
-	bt bluetooth.Service
-	au audio.Service
+	bt      bluetooth.Service
+	au      audio.Service
+	aliases *alias.Store
 
 	// Debounce: true when a refresh is already pending.
 	refreshPending bool
 }
 
-// NewAppModel creates the root app with service dependencies.
-func NewAppModel(bt bluetooth.Service, au audio.Service, store *preset.Store) AppModel {
+// NewAppModel creates the root app with service dependencies. The streamer
+// runs the event subscriptions that drive live refresh. Aliases are read
+// from aliases.yaml next to the preset store.
+func NewAppModel(bt bluetooth.Service, au audio.Service, store *preset.Store, streamer sexec.Streamer) AppModel {
 	keys := DefaultKeyMap()
+	aliases := alias.NewStore(alias.PathNextTo(store.Path()))
+	res := resolve.New(au, bt).WithAliases(aliases.Map)
 	return AppModel{
-		devices:  NewDevicesPane(bt, keys),
+		devices:  NewDevicesPane(bt, au, keys),
 		sinks:    NewSinksPane(au, keys),
 		profiles: NewProfilesPane(au, keys),
-		presets:  NewPresetsPane(store, au, keys),
+		presets:  NewPresetsPane(store, au, res, keys),
 		scanner:  NewScanOverlay(bt, keys),
 		keys:     keys,
+		streamer: streamer,
+		subs:     &liveSubscriptions{},
 		bt:       bt,
 		au:       au,
+		aliases:  aliases,
 	}
 }
 
 func (m AppModel) Init() tea.Cmd {
 	// Start live subscriptions.
-	ctx := context.Background()
-	m.paSub = NewPulseAudioSubscription(ctx)
-	m.btSub = NewBluetoothSubscription(ctx)
+	m.subs.start(m.streamer, m.au)
 
 	return tea.Batch(
 		m.devices.Init(),
 		m.sinks.Init(),
 		m.profiles.Init(),
 		m.presets.Init(),
-		m.paSub.WaitCmd(),
-		m.btSub.WaitCmd(),
+		loadAliasesCmd(m.aliases),
+		waitAudioEventCmd(m.subs.audio),
+		waitBluetoothEventCmd(m.subs.bt),
 	)
 }
 
@@ -98,6 +107,17 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.isError = true
 		return m, nil
 
+	case AliasesLoadedMsg:
+		if msg.Err != nil {
+			m.statusText = fmt.Sprintf("%v", msg.Err)
+			m.isError = true
+			return m, nil
+		}
+		m.devices.aliases = msg.Aliases
+		m.sinks.aliases = msg.Aliases
+		m.presets.aliases = msg.Aliases
+		return m, nil
+
 	case OpenScannerMsg:
 		var cmd tea.Cmd
 		m.scanner, cmd = m.scanner.Update(msg)
@@ -160,7 +180,7 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	// Data messages go to their owning pane.
 	switch msg.(type) {
-	case DevicesLoadedMsg, ConnectResultMsg, DisconnectResultMsg, ForgetResultMsg:
+	case DevicesLoadedMsg, VolumesLoadedMsg, ConnectResultMsg, DisconnectResultMsg, ForgetResultMsg:
 		var cmd tea.Cmd
 		m.devices, cmd = m.devices.Update(msg)
 		cmds = append(cmds, cmd)
@@ -172,7 +192,7 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		var cmd tea.Cmd
 		m.profiles, cmd = m.profiles.Update(msg)
 		cmds = append(cmds, cmd)
-	case PresetsLoadedMsg, ApplyPresetResultMsg, DeletePresetResultMsg, SavePresetResultMsg, OpenConfirmMsg, CloseConfirmMsg:
+	case PresetsLoadedMsg, PresetsValidatedMsg, ApplyPresetResultMsg, DeletePresetResultMsg, SavePresetResultMsg, OpenConfirmMsg, CloseConfirmMsg:
 		var cmd tea.Cmd
 		m.presets, cmd = m.presets.Update(msg)
 		cmds = append(cmds, cmd)
@@ -189,8 +209,8 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg.(type) {
 	case PulseAudioEventMsg:
 		// Re-subscribe for next event.
-		if m.paSub != nil {
-			cmds = append(cmds, m.paSub.WaitCmd())
+		if m.subs.audio != nil {
+			cmds = append(cmds, waitAudioEventCmd(m.subs.audio))
 		}
 		// Debounce: schedule a refresh if one isn't already pending.
 		if !m.refreshPending {
@@ -200,8 +220,8 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case BluetoothEventMsg:
 		// Re-subscribe for next event.
-		if m.btSub != nil {
-			cmds = append(cmds, m.btSub.WaitCmd())
+		if m.subs.bt != nil {
+			cmds = append(cmds, waitBluetoothEventCmd(m.subs.bt))
 		}
 		if !m.refreshPending {
 			m.refreshPending = true
@@ -213,8 +233,10 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.refreshPending = false
 		cmds = append(cmds,
 			loadDevicesCmd(m.bt),
+			loadVolumesCmd(m.au),
 			loadSinksCmd(m.au),
 			loadProfilesCmd(m.au),
+			loadAliasesCmd(m.aliases),
 		)
 	}
 
@@ -6,10 +6,13 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"soundctl/pkg/soundctl/alias"
 	"soundctl/pkg/soundctl/audio"
 	"soundctl/pkg/soundctl/bluetooth"
+	"soundctl/pkg/soundctl/events"
 	"soundctl/pkg/soundctl/exec"
 	"soundctl/pkg/soundctl/preset"
 )
@@ -45,6 +48,14 @@ func newTestAppWithDir(tmpDir string) (AppModel, *exec.FakeRunner) {
 		Output: "0\ttest-card\tmodule-alsa-card.c",
 	})
 
+	// Stub detailed sinks/sources for volume bars
+	runner.Set("pactl", []string{"list", "sinks"}, exec.CommandResult{
+		Output: "Sink #1\n\tName: test-sink\n\tDescription: Test Sink\n\tMute: no\n\tVolume: mono: 47186 /  72% / -8.56 dB",
+	})
+	runner.Set("pactl", []string{"list", "sources"}, exec.CommandResult{
+		Output: "Source #2\n\tName: test-source\n\tDescription: Test Source\n\tMute: yes\n\tVolume: mono: 65536 / 100% / 0.00 dB",
+	})
+
 	// Stub pactl info for defaults
 	runner.Set("pactl", []string{"info"}, exec.CommandResult{
 		Output: "Default Sink: test-sink\nDefault Source: test-source\nServer Name: PipeWire",
@@ -55,6 +66,11 @@ func newTestAppWithDir(tmpDir string) (AppModel, *exec.FakeRunner) {
 		Output: "",
 	})
 
+	// Stub source-outputs
+	runner.Set("pactl", []string{"list", "source-outputs"}, exec.CommandResult{
+		Output: "",
+	})
+
 	// Stub detailed cards for TUI profiles
 	runner.Set("pactl", []string{"list", "cards"}, exec.CommandResult{
 		Output: "Card #0\n\tName: test-card\n\tDriver: module-alsa-card.c\n\tProfiles:\n\t\toutput:stereo: Stereo Output (sinks: 1, sources: 0, priority: 6500, available: yes)\n\t\toff: Off (sinks: 0, sources: 0, priority: 0, available: yes)\n\tActive Profile: output:stereo",
@@ -64,7 +80,7 @@ func newTestAppWithDir(tmpDir string) (AppModel, *exec.FakeRunner) {
 	au := audio.NewExecService(runner)
 	store := preset.NewStore(filepath.Join(tmpDir, "presets.yaml"))
 
-	model := NewAppModel(bt, au, store)
+	model := NewAppModel(bt, au, store, exec.NewFakeStreamer())
 	return model, runner
 }
 
@@ -196,6 +212,40 @@ func TestDevicesLoadedMsg(t *testing.T) {
 	}
 }
 
+func TestVolumesLoadedMsgRendersLiveLevels(t *testing.T) {
+	model, _ := newTestApp()
+	m, _ := model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	model = m.(AppModel)
+
+	msg := loadVolumesCmd(model.au)()
+	loaded, ok := msg.(VolumesLoadedMsg)
+	if !ok || loaded.Err != nil {
+		t.Fatalf("expected VolumesLoadedMsg without error, got %#v", msg)
+	}
+	m, _ = model.Update(loaded)
+	model = m.(AppModel)
+
+	if len(model.devices.sinks) != 1 || model.devices.defaultSink != "test-sink" {
+		t.Fatalf("unexpected volume state: %+v", model.devices.sinks)
+	}
+	view := model.View()
+	for _, want := range []string{"Output", "72%", "Input", "100%", "🔇"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("devices view missing %q", want)
+		}
+	}
+}
+
+func TestVolumeLineTruncatesByWidth(t *testing.T) {
+	line := renderVolumeLine("Écouteurs de Zoé", 50, false, 10)
+	if !utf8.ValidString(line) || !strings.Contains(line, "Écouteurs…") {
+		t.Fatalf("expected label cut on a character boundary, got %q", line)
+	}
+	if got := truncate("会議用ヘッドセット", 10); got != "会議用ヘ…" {
+		t.Fatalf("expected wide characters to count double, got %q", got)
+	}
+}
+
 func TestSinksLoadedMsg(t *testing.T) {
 	model, _ := newTestApp()
 	m, _ := model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
@@ -307,13 +357,14 @@ func TestViewRendersSinksTab(t *testing.T) {
 	m, _ = model.Update(SinksLoadedMsg{
 		Sinks:             []audio.ShortRecord{{ID: 1, Name: "alsa-sink-stereo", State: "RUNNING"}},
 		Sources:           []audio.ShortRecord{{ID: 2, Name: "alsa-source-mono", State: "IDLE"}},
+		SourceOutputs:     []audio.SourceOutput{{Index: 61, SourceIndex: 2, AppName: "Zoom", SourceName: "alsa-source-mono"}},
 		DefaultSinkName:   "alsa-sink-stereo",
 		DefaultSourceName: "alsa-source-mono",
 	})
 	model = m.(AppModel)
 
 	view := model.View()
-	for _, want := range []string{"Output Sinks", "Input Sources", "App Routing", "alsa-sink-stereo", "alsa-source-mono", "default"} {
+	for _, want := range []string{"Output Sinks", "Input Sources", "App Routing", "Recording", "Zoom", "alsa-sink-stereo", "alsa-source-mono", "default"} {
 		if !strings.Contains(view, want) {
 			t.Errorf("sinks view missing %q", want)
 		}
@@ -352,6 +403,27 @@ func TestViewRendersProfilesTab(t *testing.T) {
 			t.Errorf("profiles view missing %q", want)
 		}
 	}
+
+	// Bluetooth cards get a codec column and the active codec in the title.
+	m, _ = model.Update(ProfilesLoadedMsg{
+		Cards: []audio.Card{{
+			Index:         1,
+			Name:          "bluez_card.08_FF_44_2B_4C_90",
+			ActiveProfile: "a2dp-sink-aac",
+			Codec:         "aac",
+			Profiles: []audio.CardProfile{
+				{Name: "a2dp-sink-aac", Description: "High Fidelity Playback (A2DP Sink, codec AAC)", Available: true, Codec: "aac"},
+				{Name: "a2dp-sink-ldac", Description: "High Fidelity Playback (A2DP Sink, codec LDAC)", Available: true, Codec: "ldac"},
+			},
+		}},
+	})
+	model = m.(AppModel)
+	view = model.View()
+	for _, want := range []string{"codec: aac", "ldac"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("profiles view missing %q", want)
+		}
+	}
 }
 
 func TestScannerOverlayViewWithDevices(t *testing.T) {
@@ -409,6 +481,28 @@ func TestSinksWithAppRouting(t *testing.T) {
 	}
 }
 
+func TestSinksShowAliases(t *testing.T) {
+	model, _ := newTestApp()
+	m, _ := model.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	model = m.(AppModel)
+	m, _ = model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	model = m.(AppModel)
+
+	m, _ = model.Update(AliasesLoadedMsg{Aliases: alias.Set{{Name: "desk", Target: "alsa_output.usb-dock"}}})
+	model = m.(AppModel)
+	m, _ = model.Update(SinksLoadedMsg{
+		Sinks:           []audio.ShortRecord{{ID: 1, Name: "alsa_output.usb-dock", State: "RUNNING"}},
+		SinkInputs:      []audio.SinkInput{{Index: 57, SinkIndex: 1, AppName: "Firefox", SinkName: "alsa_output.usb-dock"}},
+		DefaultSinkName: "alsa_output.usb-dock",
+	})
+	model = m.(AppModel)
+
+	view := model.View()
+	if !strings.Contains(view, "Firefox") || !strings.Contains(view, "→ desk") {
+		t.Errorf("sinks view does not show the alias:\n%s", view)
+	}
+}
+
 func TestProfilesApplyViaEnter(t *testing.T) {
 	model, _ := newTestApp()
 	m, _ := model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
@@ -455,7 +549,7 @@ func TestPulseAudioEventTriggersRefresh(t *testing.T) {
 	model = m.(AppModel)
 
 	// Simulate a PulseAudio event
-	m, cmd := model.Update(PulseAudioEventMsg{EventType: "change", Facility: "sink"})
+	m, cmd := model.Update(PulseAudioEventMsg{Event: events.AudioEvent{Kind: events.KindChange, Facility: "sink", Index: 1}})
 	model = m.(AppModel)
 
 	if !model.refreshPending {
@@ -472,7 +566,7 @@ func TestBluetoothEventTriggersRefresh(t *testing.T) {
 	model = m.(AppModel)
 
 	// Simulate a Bluetooth event
-	m, cmd := model.Update(BluetoothEventMsg{EventType: "property-changed", Detail: "test"})
+	m, cmd := model.Update(BluetoothEventMsg{Event: events.BluetoothEvent{Kind: events.KindPropertyChanged, Address: "AA:BB:CC:DD:EE:FF"}})
 	model = m.(AppModel)
 
 	if !model.refreshPending {
@@ -509,14 +603,14 @@ func TestDebouncePreventsDoubleRefresh(t *testing.T) {
 	model = m.(AppModel)
 
 	// First event sets pending
-	m, _ = model.Update(PulseAudioEventMsg{EventType: "change", Facility: "sink"})
+	m, _ = model.Update(PulseAudioEventMsg{Event: events.AudioEvent{Kind: events.KindChange, Facility: "sink", Index: 1}})
 	model = m.(AppModel)
 	if !model.refreshPending {
 		t.Fatal("expected pending after first event")
 	}
 
 	// Second event should not schedule another debounce
-	m, _ = model.Update(PulseAudioEventMsg{EventType: "change", Facility: "source"})
+	m, _ = model.Update(PulseAudioEventMsg{Event: events.AudioEvent{Kind: events.KindChange, Facility: "source", Index: 2}})
 	model = m.(AppModel)
 	// Still pending — only one debounce timer should be active
 	if !model.refreshPending {
@@ -740,6 +834,86 @@ func TestPresetsApplyConfirmFlow(t *testing.T) {
 	}
 }
 
+func TestPresetsConfirmAtomicRollback(t *testing.T) {
+	model, _ := newTestApp()
+	m, _ := model.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	model = m.(AppModel)
+	for i := 0; i < 3; i++ {
+		m, _ = model.Update(tea.KeyMsg{Type: tea.KeyTab})
+		model = m.(AppModel)
+	}
+	m, _ = model.Update(OpenConfirmMsg{Preset: preset.Preset{Name: "Desk", DefaultSink: "sink"}})
+	model = m.(AppModel)
+
+	m, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	model = m.(AppModel)
+	if !model.presets.confirmAtomic {
+		t.Fatal("expected 'a' to enable atomic apply")
+	}
+	if !strings.Contains(model.View(), "[x] Atomic") {
+		t.Error("confirm view missing the atomic checkbox")
+	}
+
+	pane, cmd := model.presets.Update(ApplyPresetResultMsg{
+		Name:       "Desk",
+		Result:     preset.ApplyResult{Errors: []error{fmt.Errorf("set profile x→y: no such entity")}},
+		RolledBack: true,
+	})
+	if pane.confirmVisible || pane.activePreset != "" {
+		t.Fatalf("expected the overlay closed and no active preset, got visible=%t active=%q", pane.confirmVisible, pane.activePreset)
+	}
+	msg, ok := cmd().(ErrorMsg)
+	if !ok || !strings.Contains(msg.Err.Error(), `preset "Desk" rolled back: set profile x→y`) {
+		t.Fatalf("unexpected message %#v", msg)
+	}
+}
+
+func TestPresetsConfirmShowsPreflight(t *testing.T) {
+	model, runner := newTestApp()
+	m, _ := model.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	model = m.(AppModel)
+	for i := 0; i < 3; i++ {
+		m, _ = model.Update(tea.KeyMsg{Type: tea.KeyTab})
+		model = m.(AppModel)
+	}
+	m, _ = model.Update(PresetsLoadedMsg{
+		Presets: []preset.Preset{{Name: "Broken", DefaultSink: "gone-sink"}},
+	})
+	model = m.(AppModel)
+
+	// Enter checks the preset before the overlay opens.
+	m, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = m.(AppModel)
+	open, ok := cmd().(OpenConfirmMsg)
+	if !ok || !preset.HasErrors(open.Findings) {
+		t.Fatalf("expected pre-flight errors, got %#v", open)
+	}
+	m, _ = model.Update(open)
+	model = m.(AppModel)
+	view := model.View()
+	if !strings.Contains(view, "Pre-flight:") || !strings.Contains(view, `✗ Default sink: sink "gone-sink" not found`) {
+		t.Fatalf("confirm view missing the pre-flight errors:\n%s", view)
+	}
+
+	// Applying anyway is refused and the overlay stays on the findings.
+	m, cmd = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = m.(AppModel)
+	result, ok := cmd().(ApplyPresetResultMsg)
+	if !ok || result.Err == nil || !preset.HasErrors(result.Findings) {
+		t.Fatalf("expected the apply to be refused, got %#v", result)
+	}
+	for _, call := range runner.Calls() {
+		if strings.Contains(call, "set-default-sink") {
+			t.Fatalf("preset applied despite pre-flight errors: %v", runner.Calls())
+		}
+	}
+	m, _ = model.Update(result)
+	model = m.(AppModel)
+	if !model.presets.confirmVisible || model.presets.activePreset != "" {
+		t.Fatalf("expected the overlay open and no active preset, got visible=%t active=%q", model.presets.confirmVisible, model.presets.activePreset)
+	}
+}
+
 func TestPresetsActiveMarker(t *testing.T) {
 	model, _ := newTestApp()
 	m, _ := model.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
@@ -776,6 +950,45 @@ func TestPresetsActiveMarker(t *testing.T) {
 	}
 }
 
+func TestPresetsValidationMarker(t *testing.T) {
+	model, _ := newTestApp()
+	m, _ := model.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	model = m.(AppModel)
+
+	// Switch to Presets tab
+	for i := 0; i < 3; i++ {
+		m, _ = model.Update(tea.KeyMsg{Type: tea.KeyTab})
+		model = m.(AppModel)
+	}
+
+	m, cmd := model.Update(PresetsLoadedMsg{
+		Presets: []preset.Preset{
+			{Name: "Broken", DefaultSink: "gone-sink"},
+			{Name: "Fine", DefaultSink: "test-sink"},
+		},
+	})
+	model = m.(AppModel)
+	if cmd == nil {
+		t.Fatal("expected loading presets to start validation")
+	}
+
+	m, _ = model.Update(PresetsValidatedMsg{Findings: map[string][]preset.Finding{
+		"Broken": {{Severity: preset.SeverityError, Code: preset.FindingUnknownSink, Field: "Default sink", Message: `sink "gone-sink" not found`}},
+	}})
+	model = m.(AppModel)
+
+	view := model.View()
+	if !strings.Contains(view, "✗ 1 issue(s)") {
+		t.Error("view missing validation marker")
+	}
+	if !strings.Contains(view, `sink "gone-sink" not found`) {
+		t.Error("view missing finding of the selected preset")
+	}
+	if strings.Count(view, "issue(s)") != 1 {
+		t.Error("expected only the broken preset to be marked")
+	}
+}
+
 func TestDevicesCursorNavigation(t *testing.T) {
 	model, _ := newTestApp()
 	m, _ := model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
@@ -818,3 +1031,21 @@ func TestDevicesCursorNavigation(t *testing.T) {
 		t.Fatalf("expected cursor at 1, got %d", model.devices.cursor)
 	}
 }
+
+func TestInitStartsSubscriptionsFromStreamer(t *testing.T) {
+	runner := exec.NewFakeRunner()
+	streamer := exec.NewFakeStreamer()
+	streamer.Add("pactl", []string{"subscribe"}, exec.StreamResult{Lines: []string{"Event 'new' on sink-input #7"}})
+	store := preset.NewStore(filepath.Join(t.TempDir(), "presets.yaml"))
+	model := NewAppModel(bluetooth.NewExecService(runner), audio.NewExecService(runner), store, streamer)
+
+	model.Init()
+	defer model.subs.audio.Stop()
+	defer model.subs.bt.Stop()
+
+	msg := waitAudioEventCmd(model.subs.audio)()
+	ev, ok := msg.(PulseAudioEventMsg)
+	if !ok || ev.Event.Facility != "sink-input" || ev.Event.Index != 7 {
+		t.Fatalf("unexpected message: %#v", msg)
+	}
+}
@@ -4,10 +4,18 @@ import (
 	"context"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"soundctl/pkg/soundctl/alias"
 	"soundctl/pkg/soundctl/audio"
 	"soundctl/pkg/soundctl/bluetooth"
 )
 
+func loadAliasesCmd(store *alias.Store) tea.Cmd {
+	return func() tea.Msg {
+		aliases, err := store.List()
+		return AliasesLoadedMsg{Aliases: aliases, Err: err}
+	}
+}
+
 // --- Bluetooth commands ---
 
 func loadDevicesCmd(bt bluetooth.Service) tea.Cmd {
@@ -84,6 +92,10 @@ func loadSinksCmd(au audio.Service) tea.Cmd {
 		if err != nil {
 			return SinksLoadedMsg{Err: err}
 		}
+		outputs, err := au.ListSourceOutputs(ctx)
+		if err != nil {
+			return SinksLoadedMsg{Err: err}
+		}
 		defaults, err := au.GetDefaults(ctx)
 		if err != nil {
 			return SinksLoadedMsg{Err: err}
@@ -92,6 +104,31 @@ func loadSinksCmd(au audio.Service) tea.Cmd {
 			Sinks:             sinks,
 			Sources:           sources,
 			SinkInputs:        inputs,
+			SourceOutputs:     outputs,
+			DefaultSinkName:   defaults.DefaultSinkName,
+			DefaultSourceName: defaults.DefaultSourceName,
+		}
+	}
+}
+
+func loadVolumesCmd(au audio.Service) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		sinks, err := au.ListSinksDetailed(ctx)
+		if err != nil {
+			return VolumesLoadedMsg{Err: err}
+		}
+		sources, err := au.ListSourcesDetailed(ctx)
+		if err != nil {
+			return VolumesLoadedMsg{Err: err}
+		}
+		defaults, err := au.GetDefaults(ctx)
+		if err != nil {
+			return VolumesLoadedMsg{Err: err}
+		}
+		return VolumesLoadedMsg{
+			Sinks:             sinks,
+			Sources:           sources,
 			DefaultSinkName:   defaults.DefaultSinkName,
 			DefaultSourceName: defaults.DefaultSourceName,
 		}
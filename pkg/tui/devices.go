@@ -7,27 +7,36 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"soundctl/pkg/soundctl/alias"
+	"soundctl/pkg/soundctl/audio"
 	"soundctl/pkg/soundctl/bluetooth"
 )
 
 // DevicesPane shows bluetooth devices with connect/disconnect/forget actions
 // and a volume section, matching the spec Screen 1 layout.
 type DevicesPane struct {
-	devices    []bluetooth.Device
-	controller bluetooth.ControllerStatus
-	cursor     int
-	width      int
-	height     int
-	bt         bluetooth.Service
-	keys       KeyMap
+	devices       []bluetooth.Device
+	controller    bluetooth.ControllerStatus
+	sinks         []audio.Device
+	sources       []audio.Device
+	defaultSink   string
+	defaultSource string
+	cursor        int
+	width         int
+	height        int
+	bt            bluetooth.Service
+	aliases       alias.Set
+	au            audio.Service
+	keys          KeyMap
 }
 
-func NewDevicesPane(bt bluetooth.Service, keys KeyMap) DevicesPane {
-	return DevicesPane{bt: bt, keys: keys}
+func NewDevicesPane(bt bluetooth.Service, au audio.Service, keys KeyMap) DevicesPane {
+	return DevicesPane{bt: bt, au: au, keys: keys}
 }
 
 func (m DevicesPane) Init() tea.Cmd {
-	return loadDevicesCmd(m.bt)
+	return tea.Batch(loadDevicesCmd(m.bt), loadVolumesCmd(m.au))
 }
 
 func (m DevicesPane) Update(msg tea.Msg) (DevicesPane, tea.Cmd) {
@@ -42,6 +51,15 @@ func (m DevicesPane) Update(msg tea.Msg) (DevicesPane, tea.Cmd) {
 			m.cursor = max(0, len(m.devices)-1)
 		}
 
+	case VolumesLoadedMsg:
+		if msg.Err != nil {
+			return m, func() tea.Msg { return ErrorMsg{Err: msg.Err} }
+		}
+		m.sinks = msg.Sinks
+		m.sources = msg.Sources
+		m.defaultSink = msg.DefaultSinkName
+		m.defaultSource = msg.DefaultSourceName
+
 	case ConnectResultMsg:
 		if msg.Err != nil {
 			return m, func() tea.Msg {
@@ -154,11 +172,7 @@ func (m DevicesPane) View() string {
 	if volBarW < 10 {
 		volBarW = 10
 	}
-	var volRows []string
-	// Use a dummy volume for now – Phase 2.3 will add live subscriptions
-	volRows = append(volRows, renderVolumeLine("Master", 72, volBarW))
-	volRows = append(volRows, renderVolumeLine("Media", 90, volBarW))
-	volRows = append(volRows, renderVolumeLine("Alerts", 35, volBarW))
+	volRows := m.renderVolumes(volBarW)
 
 	volContent := strings.Join(volRows, "\n")
 	volBox := sectionBox.Width(innerW).Render(
@@ -199,12 +213,13 @@ func (m DevicesPane) renderDeviceRow(idx int, d bluetooth.Device, rowW int) stri
 	// Name
 	nameW := 28
 	name := d.Name
-	if len(name) > nameW {
-		name = name[:nameW-1] + "…"
+	if a := m.aliases.NameFor(d.Address); a != "" {
+		name = a
 	}
-	nameStr := nameNormalStyle.Render(fmt.Sprintf("%-*s", nameW, name))
+	name = runewidth.FillRight(truncate(name, nameW), nameW)
+	nameStr := nameNormalStyle.Render(name)
 	if idx == m.cursor {
-		nameStr = nameHighlightStyle.Render(fmt.Sprintf("%-*s", nameW, name))
+		nameStr = nameHighlightStyle.Render(name)
 	}
 
 	// Status
@@ -227,11 +242,51 @@ func (m DevicesPane) renderButtons() string {
 	return "  " + scanBtn + gap + disconnBtn + gap + forgetBtn
 }
 
-func renderVolumeLine(label string, pct, barW int) string {
+// renderVolumes lists the default sink and source first, followed by the
+// remaining sinks.
+func (m DevicesPane) renderVolumes(barW int) []string {
+	var rows []string
+	for _, d := range m.sinks {
+		if d.Name == m.defaultSink {
+			rows = append(rows, renderVolumeLine("Output", d.VolumePercent(), d.Muted, barW))
+		}
+	}
+	for _, d := range m.sources {
+		if d.Name == m.defaultSource {
+			rows = append(rows, renderVolumeLine("Input", d.VolumePercent(), d.Muted, barW))
+		}
+	}
+	for _, d := range m.sinks {
+		if d.Name == m.defaultSink {
+			continue
+		}
+		label := m.aliases.NameFor(d.Name)
+		if label == "" {
+			label = d.Description
+		}
+		if label == "" {
+			label = displayName(nil, d.Name)
+		}
+		rows = append(rows, renderVolumeLine(label, d.VolumePercent(), d.Muted, barW))
+	}
+	if len(rows) == 0 {
+		rows = append(rows, dimStyle.Render("  No sinks found."))
+	}
+	return rows
+}
+
+// truncate shortens s to at most w terminal cells, ending in "…" when cut.
+// It counts display width, so accented and wide names are not split
+// mid-character.
+func truncate(s string, w int) string {
+	return runewidth.Truncate(s, w, "…")
+}
+
+func renderVolumeLine(label string, pct int, muted bool, barW int) string {
 	labelStr := lipgloss.NewStyle().
 		Width(10).
 		Foreground(colorDim).
-		Render(label)
+		Render(truncate(label, 10))
 
 	bar := volumeBar(pct, barW)
 	pctStr := lipgloss.NewStyle().
@@ -240,7 +295,12 @@ func renderVolumeLine(label string, pct, barW int) string {
 		Foreground(colorBright).
 		Render(fmt.Sprintf("%d%%", pct))
 
-	return fmt.Sprintf("  %s %s %s", labelStr, bar, pctStr)
+	mute := ""
+	if muted {
+		mute = " " + errorBarStyle.Render("🔇")
+	}
+
+	return fmt.Sprintf("  %s %s %s%s", labelStr, bar, pctStr, mute)
 }
 
 func capitalize(s string) string {
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"soundctl/pkg/soundctl/alias"
 	"soundctl/pkg/soundctl/audio"
 	"soundctl/pkg/soundctl/bluetooth"
 )
@@ -22,6 +23,12 @@ type StatusMsg struct{ Text string }
 // ErrorMsg reports an error to the status bar.
 type ErrorMsg struct{ Err error }
 
+// AliasesLoadedMsg carries the user-defined device aliases.
+type AliasesLoadedMsg struct {
+	Aliases alias.Set
+	Err     error
+}
+
 // --- Bluetooth domain messages ---
 
 // DevicesLoadedMsg carries refreshed device list.
@@ -71,11 +78,21 @@ type PairResultMsg struct {
 
 // --- Audio domain messages ---
 
-// SinksLoadedMsg carries refreshed sink/source/routing data.
+// SinksLoadedMsg carries refreshed sink/source/routing/recording data.
 type SinksLoadedMsg struct {
 	Sinks             []audio.ShortRecord
 	Sources           []audio.ShortRecord
 	SinkInputs        []audio.SinkInput
+	SourceOutputs     []audio.SourceOutput
+	DefaultSinkName   string
+	DefaultSourceName string
+	Err               error
+}
+
+// VolumesLoadedMsg carries detailed sink/source volume and mute state.
+type VolumesLoadedMsg struct {
+	Sinks             []audio.Device
+	Sources           []audio.Device
 	DefaultSinkName   string
 	DefaultSourceName string
 	Err               error
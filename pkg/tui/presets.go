@@ -2,6 +2,7 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -9,8 +10,10 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"soundctl/pkg/soundctl/alias"
 	"soundctl/pkg/soundctl/audio"
 	"soundctl/pkg/soundctl/preset"
+	"soundctl/pkg/soundctl/resolve"
 )
 
 // ── Messages ────────────────────────────────────────────────────────────────
@@ -21,10 +24,19 @@ type PresetsLoadedMsg struct {
 	Err     error
 }
 
+// PresetsValidatedMsg carries the findings of checking each preset
+// against the live system, by preset name.
+type PresetsValidatedMsg struct {
+	Findings map[string][]preset.Finding
+}
+
 // ApplyPresetResultMsg reports apply outcome.
 type ApplyPresetResultMsg struct {
-	Name   string
-	Result preset.ApplyResult
+	Name       string
+	Result     preset.ApplyResult
+	RolledBack bool             // an atomic apply failed and restored the previous state
+	Err        error            // the preset failed its pre-flight check or an atomic apply could not start
+	Findings   []preset.Finding // pre-flight findings, when the check failed
 }
 
 // DeletePresetResultMsg reports delete outcome.
@@ -41,8 +53,9 @@ type SavePresetResultMsg struct {
 
 // OpenConfirmMsg opens the apply confirmation overlay.
 type OpenConfirmMsg struct {
-	Preset preset.Preset
-	Diffs  []preset.DiffLine
+	Preset   preset.Preset
+	Diffs    []preset.DiffLine
+	Findings []preset.Finding // pre-flight check against the live system
 }
 
 // CloseConfirmMsg closes the confirmation overlay.
@@ -57,13 +70,95 @@ func loadPresetsCmd(store *preset.Store) tea.Cmd {
 	}
 }
 
-func applyPresetCmd(au audio.Service, p preset.Preset) tea.Cmd {
+// validatePresetsCmd checks every preset, as it would be applied, against
+// the live system. Presets that cannot be checked get no findings.
+func validatePresetsCmd(au audio.Service, res *resolve.Resolver, presets []preset.Preset) tea.Cmd {
 	return func() tea.Msg {
-		result := preset.Apply(context.Background(), au, p)
+		ctx := context.Background()
+		findings := map[string][]preset.Finding{}
+		for _, p := range presets {
+			resolved, err := preset.Resolve(p, presets)
+			if err != nil {
+				continue
+			}
+			resolved, _ = resolveNames(ctx, res, resolved)
+			if f, err := preset.Validate(ctx, au, resolved); err == nil && len(f) > 0 {
+				findings[p.Name] = f
+			}
+		}
+		return PresetsValidatedMsg{Findings: findings}
+	}
+}
+
+// resolveNames replaces the aliases p names with what they stand for.
+func resolveNames(ctx context.Context, res *resolve.Resolver, p preset.Preset) (preset.Preset, []error) {
+	return preset.ResolveNames(ctx, p, func(ctx context.Context, kind string, name string) (string, error) {
+		return res.ResolveAlias(ctx, resolve.Kind(kind), name)
+	})
+}
+
+// preflight resolves the aliases p names and checks the result against the
+// live system, as `presets apply` does before applying.
+func preflight(ctx context.Context, au audio.Service, res *resolve.Resolver, p preset.Preset) (preset.Preset, []error, []preset.Finding, error) {
+	resolved, errs := resolveNames(ctx, res, p)
+	findings, err := preset.Validate(ctx, au, resolved)
+	if err != nil {
+		return resolved, errs, nil, fmt.Errorf("pre-flight check: %w", err)
+	}
+	return resolved, errs, findings, nil
+}
+
+// confirmPresetCmd opens the confirmation overlay for p with the findings
+// of its pre-flight check.
+func confirmPresetCmd(au audio.Service, res *resolve.Resolver, p preset.Preset, diffs []preset.DiffLine) tea.Cmd {
+	return func() tea.Msg {
+		var findings []preset.Finding
+		if au != nil {
+			_, _, findings, _ = preflight(context.Background(), au, res, p)
+		}
+		return OpenConfirmMsg{Preset: p, Diffs: diffs, Findings: findings}
+	}
+}
+
+// errPreflight keeps a preset whose pre-flight check found errors from
+// being applied; the findings are shown in the confirmation overlay.
+var errPreflight = errors.New("pre-flight check failed")
+
+func applyPresetCmd(au audio.Service, res *resolve.Resolver, p preset.Preset) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		resolved, errs, findings, err := preflight(ctx, au, res, p)
+		if err != nil {
+			return ApplyPresetResultMsg{Name: p.Name, Err: err}
+		}
+		if preset.HasErrors(findings) {
+			return ApplyPresetResultMsg{Name: p.Name, Err: errPreflight, Findings: findings}
+		}
+		result := preset.Apply(ctx, au, resolved)
+		result.Errors = append(errs, result.Errors...)
 		return ApplyPresetResultMsg{Name: p.Name, Result: result}
 	}
 }
 
+// applyPresetAtomicCmd applies p all-or-nothing, see preset.ApplyAtomic.
+func applyPresetAtomicCmd(au audio.Service, res *resolve.Resolver, p preset.Preset) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		resolved, errs, findings, err := preflight(ctx, au, res, p)
+		if err != nil {
+			return ApplyPresetResultMsg{Name: p.Name, Err: err}
+		}
+		if preset.HasErrors(findings) {
+			return ApplyPresetResultMsg{Name: p.Name, Err: errPreflight, Findings: findings}
+		}
+		if len(errs) > 0 {
+			return ApplyPresetResultMsg{Name: p.Name, Err: errs[0]}
+		}
+		result, err := preset.ApplyAtomic(ctx, au, resolved, preset.DefaultApplyOptions())
+		return ApplyPresetResultMsg{Name: p.Name, Result: result.ApplyResult, RolledBack: result.RolledBack, Err: err}
+	}
+}
+
 func deletePresetCmd(store *preset.Store, name string) tea.Cmd {
 	return func() tea.Msg {
 		err := store.Delete(name)
@@ -94,17 +189,22 @@ type PresetsPane struct {
 	height       int
 	store        *preset.Store
 	au           audio.Service
+	res          *resolve.Resolver
+	aliases      alias.Set
+	findings     map[string][]preset.Finding // live-system problems, by preset name
 	keys         KeyMap
 
 	// Confirmation overlay (Screen 7)
-	confirmVisible bool
-	confirmPreset  preset.Preset
-	confirmDiffs   []preset.DiffLine
-	confirmCursor  int // 0=apply, 1=cancel
+	confirmVisible  bool
+	confirmPreset   preset.Preset
+	confirmDiffs    []preset.DiffLine
+	confirmFindings []preset.Finding // pre-flight findings for confirmPreset
+	confirmCursor   int              // 0=apply, 1=cancel
+	confirmAtomic   bool             // verify and roll back on failure
 }
 
-func NewPresetsPane(store *preset.Store, au audio.Service, keys KeyMap) PresetsPane {
-	return PresetsPane{store: store, au: au, keys: keys}
+func NewPresetsPane(store *preset.Store, au audio.Service, res *resolve.Resolver, keys KeyMap) PresetsPane {
+	return PresetsPane{store: store, au: au, res: res, keys: keys}
 }
 
 func (m PresetsPane) Init() tea.Cmd {
@@ -121,14 +221,48 @@ func (m PresetsPane) Update(msg tea.Msg) (PresetsPane, tea.Cmd) {
 		if m.cursor >= len(m.presets) {
 			m.cursor = max(0, len(m.presets)-1)
 		}
+		if m.au != nil && len(m.presets) > 0 {
+			return m, validatePresetsCmd(m.au, m.res, m.presets)
+		}
+
+	case PresetsValidatedMsg:
+		m.findings = msg.Findings
 
 	case ApplyPresetResultMsg:
+		if msg.Findings != nil {
+			// Keep the overlay open on what kept the preset from applying.
+			m.confirmFindings = msg.Findings
+		}
+		if msg.Err != nil {
+			return m, func() tea.Msg {
+				return ErrorMsg{Err: fmt.Errorf("preset %q not applied: %w", msg.Name, msg.Err)}
+			}
+		}
+		if msg.RolledBack {
+			m.confirmVisible = false
+			reason := "unresolved targets"
+			if len(msg.Result.Errors) > 0 {
+				reason = msg.Result.Errors[0].Error()
+			}
+			return m, func() tea.Msg {
+				return ErrorMsg{Err: fmt.Errorf("preset %q rolled back: %s", msg.Name, reason)}
+			}
+		}
 		m.activePreset = msg.Name
 		if len(msg.Result.Errors) > 0 {
 			return m, func() tea.Msg {
 				return ErrorMsg{Err: fmt.Errorf("preset %q applied with %d error(s)", msg.Name, len(msg.Result.Errors))}
 			}
 		}
+		if len(msg.Result.Unresolved) > 0 {
+			targets := make([]string, len(msg.Result.Unresolved))
+			for i, u := range msg.Result.Unresolved {
+				targets[i] = fmt.Sprintf("%s%s (%s)", preset.TargetPrefix, u.Target, u.Kind)
+			}
+			return m, func() tea.Msg {
+				return ErrorMsg{Err: fmt.Errorf("preset %q applied; unresolved: %s", msg.Name, strings.Join(targets, ", "))}
+			}
+		}
 		m.confirmVisible = false
 		return m, tea.Batch(
 			loadPresetsCmd(m.store),
@@ -157,6 +291,7 @@ func (m PresetsPane) Update(msg tea.Msg) (PresetsPane, tea.Cmd) {
 		m.confirmVisible = true
 		m.confirmPreset = msg.Preset
 		m.confirmDiffs = msg.Diffs
+		m.confirmFindings = msg.Findings
 		m.confirmCursor = 0
 
 	case CloseConfirmMsg:
@@ -183,12 +318,15 @@ func (m PresetsPane) handleKey(msg tea.KeyMsg) (PresetsPane, tea.Cmd) {
 		}
 	case key.Matches(msg, m.keys.Enter):
 		if p, ok := m.selected(); ok {
+			// Confirm and apply the preset with the presets it extends.
+			p, err := preset.Resolve(p, m.presets)
+			if err != nil {
+				return m, func() tea.Msg { return ErrorMsg{Err: err} }
+			}
 			// Build diff against a "current state" pseudo-preset
 			currentPseudo := preset.Preset{} // simplified — full diff requires snapshot
 			diffs := preset.Diff(currentPseudo, p)
-			return m, func() tea.Msg {
-				return OpenConfirmMsg{Preset: p, Diffs: diffs}
-			}
+			return m, confirmPresetCmd(m.au, m.res, p, diffs)
 		}
 	case key.Matches(msg, m.keys.Forget): // X = delete
 		if p, ok := m.selected(); ok {
@@ -208,10 +346,14 @@ func (m PresetsPane) handleConfirmKey(msg tea.KeyMsg) (PresetsPane, tea.Cmd) {
 		m.confirmVisible = false
 	case key.Matches(msg, m.keys.Enter):
 		if m.confirmCursor == 0 {
-			// Apply
-			return m, applyPresetCmd(m.au, m.confirmPreset)
+			if m.confirmAtomic {
+				return m, applyPresetAtomicCmd(m.au, m.res, m.confirmPreset)
+			}
+			return m, applyPresetCmd(m.au, m.res, m.confirmPreset)
 		}
 		m.confirmVisible = false
+	case msg.String() == "a":
+		m.confirmAtomic = !m.confirmAtomic
 	case msg.String() == "left", msg.String() == "h":
 		m.confirmCursor = 0
 	case msg.String() == "right", msg.String() == "l":
@@ -287,41 +429,69 @@ func (m PresetsPane) renderPresetRow(idx int, p preset.Preset) string {
 	if isActive {
 		badge = "  " + lipgloss.NewStyle().Foreground(colorSuccess).Bold(true).Render("[active]")
 	}
+	badge += m.findingsBadge(p.Name)
 
 	// Summary line
 	summary := m.presetSummary(p)
 	summaryStr := dimStyle.Render("    " + summary)
+	if f := m.findings[p.Name]; len(f) > 0 && isCursor {
+		summaryStr += "\n" + dimStyle.Render("    "+f[0].Field+": "+f[0].Message)
+	}
 
 	return fmt.Sprintf("%s%s%s%s\n%s", cur, star, nameStr, badge, summaryStr)
 }
 
+// findingsBadge marks a preset that would not apply cleanly right now:
+// red for errors, yellow for warnings only.
+func (m PresetsPane) findingsBadge(name string) string {
+	findings := m.findings[name]
+	if len(findings) == 0 {
+		return ""
+	}
+	color, mark := colorWarning, "⚠"
+	if preset.HasErrors(findings) {
+		color, mark = colorDanger, "✗"
+	}
+	return "  " + lipgloss.NewStyle().Foreground(color).Bold(true).Render(fmt.Sprintf("%s %d issue(s)", mark, len(findings)))
+}
+
 func (m PresetsPane) presetSummary(p preset.Preset) string {
 	var parts []string
 	for card, prof := range p.CardProfiles {
-		short := card
-		if idx := strings.LastIndex(card, "."); idx >= 0 {
-			short = card[idx+1:]
+		short := m.aliases.NameFor(card)
+		if short == "" {
+			short = card
+			if idx := strings.LastIndex(card, "."); idx >= 0 {
+				short = card[idx+1:]
+			}
 		}
 		parts = append(parts, short+"→"+prof)
 	}
 	if p.DefaultSink != "" {
-		parts = append(parts, "Sink:"+friendlyName(p.DefaultSink))
+		parts = append(parts, "Sink:"+displayName(m.aliases, p.DefaultSink))
 	}
-	for ch, vol := range p.Volumes {
+	parts = append(parts, volumeSummary(p.Volumes, "")...)
+	parts = append(parts, volumeSummary(p.SourceVolumes, "🎙")...)
+	if len(parts) == 0 {
+		return "(empty)"
+	}
+	return strings.Join(parts, "  ")
+}
+
+func volumeSummary(volumes map[string]preset.VolumeSpec, prefix string) []string {
+	var parts []string
+	for ch, vol := range volumes {
 		label := ch
 		if idx := strings.LastIndex(ch, "."); idx >= 0 {
 			label = ch[idx+1:]
 		}
-		s := fmt.Sprintf("%s:%d%%", label, vol.Level)
+		s := fmt.Sprintf("%s%s:%d%%", prefix, label, vol.Level)
 		if vol.Muted {
 			s += "🔇"
 		}
 		parts = append(parts, s)
 	}
-	if len(parts) == 0 {
-		return "(empty)"
-	}
-	return strings.Join(parts, "  ")
+	return parts
 }
 
 func (m PresetsPane) renderConfirm(w int) string {
@@ -337,6 +507,26 @@ func (m PresetsPane) renderConfirm(w int) string {
 	for _, d := range m.confirmDiffs {
 		rows = append(rows, dimStyle.Render(fmt.Sprintf("    %s  %s → %s", d.Field, d.From, d.To)))
 	}
+	if len(m.confirmFindings) > 0 {
+		rows = append(rows, "")
+		rows = append(rows, lipgloss.NewStyle().Bold(true).Foreground(colorBright).Render("  Pre-flight:"))
+		for _, f := range m.confirmFindings {
+			color, mark := colorWarning, "⚠"
+			if f.Severity == preset.SeverityError {
+				color, mark = colorDanger, "✗"
+			}
+			rows = append(rows, lipgloss.NewStyle().Foreground(color).Render(fmt.Sprintf("    %s %s: %s", mark, f.Field, f.Message)))
+		}
+		if preset.HasErrors(m.confirmFindings) {
+			rows = append(rows, dimStyle.Render("    Errors keep the preset from applying."))
+		}
+	}
+	rows = append(rows, "")
+	check := "[ ]"
+	if m.confirmAtomic {
+		check = "[x]"
+	}
+	rows = append(rows, fmt.Sprintf("  %s Atomic: verify, roll back on failure %s", check, dimStyle.Render("(a)")))
 	rows = append(rows, "")
 
 	// Buttons
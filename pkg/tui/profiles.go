@@ -16,6 +16,9 @@ type flatProfile struct {
 	cardName      string
 	profName      string
 	profDesc      string
+	codec         string // bluetooth codec the profile selects, if any
+	cardCodec     string // codec the card is currently streaming with
+	hasCodecs     bool   // the card has a codec column
 	isActive      bool
 	isAvailable   bool
 	isFirstInCard bool // marks the start of a new card group
@@ -99,12 +102,19 @@ func (m ProfilesPane) flattenProfiles() []flatProfile {
 	var out []flatProfile
 	for _, card := range m.cards {
 		first := true
+		hasCodecs := false
+		for _, prof := range card.Profiles {
+			hasCodecs = hasCodecs || prof.Codec != ""
+		}
 		for _, prof := range card.Profiles {
 			out = append(out, flatProfile{
 				cardIndex:     card.Index,
 				cardName:      card.Name,
 				profName:      prof.Name,
 				profDesc:      prof.Description,
+				codec:         prof.Codec,
+				cardCodec:     card.Codec,
+				hasCodecs:     hasCodecs,
 				isActive:      prof.Name == card.ActiveProfile,
 				isAvailable:   prof.Available,
 				isFirstInCard: first,
@@ -137,15 +147,16 @@ func (m ProfilesPane) View() string {
 
 	// Group profiles by card, render each card as a bordered section.
 	type cardGroup struct {
-		name string
-		rows []string
+		name  string
+		codec string
+		rows  []string
 	}
 	groups := make(map[int]*cardGroup)
 	var order []int
 
 	for i, fp := range m.flat {
 		if fp.isFirstInCard {
-			groups[fp.cardIndex] = &cardGroup{name: fp.cardName}
+			groups[fp.cardIndex] = &cardGroup{name: fp.cardName, codec: fp.cardCodec}
 			order = append(order, fp.cardIndex)
 		}
 		g := groups[fp.cardIndex]
@@ -156,6 +167,9 @@ func (m ProfilesPane) View() string {
 	for _, idx := range order {
 		g := groups[idx]
 		title := sectionTitle(friendlyCardName(g.name))
+		if g.codec != "" {
+			title += "  " + dimStyle.Render("codec: "+g.codec)
+		}
 		content := strings.Join(g.rows, "\n")
 		box := sectionBox.Width(innerW).Render(title + "\n" + content)
 		boxes = append(boxes, box)
@@ -201,13 +215,24 @@ func (m ProfilesPane) renderProfileRow(flatIdx int, fp flatProfile) string {
 		descStr = nameHighlightStyle.Render(desc)
 	}
 
+	// Codec column, only for cards with per-codec profiles
+	codec := ""
+	if fp.hasCodecs {
+		codec = fmt.Sprintf("%-8s ", fp.codec)
+		if fp.codec != "" && fp.codec == fp.cardCodec {
+			codec = connectedStyle.Render(codec)
+		} else {
+			codec = dimStyle.Render(codec)
+		}
+	}
+
 	// Availability badge
 	avail := ""
 	if !fp.isAvailable {
 		avail = "  " + dimStyle.Render("(unavailable)")
 	}
 
-	return fmt.Sprintf("%s%s %s%s", cur, bullet, descStr, avail)
+	return fmt.Sprintf("%s%s %s%s%s", cur, bullet, codec, descStr, avail)
 }
 
 func (m ProfilesPane) ShortHelp() string {
@@ -7,6 +7,7 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"soundctl/pkg/soundctl/alias"
 	"soundctl/pkg/soundctl/audio"
 )
 
@@ -14,19 +15,23 @@ const (
 	sinksSectionOutputs = 0
 	sinksSectionInputs  = 1
 	sinksSectionRoutes  = 2
+	sinksSectionRecords = 3
 )
 
-// SinksPane shows output sinks, input sources, and app routing (Screen 2).
+// SinksPane shows output sinks, input sources, app routing and recording
+// streams (Screen 2).
 type SinksPane struct {
 	sinks             []audio.ShortRecord
 	sources           []audio.ShortRecord
 	sinkInputs        []audio.SinkInput
+	sourceOutputs     []audio.SourceOutput
 	defaultSinkName   string
 	defaultSourceName string
-	section           int // 0=outputs, 1=inputs, 2=routes
+	section           int // 0=outputs, 1=inputs, 2=routes, 3=recording
 	cursor            int
 	width             int
 	height            int
+	aliases           alias.Set
 	au                audio.Service
 	keys              KeyMap
 }
@@ -48,6 +53,7 @@ func (m SinksPane) Update(msg tea.Msg) (SinksPane, tea.Cmd) {
 		m.sinks = msg.Sinks
 		m.sources = msg.Sources
 		m.sinkInputs = msg.SinkInputs
+		m.sourceOutputs = msg.SourceOutputs
 		m.defaultSinkName = msg.DefaultSinkName
 		m.defaultSourceName = msg.DefaultSourceName
 		m.clampCursor()
@@ -93,7 +99,7 @@ func (m SinksPane) handleKey(msg tea.KeyMsg) (SinksPane, tea.Cmd) {
 	case key.Matches(msg, m.keys.Down):
 		if m.cursor < items-1 {
 			m.cursor++
-		} else if m.section < 2 {
+		} else if m.section < sinksSectionRecords {
 			m.section++
 			m.cursor = 0
 		}
@@ -121,6 +127,8 @@ func (m SinksPane) currentItemCount() int {
 		return len(m.sources)
 	case sinksSectionRoutes:
 		return len(m.sinkInputs)
+	case sinksSectionRecords:
+		return len(m.sourceOutputs)
 	}
 	return 0
 }
@@ -173,12 +181,19 @@ func (m SinksPane) View() string {
 		sectionTitle("App Routing") + "\n" + m.renderRoutes(),
 	)
 
+	// ── Recording ──
+	recordBox := sectionBox.Width(innerW).Render(
+		sectionTitle("Recording") + "\n" + m.renderRecording(),
+	)
+
 	return lipgloss.JoinVertical(lipgloss.Left,
 		outputBox,
 		"",
 		inputBox,
 		"",
 		routeBox,
+		"",
+		recordBox,
 	)
 }
 
@@ -201,9 +216,13 @@ func (m SinksPane) renderSinkList(items []audio.ShortRecord, section int, defaul
 			star = defaultStarStyle.Render("★ ")
 		}
 
-		nameStr := nameNormalStyle.Render(item.Name)
+		name := item.Name
+		if a := m.aliases.NameFor(item.Name); a != "" {
+			name = a + dimStyle.Render(" "+item.Name)
+		}
+		nameStr := nameNormalStyle.Render(name)
 		if isCursor {
-			nameStr = nameHighlightStyle.Render(item.Name)
+			nameStr = nameHighlightStyle.Render(name)
 		}
 
 		// Default badge
@@ -260,7 +279,7 @@ func (m SinksPane) renderRoutes() string {
 		}
 
 		arrow := dimStyle.Render(" → ")
-		sinkStr := lipgloss.NewStyle().Foreground(colorAccent).Render(friendlyName(sinkName))
+		sinkStr := lipgloss.NewStyle().Foreground(colorAccent).Render(displayName(m.aliases, sinkName))
 
 		reroute := ""
 		if isCursor {
@@ -272,8 +291,48 @@ func (m SinksPane) renderRoutes() string {
 	return strings.Join(rows, "\n")
 }
 
-// friendlyName strips common pactl name prefixes for display.
-func friendlyName(name string) string {
+func (m SinksPane) renderRecording() string {
+	if len(m.sourceOutputs) == 0 {
+		return dimStyle.Render("  No recording streams")
+	}
+	var rows []string
+	for i, so := range m.sourceOutputs {
+		isCursor := m.section == sinksSectionRecords && i == m.cursor
+
+		cur := "  "
+		if isCursor {
+			cur = cursorStyle.Render("▸ ")
+		}
+
+		appName := so.AppName
+		if appName == "" {
+			appName = fmt.Sprintf("Stream #%d", so.Index)
+		}
+
+		sourceName := so.SourceName
+		if sourceName == "" {
+			sourceName = fmt.Sprintf("source:%d", so.SourceIndex)
+		}
+
+		appStr := nameNormalStyle.Render(fmt.Sprintf("%-16s", appName))
+		if isCursor {
+			appStr = nameHighlightStyle.Render(fmt.Sprintf("%-16s", appName))
+		}
+
+		arrow := dimStyle.Render(" ← ")
+		sourceStr := lipgloss.NewStyle().Foreground(colorAccent).Render(displayName(m.aliases, sourceName))
+
+		rows = append(rows, fmt.Sprintf("%s%s%s%s", cur, appStr, arrow, sourceStr))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// displayName returns the user's alias for a sink or source, or its
+// name with the common pactl prefixes stripped.
+func displayName(aliases alias.Set, name string) string {
+	if a := aliases.NameFor(name); a != "" {
+		return a
+	}
 	for _, prefix := range []string{"alsa_output.", "alsa_input.", "bluez_sink.", "bluez_source."} {
 		if strings.HasPrefix(name, prefix) {
 			return strings.TrimPrefix(name, prefix)